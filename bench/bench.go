@@ -0,0 +1,226 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultConcurrency = 10
+	DefaultDuration    = 30 * time.Second
+
+	// maxErrorSamples limits the number of the distinct error messages kept in the report
+	maxErrorSamples = 10
+)
+
+// Task runs one operation against the middleware, worker is the index of the goroutine,
+// seq is the global sequence number of the operation, it could be used to generate the payload
+type Task func(ctx context.Context, worker int, seq int64) error
+
+type Config struct {
+	// Concurrency is the number of the goroutines which run the task
+	Concurrency int
+	// Duration is the time of the test, 0 means the test stops after Requests operations
+	Duration time.Duration
+	// Requests is the total number of the operations, 0 means the test stops after Duration
+	Requests int64
+	// Warmup is the time before the measurement, the operations during the warmup are not reported
+	Warmup time.Duration
+}
+
+// NewConfig returns a new Config
+func NewConfig(concurrency int, duration time.Duration, requests int64) Config {
+	return Config{
+		Concurrency: concurrency,
+		Duration:    duration,
+		Requests:    requests,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with default values
+func NewConfigWithDefault() Config {
+	return NewConfig(DefaultConcurrency, DefaultDuration, constant.ZeroInt)
+}
+
+// Validate validates the config
+func (cfg *Config) Validate() (bool, error) {
+	if cfg.Concurrency <= constant.ZeroInt {
+		return false, errors.New("concurrency should be larger than 0")
+	}
+	if cfg.Duration < constant.ZeroInt || cfg.Requests < constant.ZeroInt || cfg.Warmup < constant.ZeroInt {
+		return false, errors.New("duration, requests and warmup should not be smaller than 0")
+	}
+	if cfg.Duration == constant.ZeroInt && cfg.Requests == constant.ZeroInt {
+		return false, errors.New("either duration or requests should be larger than 0")
+	}
+
+	return true, nil
+}
+
+// Report is the result of the test
+type Report struct {
+	Total      int64
+	Errors     int64
+	Duration   time.Duration
+	Throughput float64
+	Min        time.Duration
+	Max        time.Duration
+	Mean       time.Duration
+	P50        time.Duration
+	P90        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	// ErrorSamples are the distinct error messages and their counts, at most 10 messages are kept
+	ErrorSamples map[string]int64
+}
+
+// String returns the readable report
+func (r *Report) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("requests: %d, errors: %d, duration: %s, throughput: %.2f/s\n",
+		r.Total, r.Errors, r.Duration.String(), r.Throughput))
+	sb.WriteString(fmt.Sprintf("latency min: %s, mean: %s, p50: %s, p90: %s, p95: %s, p99: %s, max: %s",
+		r.Min.String(), r.Mean.String(), r.P50.String(), r.P90.String(), r.P95.String(), r.P99.String(), r.Max.String()))
+	for msg, count := range r.ErrorSamples {
+		sb.WriteString(fmt.Sprintf("\nerror: %s, count: %d", msg, count))
+	}
+
+	return sb.String()
+}
+
+type recorder struct {
+	mutex        sync.Mutex
+	latencies    []time.Duration
+	errors       int64
+	errorSamples map[string]int64
+}
+
+// record records the latency and the error of one operation
+func (r *recorder) record(latency time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.latencies = append(r.latencies, latency)
+	if err == nil {
+		return
+	}
+	r.errors++
+	msg := err.Error()
+	_, ok := r.errorSamples[msg]
+	if ok || len(r.errorSamples) < maxErrorSamples {
+		r.errorSamples[msg]++
+	}
+}
+
+// Run runs the task with given config and reports the latencies and the throughput,
+// the test stops when the duration elapses, the requests are completed or the context is done
+func Run(ctx context.Context, config Config, task Task) (*Report, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+
+	if config.Warmup > constant.ZeroInt {
+		warmupCtx, cancel := context.WithTimeout(ctx, config.Warmup)
+		run(warmupCtx, config.Concurrency, constant.ZeroInt, task, nil)
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	runCtx := ctx
+	if config.Duration > constant.ZeroInt {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	r := &recorder{errorSamples: make(map[string]int64)}
+	start := time.Now()
+	run(runCtx, config.Concurrency, config.Requests, task, r)
+
+	return newReport(r, time.Since(start)), nil
+}
+
+// run starts the workers and waits for them to complete, if r is nil, nothing will be recorded
+func run(ctx context.Context, concurrency int, requests int64, task Task, r *recorder) {
+	var (
+		wg  sync.WaitGroup
+		seq int64
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				n := atomic.AddInt64(&seq, 1)
+				if requests > constant.ZeroInt && n > requests {
+					return
+				}
+
+				start := time.Now()
+				err := task(ctx, worker, n)
+				// the operation interrupted by the end of the test is not counted
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+				if r != nil {
+					r.record(time.Since(start), err)
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// newReport calculates the report with the recorded latencies
+func newReport(r *recorder, duration time.Duration) *Report {
+	report := &Report{
+		Total:        int64(len(r.latencies)),
+		Errors:       r.errors,
+		Duration:     duration,
+		ErrorSamples: r.errorSamples,
+	}
+	if report.Total == constant.ZeroInt {
+		return report
+	}
+
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	var sum time.Duration
+	for _, latency := range r.latencies {
+		sum += latency
+	}
+
+	report.Throughput = float64(report.Total) / duration.Seconds()
+	report.Min = r.latencies[constant.ZeroInt]
+	report.Max = r.latencies[len(r.latencies)-1]
+	report.Mean = sum / time.Duration(report.Total)
+	report.P50 = percentile(r.latencies, 50)
+	report.P90 = percentile(r.latencies, 90)
+	report.P95 = percentile(r.latencies, 95)
+	report.P99 = percentile(r.latencies, 99)
+
+	return report
+}
+
+// percentile returns the p-th percentile of the sorted latencies with the nearest-rank method
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
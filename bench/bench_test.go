@@ -0,0 +1,66 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	asst := assert.New(t)
+
+	var calls int64
+	task := func(ctx context.Context, worker int, seq int64) error {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(time.Millisecond)
+		if seq%10 == 0 {
+			return errors.New("test")
+		}
+		return nil
+	}
+
+	report, err := Run(context.Background(), NewConfig(4, 0, 100), task)
+	asst.Nil(err, "test Run() failed")
+	asst.Equal(int64(100), report.Total, "test Run() failed")
+	asst.Equal(int64(10), report.Errors, "test Run() failed")
+	asst.Equal(int64(10), report.ErrorSamples["test"], "test Run() failed")
+	asst.True(report.Min <= report.P50 && report.P50 <= report.P99 && report.P99 <= report.Max, "test Run() failed")
+	asst.True(report.Throughput > 0, "test Run() failed")
+	t.Log(report.String())
+
+	config := NewConfig(2, 50*time.Millisecond, 0)
+	config.Warmup = 20 * time.Millisecond
+	report, err = Run(context.Background(), config, task)
+	asst.Nil(err, "test Run() failed")
+	asst.True(report.Total > 0, "test Run() failed")
+	asst.True(atomic.LoadInt64(&calls) > 100+report.Total, "test Run() failed")
+
+	_, err = Run(context.Background(), NewConfig(0, time.Second, 0), task)
+	asst.NotNil(err, "test Run() failed")
+}
+
+func TestNewHTTPTask(t *testing.T) {
+	asst := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	report, err := Run(context.Background(), NewConfig(2, 0, 20), NewHTTPTask(nil, http.MethodPost, server.URL, RandomPayload(16)))
+	asst.Nil(err, "test NewHTTPTask() failed")
+	asst.Equal(int64(20), report.Total, "test NewHTTPTask() failed")
+	asst.Equal(int64(0), report.Errors, "test NewHTTPTask() failed")
+
+	report, err = Run(context.Background(), NewConfig(1, 0, 5), NewHTTPTask(nil, http.MethodGet, server.URL+"/error", nil))
+	asst.Nil(err, "test NewHTTPTask() failed")
+	asst.Equal(int64(5), report.Errors, "test NewHTTPTask() failed")
+}
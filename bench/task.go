@@ -0,0 +1,112 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+const payloadLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// ArgsGenerator generates the arguments of the command of given sequence number
+type ArgsGenerator func(seq int64) []interface{}
+
+// PayloadGenerator generates the payload of given sequence number
+type PayloadGenerator func(seq int64) []byte
+
+// RandomPayload returns a PayloadGenerator which generates random alphanumeric payload of given size
+func RandomPayload(size int) PayloadGenerator {
+	return func(seq int64) []byte {
+		payload := make([]byte, size)
+		for i := range payload {
+			payload[i] = payloadLetters[rand.Intn(len(payloadLetters))]
+		}
+
+		return payload
+	}
+}
+
+// FixedPayload returns a PayloadGenerator which always returns given payload
+func FixedPayload(payload []byte) PayloadGenerator {
+	return func(seq int64) []byte {
+		return payload
+	}
+}
+
+// NewPoolTask returns a Task which gets a connection from the pool and executes the command,
+// it works with the pools of mysql, clickhouse and the other middlewares which implement middleware.Pool,
+// gen could be nil if the command has no placeholders
+func NewPoolTask(pool middleware.Pool, command string, gen ArgsGenerator) Task {
+	return func(ctx context.Context, worker int, seq int64) error {
+		conn, err := pool.Get()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = conn.Close() }()
+
+		var args []interface{}
+		if gen != nil {
+			args = gen(seq)
+		}
+		_, err = conn.ExecuteContext(ctx, command, args...)
+
+		return err
+	}
+}
+
+// NewKafkaTask returns a Task which sends the message to the topic and waits for the acknowledgement,
+// the sync producer could be created with sarama.NewSyncProducerFromClient() and the client of kafka.AsyncProducer
+func NewKafkaTask(producer sarama.SyncProducer, topic string, gen PayloadGenerator) Task {
+	return func(ctx context.Context, worker int, seq int64) error {
+		_, _, err := producer.SendMessage(&sarama.ProducerMessage{
+			Topic: topic,
+			Value: sarama.ByteEncoder(gen(seq)),
+		})
+
+		return err
+	}
+}
+
+// NewHTTPTask returns a Task which sends the request to the url, the response with non-2xx status code is treated as an error,
+// gen could be nil if the request has no body
+func NewHTTPTask(client *http.Client, method, url string, gen PayloadGenerator) Task {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, worker int, seq int64) error {
+		var body io.Reader
+		if gen != nil {
+			body = bytes.NewReader(gen(seq))
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		// read the whole body, so that the connection could be reused
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return errors.New(fmt.Sprintf("unexpected status code %d", resp.StatusCode))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,355 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/metrics"
+)
+
+const (
+	DefaultWindowSize           = 100
+	DefaultMinimumCalls         = 10
+	DefaultFailureRateThreshold = 50
+	DefaultOpenTimeout          = 30 * time.Second
+	DefaultHalfOpenMaxCalls     = 5
+)
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+var (
+	// ErrOpen is returned when the breaker is open
+	ErrOpen = errors.New("circuit breaker is open")
+	// ErrTooManyCalls is returned when the breaker is half-open and the trial calls are used up
+	ErrTooManyCalls = errors.New("circuit breaker is half-open and too many calls are in flight")
+)
+
+// State is the state of the breaker
+type State int
+
+// String returns the string form of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// StateChangeFunc is called when the state of the breaker changes, it is called with the lock held,
+// so it should return quickly and must not call the breaker
+type StateChangeFunc func(name string, from, to State)
+
+type Config struct {
+	// WindowSize is the number of the most recent calls used to calculate the rates in closed state
+	WindowSize int
+	// MinimumCalls is the minimum number of calls in the window before the rates are calculated
+	MinimumCalls int
+	// FailureRateThreshold is the failure percentage which opens the breaker
+	FailureRateThreshold float64
+	// SlowCallDurationThreshold is the duration above which the calls are considered slow, 0 disables slow call detection
+	SlowCallDurationThreshold time.Duration
+	// SlowCallRateThreshold is the slow call percentage which opens the breaker
+	SlowCallRateThreshold float64
+	// OpenTimeout is how long the breaker stays open before it becomes half-open
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of trial calls in half-open state,
+	// the breaker closes after all of them succeeded and opens again once any of them failed
+	HalfOpenMaxCalls int
+	// IsFailure reports whether the error should be counted as a failure, if it is nil, all non-nil errors are failures
+	IsFailure func(err error) bool
+}
+
+// NewConfig returns a new Config, slow call detection is disabled
+func NewConfig(windowSize, minimumCalls int, failureRateThreshold float64, openTimeout time.Duration, halfOpenMaxCalls int) Config {
+	return Config{
+		WindowSize:           windowSize,
+		MinimumCalls:         minimumCalls,
+		FailureRateThreshold: failureRateThreshold,
+		OpenTimeout:          openTimeout,
+		HalfOpenMaxCalls:     halfOpenMaxCalls,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with default values
+func NewConfigWithDefault() Config {
+	return NewConfig(DefaultWindowSize, DefaultMinimumCalls, DefaultFailureRateThreshold, DefaultOpenTimeout, DefaultHalfOpenMaxCalls)
+}
+
+// Validate validates breaker config
+func (cfg *Config) Validate() (bool, error) {
+	if cfg.WindowSize <= constant.ZeroInt {
+		return false, errors.New(fmt.Sprintf("window size must be positive. window size: %d", cfg.WindowSize))
+	}
+	if cfg.MinimumCalls <= constant.ZeroInt || cfg.MinimumCalls > cfg.WindowSize {
+		return false, errors.New(fmt.Sprintf("minimum calls must be positive and not larger than window size. minimum calls: %d, window size: %d",
+			cfg.MinimumCalls, cfg.WindowSize))
+	}
+	if cfg.FailureRateThreshold <= 0 || cfg.FailureRateThreshold > 100 {
+		return false, errors.New(fmt.Sprintf("failure rate threshold must be in (0, 100]. failure rate threshold: %f", cfg.FailureRateThreshold))
+	}
+	if cfg.SlowCallDurationThreshold > 0 && (cfg.SlowCallRateThreshold <= 0 || cfg.SlowCallRateThreshold > 100) {
+		return false, errors.New(fmt.Sprintf("slow call rate threshold must be in (0, 100]. slow call rate threshold: %f", cfg.SlowCallRateThreshold))
+	}
+	if cfg.OpenTimeout <= constant.ZeroInt {
+		return false, errors.New(fmt.Sprintf("open timeout must be positive. open timeout: %s", cfg.OpenTimeout.String()))
+	}
+	if cfg.HalfOpenMaxCalls <= constant.ZeroInt {
+		return false, errors.New(fmt.Sprintf("half-open max calls must be positive. half-open max calls: %d", cfg.HalfOpenMaxCalls))
+	}
+
+	return true, nil
+}
+
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
+// Breaker is a circuit breaker with closed, open and half-open states,
+// it opens when the failure rate or the slow call rate of the recent calls exceeds the threshold,
+// rejects the calls while open, and lets a few trial calls through after the open timeout to decide whether to close
+type Breaker struct {
+	name   string
+	config Config
+
+	mutex      sync.Mutex
+	state      State
+	generation uint64
+	openedAt   time.Time
+	// window is a ring buffer of the recent outcomes in closed state
+	window   []outcome
+	next     int
+	count    int
+	failures int
+	slows    int
+	// halfOpenCalls is the number of permitted trial calls, halfOpenSuccesses is the number of succeeded ones
+	halfOpenCalls     int
+	halfOpenSuccesses int
+
+	listeners []StateChangeFunc
+}
+
+// NewBreaker returns a new *Breaker
+func NewBreaker(name string, config Config) (*Breaker, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+
+	b := &Breaker{
+		name:   name,
+		config: config,
+		state:  StateClosed,
+		window: make([]outcome, config.WindowSize),
+	}
+	metrics.SetBreakerState(name, int(StateClosed))
+
+	return b, nil
+}
+
+// Name returns the name of the breaker
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// OnStateChange registers a callback which will be called when the state changes
+func (b *Breaker) OnStateChange(f StateChangeFunc) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.listeners = append(b.listeners, f)
+}
+
+// State returns the current state
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.checkOpenTimeout(time.Now())
+
+	return b.state
+}
+
+// Reset resets the breaker to closed state and clears the recorded calls
+func (b *Breaker) Reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.setState(StateClosed, time.Now())
+}
+
+// Allow checks if a call is permitted, if permitted, the caller must call done with the result of the call
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	b.checkOpenTimeout(now)
+
+	switch b.state {
+	case StateOpen:
+		metrics.CountBreakerCall(b.name, metrics.BreakerResultRejected)
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.halfOpenCalls >= b.config.HalfOpenMaxCalls {
+			metrics.CountBreakerCall(b.name, metrics.BreakerResultRejected)
+			return nil, ErrTooManyCalls
+		}
+		b.halfOpenCalls++
+	}
+
+	generation := b.generation
+	start := now
+
+	return func(err error) {
+		b.onResult(generation, err, time.Since(start))
+	}, nil
+}
+
+// Execute runs fn if the breaker permits, and records the result
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	done(err)
+
+	return err
+}
+
+// isFailure reports whether the error is a failure
+func (b *Breaker) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if b.config.IsFailure == nil {
+		return true
+	}
+
+	return b.config.IsFailure(err)
+}
+
+// onResult records the result of the call, the results of the calls permitted in a previous state are ignored
+func (b *Breaker) onResult(generation uint64, err error, duration time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	o := outcome{
+		failed: b.isFailure(err),
+		slow:   b.config.SlowCallDurationThreshold > 0 && duration > b.config.SlowCallDurationThreshold,
+	}
+	switch {
+	case o.failed:
+		metrics.CountBreakerCall(b.name, metrics.BreakerResultFailure)
+	case o.slow:
+		metrics.CountBreakerCall(b.name, metrics.BreakerResultSlow)
+	default:
+		metrics.CountBreakerCall(b.name, metrics.BreakerResultSuccess)
+	}
+
+	if generation != b.generation {
+		return
+	}
+
+	now := time.Now()
+	switch b.state {
+	case StateClosed:
+		b.record(o)
+		if b.count >= b.config.MinimumCalls && b.exceedsThreshold() {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		if o.failed || o.slow {
+			b.setState(StateOpen, now)
+			return
+		}
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.config.HalfOpenMaxCalls {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+// record puts the outcome into the window
+func (b *Breaker) record(o outcome) {
+	if b.count == len(b.window) {
+		old := b.window[b.next]
+		if old.failed {
+			b.failures--
+		}
+		if old.slow {
+			b.slows--
+		}
+	} else {
+		b.count++
+	}
+
+	b.window[b.next] = o
+	b.next = (b.next + 1) % len(b.window)
+	if o.failed {
+		b.failures++
+	}
+	if o.slow {
+		b.slows++
+	}
+}
+
+// exceedsThreshold reports whether the failure rate or the slow call rate exceeds the threshold
+func (b *Breaker) exceedsThreshold() bool {
+	if float64(b.failures)*100/float64(b.count) >= b.config.FailureRateThreshold {
+		return true
+	}
+
+	return b.config.SlowCallDurationThreshold > 0 &&
+		float64(b.slows)*100/float64(b.count) >= b.config.SlowCallRateThreshold
+}
+
+// checkOpenTimeout turns the breaker to half-open state if it had been open for long enough
+func (b *Breaker) checkOpenTimeout(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(StateHalfOpen, now)
+	}
+}
+
+// setState changes the state, clears the counts and notifies the listeners, it must be called with the lock held
+func (b *Breaker) setState(state State, now time.Time) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.next = constant.ZeroInt
+	b.count = constant.ZeroInt
+	b.failures = constant.ZeroInt
+	b.slows = constant.ZeroInt
+	b.halfOpenCalls = constant.ZeroInt
+	b.halfOpenSuccesses = constant.ZeroInt
+	if state == StateOpen {
+		b.openedAt = now
+	}
+
+	if from == state {
+		return
+	}
+
+	log.Infof("circuit breaker state changed. name: %s, from: %s, to: %s", b.name, from.String(), state.String())
+	metrics.SetBreakerState(b.name, int(state))
+	for _, listener := range b.listeners {
+		listener(b.name, from, state)
+	}
+}
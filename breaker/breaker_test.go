@@ -0,0 +1,87 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("test")
+
+func TestBreaker_Execute(t *testing.T) {
+	asst := assert.New(t)
+
+	config := NewConfig(4, 2, 50, 20*time.Millisecond, 2)
+	config.MinimumCalls = 5
+	_, err := NewBreaker("test", config)
+	asst.NotNil(err, "test NewBreaker() failed")
+
+	config.MinimumCalls = 2
+	b, err := NewBreaker("test", config)
+	asst.Nil(err, "test NewBreaker() failed")
+	var changes []State
+	b.OnStateChange(func(name string, from, to State) { changes = append(changes, to) })
+
+	succeed := func() error { return nil }
+	fail := func() error { return errTest }
+
+	// 1 failure out of 3 calls does not open the breaker
+	asst.Nil(b.Execute(succeed), "test Execute() failed")
+	asst.Nil(b.Execute(succeed), "test Execute() failed")
+	asst.Equal(errTest, b.Execute(fail), "test Execute() failed")
+	asst.Equal(StateClosed, b.State(), "test Execute() failed")
+	// 2 failures out of the recent 4 calls opens the breaker
+	asst.Equal(errTest, b.Execute(fail), "test Execute() failed")
+	asst.Equal(StateOpen, b.State(), "test Execute() failed")
+	asst.Equal(ErrOpen, b.Execute(succeed), "test Execute() failed")
+
+	// the breaker becomes half-open after open timeout, and only permits limited trial calls
+	time.Sleep(30 * time.Millisecond)
+	asst.Equal(StateHalfOpen, b.State(), "test State() failed")
+	done1, err := b.Allow()
+	asst.Nil(err, "test Allow() failed")
+	done2, err := b.Allow()
+	asst.Nil(err, "test Allow() failed")
+	_, err = b.Allow()
+	asst.Equal(ErrTooManyCalls, err, "test Allow() failed")
+	done1(nil)
+	done2(nil)
+	asst.Equal(StateClosed, b.State(), "test Allow() failed")
+
+	// any failure in half-open state opens the breaker again
+	asst.Equal(errTest, b.Execute(fail), "test Execute() failed")
+	asst.Equal(errTest, b.Execute(fail), "test Execute() failed")
+	time.Sleep(30 * time.Millisecond)
+	asst.Equal(errTest, b.Execute(fail), "test Execute() failed")
+	asst.Equal(StateOpen, b.State(), "test Execute() failed")
+
+	b.Reset()
+	asst.Equal(StateClosed, b.State(), "test Reset() failed")
+	asst.Equal([]State{StateOpen, StateHalfOpen, StateClosed, StateOpen, StateHalfOpen, StateOpen, StateClosed}, changes, "test OnStateChange() failed")
+}
+
+func TestBreaker_SlowCall(t *testing.T) {
+	asst := assert.New(t)
+
+	config := NewConfig(2, 2, 100, time.Second, 1)
+	config.SlowCallDurationThreshold = time.Millisecond
+	config.SlowCallRateThreshold = 100
+	config.IsFailure = func(err error) bool { return err != errTest }
+	b, err := NewBreaker("test", config)
+	asst.Nil(err, "test NewBreaker() failed")
+
+	// errTest is not counted as a failure
+	asst.Equal(errTest, b.Execute(func() error { return errTest }), "test Execute() failed")
+	asst.Equal(errTest, b.Execute(func() error { return errTest }), "test Execute() failed")
+	asst.Equal(StateClosed, b.State(), "test Execute() failed")
+
+	slow := func() error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}
+	asst.Nil(b.Execute(slow), "test Execute() failed")
+	asst.Nil(b.Execute(slow), "test Execute() failed")
+	asst.Equal(StateOpen, b.State(), "test Execute() failed")
+}
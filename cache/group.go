@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"sync"
+)
+
+type call struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// group makes sure that the function of the same key is executed only once at the same time,
+// the duplicate callers wait for the original one and share the result
+type group struct {
+	mutex sync.Mutex
+	calls map[string]*call
+}
+
+// do executes fn and returns the result, if there is an execution of the same key in flight,
+// it waits for that execution and returns its result instead
+func (g *group) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	c, ok := g.calls[key]
+	if ok {
+		g.mutex.Unlock()
+		c.wg.Wait()
+
+		return c.value, c.err
+	}
+
+	c = &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		c.wg.Done()
+	}()
+	c.value, c.err = fn()
+
+	return c.value, c.err
+}
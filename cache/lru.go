@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+// LoaderFunc loads the value of given key when it is missing in the cache
+type LoaderFunc func(ctx context.Context, key string) (interface{}, error)
+
+type entry struct {
+	key      string
+	value    interface{}
+	expireAt time.Time
+}
+
+// isExpired returns if the entry had expired, the entry without expiration never expires
+func (e *entry) isExpired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// LRU is a thread-safe in-memory cache with the least recently used eviction and per-entry ttl
+type LRU struct {
+	mutex      sync.Mutex
+	capacity   int
+	defaultTTL time.Duration
+	ll         *list.List
+	items      map[string]*list.Element
+	group      group
+}
+
+// NewLRU returns a new *LRU, capacity is the max number of the entries, 0 means no limit,
+// defaultTTL is used by Set() and GetOrLoad(), 0 means the entries never expire
+func NewLRU(capacity int, defaultTTL time.Duration) *LRU {
+	return &LRU{
+		capacity:   capacity,
+		defaultTTL: defaultTTL,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value of given key, the expired entry will be removed
+func (l *LRU) Get(key string) (interface{}, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if e.isExpired(time.Now()) {
+		l.removeElement(elem)
+		return nil, false
+	}
+	l.ll.MoveToFront(elem)
+
+	return e.value, true
+}
+
+// Set sets the value of given key with the default ttl
+func (l *LRU) Set(key string, value interface{}) {
+	l.SetWithTTL(key, value, l.defaultTTL)
+}
+
+// SetWithTTL sets the value of given key with given ttl, 0 means the entry never expires,
+// if the cache is full, the least recently used entry will be evicted
+func (l *LRU) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	var expireAt time.Time
+	if ttl > constant.ZeroInt {
+		expireAt = time.Now().Add(ttl)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elem, ok := l.items[key]
+	if ok {
+		e := elem.Value.(*entry)
+		e.value = value
+		e.expireAt = expireAt
+		l.ll.MoveToFront(elem)
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&entry{key: key, value: value, expireAt: expireAt})
+	if l.capacity > constant.ZeroInt && l.ll.Len() > l.capacity {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+// Delete deletes the entry of given key
+func (l *LRU) Delete(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elem, ok := l.items[key]
+	if ok {
+		l.removeElement(elem)
+	}
+}
+
+// Len returns the number of the entries, including the expired ones which had not been removed yet
+func (l *LRU) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.ll.Len()
+}
+
+// Purge removes all the entries
+func (l *LRU) Purge() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.ll.Init()
+	l.items = make(map[string]*list.Element)
+}
+
+// RemoveExpired removes all the expired entries, it returns the number of the removed entries
+func (l *LRU) RemoveExpired() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	removed := constant.ZeroInt
+	for elem := l.ll.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*entry).isExpired(now) {
+			l.removeElement(elem)
+			removed++
+		}
+		elem = prev
+	}
+
+	return removed
+}
+
+// GetOrLoad returns the value of given key, if it is missing, it calls loader and caches the value with the default ttl,
+// the concurrent calls of the same key share one loading, the error will not be cached
+func (l *LRU) GetOrLoad(ctx context.Context, key string, loader LoaderFunc) (interface{}, error) {
+	value, ok := l.Get(key)
+	if ok {
+		return value, nil
+	}
+
+	return l.group.do(key, func() (interface{}, error) {
+		// the value may have been loaded by the previous call which finished just now
+		value, ok := l.Get(key)
+		if ok {
+			return value, nil
+		}
+
+		value, err := loader(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		l.Set(key, value)
+
+		return value, nil
+	})
+}
+
+// removeElement removes the element from the list and the map, it must be called with lock held
+func (l *LRU) removeElement(elem *list.Element) {
+	l.ll.Remove(elem)
+	delete(l.items, elem.Value.(*entry).key)
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/locker"
+)
+
+func TestLRU_Get(t *testing.T) {
+	asst := assert.New(t)
+
+	l := NewLRU(2, 0)
+	l.Set("a", 1)
+	l.Set("b", 2)
+	_, ok := l.Get("a")
+	asst.True(ok, "test Get() failed")
+	// b is the least recently used one and will be evicted
+	l.Set("c", 3)
+	_, ok = l.Get("b")
+	asst.False(ok, "test Set() failed")
+	asst.Equal(2, l.Len(), "test Set() failed")
+
+	l.SetWithTTL("a", 10, 10*time.Millisecond)
+	value, ok := l.Get("a")
+	asst.True(ok, "test SetWithTTL() failed")
+	asst.Equal(10, value, "test SetWithTTL() failed")
+	time.Sleep(20 * time.Millisecond)
+	_, ok = l.Get("a")
+	asst.False(ok, "test SetWithTTL() failed")
+
+	l.SetWithTTL("d", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	asst.Equal(1, l.RemoveExpired(), "test RemoveExpired() failed")
+	l.Delete("c")
+	asst.Equal(0, l.Len(), "test Delete() failed")
+}
+
+func TestLRU_GetOrLoad(t *testing.T) {
+	asst := assert.New(t)
+
+	l := NewLRU(10, time.Minute)
+	var loads int32
+	loader := func(ctx context.Context, key string) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(10 * time.Millisecond)
+		return key + "-value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := l.GetOrLoad(context.Background(), "a", loader)
+			asst.Nil(err, "test GetOrLoad() failed")
+			asst.Equal("a-value", value, "test GetOrLoad() failed")
+		}()
+	}
+	wg.Wait()
+	asst.Equal(int32(1), atomic.LoadInt32(&loads), "test GetOrLoad() failed")
+
+	// the error will not be cached
+	_, err := l.GetOrLoad(context.Background(), "b", func(ctx context.Context, key string) (interface{}, error) {
+		return nil, errors.New("test")
+	})
+	asst.NotNil(err, "test GetOrLoad() failed")
+	_, ok := l.Get("b")
+	asst.False(ok, "test GetOrLoad() failed")
+}
+
+func TestTieredCache_GetOrLoad(t *testing.T) {
+	asst := assert.New(t)
+
+	data := make(map[string]string)
+	evaler := func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+		switch script {
+		case redisGetScript:
+			v, ok := data[keys[0]]
+			if !ok {
+				return []interface{}{int64(0)}, nil
+			}
+			return []interface{}{int64(1), v}, nil
+		case redisSetScript:
+			data[keys[0]] = args[0].(string)
+			return "OK", nil
+		default:
+			delete(data, keys[0])
+			return int64(1), nil
+		}
+	}
+	type value struct {
+		Name string `json:"name"`
+	}
+	codec := NewJSONCodec(func() interface{} { return &value{} })
+	remote := NewRedisTier(locker.RedisEvalFunc(evaler), "cache:")
+
+	tc1 := NewTieredCache(NewLRU(10, time.Minute), remote, codec, time.Minute)
+	v, err := tc1.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (interface{}, error) {
+		return &value{Name: key}, nil
+	})
+	asst.Nil(err, "test GetOrLoad() failed")
+	asst.Equal("a", v.(*value).Name, "test GetOrLoad() failed")
+	asst.Equal(`{"name":"a"}`, data["cache:a"], "test GetOrLoad() failed")
+
+	// the other process gets the value from redis without loading
+	tc2 := NewTieredCache(NewLRU(10, time.Minute), remote, codec, time.Minute)
+	v, err = tc2.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (interface{}, error) {
+		return nil, errors.New("should not be loaded")
+	})
+	asst.Nil(err, "test GetOrLoad() failed")
+	asst.Equal("a", v.(*value).Name, "test GetOrLoad() failed")
+
+	asst.Nil(tc2.Delete(context.Background(), "a"), "test Delete() failed")
+	asst.Equal(0, len(data), "test Delete() failed")
+}
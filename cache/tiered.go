@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/locker"
+)
+
+const (
+	// redisGetScript returns {1, value} if the key exists, otherwise, it returns {0},
+	// so that the missing key could be distinguished without depending on the nil error of the redis client
+	redisGetScript = `local v = redis.call('get', KEYS[1])
+if v then
+	return {1, v}
+end
+return {0}`
+	redisSetScript = `if tonumber(ARGV[2]) > 0 then
+	return redis.call('set', KEYS[1], ARGV[1], 'PX', ARGV[2])
+end
+return redis.call('set', KEYS[1], ARGV[1])`
+	redisDeleteScript = `return redis.call('del', KEYS[1])`
+)
+
+// Codec converts the values to bytes which could be saved in the remote tier
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+type jsonCodec struct {
+	newValue func() interface{}
+}
+
+// NewJSONCodec returns a Codec which uses json, newValue should return a pointer which the data will be unmarshalled to,
+// for example: func() interface{} { return &Table{} }
+func NewJSONCodec(newValue func() interface{}) Codec {
+	return &jsonCodec{newValue: newValue}
+}
+
+// Marshal marshals the value to json
+func (jc *jsonCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal unmarshals the json data to a new value
+func (jc *jsonCodec) Unmarshal(data []byte) (interface{}, error) {
+	value := jc.newValue()
+	err := json.Unmarshal(data, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// RedisTier is the remote tier which saves the values in redis
+type RedisTier struct {
+	evaler locker.RedisEvaler
+	prefix string
+}
+
+// NewRedisTier returns a new *RedisTier, all the keys will be prefixed with given prefix
+func NewRedisTier(evaler locker.RedisEvaler, prefix string) *RedisTier {
+	return &RedisTier{
+		evaler: evaler,
+		prefix: prefix,
+	}
+}
+
+// Get returns the data of given key
+func (rt *RedisTier) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	result, err := rt.evaler.Eval(ctx, redisGetScript, []string{rt.prefix + key})
+	if err != nil {
+		return nil, false, err
+	}
+
+	reply, ok := result.([]interface{})
+	if !ok || len(reply) == constant.ZeroInt {
+		return nil, false, errors.New(fmt.Sprintf("redis script should return an array. actual: %T", result))
+	}
+	if exists, _ := reply[constant.ZeroInt].(int64); exists == constant.ZeroInt || len(reply) < 2 {
+		return nil, false, nil
+	}
+
+	switch v := reply[1].(type) {
+	case string:
+		return []byte(v), true, nil
+	case []byte:
+		return v, true, nil
+	default:
+		return nil, false, errors.New(fmt.Sprintf("redis value should be a string. actual: %T", v))
+	}
+}
+
+// Set sets the data of given key with ttl, 0 means the key never expires
+func (rt *RedisTier) Set(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	_, err := rt.evaler.Eval(ctx, redisSetScript, []string{rt.prefix + key}, string(data), ttl.Milliseconds())
+
+	return err
+}
+
+// Delete deletes given key
+func (rt *RedisTier) Delete(ctx context.Context, key string) error {
+	_, err := rt.evaler.Eval(ctx, redisDeleteScript, []string{rt.prefix + key})
+
+	return err
+}
+
+// TieredCache looks up the local lru first, then the redis tier, and loads the value at last,
+// the redis tier shares the values among the processes, and the failures of redis only degrade to loading
+type TieredCache struct {
+	local     *LRU
+	remote    *RedisTier
+	codec     Codec
+	remoteTTL time.Duration
+	group     group
+}
+
+// NewTieredCache returns a new *TieredCache, remoteTTL is the ttl of the values in the redis tier
+func NewTieredCache(local *LRU, remote *RedisTier, codec Codec, remoteTTL time.Duration) *TieredCache {
+	return &TieredCache{
+		local:     local,
+		remote:    remote,
+		codec:     codec,
+		remoteTTL: remoteTTL,
+	}
+}
+
+// Delete deletes given key from both tiers
+func (tc *TieredCache) Delete(ctx context.Context, key string) error {
+	tc.local.Delete(key)
+
+	return tc.remote.Delete(ctx, key)
+}
+
+// GetOrLoad returns the value of given key, the concurrent calls of the same key share one lookup
+func (tc *TieredCache) GetOrLoad(ctx context.Context, key string, loader LoaderFunc) (interface{}, error) {
+	value, ok := tc.local.Get(key)
+	if ok {
+		return value, nil
+	}
+
+	return tc.group.do(key, func() (interface{}, error) {
+		value, ok := tc.local.Get(key)
+		if ok {
+			return value, nil
+		}
+
+		data, ok, err := tc.remote.Get(ctx, key)
+		if err != nil {
+			log.Warnf("get value from redis tier failed, will load it. key: %s. %s", key, err.Error())
+		}
+		if ok {
+			value, err = tc.codec.Unmarshal(data)
+			if err == nil {
+				tc.local.Set(key, value)
+				return value, nil
+			}
+			log.Warnf("unmarshal value from redis tier failed, will load it. key: %s. %s", key, err.Error())
+		}
+
+		value, err = loader(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		tc.local.Set(key, value)
+
+		data, err = tc.codec.Marshal(value)
+		if err == nil {
+			err = tc.remote.Set(ctx, key, data, tc.remoteTTL)
+		}
+		if err != nil {
+			log.Warnf("set value to redis tier failed. key: %s. %s", key, err.Error())
+		}
+
+		return value, nil
+	})
+}
@@ -10,6 +10,8 @@ type ErrMessage struct {
 	Header  string
 	ErrCode int
 	Raw     string
+	// cause is the original error which is wrapped by Wrap()
+	cause error
 }
 
 // NewErrMessage is an exported alias of newErrMessage() function
@@ -59,6 +61,31 @@ func (e *ErrMessage) Specify(ins ...interface{}) {
 	e.Raw = fmt.Sprintf(e.Raw, ins...)
 }
 
+// Wrap returns a new *ErrMessage which specifies place holders with the message of given error
+// and keeps the error as the cause, so that the original error could still be checked by errors.As()
+func (e *ErrMessage) Wrap(err error) *ErrMessage {
+	c := e.Renew(err.Error())
+	c.cause = err
+
+	return c
+}
+
+// Unwrap returns the cause of the error, it returns nil if the error does not wrap any error
+func (e *ErrMessage) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *ErrMessage with the same Header and ErrCode,
+// so that the renewed error could be checked with the original one by errors.Is()
+func (e *ErrMessage) Is(target error) bool {
+	t, ok := target.(*ErrMessage)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+
+	return e.Header == t.Header && e.ErrCode == t.ErrCode
+}
+
 // ErrorOrNil returns an error interface if both Header and ErrCode are not zero value, otherwise, returns nil.
 // This function is useful at the end of accumulation to make sure that the value
 // returned represents the existence of errors
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -63,6 +64,15 @@ func TestError(t *testing.T) {
 	asst.Equal(expectString, errMessage.Renew(line).Error(), "test Renew() failed.")
 	t.Log("==========test Renew() completed.==========")
 
+	t.Log("==========test Wrap() started.==========")
+	cause := errors.New("test cause")
+	wrapped := newErrMessage(header, errCode, "something goes wrong. %s").Wrap(cause)
+	asst.True(errors.Is(wrapped, errMessage), "test Wrap() failed.")
+	asst.True(errors.Is(wrapped, cause), "test Wrap() failed.")
+	asst.False(errors.Is(wrapped, newErrMessage(header, errCode+1, raw)), "test Wrap() failed.")
+	asst.Equal(fmt.Sprintf("%s-%d: something goes wrong. test cause", header, errCode), wrapped.Error(), "test Wrap() failed.")
+	t.Log("==========test Wrap() completed.==========")
+
 	t.Log("==========test ErrorOrNil() started.==========")
 	err = ReturnNotNil1()
 	if err == nil {
@@ -34,6 +34,9 @@ const (
 	CommaString                         = ","
 	AsteriskString                      = "*"
 	DotString                           = "."
+	DashString                          = "-"
+	SlashString                         = "/"
+	ColonString                         = ":"
 	VerticalBarString                   = "|"
 	SemicolonString                     = ";"
 	LeftParenthesis                     = "("
@@ -47,6 +50,7 @@ const (
 	DefaultMarshalTag                   = DefaultJSONTag
 	DefaultJSONTag                      = "json"
 	DefaultMiddlewareTag                = "middleware"
+	DefaultDBTag                        = "db"
 	DefaultListenIP                     = "0.0.0.0"
 	DefaultLocalHostName                = "localhost"
 	DefaultLocalHostIP                  = "127.0.0.1"
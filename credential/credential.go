@@ -0,0 +1,197 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// KeyEnv is the environment variable of the base64 encoded key
+	KeyEnv = "GO_UTIL_CREDENTIAL_KEY"
+	// KeyFileEnv is the environment variable of the key file path, the file contains the base64 encoded key
+	KeyFileEnv = "GO_UTIL_CREDENTIAL_KEY_FILE"
+	// KeySize is the size of the key, aes-256 is used
+	KeySize = 32
+
+	// EncryptedPrefix and EncryptedSuffix wrap the encrypted values, for example: ENC(base64 string),
+	// so that the plaintext and the encrypted values could be used in the same config file
+	EncryptedPrefix = "ENC("
+	EncryptedSuffix = ")"
+)
+
+var (
+	ErrKeyNotFound = errors.New(fmt.Sprintf("credential key is not found, please set it with %s or %s environment variable", KeyEnv, KeyFileEnv))
+	ErrInvalidKey  = errors.New(fmt.Sprintf("credential key should be %d bytes", KeySize))
+
+	keyMutex sync.Mutex
+	// globalKey is the key used by DecryptIfNeeded(), it is loaded from the environment at the first time
+	globalKey []byte
+)
+
+// NewKey returns a new random key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	_, err := io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// EncodeKey encodes the key to base64 string which could be saved in the environment variable or the key file
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// DecodeKey decodes the base64 string to key
+func DecodeKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("decode credential key failed. %s", err.Error()))
+	}
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
+
+// LoadKey loads the key from the environment, KeyEnv takes precedence over KeyFileEnv
+func LoadKey() ([]byte, error) {
+	s := os.Getenv(KeyEnv)
+	if s != constant.EmptyString {
+		return DecodeKey(s)
+	}
+
+	path := os.Getenv(KeyFileEnv)
+	if path == constant.EmptyString {
+		return nil, ErrKeyNotFound
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("read credential key file failed. path: %s. %s", path, err.Error()))
+	}
+
+	return DecodeKey(string(data))
+}
+
+// SetKey sets the key used by DecryptIfNeeded(), it overrides the key from the environment
+func SetKey(key []byte) error {
+	if len(key) != KeySize {
+		return ErrInvalidKey
+	}
+
+	keyMutex.Lock()
+	defer keyMutex.Unlock()
+
+	globalKey = key
+
+	return nil
+}
+
+// getKey returns the global key, it loads the key from the environment if the key is not set
+func getKey() ([]byte, error) {
+	keyMutex.Lock()
+	defer keyMutex.Unlock()
+
+	if globalKey != nil {
+		return globalKey, nil
+	}
+
+	key, err := LoadKey()
+	if err != nil {
+		return nil, err
+	}
+	globalKey = key
+
+	return globalKey, nil
+}
+
+// IsEncrypted returns if the value is wrapped by EncryptedPrefix and EncryptedSuffix
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix) && strings.HasSuffix(value, EncryptedSuffix)
+}
+
+// Encrypt encrypts the plaintext with aes-gcm and returns the wrapped value, for example: ENC(base64 string)
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	// the nonce is prepended to the ciphertext
+	data := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(data) + EncryptedSuffix, nil
+}
+
+// Decrypt decrypts the value which is returned by Encrypt(), if the value is not encrypted, it returns the value as is
+func Decrypt(key []byte, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, EncryptedPrefix), EncryptedSuffix))
+	if err != nil {
+		return constant.EmptyString, errors.New(fmt.Sprintf("decode encrypted credential failed. %s", err.Error()))
+	}
+	if len(data) < gcm.NonceSize() {
+		return constant.EmptyString, errors.New("encrypted credential is too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return constant.EmptyString, errors.New(fmt.Sprintf("decrypt credential failed, the key may be wrong. %s", err.Error()))
+	}
+
+	return string(plaintext), nil
+}
+
+// DecryptIfNeeded decrypts the value with the global key if the value is encrypted, otherwise, it returns the value as is,
+// the middleware clients call it at connect time, so the config files could contain only the encrypted values
+func DecryptIfNeeded(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	key, err := getKey()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return Decrypt(key, value)
+}
+
+// newGCM returns the aes-gcm cipher with given key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,58 @@
+package credential
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	asst := assert.New(t)
+
+	key, err := NewKey()
+	asst.Nil(err, "test NewKey() failed")
+	encrypted, err := Encrypt(key, "root")
+	asst.Nil(err, "test Encrypt() failed")
+	asst.True(IsEncrypted(encrypted), "test Encrypt() failed")
+
+	plaintext, err := Decrypt(key, encrypted)
+	asst.Nil(err, "test Decrypt() failed")
+	asst.Equal("root", plaintext, "test Decrypt() failed")
+	// the plaintext value will be returned as is
+	plaintext, err = Decrypt(key, "root")
+	asst.Nil(err, "test Decrypt() failed")
+	asst.Equal("root", plaintext, "test Decrypt() failed")
+
+	otherKey, err := NewKey()
+	asst.Nil(err, "test NewKey() failed")
+	_, err = Decrypt(otherKey, encrypted)
+	asst.NotNil(err, "test Decrypt() failed")
+	_, err = Encrypt([]byte("short"), "root")
+	asst.Equal(ErrInvalidKey, err, "test Encrypt() failed")
+}
+
+func TestDecryptIfNeeded(t *testing.T) {
+	asst := assert.New(t)
+
+	key, err := NewKey()
+	asst.Nil(err, "test NewKey() failed")
+	encrypted, err := Encrypt(key, "root")
+	asst.Nil(err, "test Encrypt() failed")
+
+	asst.Nil(os.Setenv(KeyEnv, EncodeKey(key)), "test LoadKey() failed")
+	defer func() { _ = os.Unsetenv(KeyEnv) }()
+	loaded, err := LoadKey()
+	asst.Nil(err, "test LoadKey() failed")
+	asst.Equal(key, loaded, "test LoadKey() failed")
+
+	plaintext, err := DecryptIfNeeded(encrypted)
+	asst.Nil(err, "test DecryptIfNeeded() failed")
+	asst.Equal("root", plaintext, "test DecryptIfNeeded() failed")
+
+	otherKey, err := NewKey()
+	asst.Nil(err, "test NewKey() failed")
+	asst.Nil(SetKey(otherKey), "test SetKey() failed")
+	_, err = DecryptIfNeeded(encrypted)
+	asst.NotNil(err, "test DecryptIfNeeded() failed")
+}
@@ -0,0 +1,266 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/linux"
+)
+
+const (
+	DefaultStopTimeout = 30 * time.Second
+
+	checkInterval = 100 * time.Millisecond
+)
+
+var ErrNotRunning = errors.New("process is not running")
+
+// AlreadyRunningError means another instance holds the pid file
+type AlreadyRunningError struct {
+	PidFile string
+	Pid     int
+}
+
+// Error implements error interface
+func (e *AlreadyRunningError) Error() string {
+	return fmt.Sprintf("another instance is already running. pid file: %s, pid: %d", e.PidFile, e.Pid)
+}
+
+// PidFile is the locked pid file, the lock is held until Release() is called or current process exits,
+// so the stale pid file left by the crashed process will not block the new instance
+type PidFile struct {
+	Path string
+	file *os.File
+}
+
+// CreatePidFile creates the pid file, locks it and writes the pid of current process,
+// if another process holds the lock, it returns *AlreadyRunningError
+func CreatePidFile(path string) (*PidFile, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, constant.DefaultFileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		defer func() { _ = file.Close() }()
+		if err == syscall.EWOULDBLOCK {
+			data, _ := ioutil.ReadAll(file)
+			pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+			return nil, &AlreadyRunningError{PidFile: path, Pid: pid}
+		}
+		return nil, errors.New(fmt.Sprintf("lock pid file failed. pid file: %s. %s", path, err.Error()))
+	}
+
+	err = file.Truncate(constant.ZeroInt)
+	if err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())), constant.ZeroInt)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, errors.New(fmt.Sprintf("write pid file failed. pid file: %s. %s", path, err.Error()))
+	}
+
+	return &PidFile{
+		Path: path,
+		file: file,
+	}, nil
+}
+
+// Release removes the pid file and releases the lock
+func (pf *PidFile) Release() error {
+	if pf.file == nil {
+		return nil
+	}
+
+	// remove the file before unlocking, so that the new instance will not lock the file which is going to be removed
+	err := os.Remove(pf.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err = pf.file.Close()
+	pf.file = nil
+
+	return err
+}
+
+// EnsureSingleInstance makes sure only one instance of the program runs with the pid file,
+// it returns *AlreadyRunningError if another instance is running, the returned pid file should be released when the program exits
+func EnsureSingleInstance(pidFile string) (*PidFile, error) {
+	pf, err := CreatePidFile(pidFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("pid file is created. pid file: %s, pid: %d", pidFile, os.Getpid())
+
+	return pf, nil
+}
+
+// DropPrivileges switches current process to given user and its groups, it must be called by root,
+// it is normally called after the privileged resources such as the low ports had been opened
+func DropPrivileges(userName string) error {
+	u, err := user.Lookup(userName)
+	if err != nil {
+		return err
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return err
+	}
+	groupIDs, err := u.GroupIds()
+	if err != nil {
+		return err
+	}
+	gids := make([]int, constant.ZeroInt, len(groupIDs))
+	for _, groupID := range groupIDs {
+		g, err := strconv.Atoi(groupID)
+		if err != nil {
+			return err
+		}
+		gids = append(gids, g)
+	}
+
+	if os.Geteuid() == uid {
+		return nil
+	}
+	if !linux.IsRoot() {
+		return errors.New(fmt.Sprintf("only root could drop privileges. user: %s", userName))
+	}
+
+	// the groups must be set before the user, because the non-root user could not change the groups
+	err = syscall.Setgroups(gids)
+	if err != nil {
+		return errors.New(fmt.Sprintf("set groups failed. user: %s. %s", userName, err.Error()))
+	}
+	err = syscall.Setgid(gid)
+	if err != nil {
+		return errors.New(fmt.Sprintf("set gid failed. user: %s. %s", userName, err.Error()))
+	}
+	err = syscall.Setuid(uid)
+	if err != nil {
+		return errors.New(fmt.Sprintf("set uid failed. user: %s. %s", userName, err.Error()))
+	}
+
+	return nil
+}
+
+// Status returns the pid saved in the pid file and if the process is running,
+// if the pid file does not exist, it returns ErrNotRunning
+func Status(pidFile string) (int, bool, error) {
+	exists, err := linux.PathExists(pidFile)
+	if err != nil {
+		return constant.ZeroInt, false, err
+	}
+	if !exists {
+		return constant.ZeroInt, false, ErrNotRunning
+	}
+
+	pid, err := linux.GetPidFromPidFile(pidFile)
+	if err != nil {
+		return constant.ZeroInt, false, err
+	}
+	isRunning, err := linux.IsRunningWithPid(pid)
+	if err != nil {
+		return pid, false, err
+	}
+
+	return pid, isRunning, nil
+}
+
+// Start starts the command in background like nohup, and saves the pid of the command to the pid file,
+// stdout and stderr of the command will be appended to the output file,
+// if the process of the pid file is running, it returns *AlreadyRunningError
+func Start(command, pidFile, outputFile string) (int, error) {
+	pid, isRunning, err := Status(pidFile)
+	if err != nil && err != ErrNotRunning {
+		return constant.ZeroInt, err
+	}
+	if isRunning {
+		return constant.ZeroInt, &AlreadyRunningError{PidFile: pidFile, Pid: pid}
+	}
+
+	// "exec" replaces the shell, so that the pid is the pid of the command
+	pid, err = linux.ExecuteCommandDetached("exec "+command, outputFile)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	err = linux.SavePid(pid, pidFile, constant.DefaultFileMode)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	log.Infof("process started. command: %s, pid: %d", command, pid)
+
+	return pid, nil
+}
+
+// Stop sends SIGTERM to the process of the pid file and waits for it to exit,
+// if the process does not exit within the timeout, SIGKILL will be sent, the pid file will be removed at last
+func Stop(pidFile string, timeout time.Duration) error {
+	pid, isRunning, err := Status(pidFile)
+	if err != nil {
+		return err
+	}
+	if !isRunning {
+		return removePidFile(pidFile)
+	}
+
+	err = linux.ShutdownServer(pid)
+	if err != nil {
+		return err
+	}
+	if !waitForExit(pid, timeout) {
+		log.Warnf("process did not exit within the timeout, will kill it. pid: %d, timeout: %s", pid, timeout.String())
+		err = linux.KillServer(pid)
+		if err != nil {
+			return err
+		}
+		if !waitForExit(pid, timeout) {
+			return errors.New(fmt.Sprintf("process could not be killed. pid: %d", pid))
+		}
+	}
+	log.Infof("process stopped. pid: %d", pid)
+
+	return removePidFile(pidFile)
+}
+
+// waitForExit waits for the process to exit, it returns false if the process is still running after the timeout
+func waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		isRunning, err := linux.IsRunningWithPid(pid)
+		if err == nil && !isRunning {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(checkInterval)
+	}
+}
+
+// removePidFile removes the pid file, the process may have removed it by itself
+func removePidFile(pidFile string) error {
+	err := os.Remove(pidFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
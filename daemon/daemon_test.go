@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureSingleInstance(t *testing.T) {
+	asst := assert.New(t)
+
+	pidFile := filepath.Join(t.TempDir(), "test.pid")
+	pf, err := EnsureSingleInstance(pidFile)
+	asst.Nil(err, "test EnsureSingleInstance() failed")
+
+	_, err = EnsureSingleInstance(pidFile)
+	asst.NotNil(err, "test EnsureSingleInstance() failed")
+	are, ok := err.(*AlreadyRunningError)
+	asst.True(ok, "test EnsureSingleInstance() failed")
+	asst.Equal(os.Getpid(), are.Pid, "test EnsureSingleInstance() failed")
+
+	asst.Nil(pf.Release(), "test Release() failed")
+	_, err = os.Stat(pidFile)
+	asst.True(os.IsNotExist(err), "test Release() failed")
+	pf, err = EnsureSingleInstance(pidFile)
+	asst.Nil(err, "test EnsureSingleInstance() failed")
+	asst.Nil(pf.Release(), "test Release() failed")
+}
+
+func TestStartStop(t *testing.T) {
+	asst := assert.New(t)
+
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "sleep.pid")
+	_, _, err := Status(pidFile)
+	asst.Equal(ErrNotRunning, err, "test Status() failed")
+
+	pid, err := Start("sleep 10", pidFile, filepath.Join(dir, "sleep.out"))
+	asst.Nil(err, "test Start() failed")
+	_, err = Start("sleep 10", pidFile, filepath.Join(dir, "sleep.out"))
+	asst.NotNil(err, "test Start() failed")
+
+	statusPid, isRunning, err := Status(pidFile)
+	asst.Nil(err, "test Status() failed")
+	asst.True(isRunning, "test Status() failed")
+	asst.Equal(pid, statusPid, "test Status() failed")
+
+	asst.Nil(Stop(pidFile, 5*time.Second), "test Stop() failed")
+	_, _, err = Status(pidFile)
+	asst.Equal(ErrNotRunning, err, "test Stop() failed")
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownFunc releases the resources, it should return once the context is done
+type ShutdownFunc func(ctx context.Context) error
+
+type shutdownHook struct {
+	name string
+	fn   ShutdownFunc
+}
+
+// ShutdownManager runs the registered shutdown functions in reverse order of registration,
+// so that the resources registered later, which normally depend on the earlier ones, are released first
+type ShutdownManager struct {
+	timeout time.Duration
+
+	mutex sync.Mutex
+	hooks []shutdownHook
+	once  sync.Once
+	done  chan struct{}
+	err   error
+}
+
+// NewShutdownManager returns a new *ShutdownManager, timeout limits the total time of all the shutdown functions,
+// if timeout is not positive, DefaultShutdownTimeout will be used
+func NewShutdownManager(timeout time.Duration) *ShutdownManager {
+	if timeout <= constant.ZeroInt {
+		timeout = DefaultShutdownTimeout
+	}
+
+	return &ShutdownManager{
+		timeout: timeout,
+		done:    make(chan struct{}),
+	}
+}
+
+// Register registers the shutdown function
+func (sm *ShutdownManager) Register(name string, fn ShutdownFunc) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.hooks = append(sm.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Shutdown runs the shutdown functions, it runs only once, the following calls return the same error
+func (sm *ShutdownManager) Shutdown() error {
+	sm.once.Do(func() {
+		defer close(sm.done)
+
+		sm.mutex.Lock()
+		hooks := make([]shutdownHook, len(sm.hooks))
+		copy(hooks, sm.hooks)
+		sm.mutex.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), sm.timeout)
+		defer cancel()
+
+		var merr *multierror.Error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			log.Infof("shutting down. name: %s", hooks[i].name)
+			err := hooks[i].fn(ctx)
+			if err != nil {
+				log.Errorf("shut down failed. name: %s. %s", hooks[i].name, err.Error())
+				merr = multierror.Append(merr, errors.New(fmt.Sprintf("shut down failed. name: %s. %s", hooks[i].name, err.Error())))
+			}
+		}
+		sm.err = merr.ErrorOrNil()
+	})
+
+	<-sm.done
+
+	return sm.err
+}
+
+// Done returns a channel which will be closed after the shutdown completes
+func (sm *ShutdownManager) Done() <-chan struct{} {
+	return sm.done
+}
+
+// Wait waits for the shutdown to complete and returns the error of the shutdown functions
+func (sm *ShutdownManager) Wait() error {
+	<-sm.done
+
+	return sm.err
+}
@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/romberli/log"
+	"go.uber.org/zap/zapcore"
+)
+
+// ReloadFunc reloads the config, it is called when SIGHUP is received
+type ReloadFunc func() error
+
+// SignalHandler handles the operating system signals:
+// SIGTERM and SIGINT shut down the process gracefully with the shutdown manager,
+// SIGHUP calls the reload function,
+// SIGUSR1 switches the log level between debug and the level before the first switch
+type SignalHandler struct {
+	shutdown *ShutdownManager
+
+	mutex       sync.Mutex
+	reload      ReloadFunc
+	levelBumped bool
+	level       zapcore.Level
+	signals     chan os.Signal
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewSignalHandler returns a new *SignalHandler
+func NewSignalHandler(shutdown *ShutdownManager) *SignalHandler {
+	return &SignalHandler{shutdown: shutdown}
+}
+
+// OnReload sets the reload function, for example, reloading the config file
+func (sh *SignalHandler) OnReload(fn ReloadFunc) *SignalHandler {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	sh.reload = fn
+
+	return sh
+}
+
+// Start starts handling the signals
+func (sh *SignalHandler) Start() {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if sh.signals != nil {
+		return
+	}
+	sh.signals = make(chan os.Signal, 1)
+	sh.stop = make(chan struct{})
+	signal.Notify(sh.signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP, syscall.SIGUSR1)
+
+	sh.wg.Add(1)
+	go sh.loop(sh.signals, sh.stop)
+}
+
+// Stop stops handling the signals, the signals will take the default actions again
+func (sh *SignalHandler) Stop() {
+	sh.mutex.Lock()
+	signals, stop := sh.signals, sh.stop
+	sh.signals, sh.stop = nil, nil
+	sh.mutex.Unlock()
+
+	if signals == nil {
+		return
+	}
+	signal.Stop(signals)
+	close(stop)
+	sh.wg.Wait()
+}
+
+// loop handles the signals until it is stopped or the process is shut down
+func (sh *SignalHandler) loop(signals chan os.Signal, stop chan struct{}) {
+	defer sh.wg.Done()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sh.shutdown.Done():
+			return
+		case sig := <-signals:
+			sh.handle(sig)
+		}
+	}
+}
+
+// handle handles one signal
+func (sh *SignalHandler) handle(sig os.Signal) {
+	switch sig {
+	case syscall.SIGTERM, syscall.SIGINT:
+		log.Infof("got signal %s, will shut down gracefully", sig.String())
+		// the shutdown may take a while, do not block the handling of the other signals
+		go func() {
+			_ = sh.shutdown.Shutdown()
+		}()
+	case syscall.SIGHUP:
+		sh.mutex.Lock()
+		reload := sh.reload
+		sh.mutex.Unlock()
+		if reload == nil {
+			log.Warnf("got signal %s, but reload function is not set, ignore it", sig.String())
+			return
+		}
+		log.Infof("got signal %s, will reload", sig.String())
+		err := reload()
+		if err != nil {
+			log.Errorf("reload failed. %s", err.Error())
+		}
+	case syscall.SIGUSR1:
+		sh.bumpLogLevel()
+	}
+}
+
+// bumpLogLevel switches the log level to debug, and switches it back at the next time
+func (sh *SignalHandler) bumpLogLevel() {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if sh.levelBumped {
+		log.SetLevel(sh.level)
+		sh.levelBumped = false
+		log.Infof("log level is restored to %s", sh.level.String())
+		return
+	}
+
+	sh.level = log.GetLevel()
+	log.SetLevel(zapcore.DebugLevel)
+	sh.levelBumped = true
+	log.Infof("log level is switched from %s to %s", sh.level.String(), zapcore.DebugLevel.String())
+}
+
+// HandleSignals handles the signals with the shutdown manager and the reload function,
+// and blocks until the shutdown completes, reload could be nil
+func HandleSignals(shutdown *ShutdownManager, reload ReloadFunc) error {
+	sh := NewSignalHandler(shutdown).OnReload(reload)
+	sh.Start()
+	defer sh.Stop()
+
+	return shutdown.Wait()
+}
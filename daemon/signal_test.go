@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/romberli/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestShutdownManager(t *testing.T) {
+	asst := assert.New(t)
+
+	sm := NewShutdownManager(time.Second)
+	var order []string
+	sm.Register("first", func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	sm.Register("second", func(ctx context.Context) error {
+		order = append(order, "second")
+		return errors.New("test")
+	})
+
+	asst.NotNil(sm.Shutdown(), "test Shutdown() failed")
+	asst.Equal([]string{"second", "first"}, order, "test Shutdown() failed")
+	// shutdown runs only once
+	asst.NotNil(sm.Shutdown(), "test Shutdown() failed")
+	asst.Equal(2, len(order), "test Shutdown() failed")
+}
+
+func TestSignalHandler(t *testing.T) {
+	asst := assert.New(t)
+
+	sm := NewShutdownManager(time.Second)
+	var shutdowns, reloads int32
+	sm.Register("test", func(ctx context.Context) error {
+		atomic.AddInt32(&shutdowns, 1)
+		return nil
+	})
+	sh := NewSignalHandler(sm).OnReload(func() error {
+		atomic.AddInt32(&reloads, 1)
+		return nil
+	})
+	sh.Start()
+	defer sh.Stop()
+
+	level := log.GetLevel()
+	asst.Nil(syscall.Kill(os.Getpid(), syscall.SIGUSR1), "test SignalHandler failed")
+	time.Sleep(50 * time.Millisecond)
+	asst.Equal(zapcore.DebugLevel, log.GetLevel(), "test SignalHandler failed")
+	asst.Nil(syscall.Kill(os.Getpid(), syscall.SIGUSR1), "test SignalHandler failed")
+	time.Sleep(50 * time.Millisecond)
+	asst.Equal(level, log.GetLevel(), "test SignalHandler failed")
+
+	asst.Nil(syscall.Kill(os.Getpid(), syscall.SIGHUP), "test SignalHandler failed")
+	time.Sleep(50 * time.Millisecond)
+	asst.Equal(int32(1), atomic.LoadInt32(&reloads), "test SignalHandler failed")
+
+	asst.Nil(syscall.Kill(os.Getpid(), syscall.SIGTERM), "test SignalHandler failed")
+	select {
+	case <-sm.Done():
+	case <-time.After(time.Second):
+		asst.Fail("test SignalHandler failed")
+	}
+	asst.Equal(int32(1), atomic.LoadInt32(&shutdowns), "test SignalHandler failed")
+}
@@ -0,0 +1,443 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	TypeMySQL      = "mysql"
+	TypeRedis      = "redis"
+	TypeAMQP       = "amqp"
+	TypeKafka      = "kafka"
+	TypePrometheus = "prometheus"
+
+	DefaultMySQLPort      = 3306
+	DefaultRedisPort      = 6379
+	DefaultAMQPPort       = 5672
+	DefaultAMQPSPort      = 5671
+	DefaultKafkaPort      = 9092
+	DefaultPrometheusPort = 9090
+
+	DefaultAMQPVHost = "/"
+
+	schemeSeparator = "://"
+	mysqlProtocol   = "tcp"
+	schemeRedis     = "redis"
+	schemeRedisTLS  = "rediss"
+	schemeAMQP      = "amqp"
+	schemeAMQPS     = "amqps"
+	schemeHTTP      = "http"
+	schemeHTTPS     = "https"
+)
+
+// Host is a host and port pair
+type Host struct {
+	Host string
+	Port int
+}
+
+// NewHost returns a new Host
+func NewHost(host string, port int) Host {
+	return Host{
+		Host: host,
+		Port: port,
+	}
+}
+
+// ParseHost parses the address of host:port style, if the port is missing, default port will be used
+func ParseHost(addr string, defaultPort int) (Host, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		// the port is missing
+		if strings.Contains(err.Error(), "missing port") {
+			return NewHost(strings.Trim(addr, "[]"), defaultPort), nil
+		}
+
+		return Host{}, err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return Host{}, errors.New(fmt.Sprintf("port should be an integer, %s is not valid", portStr))
+	}
+
+	return NewHost(host, port), nil
+}
+
+// String returns the host:port style address
+func (h Host) String() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+}
+
+// DSN is the parsed connection string of the middleware
+type DSN struct {
+	Type string
+	// Scheme is the scheme of url style connection string, for example, rediss, amqps and https
+	Scheme   string
+	User     string
+	Password string
+	Hosts    []Host
+	// Database is the database name of mysql, the database number of redis,
+	// the virtual host of amqp or the path prefix of prometheus
+	Database string
+	Params   map[string]string
+}
+
+// Parse parses the connection string of given middleware type
+func Parse(typ, s string) (*DSN, error) {
+	switch typ {
+	case TypeMySQL:
+		return ParseMySQL(s)
+	case TypeRedis:
+		return ParseRedis(s)
+	case TypeAMQP:
+		return ParseAMQP(s)
+	case TypeKafka:
+		return ParseKafka(s)
+	case TypePrometheus:
+		return ParsePrometheus(s)
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported dsn type: %s", typ))
+	}
+}
+
+// Addr returns the address of the first host
+func (d *DSN) Addr() string {
+	if len(d.Hosts) == constant.ZeroInt {
+		return constant.EmptyString
+	}
+
+	return d.Hosts[constant.ZeroInt].String()
+}
+
+// Addrs returns the addresses of all the hosts
+func (d *DSN) Addrs() []string {
+	addrs := make([]string, len(d.Hosts))
+	for i, host := range d.Hosts {
+		addrs[i] = host.String()
+	}
+
+	return addrs
+}
+
+// Validate validates the dsn
+func (d *DSN) Validate() (bool, error) {
+	if len(d.Hosts) == constant.ZeroInt {
+		return false, errors.New("host should not be empty")
+	}
+	for _, host := range d.Hosts {
+		if host.Host == constant.EmptyString {
+			return false, errors.New("host should not be empty")
+		}
+		if host.Port <= constant.ZeroInt || host.Port > 65535 {
+			return false, errors.New(fmt.Sprintf("port should be between 1 and 65535, %d is not valid", host.Port))
+		}
+	}
+
+	switch d.Type {
+	case TypeMySQL, TypeAMQP, TypePrometheus:
+		if len(d.Hosts) > 1 {
+			return false, errors.New(fmt.Sprintf("%s dsn should have only one host", d.Type))
+		}
+	case TypeRedis:
+		if len(d.Hosts) > 1 {
+			return false, errors.New(fmt.Sprintf("%s dsn should have only one host", d.Type))
+		}
+		if d.Database != constant.EmptyString {
+			db, err := strconv.Atoi(d.Database)
+			if err != nil || db < constant.ZeroInt {
+				return false, errors.New(fmt.Sprintf("redis database should be a non-negative integer, %s is not valid", d.Database))
+			}
+		}
+	case TypeKafka:
+	default:
+		return false, errors.New(fmt.Sprintf("unsupported dsn type: %s", d.Type))
+	}
+
+	return true, nil
+}
+
+// String builds the connection string of the dsn,
+// mysql dsn is built in the style of go-sql-driver, kafka dsn is a comma separated broker list,
+// the others are built in url style
+func (d *DSN) String() string {
+	switch d.Type {
+	case TypeMySQL:
+		return d.mysqlString()
+	case TypeKafka:
+		return strings.Join(d.Addrs(), constant.CommaString)
+	default:
+		return d.urlString()
+	}
+}
+
+// mysqlString builds the connection string of go-sql-driver style,
+// user:pass@tcp(host:port)/db?params
+func (d *DSN) mysqlString() string {
+	var sb strings.Builder
+	if d.User != constant.EmptyString {
+		sb.WriteString(d.User)
+		if d.Password != constant.EmptyString {
+			sb.WriteString(":")
+			sb.WriteString(d.Password)
+		}
+		sb.WriteString("@")
+	}
+	sb.WriteString(fmt.Sprintf("%s(%s)", mysqlProtocol, d.Addr()))
+	sb.WriteString(constant.SlashString)
+	sb.WriteString(d.Database)
+	if len(d.Params) > constant.ZeroInt {
+		sb.WriteString("?")
+		sb.WriteString(encodeParams(d.Params))
+	}
+
+	return sb.String()
+}
+
+// urlString builds the connection string of url style
+func (d *DSN) urlString() string {
+	u := &url.URL{
+		Scheme: d.Scheme,
+		Host:   d.Addr(),
+	}
+	if d.User != constant.EmptyString || d.Password != constant.EmptyString {
+		if d.Password != constant.EmptyString {
+			u.User = url.UserPassword(d.User, d.Password)
+		} else {
+			u.User = url.User(d.User)
+		}
+	}
+	if d.Database != constant.EmptyString {
+		if d.Type == TypeAMQP {
+			// the virtual host may contain slash, so it should be escaped as a whole
+			u.RawPath = constant.SlashString + url.PathEscape(d.Database)
+			u.Path = constant.SlashString + d.Database
+		} else {
+			u.Path = constant.SlashString + strings.TrimPrefix(d.Database, constant.SlashString)
+		}
+	}
+	if len(d.Params) > constant.ZeroInt {
+		u.RawQuery = encodeParams(d.Params)
+	}
+
+	return u.String()
+}
+
+// ParseMySQL parses the mysql connection string,
+// both go-sql-driver style [user[:pass]@][tcp(host[:port])]/[db][?params]
+// and url style mysql://[user[:pass]@]host[:port]/[db][?params] are supported
+func ParseMySQL(s string) (*DSN, error) {
+	if strings.HasPrefix(s, TypeMySQL+schemeSeparator) {
+		d, err := parseURL(TypeMySQL, s, DefaultMySQLPort)
+		if err != nil {
+			return nil, err
+		}
+		d.Database = strings.TrimPrefix(d.Database, constant.SlashString)
+
+		return validate(d)
+	}
+
+	d := &DSN{Type: TypeMySQL, Params: make(map[string]string)}
+
+	// the password may contain slash, so use the last slash to split the database
+	slash := strings.LastIndex(s, constant.SlashString)
+	if slash < constant.ZeroInt {
+		return nil, errors.New(fmt.Sprintf("invalid mysql dsn, database separator is missing: %s", s))
+	}
+	prefix, suffix := s[:slash], s[slash+1:]
+
+	// database and params
+	question := strings.Index(suffix, "?")
+	if question >= constant.ZeroInt {
+		params, err := url.ParseQuery(suffix[question+1:])
+		if err != nil {
+			return nil, err
+		}
+		for key := range params {
+			d.Params[key] = params.Get(key)
+		}
+		suffix = suffix[:question]
+	}
+	d.Database = suffix
+
+	// user and password, the password may contain @, so use the last @ to split the address
+	at := strings.LastIndex(prefix, "@")
+	if at >= constant.ZeroInt {
+		userInfo := prefix[:at]
+		prefix = prefix[at+1:]
+		colon := strings.Index(userInfo, ":")
+		if colon >= constant.ZeroInt {
+			d.User, d.Password = userInfo[:colon], userInfo[colon+1:]
+		} else {
+			d.User = userInfo
+		}
+	}
+
+	// protocol and address
+	addr := prefix
+	open := strings.Index(prefix, "(")
+	if open >= constant.ZeroInt {
+		if !strings.HasSuffix(prefix, ")") {
+			return nil, errors.New(fmt.Sprintf("invalid mysql dsn, address is not closed: %s", s))
+		}
+		protocol := prefix[:open]
+		if protocol != mysqlProtocol {
+			return nil, errors.New(fmt.Sprintf("only tcp protocol is supported, %s is not valid", protocol))
+		}
+		addr = prefix[open+1 : len(prefix)-1]
+	}
+	if addr == constant.EmptyString {
+		addr = "127.0.0.1"
+	}
+	host, err := ParseHost(addr, DefaultMySQLPort)
+	if err != nil {
+		return nil, err
+	}
+	d.Hosts = []Host{host}
+
+	return validate(d)
+}
+
+// ParseRedis parses the redis connection string,
+// both url style redis[s]://[user][:pass]@host[:port][/db][?params] and host[:port] are supported
+func ParseRedis(s string) (*DSN, error) {
+	if !strings.Contains(s, schemeSeparator) {
+		s = schemeRedis + schemeSeparator + s
+	}
+	d, err := parseURL(TypeRedis, s, DefaultRedisPort)
+	if err != nil {
+		return nil, err
+	}
+	if d.Scheme != schemeRedis && d.Scheme != schemeRedisTLS {
+		return nil, errors.New(fmt.Sprintf("redis scheme should be either %s or %s, %s is not valid", schemeRedis, schemeRedisTLS, d.Scheme))
+	}
+	d.Database = strings.TrimPrefix(d.Database, constant.SlashString)
+
+	return validate(d)
+}
+
+// ParseAMQP parses the amqp connection string of url style amqp[s]://[user[:pass]@]host[:port][/vhost],
+// the virtual host should be escaped, if it is missing, DefaultAMQPVHost will be used
+func ParseAMQP(s string) (*DSN, error) {
+	port := DefaultAMQPPort
+	if strings.HasPrefix(s, schemeAMQPS+schemeSeparator) {
+		port = DefaultAMQPSPort
+	}
+	d, err := parseURL(TypeAMQP, s, port)
+	if err != nil {
+		return nil, err
+	}
+	if d.Scheme != schemeAMQP && d.Scheme != schemeAMQPS {
+		return nil, errors.New(fmt.Sprintf("amqp scheme should be either %s or %s, %s is not valid", schemeAMQP, schemeAMQPS, d.Scheme))
+	}
+	d.Database = strings.TrimPrefix(d.Database, constant.SlashString)
+	if d.Database == constant.EmptyString {
+		d.Database = DefaultAMQPVHost
+	}
+
+	return validate(d)
+}
+
+// ParseKafka parses the broker list of kafka, it is comma separated host[:port] list,
+// and it could be prefixed with kafka://
+func ParseKafka(s string) (*DSN, error) {
+	d := &DSN{Type: TypeKafka, Params: make(map[string]string)}
+	s = strings.TrimPrefix(s, TypeKafka+schemeSeparator)
+
+	for _, addr := range strings.Split(s, constant.CommaString) {
+		addr = strings.TrimSpace(addr)
+		if addr == constant.EmptyString {
+			continue
+		}
+		host, err := ParseHost(addr, DefaultKafkaPort)
+		if err != nil {
+			return nil, err
+		}
+		d.Hosts = append(d.Hosts, host)
+	}
+
+	return validate(d)
+}
+
+// ParsePrometheus parses the prometheus address, both http[s]://[user[:pass]@]host[:port][/prefix]
+// and host[:port][/prefix] are supported, the scheme is http if it is missing
+func ParsePrometheus(s string) (*DSN, error) {
+	if !strings.Contains(s, schemeSeparator) {
+		s = schemeHTTP + schemeSeparator + s
+	}
+	d, err := parseURL(TypePrometheus, s, DefaultPrometheusPort)
+	if err != nil {
+		return nil, err
+	}
+	if d.Scheme != schemeHTTP && d.Scheme != schemeHTTPS {
+		return nil, errors.New(fmt.Sprintf("prometheus scheme should be either %s or %s, %s is not valid", schemeHTTP, schemeHTTPS, d.Scheme))
+	}
+	d.Database = strings.TrimSuffix(d.Database, constant.SlashString)
+
+	return validate(d)
+}
+
+// parseURL parses the url style connection string
+func parseURL(typ, s string, defaultPort int) (*DSN, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DSN{
+		Type:     typ,
+		Scheme:   u.Scheme,
+		Database: u.Path,
+		Params:   make(map[string]string),
+	}
+	if u.User != nil {
+		d.User = u.User.Username()
+		d.Password, _ = u.User.Password()
+	}
+	if u.Host != constant.EmptyString {
+		host, err := ParseHost(u.Host, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+		d.Hosts = []Host{host}
+	}
+	for key, values := range u.Query() {
+		d.Params[key] = values[constant.ZeroInt]
+	}
+
+	return d, nil
+}
+
+// validate validates the dsn, it returns the dsn if it is valid
+func validate(d *DSN) (*DSN, error) {
+	ok, err := d.Validate()
+	if !ok {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// encodeParams encodes the params sorted by the keys
+func encodeParams(params map[string]string) string {
+	keys := make([]string, constant.ZeroInt, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = url.QueryEscape(key) + "=" + url.QueryEscape(params[key])
+	}
+
+	return strings.Join(pairs, "&")
+}
@@ -0,0 +1,264 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/healthcheck"
+)
+
+// ErrNoPrimary is returned when there is no healthy endpoint could be elected as the primary
+var ErrNoPrimary = errors.New("no healthy endpoint could be elected as the primary")
+
+// Endpoint is an instance of the middleware cluster
+type Endpoint struct {
+	Name string
+	Addr string
+	// Priority is used by PrioritySource, the smaller the value, the higher the priority
+	Priority int
+	Checker  healthcheck.Checker
+}
+
+// NewEndpoint returns a new *Endpoint
+func NewEndpoint(name, addr string, priority int, checker healthcheck.Checker) *Endpoint {
+	return &Endpoint{
+		Name:     name,
+		Addr:     addr,
+		Priority: priority,
+		Checker:  checker,
+	}
+}
+
+// String returns the readable description of the endpoint
+func (e *Endpoint) String() string {
+	return fmt.Sprintf("%s(%s)", e.Name, e.Addr)
+}
+
+// ElectionSource elects the primary from the healthy endpoints, current is the current primary, it may be nil,
+// the healthy endpoints are sorted by the priority
+type ElectionSource interface {
+	Elect(ctx context.Context, healthy []*Endpoint, current *Endpoint) (*Endpoint, error)
+}
+
+// ElectionFunc is an adapter to allow the use of ordinary functions as ElectionSource
+type ElectionFunc func(ctx context.Context, healthy []*Endpoint, current *Endpoint) (*Endpoint, error)
+
+// Elect calls f(ctx, healthy, current)
+func (f ElectionFunc) Elect(ctx context.Context, healthy []*Endpoint, current *Endpoint) (*Endpoint, error) {
+	return f(ctx, healthy, current)
+}
+
+// NewPrioritySource returns an ElectionSource which keeps the current primary as long as it is healthy,
+// otherwise, it elects the healthy endpoint with the highest priority
+func NewPrioritySource() ElectionSource {
+	return ElectionFunc(func(ctx context.Context, healthy []*Endpoint, current *Endpoint) (*Endpoint, error) {
+		for _, endpoint := range healthy {
+			if endpoint == current {
+				return current, nil
+			}
+		}
+		if len(healthy) == constant.ZeroInt {
+			return nil, ErrNoPrimary
+		}
+
+		return healthy[constant.ZeroInt], nil
+	})
+}
+
+// NewQuerySource returns an ElectionSource which asks each healthy endpoint if it is the primary,
+// for example, checking the read_only variable of mysql or the leader of etcd,
+// the first endpoint which reports it is the primary will be elected
+func NewQuerySource(isPrimary func(ctx context.Context, endpoint *Endpoint) (bool, error)) ElectionSource {
+	return ElectionFunc(func(ctx context.Context, healthy []*Endpoint, current *Endpoint) (*Endpoint, error) {
+		for _, endpoint := range healthy {
+			ok, err := isPrimary(ctx, endpoint)
+			if err != nil {
+				log.Warnf("failover: query primary status of endpoint failed. endpoint: %s. %s", endpoint.String(), err.Error())
+				continue
+			}
+			if ok {
+				return endpoint, nil
+			}
+		}
+
+		return nil, ErrNoPrimary
+	})
+}
+
+// Listener is notified when the primary changes, old is nil at the first election
+type Listener func(old, new *Endpoint)
+
+// Retargeter is implemented by the pools which could switch the connections to another instance,
+// for example, *mysql.Pool
+type Retargeter interface {
+	Retarget(addr string) error
+}
+
+// Manager tracks the health of the endpoints with the health check registry,
+// and maintains the current primary with the election source
+type Manager struct {
+	registry *healthcheck.Registry
+	source   ElectionSource
+
+	mutex     sync.RWMutex
+	endpoints map[string]*Endpoint
+	primary   *Endpoint
+	healthy   []*Endpoint
+	listeners []Listener
+	stopChan  chan struct{}
+}
+
+// NewManager returns a new *Manager, each health check will be cancelled after timeout,
+// if source is nil, NewPrioritySource() will be used
+func NewManager(timeout time.Duration, source ElectionSource, endpoints ...*Endpoint) (*Manager, error) {
+	if source == nil {
+		source = NewPrioritySource()
+	}
+
+	m := &Manager{
+		registry:  healthcheck.NewRegistry(timeout),
+		source:    source,
+		endpoints: make(map[string]*Endpoint),
+	}
+	for _, endpoint := range endpoints {
+		err := m.registry.Register(endpoint.Name, endpoint.Checker, false)
+		if err != nil {
+			return nil, err
+		}
+		m.endpoints[endpoint.Name] = endpoint
+	}
+
+	return m, nil
+}
+
+// OnChange registers the listener which is called when the primary changes
+func (m *Manager) OnChange(listener Listener) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.listeners = append(m.listeners, listener)
+}
+
+// AddRetargeter registers the pool which will be re-targeted to the new primary when the primary changes
+func (m *Manager) AddRetargeter(name string, retargeter Retargeter) {
+	m.OnChange(func(old, new *Endpoint) {
+		err := retargeter.Retarget(new.Addr)
+		if err != nil {
+			log.Errorf("failover: retarget failed. retargeter: %s, addr: %s. %s", name, new.Addr, err.Error())
+		}
+	})
+}
+
+// Primary returns the current primary, it returns nil if the primary had not been elected
+func (m *Manager) Primary() *Endpoint {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.primary
+}
+
+// Healthy returns the healthy endpoints of the latest check, they are sorted by the priority
+func (m *Manager) Healthy() []*Endpoint {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	healthy := make([]*Endpoint, len(m.healthy))
+	copy(healthy, m.healthy)
+
+	return healthy
+}
+
+// Refresh checks the health of the endpoints and elects the primary,
+// if the primary changes, the listeners will be notified,
+// if no primary could be elected, the current primary will be kept and the error will be returned
+func (m *Manager) Refresh(ctx context.Context) error {
+	report := m.registry.Check(ctx)
+
+	healthy := make([]*Endpoint, constant.ZeroInt, len(report.Results))
+	for _, result := range report.Results {
+		if result.Status != healthcheck.StatusUp {
+			continue
+		}
+		endpoint, ok := m.endpoints[result.Name]
+		if ok {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	sort.SliceStable(healthy, func(i, j int) bool { return healthy[i].Priority < healthy[j].Priority })
+
+	current := m.Primary()
+	primary, err := m.source.Elect(ctx, healthy, current)
+	if err == nil && primary == nil {
+		err = ErrNoPrimary
+	}
+
+	m.mutex.Lock()
+	m.healthy = healthy
+	if err != nil || primary == current {
+		m.mutex.Unlock()
+		return err
+	}
+	m.primary = primary
+	listeners := make([]Listener, len(m.listeners))
+	copy(listeners, m.listeners)
+	m.mutex.Unlock()
+
+	if current == nil {
+		log.Infof("failover: primary is elected. primary: %s", primary.String())
+	} else {
+		log.Warnf("failover: primary changed. old: %s, new: %s", current.String(), primary.String())
+	}
+	for _, listener := range listeners {
+		listener(current, primary)
+	}
+
+	return nil
+}
+
+// Start refreshes the primary periodically in the background
+func (m *Manager) Start(interval time.Duration) {
+	m.mutex.Lock()
+	if m.stopChan != nil {
+		m.mutex.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	m.stopChan = stopChan
+	m.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			err := m.Refresh(context.Background())
+			if err != nil {
+				log.Errorf("failover: refresh primary failed. %s", err.Error())
+			}
+
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the background refreshing
+func (m *Manager) Stop() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stopChan != nil {
+		close(m.stopChan)
+		m.stopChan = nil
+	}
+}
@@ -0,0 +1,92 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/healthcheck"
+)
+
+type testEndpoint struct {
+	down int32
+}
+
+func (te *testEndpoint) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&te.down) == 1 {
+		return errors.New("test down")
+	}
+
+	return nil
+}
+
+type testRetargeter struct {
+	addr string
+}
+
+func (tr *testRetargeter) Retarget(addr string) error {
+	tr.addr = addr
+	return nil
+}
+
+func TestManager_Refresh(t *testing.T) {
+	asst := assert.New(t)
+
+	e1, e2 := &testEndpoint{}, &testEndpoint{}
+	m, err := NewManager(time.Second, nil,
+		NewEndpoint("e2", "127.0.0.1:3307", 2, e2),
+		NewEndpoint("e1", "127.0.0.1:3306", 1, e1),
+	)
+	asst.Nil(err, "test NewManager() failed")
+
+	var changes int
+	m.OnChange(func(old, new *Endpoint) { changes++ })
+	tr := &testRetargeter{}
+	m.AddRetargeter("test", tr)
+
+	asst.Nil(m.Refresh(context.Background()), "test Refresh() failed")
+	asst.Equal("e1", m.Primary().Name, "test Refresh() failed")
+	asst.Equal(2, len(m.Healthy()), "test Refresh() failed")
+	asst.Equal("127.0.0.1:3306", tr.addr, "test Refresh() failed")
+
+	// failover to e2
+	atomic.StoreInt32(&e1.down, 1)
+	asst.Nil(m.Refresh(context.Background()), "test Refresh() failed")
+	asst.Equal("e2", m.Primary().Name, "test Refresh() failed")
+	asst.Equal("127.0.0.1:3307", tr.addr, "test Refresh() failed")
+
+	// the primary is sticky with the priority source
+	atomic.StoreInt32(&e1.down, 0)
+	asst.Nil(m.Refresh(context.Background()), "test Refresh() failed")
+	asst.Equal("e2", m.Primary().Name, "test Refresh() failed")
+	asst.Equal(2, changes, "test Refresh() failed")
+
+	// no primary, the current primary is kept
+	atomic.StoreInt32(&e1.down, 1)
+	atomic.StoreInt32(&e2.down, 1)
+	asst.Equal(ErrNoPrimary, m.Refresh(context.Background()), "test Refresh() failed")
+	asst.Equal("e2", m.Primary().Name, "test Refresh() failed")
+	asst.Equal(0, len(m.Healthy()), "test Refresh() failed")
+}
+
+func TestNewQuerySource(t *testing.T) {
+	asst := assert.New(t)
+
+	source := NewQuerySource(func(ctx context.Context, endpoint *Endpoint) (bool, error) {
+		return endpoint.Name == "e2", nil
+	})
+	m, err := NewManager(time.Second, source,
+		NewEndpoint("e1", "127.0.0.1:2379", 1, healthcheck.CheckerFunc(func(ctx context.Context) error { return nil })),
+		NewEndpoint("e2", "127.0.0.1:2380", 2, healthcheck.CheckerFunc(func(ctx context.Context) error { return nil })),
+	)
+	asst.Nil(err, "test NewManager() failed")
+	asst.Nil(m.Refresh(context.Background()), "test Refresh() failed")
+	asst.Equal("e2", m.Primary().Name, "test Refresh() failed")
+
+	_, err = NewManager(time.Second, nil, NewEndpoint("e1", "", 1, nil), NewEndpoint("e1", "", 1, nil))
+	asst.NotNil(err, "test NewManager() failed")
+}
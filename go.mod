@@ -7,10 +7,14 @@ replace github.com/ClickHouse/clickhouse-go v1.4.3 => github.com/romberli/clickh
 require (
 	github.com/ClickHouse/clickhouse-go v1.4.3
 	github.com/Shopify/sarama v1.26.1
+	github.com/apache/pulsar-client-go v0.5.0
+	github.com/bradfitz/gomemcache v0.0.0-20190913173617-a41fca850d0b
 	github.com/go-mysql-org/go-mysql v1.3.0
 	github.com/hashicorp/go-multierror v1.1.0
 	github.com/json-iterator/go v1.1.10
+	github.com/opentracing/opentracing-go v1.1.0
 	github.com/percona/go-mysql v0.0.0-20210427141028-73d29c6da78c
+	github.com/pingcap/errors v0.11.5-0.20201126102027-b0a155152ca3
 	github.com/pingcap/parser v0.0.0-20210525032559-c37778aff307
 	github.com/pingcap/tidb v1.1.0-beta.0.20210526073135-acf5e52ffc78
 	github.com/pkg/errors v0.9.1
@@ -24,5 +28,9 @@ require (
 	github.com/stretchr/testify v1.6.1
 	go.etcd.io/etcd v0.5.0-alpha.5.0.20200824191128-ae9734ed278b
 	go.uber.org/zap v1.16.0
-	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a
+	golang.org/x/crypto v0.0.0-20201002170205-7f63de1d35b0
+	google.golang.org/grpc v1.27.1
+	k8s.io/api v0.20.15
+	k8s.io/apimachinery v0.20.15
+	k8s.io/client-go v0.20.15
 )
@@ -0,0 +1,85 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+var (
+	// ErrInstanceDown is returned when the middleware instance reports it is unhealthy
+	ErrInstanceDown = errors.New("instance status is not ok")
+	// ErrInvalidConnection is returned when the connection got from the pool is invalid
+	ErrInvalidConnection = errors.New("connection got from the pool is invalid")
+)
+
+// Checker checks the health of a component, it should return quickly after the context is done
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx)
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// StatusChecker is implemented by the middleware connections, such as mysql, clickhouse, prometheus and so on
+type StatusChecker interface {
+	CheckInstanceStatus() bool
+}
+
+// NewStatusChecker returns a Checker which calls CheckInstanceStatus() of the connection,
+// as CheckInstanceStatus() does not accept a context, it runs in a new goroutine,
+// and the checker returns the context error once the context is done
+func NewStatusChecker(sc StatusChecker) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		okChan := make(chan bool, 1)
+		go func() {
+			okChan <- sc.CheckInstanceStatus()
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ok := <-okChan:
+			if !ok {
+				return ErrInstanceDown
+			}
+
+			return nil
+		}
+	})
+}
+
+// NewPoolChecker returns a Checker which gets a connection from the pool and validates it
+func NewPoolChecker(pool middleware.Pool) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		errChan := make(chan error, 1)
+		go func() {
+			conn, err := pool.Get()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			defer func() { _ = conn.Close() }()
+
+			if !conn.IsValid() {
+				errChan <- ErrInvalidConnection
+				return
+			}
+
+			errChan <- nil
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errChan:
+			return err
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultTimeout = 3 * time.Second
+
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Status is the health status of a component or the whole registry
+type Status string
+
+// Result is the check result of a single component
+type Result struct {
+	Name      string        `json:"name"`
+	Status    Status        `json:"status"`
+	Critical  bool          `json:"critical"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report is the aggregated check result of all the registered components,
+// the status is down if any critical component is down,
+// and it is degraded if only non-critical components are down
+type Report struct {
+	Status    Status    `json:"status"`
+	Results   []*Result `json:"results"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	critical bool
+}
+
+// Registry holds the checkers of the components and runs them on demand or periodically
+type Registry struct {
+	sync.RWMutex
+	timeout  time.Duration
+	checks   map[string]*check
+	report   *Report
+	stopChan chan struct{}
+}
+
+// NewRegistry returns a new *Registry, each checker will be cancelled after timeout,
+// if timeout is not positive, it will use DefaultTimeout
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= constant.ZeroInt {
+		timeout = DefaultTimeout
+	}
+
+	return &Registry{
+		timeout: timeout,
+		checks:  make(map[string]*check),
+	}
+}
+
+// Register registers the checker with given name, if critical is true,
+// the registry status will be down once the checker fails, otherwise, it will be degraded
+func (r *Registry) Register(name string, checker Checker, critical bool) error {
+	r.Lock()
+	defer r.Unlock()
+
+	_, ok := r.checks[name]
+	if ok {
+		return errors.New(fmt.Sprintf("checker already registered. name: %s", name))
+	}
+
+	r.checks[name] = &check{
+		name:     name,
+		checker:  checker,
+		critical: critical,
+	}
+
+	return nil
+}
+
+// Unregister unregisters the checker with given name
+func (r *Registry) Unregister(name string) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.checks, name)
+}
+
+// Check runs all the checkers concurrently and returns the aggregated report
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.RLock()
+	checks := make([]*check, constant.ZeroInt, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	r.RUnlock()
+
+	results := make([]*Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = r.runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := &Report{
+		Status:    StatusUp,
+		Results:   results,
+		CheckedAt: time.Now(),
+	}
+	for _, result := range results {
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+
+	r.Lock()
+	r.report = report
+	r.Unlock()
+
+	return report
+}
+
+// runCheck runs the checker with timeout
+func (r *Registry) runCheck(ctx context.Context, c *check) *Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+	result := &Result{
+		Name:      c.name,
+		Status:    StatusUp,
+		Critical:  c.critical,
+		Duration:  time.Since(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		log.Warnf("health check failed. name: %s, critical: %t. %s", c.name, c.critical, err.Error())
+	}
+
+	return result
+}
+
+// Report returns the latest report, if the checkers had never been run, it runs them first
+func (r *Registry) Report(ctx context.Context) *Report {
+	r.RLock()
+	report := r.report
+	r.RUnlock()
+
+	if report != nil {
+		return report
+	}
+
+	return r.Check(ctx)
+}
+
+// Start runs the checkers periodically in the background, Report() will return the latest report after that
+func (r *Registry) Start(interval time.Duration) {
+	r.Lock()
+	if r.stopChan != nil {
+		r.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	r.stopChan = stopChan
+	r.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		r.Check(context.Background())
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				r.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop stops the background checks
+func (r *Registry) Stop() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.stopChan != nil {
+		close(r.stopChan)
+		r.stopChan = nil
+	}
+}
+
+// IsRunning returns if the background checks are running
+func (r *Registry) IsRunning() bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.stopChan != nil
+}
+
+// Handler returns a http.Handler which responds with the report in json,
+// the status code is 503 if the registry status is down, otherwise, it is 200,
+// so that it could be used as the kubernetes liveness or readiness probe,
+// if the background checks are running, it responds with the latest report, otherwise, it runs the checkers
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var report *Report
+		if r.IsRunning() {
+			report = r.Report(req.Context())
+		} else {
+			report = r.Check(req.Context())
+		}
+
+		statusCode := http.StatusOK
+		if report.Status == StatusDown {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		err := json.NewEncoder(w).Encode(report)
+		if err != nil {
+			log.Errorf("write health check report failed. %s", err.Error())
+		}
+	})
+}
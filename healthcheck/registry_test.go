@@ -0,0 +1,77 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStatusChecker struct {
+	ok    bool
+	delay time.Duration
+}
+
+func (tsc *testStatusChecker) CheckInstanceStatus() bool {
+	time.Sleep(tsc.delay)
+
+	return tsc.ok
+}
+
+func TestRegistry_Check(t *testing.T) {
+	asst := assert.New(t)
+
+	r := NewRegistry(20 * time.Millisecond)
+	asst.Nil(r.Register("mysql", NewStatusChecker(&testStatusChecker{ok: true}), true), "test Register() failed")
+	asst.NotNil(r.Register("mysql", NewStatusChecker(&testStatusChecker{ok: true}), true), "test Register() failed")
+	asst.Nil(r.Register("cache", CheckerFunc(func(ctx context.Context) error { return errors.New("test") }), false), "test Register() failed")
+
+	report := r.Check(context.Background())
+	asst.Equal(StatusDegraded, report.Status, "test Check() failed")
+	asst.Equal(2, len(report.Results), "test Check() failed")
+	asst.Equal("cache", report.Results[0].Name, "test Check() failed")
+	asst.Equal("test", report.Results[0].Error, "test Check() failed")
+
+	// slow checker will be cancelled after timeout
+	asst.Nil(r.Register("prometheus", NewStatusChecker(&testStatusChecker{ok: true, delay: time.Second}), true), "test Register() failed")
+	report = r.Check(context.Background())
+	asst.Equal(StatusDown, report.Status, "test Check() failed")
+	asst.Equal(context.DeadlineExceeded.Error(), report.Results[2].Error, "test Check() failed")
+
+	r.Unregister("prometheus")
+	r.Unregister("cache")
+	report = r.Check(context.Background())
+	asst.Equal(StatusUp, report.Status, "test Check() failed")
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	asst := assert.New(t)
+
+	checker := &testStatusChecker{ok: true}
+	r := NewRegistry(time.Second)
+	asst.Nil(r.Register("mysql", NewStatusChecker(checker), true), "test Register() failed")
+	server := httptest.NewServer(r.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	asst.Nil(err, "test Handler() failed")
+	asst.Equal(http.StatusOK, resp.StatusCode, "test Handler() failed")
+	report := &Report{}
+	asst.Nil(json.NewDecoder(resp.Body).Decode(report), "test Handler() failed")
+	_ = resp.Body.Close()
+	asst.Equal(StatusUp, report.Status, "test Handler() failed")
+
+	checker.ok = false
+	r.Start(time.Hour)
+	defer r.Stop()
+	time.Sleep(50 * time.Millisecond)
+	resp, err = http.Get(server.URL)
+	asst.Nil(err, "test Handler() failed")
+	_ = resp.Body.Close()
+	asst.Equal(http.StatusServiceUnavailable, resp.StatusCode, "test Handler() failed")
+}
@@ -2,7 +2,13 @@ package linux
 
 import (
 	"bytes"
+	"context"
+	"os"
 	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/romberli/go-util/constant"
 )
 
 // ExecuteCommand is an alias of ExecuteCommandAndWait
@@ -35,3 +41,93 @@ func ExecuteCommandNoWait(command string) (output string, err error) {
 
 	return stdoutBuffer.String(), err
 }
+
+// CommandResult is the result of the command
+type CommandResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// ExecuteCommandContext executes shell command with context and waits for it to complete,
+// stdout and stderr are captured separately, if the context is done before the command completes,
+// the whole process group of the command will be killed and the error of the context will be returned,
+// if the command exits with non-zero code, the result and the error are both returned
+func ExecuteCommandContext(ctx context.Context, command string) (*CommandResult, error) {
+	var stdoutBuffer, stderrBuffer bytes.Buffer
+
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stdout = &stdoutBuffer
+	cmd.Stderr = &stderrBuffer
+	// run the command in a new process group, so that the child processes could be killed together
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	start := time.Now()
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// negative pid means the process group
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		err = ctx.Err()
+	}
+
+	result := &CommandResult{
+		Stdout:   stdoutBuffer.String(),
+		Stderr:   stderrBuffer.String(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Duration: time.Since(start),
+	}
+
+	return result, err
+}
+
+// ExecuteCommandWithTimeout executes shell command and waits for it to complete,
+// the command will be killed if it does not complete within the timeout
+func ExecuteCommandWithTimeout(command string, timeout time.Duration) (*CommandResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return ExecuteCommandContext(ctx, command)
+}
+
+// ExecuteCommandDetached executes shell command in a new session like nohup, the command keeps running after current process exits,
+// stdout and stderr will be appended to the output file, if the output file is empty, they will be discarded,
+// it returns the pid of the command
+func ExecuteCommandDetached(command, outputFile string) (int, error) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Stdin = nil
+
+	if outputFile != constant.EmptyString {
+		f, err := os.OpenFile(outputFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, constant.DefaultFileMode)
+		if err != nil {
+			return constant.ZeroInt, err
+		}
+		defer func() { _ = f.Close() }()
+		cmd.Stdout = f
+		cmd.Stderr = f
+	}
+
+	err := cmd.Start()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	// reap the command when it exits, so that it will not become a zombie process while current process is running
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return cmd.Process.Pid, nil
+}
@@ -1,10 +1,14 @@
 package linux
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
 )
 
 func TestCommand(t *testing.T) {
@@ -30,3 +34,29 @@ func TestCommand(t *testing.T) {
 	asst.NotNil(err, "test command failed.\ncmd: %s\n%v", cmd, err)
 	t.Log("==========test command completed.==========\n")
 }
+
+func TestExecuteCommandContext(t *testing.T) {
+	asst := assert.New(t)
+
+	result, err := ExecuteCommandContext(context.Background(), "echo out; echo err >&2; exit 3")
+	asst.NotNil(err, "test ExecuteCommandContext() failed")
+	asst.Equal("out\n", result.Stdout, "test ExecuteCommandContext() failed")
+	asst.Equal("err\n", result.Stderr, "test ExecuteCommandContext() failed")
+	asst.Equal(3, result.ExitCode, "test ExecuteCommandContext() failed")
+
+	start := time.Now()
+	_, err = ExecuteCommandWithTimeout("sleep 10", 100*time.Millisecond)
+	asst.Equal(context.DeadlineExceeded, err, "test ExecuteCommandWithTimeout() failed")
+	asst.True(time.Since(start) < 5*time.Second, "test ExecuteCommandWithTimeout() failed")
+}
+
+func TestExecuteCommandDetached(t *testing.T) {
+	asst := assert.New(t)
+
+	pid, err := ExecuteCommandDetached("sleep 10", constant.EmptyString)
+	asst.Nil(err, "test ExecuteCommandDetached() failed")
+	isRunning, err := IsRunningWithPid(pid)
+	asst.Nil(err, "test ExecuteCommandDetached() failed")
+	asst.True(isRunning, "test ExecuteCommandDetached() failed")
+	asst.Nil(KillServer(pid), "test ExecuteCommandDetached() failed")
+}
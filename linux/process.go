@@ -10,10 +10,12 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/romberli/log"
 
 	"github.com/romberli/go-util/constant"
 
+	"github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
@@ -157,3 +159,126 @@ func HandleSignalsWithPidFile(pidFile string) {
 		}
 	}
 }
+
+// ProcessInfo is the brief information of a process
+type ProcessInfo struct {
+	Pid      int
+	Name     string
+	Cmdline  string
+	Username string
+}
+
+// newProcessInfo returns a new *ProcessInfo, the fields which could not be got will be empty
+func newProcessInfo(p *process.Process) *ProcessInfo {
+	name, _ := p.Name()
+	cmdline, _ := p.Cmdline()
+	username, _ := p.Username()
+
+	return &ProcessInfo{
+		Pid:      int(p.Pid),
+		Name:     name,
+		Cmdline:  cmdline,
+		Username: username,
+	}
+}
+
+// ListProcesses returns all the processes
+func ListProcesses() ([]*ProcessInfo, error) {
+	processes, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*ProcessInfo, constant.ZeroInt, len(processes))
+	for _, p := range processes {
+		infos = append(infos, newProcessInfo(p))
+	}
+
+	return infos, nil
+}
+
+// FindProcessesByName returns the processes of which name equals to given name or command line contains given name
+func FindProcessesByName(name string) ([]*ProcessInfo, error) {
+	processes, err := ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*ProcessInfo
+	for _, p := range processes {
+		if p.Pid == os.Getpid() {
+			continue
+		}
+		if p.Name == name || strings.Contains(p.Cmdline, name) {
+			infos = append(infos, p)
+		}
+	}
+
+	return infos, nil
+}
+
+// FindProcessesByPort returns the processes which listen on given port
+func FindProcessesByPort(port int) ([]*ProcessInfo, error) {
+	conns, err := net.Connections("inet")
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*ProcessInfo
+	pids := make(map[int32]bool)
+	for _, conn := range conns {
+		if conn.Laddr.Port != uint32(port) || conn.Status != "LISTEN" || conn.Pid == constant.ZeroInt || pids[conn.Pid] {
+			continue
+		}
+		pids[conn.Pid] = true
+
+		p, err := process.NewProcess(conn.Pid)
+		if err != nil {
+			if err == process.ErrorProcessNotRunning {
+				continue
+			}
+			return nil, err
+		}
+		infos = append(infos, newProcessInfo(p))
+	}
+
+	return infos, nil
+}
+
+// KillProcessesByName sends signal to the processes found by FindProcessesByName(), it returns the killed pids
+func KillProcessesByName(name string, sig syscall.Signal) ([]int, error) {
+	processes, err := FindProcessesByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return signalProcesses(processes, sig)
+}
+
+// KillProcessesByPort sends signal to the processes which listen on given port, it returns the killed pids
+func KillProcessesByPort(port int, sig syscall.Signal) ([]int, error) {
+	processes, err := FindProcessesByPort(port)
+	if err != nil {
+		return nil, err
+	}
+
+	return signalProcesses(processes, sig)
+}
+
+// signalProcesses sends signal to the processes, it returns the pids which had been sent the signal successfully
+func signalProcesses(processes []*ProcessInfo, sig syscall.Signal) ([]int, error) {
+	var (
+		pids []int
+		merr *multierror.Error
+	)
+	for _, p := range processes {
+		err := SendSignal(p.Pid, sig)
+		if err != nil {
+			merr = multierror.Append(merr, errors.New(fmt.Sprintf("send signal to process failed. pid: %d, signal: %d. %s", p.Pid, sig, err.Error())))
+			continue
+		}
+		pids = append(pids, p.Pid)
+	}
+
+	return pids, merr.ErrorOrNil()
+}
@@ -3,6 +3,7 @@ package linux
 import (
 	"fmt"
 	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -96,3 +97,18 @@ func TestProcess(t *testing.T) {
 	asst.Nil(err, "HandleSignalsWithPidFile failed.")
 	t.Log("==========HandleSignalsWithPidFile completed.==========")
 }
+
+func TestFindProcessesByName(t *testing.T) {
+	asst := assert.New(t)
+
+	pid, err := ExecuteCommandDetached("sleep 12.345", constant.EmptyString)
+	asst.Nil(err, "test FindProcessesByName() failed")
+	time.Sleep(100 * time.Millisecond)
+
+	processes, err := FindProcessesByName("sleep 12.345")
+	asst.Nil(err, "test FindProcessesByName() failed")
+	asst.True(len(processes) > 0, "test FindProcessesByName() failed")
+	pids, err := KillProcessesByName("sleep 12.345", syscall.SIGKILL)
+	asst.Nil(err, "test KillProcessesByName() failed")
+	asst.Contains(pids, pid, "test KillProcessesByName() failed")
+}
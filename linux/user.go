@@ -0,0 +1,86 @@
+package linux
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const rootUid = 0
+
+// UserExists returns if the user exists
+func UserExists(userName string) (bool, error) {
+	_, err := user.Lookup(userName)
+	if err != nil {
+		if _, ok := err.(user.UnknownUserError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GroupExists returns if the group exists
+func GroupExists(groupName string) (bool, error) {
+	_, err := user.LookupGroup(groupName)
+	if err != nil {
+		if _, ok := err.(user.UnknownGroupError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsUserInGroup returns if the user belongs to the group, the primary group is included
+func IsUserInGroup(userName, groupName string) (bool, error) {
+	u, err := user.Lookup(userName)
+	if err != nil {
+		return false, err
+	}
+	g, err := user.LookupGroup(groupName)
+	if err != nil {
+		return false, err
+	}
+
+	gids, err := u.GroupIds()
+	if err != nil {
+		return false, err
+	}
+	for _, gid := range gids {
+		if gid == g.Gid {
+			return true, nil
+		}
+	}
+
+	return u.Gid == g.Gid, nil
+}
+
+// GetCurrentUserName returns the name of current user
+func GetCurrentUserName() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return u.Username, nil
+}
+
+// IsCurrentUser returns if current user is given user
+func IsCurrentUser(userName string) (bool, error) {
+	u, err := user.Lookup(userName)
+	if err != nil {
+		return false, err
+	}
+
+	return u.Uid == strconv.Itoa(os.Geteuid()), nil
+}
+
+// IsRoot returns if current process runs as root
+func IsRoot() bool {
+	return os.Geteuid() == rootUid
+}
@@ -0,0 +1,32 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser(t *testing.T) {
+	asst := assert.New(t)
+
+	userName, err := GetCurrentUserName()
+	asst.Nil(err, "test GetCurrentUserName() failed")
+	exists, err := UserExists(userName)
+	asst.Nil(err, "test UserExists() failed")
+	asst.True(exists, "test UserExists() failed")
+	exists, err = UserExists("go-util-not-exists")
+	asst.Nil(err, "test UserExists() failed")
+	asst.False(exists, "test UserExists() failed")
+	isCurrent, err := IsCurrentUser(userName)
+	asst.Nil(err, "test IsCurrentUser() failed")
+	asst.True(isCurrent, "test IsCurrentUser() failed")
+
+	exists, err = GroupExists("go-util-not-exists")
+	asst.Nil(err, "test GroupExists() failed")
+	asst.False(exists, "test GroupExists() failed")
+	if IsRoot() {
+		inGroup, err := IsUserInGroup(userName, "root")
+		asst.Nil(err, "test IsUserInGroup() failed")
+		asst.True(inGroup, "test IsUserInGroup() failed")
+	}
+}
@@ -0,0 +1,121 @@
+package locker
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+
+	"github.com/romberli/go-util/middleware/etcd"
+)
+
+var _ Locker = (*EtcdLocker)(nil)
+
+// EtcdLocker acquires locks by creating a key attached to a lease,
+// the create revision of the key is used as the fencing token which increases monotonically in the cluster
+type EtcdLocker struct {
+	conn   *etcd.Conn
+	prefix string
+}
+
+// NewEtcdLocker returns a new *EtcdLocker, all the lock keys will be prefixed with given prefix
+func NewEtcdLocker(conn *etcd.Conn, prefix string) *EtcdLocker {
+	return &EtcdLocker{
+		conn:   conn,
+		prefix: prefix,
+	}
+}
+
+// Lock tries to acquire the lock of given key once, etcd lease ttl is in seconds, so ttl will be rounded up to seconds
+func (el *EtcdLocker) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	leaseResp, err := el.conn.Grant(ctx, ttlSeconds(ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	lockKey := el.prefix + key
+	txnResp, err := el.conn.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(lockKey), "=", etcd.ZeroRevision)).
+		Then(clientv3.OpPut(lockKey, owner, clientv3.WithLease(leaseResp.ID))).
+		Commit()
+	if err != nil || !txnResp.Succeeded {
+		_, _ = el.conn.Revoke(context.Background(), leaseResp.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrNotAcquired
+	}
+
+	return &etcdLock{
+		locker:  el,
+		key:     key,
+		lockKey: lockKey,
+		owner:   owner,
+		leaseID: leaseResp.ID,
+		token:   txnResp.Header.Revision,
+	}, nil
+}
+
+type etcdLock struct {
+	locker  *EtcdLocker
+	key     string
+	lockKey string
+	owner   string
+	leaseID clientv3.LeaseID
+	token   int64
+}
+
+// Key returns the key of the lock
+func (el *etcdLock) Key() string {
+	return el.key
+}
+
+// Token returns the fencing token
+func (el *etcdLock) Token() int64 {
+	return el.token
+}
+
+// Renew keeps the lease alive, note that etcd always renews the lease with the ttl granted at the beginning,
+// so the ttl argument is ignored
+func (el *etcdLock) Renew(ctx context.Context, ttl time.Duration) error {
+	resp, err := el.locker.conn.KeepAliveOnce(ctx, el.leaseID)
+	if err == rpctypes.ErrLeaseNotFound {
+		return ErrLockLost
+	}
+	if err != nil {
+		return err
+	}
+	if resp.TTL <= 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Unlock releases the lock by revoking the lease, the lock key will be deleted with the lease
+func (el *etcdLock) Unlock(ctx context.Context) error {
+	_, err := el.locker.conn.Revoke(ctx, el.leaseID)
+	if err == rpctypes.ErrLeaseNotFound {
+		return ErrLockLost
+	}
+
+	return err
+}
+
+// ttlSeconds converts ttl to seconds, it rounds up and the minimum value is 1
+func ttlSeconds(ttl time.Duration) int64 {
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+
+	return seconds
+}
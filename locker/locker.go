@@ -0,0 +1,82 @@
+package locker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultRetryInterval = 100 * time.Millisecond
+
+	ownerRandomBytes = 8
+)
+
+var (
+	// ErrNotAcquired is returned when the lock is held by others
+	ErrNotAcquired = errors.New("lock is held by others")
+	// ErrLockLost is returned when renewing or unlocking a lock which had expired and may be acquired by others
+	ErrLockLost = errors.New("lock had expired or been acquired by others")
+)
+
+// Locker acquires distributed locks from the backend
+type Locker interface {
+	// Lock tries to acquire the lock of given key once, the lock will expire after ttl if it is not renewed,
+	// if the lock is held by others, it returns ErrNotAcquired
+	Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is an acquired distributed lock
+type Lock interface {
+	// Key returns the key of the lock
+	Key() string
+	// Token returns the fencing token, it increases every time the lock of the same key is acquired,
+	// the protected resource should reject the requests with a smaller token than the one it has seen
+	Token() int64
+	// Renew extends the expiration of the lock, if the lock had been lost, it returns ErrLockLost
+	Renew(ctx context.Context, ttl time.Duration) error
+	// Unlock releases the lock, if the lock had been lost, it returns ErrLockLost
+	Unlock(ctx context.Context) error
+}
+
+// LockWithRetry tries to acquire the lock until succeeded or the context is done
+func LockWithRetry(ctx context.Context, locker Locker, key string, ttl, retryInterval time.Duration) (Lock, error) {
+	if retryInterval <= constant.ZeroInt {
+		retryInterval = DefaultRetryInterval
+	}
+
+	for {
+		lock, err := locker.Lock(ctx, key, ttl)
+		if err != ErrNotAcquired {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.New(fmt.Sprintf("acquiring lock failed. key: %s. %s", key, ctx.Err().Error()))
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// newOwner returns a unique owner id which identifies the lock holder, it is in the form of hostname-pid-random
+func newOwner() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	b := make([]byte, ownerRandomBytes)
+	_, err = rand.Read(b)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,156 @@
+package locker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+)
+
+const (
+	DefaultMySQLLockTable = "t_lock"
+
+	mysqlCreateTableSQL = `create table if not exists %s (
+	lock_key varchar(200) not null primary key,
+	owner varchar(200) not null,
+	token bigint not null,
+	expire_time datetime(6) not null
+) engine = innodb`
+	// mysqlLockSQL inserts the lock row or takes it over if it had expired,
+	// note that mysql assigns the columns from left to right, so expire_time must be the last one
+	mysqlLockSQL = `insert into %s(lock_key, owner, token, expire_time) values(?, ?, 1, now(6) + interval ? microsecond)
+on duplicate key update
+	owner = if(expire_time < now(6), values(owner), owner),
+	token = if(expire_time < now(6), token + 1, token),
+	expire_time = if(expire_time < now(6), values(expire_time), expire_time)`
+	mysqlSelectSQL = `select owner, token from %s where lock_key = ?`
+	mysqlRenewSQL  = `update %s set expire_time = now(6) + interval ? microsecond
+where lock_key = ? and owner = ? and expire_time >= now(6)`
+	// mysqlUnlockSQL expires the lock row instead of deleting it, so that the token keeps increasing
+	mysqlUnlockSQL = `update %s set expire_time = now(6) - interval 1 second
+where lock_key = ? and owner = ? and expire_time >= now(6)`
+)
+
+var _ Locker = (*MySQLLocker)(nil)
+
+// MySQLLocker acquires locks by maintaining rows in a lock table, the token column is used as the fencing token
+type MySQLLocker struct {
+	pool  middleware.Pool
+	table string
+}
+
+// NewMySQLLocker returns a new *MySQLLocker, if table is empty, it will use DefaultMySQLLockTable
+func NewMySQLLocker(pool middleware.Pool, table string) *MySQLLocker {
+	if table == constant.EmptyString {
+		table = DefaultMySQLLockTable
+	}
+
+	return &MySQLLocker{
+		pool:  pool,
+		table: table,
+	}
+}
+
+// CreateTable creates the lock table if it does not exist
+func (ml *MySQLLocker) CreateTable(ctx context.Context) error {
+	_, err := ml.execute(ctx, fmt.Sprintf(mysqlCreateTableSQL, ml.table))
+
+	return err
+}
+
+// Lock tries to acquire the lock of given key once
+func (ml *MySQLLocker) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ml.execute(ctx, fmt.Sprintf(mysqlLockSQL, ml.table), key, owner, ttl.Microseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ml.execute(ctx, fmt.Sprintf(mysqlSelectSQL, ml.table), key)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return nil, ErrNotAcquired
+	}
+	currentOwner, err := result.GetString(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return nil, err
+	}
+	if currentOwner != owner {
+		return nil, ErrNotAcquired
+	}
+	token, err := result.GetInt(constant.ZeroInt, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mysqlLock{
+		locker: ml,
+		key:    key,
+		owner:  owner,
+		token:  int64(token),
+	}, nil
+}
+
+// execute gets a connection from the pool and executes given command
+func (ml *MySQLLocker) execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	conn, err := ml.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.ExecuteContext(ctx, command, args...)
+}
+
+// update executes given command and returns ErrLockLost if no row was affected
+func (ml *MySQLLocker) update(ctx context.Context, command string, args ...interface{}) error {
+	result, err := ml.execute(ctx, command, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == constant.ZeroInt {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+type mysqlLock struct {
+	locker *MySQLLocker
+	key    string
+	owner  string
+	token  int64
+}
+
+// Key returns the key of the lock
+func (ml *mysqlLock) Key() string {
+	return ml.key
+}
+
+// Token returns the fencing token
+func (ml *mysqlLock) Token() int64 {
+	return ml.token
+}
+
+// Renew extends the expiration of the lock
+func (ml *mysqlLock) Renew(ctx context.Context, ttl time.Duration) error {
+	return ml.locker.update(ctx, fmt.Sprintf(mysqlRenewSQL, ml.locker.table), ttl.Microseconds(), ml.key, ml.owner)
+}
+
+// Unlock releases the lock
+func (ml *mysqlLock) Unlock(ctx context.Context) error {
+	return ml.locker.update(ctx, fmt.Sprintf(mysqlUnlockSQL, ml.locker.table), ml.key, ml.owner)
+}
@@ -0,0 +1,149 @@
+package locker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	redisTokenKeySuffix = ":token"
+
+	// redisLockScript sets the lock key if it does not exist and increases the fencing token,
+	// it returns the token if succeeded, otherwise, it returns 0
+	redisLockScript = `if redis.call('set', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('incr', KEYS[2])
+end
+return 0`
+	// redisRenewScript extends the expiration of the lock key only if it is still held by the owner
+	redisRenewScript = `if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0`
+	// redisUnlockScript deletes the lock key only if it is still held by the owner
+	redisUnlockScript = `if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0`
+)
+
+// RedisEvaler runs lua scripts on redis, the result of integer reply should be int64,
+// most redis clients could be adapted by RedisEvalFunc, for example, with go-redis:
+//
+//	locker.RedisEvalFunc(func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//	    return client.Eval(ctx, script, keys, args...).Result()
+//	})
+type RedisEvaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisEvalFunc is an adapter to allow the use of ordinary functions as RedisEvaler
+type RedisEvalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+
+// Eval calls f(ctx, script, keys, args...)
+func (f RedisEvalFunc) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return f(ctx, script, keys, args...)
+}
+
+var _ Locker = (*RedisLocker)(nil)
+
+// RedisLocker acquires locks with SET NX, the fencing token is maintained in another key with INCR
+type RedisLocker struct {
+	evaler RedisEvaler
+	prefix string
+}
+
+// NewRedisLocker returns a new *RedisLocker, all the lock keys will be prefixed with given prefix
+func NewRedisLocker(evaler RedisEvaler, prefix string) *RedisLocker {
+	return &RedisLocker{
+		evaler: evaler,
+		prefix: prefix,
+	}
+}
+
+// Lock tries to acquire the lock of given key once
+func (rl *RedisLocker) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	lockKey := rl.prefix + key
+	token, err := rl.eval(ctx, redisLockScript, []string{lockKey, lockKey + redisTokenKeySuffix}, owner, ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	if token == constant.ZeroInt {
+		return nil, ErrNotAcquired
+	}
+
+	return &redisLock{
+		locker:  rl,
+		key:     key,
+		lockKey: lockKey,
+		owner:   owner,
+		token:   token,
+	}, nil
+}
+
+// eval runs the script and returns the integer reply
+func (rl *RedisLocker) eval(ctx context.Context, script string, keys []string, args ...interface{}) (int64, error) {
+	result, err := rl.evaler.Eval(ctx, script, keys, args...)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	n, ok := result.(int64)
+	if !ok {
+		return constant.ZeroInt, errors.New(fmt.Sprintf("redis script should return an integer. actual: %T", result))
+	}
+
+	return n, nil
+}
+
+type redisLock struct {
+	locker  *RedisLocker
+	key     string
+	lockKey string
+	owner   string
+	token   int64
+}
+
+// Key returns the key of the lock
+func (rl *redisLock) Key() string {
+	return rl.key
+}
+
+// Token returns the fencing token
+func (rl *redisLock) Token() int64 {
+	return rl.token
+}
+
+// Renew extends the expiration of the lock
+func (rl *redisLock) Renew(ctx context.Context, ttl time.Duration) error {
+	n, err := rl.locker.eval(ctx, redisRenewScript, []string{rl.lockKey}, rl.owner, ttl.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if n == constant.ZeroInt {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Unlock releases the lock
+func (rl *redisLock) Unlock(ctx context.Context) error {
+	n, err := rl.locker.eval(ctx, redisUnlockScript, []string{rl.lockKey}, rl.owner)
+	if err != nil {
+		return err
+	}
+	if n == constant.ZeroInt {
+		return ErrLockLost
+	}
+
+	return nil
+}
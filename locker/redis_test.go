@@ -0,0 +1,111 @@
+package locker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRedisEntry struct {
+	value    string
+	expireAt time.Time
+}
+
+// testRedis simulates the lua scripts used by RedisLocker in memory
+type testRedis struct {
+	sync.Mutex
+	data   map[string]*testRedisEntry
+	tokens map[string]int64
+}
+
+func newTestRedis() *testRedis {
+	return &testRedis{
+		data:   make(map[string]*testRedisEntry),
+		tokens: make(map[string]int64),
+	}
+}
+
+func (tr *testRedis) get(key string) (string, bool) {
+	entry, ok := tr.data[key]
+	if !ok || time.Now().After(entry.expireAt) {
+		delete(tr.data, key)
+		return "", false
+	}
+
+	return entry.value, true
+}
+
+func (tr *testRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	tr.Lock()
+	defer tr.Unlock()
+
+	value, exists := tr.get(keys[0])
+	switch script {
+	case redisLockScript:
+		if exists {
+			return int64(0), nil
+		}
+		tr.data[keys[0]] = &testRedisEntry{args[0].(string), time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)}
+		tr.tokens[keys[1]]++
+		return tr.tokens[keys[1]], nil
+	case redisRenewScript:
+		if !exists || value != args[0].(string) {
+			return int64(0), nil
+		}
+		tr.data[keys[0]].expireAt = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+		return int64(1), nil
+	case redisUnlockScript:
+		if !exists || value != args[0].(string) {
+			return int64(0), nil
+		}
+		delete(tr.data, keys[0])
+		return int64(1), nil
+	}
+
+	return nil, nil
+}
+
+func TestRedisLocker_Lock(t *testing.T) {
+	asst := assert.New(t)
+
+	ctx := context.Background()
+	locker := NewRedisLocker(newTestRedis(), "lock:")
+
+	lock1, err := locker.Lock(ctx, "test", time.Second)
+	asst.Nil(err, "test Lock() failed")
+	asst.Equal("test", lock1.Key(), "test Lock() failed")
+	asst.Equal(int64(1), lock1.Token(), "test Lock() failed")
+	_, err = locker.Lock(ctx, "test", time.Second)
+	asst.Equal(ErrNotAcquired, err, "test Lock() failed")
+
+	asst.Nil(lock1.Renew(ctx, time.Second), "test Renew() failed")
+	asst.Nil(lock1.Unlock(ctx), "test Unlock() failed")
+	asst.Equal(ErrLockLost, lock1.Unlock(ctx), "test Unlock() failed")
+
+	// the token increases every time the lock is acquired
+	lock2, err := locker.Lock(ctx, "test", 10*time.Millisecond)
+	asst.Nil(err, "test Lock() failed")
+	asst.Equal(int64(2), lock2.Token(), "test Lock() failed")
+
+	// the expired lock could be acquired by others and could not be renewed any more
+	lock3, err := LockWithRetry(ctx, locker, "test", time.Second, 5*time.Millisecond)
+	asst.Nil(err, "test LockWithRetry() failed")
+	asst.Equal(int64(3), lock3.Token(), "test LockWithRetry() failed")
+	asst.Equal(ErrLockLost, lock2.Renew(ctx, time.Second), "test Renew() failed")
+}
+
+func TestLockWithRetry(t *testing.T) {
+	asst := assert.New(t)
+
+	locker := NewRedisLocker(newTestRedis(), "lock:")
+	_, err := locker.Lock(context.Background(), "test", time.Minute)
+	asst.Nil(err, "test Lock() failed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = LockWithRetry(ctx, locker, "test", time.Second, 5*time.Millisecond)
+	asst.NotNil(err, "test LockWithRetry() failed")
+}
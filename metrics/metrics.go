@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	Namespace = "go_util"
+
+	ComponentMySQL      = "mysql"
+	ComponentClickhouse = "clickhouse"
+	ComponentPrometheus = "prometheus"
+	ComponentKafka      = "kafka"
+
+	OperationExecute = "execute"
+	OperationPublish = "publish"
+
+	BreakerResultSuccess  = "success"
+	BreakerResultFailure  = "failure"
+	BreakerResultSlow     = "slow"
+	BreakerResultRejected = "rejected"
+
+	componentLabel = "component"
+	operationLabel = "operation"
+	nameLabel      = "name"
+	resultLabel    = "result"
+)
+
+var (
+	// DefaultBuckets are the histogram buckets in seconds, they range from 1ms to 10s
+	DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	globalMutex      sync.RWMutex
+	globalCollectors *collectors
+)
+
+type collectors struct {
+	registerer        prometheus.Registerer
+	acquireDuration   *prometheus.HistogramVec
+	acquireErrors     *prometheus.CounterVec
+	operationDuration *prometheus.HistogramVec
+	operationErrors   *prometheus.CounterVec
+	breakerState      *prometheus.GaugeVec
+	breakerCalls      *prometheus.CounterVec
+}
+
+// newCollectors returns a new *collectors
+func newCollectors(registerer prometheus.Registerer) *collectors {
+	return &collectors{
+		registerer: registerer,
+		acquireDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "connection_acquire_duration_seconds",
+			Help:      "Time spent on acquiring a connection from the pool.",
+			Buckets:   DefaultBuckets,
+		}, []string{componentLabel}),
+		acquireErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "connection_acquire_errors_total",
+			Help:      "Number of failures when acquiring a connection from the pool.",
+		}, []string{componentLabel}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of the operations on the middlewares, such as query and publish.",
+			Buckets:   DefaultBuckets,
+		}, []string{componentLabel, operationLabel}),
+		operationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "operation_errors_total",
+			Help:      "Number of failed operations on the middlewares.",
+		}, []string{componentLabel, operationLabel}),
+		breakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breakers, 0 is closed, 1 is open and 2 is half-open.",
+		}, []string{nameLabel}),
+		breakerCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "circuit_breaker_calls_total",
+			Help:      "Number of the calls through the circuit breakers by result.",
+		}, []string{nameLabel, resultLabel}),
+	}
+}
+
+// all returns all the collectors
+func (c *collectors) all() []prometheus.Collector {
+	return []prometheus.Collector{c.acquireDuration, c.acquireErrors, c.operationDuration, c.operationErrors,
+		c.breakerState, c.breakerCalls}
+}
+
+// EnableMetrics registers the collectors to given registerer and enables the instrumentation of all the middlewares,
+// if registerer is nil, it will use prometheus.DefaultRegisterer,
+// if metrics had already been enabled, it will be disabled first
+func EnableMetrics(registerer prometheus.Registerer) error {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	if globalCollectors != nil {
+		globalCollectors.unregister()
+		globalCollectors = nil
+	}
+
+	c := newCollectors(registerer)
+	var registered []prometheus.Collector
+	for _, collector := range c.all() {
+		err := registerer.Register(collector)
+		if err != nil {
+			for _, r := range registered {
+				registerer.Unregister(r)
+			}
+
+			return err
+		}
+		registered = append(registered, collector)
+	}
+
+	globalCollectors = c
+
+	return nil
+}
+
+// DisableMetrics unregisters the collectors and disables the instrumentation
+func DisableMetrics() {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	if globalCollectors != nil {
+		globalCollectors.unregister()
+		globalCollectors = nil
+	}
+}
+
+// IsEnabled returns if metrics is enabled
+func IsEnabled() bool {
+	return getCollectors() != nil
+}
+
+// unregister unregisters all the collectors
+func (c *collectors) unregister() {
+	for _, collector := range c.all() {
+		c.registerer.Unregister(collector)
+	}
+}
+
+// ObserveAcquire records the time spent on acquiring a connection from the pool of given component,
+// it does nothing if metrics is disabled
+func ObserveAcquire(component string, start time.Time, err error) {
+	c := getCollectors()
+	if c == nil {
+		return
+	}
+
+	c.acquireDuration.WithLabelValues(component).Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.acquireErrors.WithLabelValues(component).Inc()
+	}
+}
+
+// ObserveOperation records the latency of the operation on given component,
+// it does nothing if metrics is disabled
+func ObserveOperation(component, operation string, start time.Time, err error) {
+	c := getCollectors()
+	if c == nil {
+		return
+	}
+
+	c.operationDuration.WithLabelValues(component, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.operationErrors.WithLabelValues(component, operation).Inc()
+	}
+}
+
+// CountOperationError increases the error count of the operation on given component, it is used for the asynchronous
+// operations whose latency could not be observed, it does nothing if metrics is disabled
+func CountOperationError(component, operation string) {
+	c := getCollectors()
+	if c == nil {
+		return
+	}
+
+	c.operationErrors.WithLabelValues(component, operation).Inc()
+}
+
+// SetBreakerState records the state of the circuit breaker, it does nothing if metrics is disabled
+func SetBreakerState(name string, state int) {
+	c := getCollectors()
+	if c == nil {
+		return
+	}
+
+	c.breakerState.WithLabelValues(name).Set(float64(state))
+}
+
+// CountBreakerCall increases the call count of the circuit breaker by result, it does nothing if metrics is disabled
+func CountBreakerCall(name, result string) {
+	c := getCollectors()
+	if c == nil {
+		return
+	}
+
+	c.breakerCalls.WithLabelValues(name, result).Inc()
+}
+
+// getCollectors returns the global collectors, it returns nil if metrics is disabled
+func getCollectors() *collectors {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	return globalCollectors
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableMetrics(t *testing.T) {
+	asst := assert.New(t)
+
+	// observing does nothing before enabled
+	ObserveOperation(ComponentMySQL, OperationExecute, time.Now(), nil)
+	asst.False(IsEnabled(), "test IsEnabled() failed")
+
+	registry := prometheus.NewRegistry()
+	asst.Nil(EnableMetrics(registry), "test EnableMetrics() failed")
+	defer DisableMetrics()
+	asst.True(IsEnabled(), "test IsEnabled() failed")
+
+	ObserveAcquire(ComponentMySQL, time.Now(), nil)
+	ObserveAcquire(ComponentMySQL, time.Now(), errors.New("test"))
+	ObserveOperation(ComponentMySQL, OperationExecute, time.Now(), errors.New("test"))
+	CountOperationError(ComponentKafka, OperationPublish)
+
+	c := getCollectors()
+	asst.Equal(float64(1), testutil.ToFloat64(c.acquireErrors.WithLabelValues(ComponentMySQL)), "test ObserveAcquire() failed")
+	asst.Equal(float64(1), testutil.ToFloat64(c.operationErrors.WithLabelValues(ComponentMySQL, OperationExecute)), "test ObserveOperation() failed")
+	asst.Equal(float64(1), testutil.ToFloat64(c.operationErrors.WithLabelValues(ComponentKafka, OperationPublish)), "test CountOperationError() failed")
+	count, err := testutil.GatherAndCount(registry, Namespace+"_connection_acquire_duration_seconds")
+	asst.Nil(err, "test ObserveAcquire() failed")
+	asst.Equal(1, count, "test ObserveAcquire() failed")
+
+	// enabling again will replace the registered collectors
+	asst.Nil(EnableMetrics(registry), "test EnableMetrics() failed")
+	DisableMetrics()
+	asst.False(IsEnabled(), "test DisableMetrics() failed")
+	count, err = testutil.GatherAndCount(registry)
+	asst.Nil(err, "test DisableMetrics() failed")
+	asst.Equal(0, count, "test DisableMetrics() failed")
+}
@@ -0,0 +1,205 @@
+package alertmanager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	alertsPath      = apiV2Prefix + "/alerts"
+	alertGroupsPath = alertsPath + "/groups"
+
+	alertNameLabel = "alertname"
+
+	AlertStateActive      = "active"
+	AlertStateSuppressed  = "suppressed"
+	AlertStateUnprocessed = "unprocessed"
+)
+
+// Alert is used to post alerts to alertmanager
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// postableAlert is the alert sent to alertmanager, zero time must be omitted,
+// otherwise, alertmanager will consider the alert as resolved
+type postableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     *time.Time        `json:"startsAt,omitempty"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// newPostableAlert converts the alert to a *postableAlert
+func newPostableAlert(a *Alert) *postableAlert {
+	pa := &postableAlert{
+		Labels:       a.Labels,
+		Annotations:  a.Annotations,
+		GeneratorURL: a.GeneratorURL,
+	}
+	if !a.StartsAt.IsZero() {
+		startsAt := a.StartsAt
+		pa.StartsAt = &startsAt
+	}
+	if !a.EndsAt.IsZero() {
+		endsAt := a.EndsAt
+		pa.EndsAt = &endsAt
+	}
+
+	return pa
+}
+
+// NewAlert returns a new *Alert with given alert name, other labels could be added by AddLabel()
+func NewAlert(name string) *Alert {
+	return &Alert{
+		Labels:      map[string]string{alertNameLabel: name},
+		Annotations: make(map[string]string),
+		StartsAt:    time.Now(),
+	}
+}
+
+// AddLabel adds a label to the alert and returns the alert itself
+func (a *Alert) AddLabel(name, value string) *Alert {
+	a.Labels[name] = value
+
+	return a
+}
+
+// AddAnnotation adds an annotation to the alert and returns the alert itself
+func (a *Alert) AddAnnotation(name, value string) *Alert {
+	a.Annotations[name] = value
+
+	return a
+}
+
+// Resolve sets the end time of the alert to now, posting the alert again will resolve it
+func (a *Alert) Resolve() *Alert {
+	a.EndsAt = time.Now()
+
+	return a
+}
+
+type AlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+type Receiver struct {
+	Name string `json:"name"`
+}
+
+// GettableAlert is the alert returned by alertmanager
+type GettableAlert struct {
+	Alert
+	Fingerprint string      `json:"fingerprint"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Status      AlertStatus `json:"status"`
+	Receivers   []*Receiver `json:"receivers"`
+}
+
+// AlertFilter is used to filter the alerts, nil means no filter on the state
+type AlertFilter struct {
+	Active      *bool
+	Silenced    *bool
+	Inhibited   *bool
+	Unprocessed *bool
+	Receiver    string
+	// Matchers are label matcher expressions, for example: severity=~"critical|warning"
+	Matchers []string
+}
+
+// values returns the filter as url values
+func (f *AlertFilter) values() url.Values {
+	values := url.Values{}
+	if f == nil {
+		return values
+	}
+
+	setBool := func(key string, value *bool) {
+		if value != nil {
+			values.Set(key, strconv.FormatBool(*value))
+		}
+	}
+	setBool("active", f.Active)
+	setBool("silenced", f.Silenced)
+	setBool("inhibited", f.Inhibited)
+	setBool("unprocessed", f.Unprocessed)
+	if f.Receiver != constant.EmptyString {
+		values.Set("receiver", f.Receiver)
+	}
+
+	return values
+}
+
+// PostAlerts posts the alerts to alertmanager
+func (conn *Conn) PostAlerts(ctx context.Context, alerts ...*Alert) error {
+	if len(alerts) == constant.ZeroInt {
+		return errors.New("alerts should not be empty")
+	}
+	postableAlerts := make([]*postableAlert, len(alerts))
+	for i, alert := range alerts {
+		if len(alert.Labels) == constant.ZeroInt {
+			return errors.New("alert must have at least one label")
+		}
+		postableAlerts[i] = newPostableAlert(alert)
+	}
+
+	return conn.do(ctx, http.MethodPost, alertsPath, postableAlerts, nil)
+}
+
+// GetAlerts returns the alerts which match the filter, if filter is nil, all the alerts will be returned
+func (conn *Conn) GetAlerts(ctx context.Context, filter *AlertFilter) ([]*GettableAlert, error) {
+	var matchers []string
+	if filter != nil {
+		matchers = filter.Matchers
+	}
+
+	var alerts []*GettableAlert
+
+	err := conn.do(ctx, http.MethodGet, withFilters(alertsPath, filter.values(), matchers), nil, &alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// AlertGroup is the alerts grouped by the route of alertmanager
+type AlertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver Receiver          `json:"receiver"`
+	Alerts   []*GettableAlert  `json:"alerts"`
+}
+
+// GetAlertGroups returns the alert groups which contain the alerts matching the filter,
+// if filter is nil, all the alert groups will be returned, note that unprocessed is not supported by this api
+func (conn *Conn) GetAlertGroups(ctx context.Context, filter *AlertFilter) ([]*AlertGroup, error) {
+	var matchers []string
+	if filter != nil {
+		matchers = filter.Matchers
+	}
+
+	values := filter.values()
+	values.Del("unprocessed")
+
+	var groups []*AlertGroup
+
+	err := conn.do(ctx, http.MethodGet, withFilters(alertGroupsPath, values, matchers), nil, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,179 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+
+	defaultHTTPPrefix  = "http://"
+	defaultHTTPSPrefix = "https://"
+
+	contentTypeHeader   = "Content-Type"
+	authorizationHeader = "Authorization"
+	bearerAuthPrefix    = "Bearer "
+	jsonContentType     = "application/json"
+	healthPath          = "/-/healthy"
+	apiV2Prefix         = "/api/v2"
+)
+
+type Config struct {
+	Addr string
+	User string
+	Pass string
+	// Token is the bearer token, it is used only if User is empty, it could be encrypted by the credential package
+	Token   string
+	Timeout time.Duration
+	// TLSConfig is used to connect to alertmanager with https, nil means the default tls config
+	TLSConfig *tls.Config
+}
+
+// NewConfig returns a new Config
+func NewConfig(addr, user, pass string, timeout time.Duration) Config {
+	address := strings.ToLower(addr)
+	if !strings.HasPrefix(address, defaultHTTPPrefix) && !strings.HasPrefix(address, defaultHTTPSPrefix) {
+		addr = defaultHTTPPrefix + addr
+	}
+
+	return Config{
+		Addr:    strings.TrimSuffix(addr, constant.SlashString),
+		User:    user,
+		Pass:    pass,
+		Timeout: timeout,
+	}
+}
+
+// NewConfigWithToken returns a new Config with bearer token authentication
+func NewConfigWithToken(addr, token string, timeout time.Duration) Config {
+	cfg := NewConfig(addr, constant.EmptyString, constant.EmptyString, timeout)
+	cfg.Token = token
+
+	return cfg
+}
+
+// NewConfigWithDefault returns a new Config without authentication
+func NewConfigWithDefault(addr string) Config {
+	return NewConfig(addr, constant.EmptyString, constant.EmptyString, DefaultTimeout)
+}
+
+// APIError is returned when alertmanager responds with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("alertmanager api returned an error. status code: %d, message: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns if given error is an alertmanager api error with 404 status code
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+type Conn struct {
+	Config
+	Client *http.Client
+}
+
+// NewConn returns a new *Conn with given address
+func NewConn(addr string) *Conn {
+	return NewConnWithConfig(NewConfigWithDefault(addr))
+}
+
+// NewConnWithConfig returns a new *Conn with given config
+func NewConnWithConfig(config Config) *Conn {
+	cli := &http.Client{Timeout: config.Timeout}
+	if config.TLSConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config.TLSConfig
+		cli.Transport = transport
+	}
+
+	return &Conn{
+		Config: config,
+		Client: cli,
+	}
+}
+
+// CheckInstanceStatus checks alertmanager instance status
+func (conn *Conn) CheckInstanceStatus() bool {
+	return conn.do(context.Background(), http.MethodGet, healthPath, nil, nil) == nil
+}
+
+// do sends the request to alertmanager, in will be marshaled as the request body if it is not nil,
+// and the response body will be unmarshaled to out if it is not nil
+func (conn *Conn) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, conn.Addr+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set(contentTypeHeader, jsonContentType)
+	}
+	if conn.User != constant.EmptyString {
+		pass, err := credential.DecryptIfNeeded(conn.Pass)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(conn.User, pass)
+	} else if conn.Token != constant.EmptyString {
+		token, err := credential.DecryptIfNeeded(conn.Token)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(authorizationHeader, bearerAuthPrefix+token)
+	}
+
+	resp, err := conn.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+
+	if out == nil || len(data) == constant.ZeroInt {
+		return nil
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return errors.New(fmt.Sprintf("unmarshal alertmanager response failed. method: %s, path: %s. %s", method, path, err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer returns a fake alertmanager server which only serves the apis used in the tests
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	})
+	mux.HandleFunc(silencesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"silenceID": "s01"}`))
+			return
+		}
+		assert.Equal(t, []string{`alertname="test"`}, r.URL.Query()["filter"], "test GetSilences() failed")
+		_, _ = w.Write([]byte(`[
+			{"id": "s01", "matchers": [{"name": "alertname", "value": "test", "isRegex": false}], "status": {"state": "active"}},
+			{"id": "s02", "matchers": [{"name": "alertname", "value": "test", "isRegex": false}], "status": {"state": "expired"}}
+		]`))
+	})
+	mux.HandleFunc(silencePath+"s01", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "test ExpireSilence() failed")
+	})
+	mux.HandleFunc(silencePath+"s03", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc(alertsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var alerts []map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&alerts)
+			assert.Equal(t, 1, len(alerts), "test PostAlerts() failed")
+			_, ok := alerts[0]["endsAt"]
+			assert.False(t, ok, "test PostAlerts() failed")
+			return
+		}
+		assert.Equal(t, "true", r.URL.Query().Get("active"), "test GetAlerts() failed")
+		_, _ = w.Write([]byte(`[{"labels": {"alertname": "test"}, "fingerprint": "f01", "status": {"state": "active"}}]`))
+	})
+	mux.HandleFunc(alertGroupsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"), "test GetAlertGroups() failed")
+		assert.Equal(t, []string{`severity="critical"`}, r.URL.Query()["filter"], "test GetAlertGroups() failed")
+		_, _ = w.Write([]byte(`[{"labels": {"alertname": "test"}, "receiver": {"name": "ops"}, "alerts": [{"labels": {"alertname": "test"}, "fingerprint": "f01"}]}]`))
+	})
+	mux.HandleFunc(statusPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"cluster": {"status": "ready"}, "versionInfo": {"version": "0.21.0"}}`))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestConn_Silence(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer(t)
+	defer server.Close()
+	conn := NewConn(server.URL)
+	asst.True(conn.CheckInstanceStatus(), "test CheckInstanceStatus() failed")
+
+	_, err := conn.CreateSilence(context.Background(), NewSilence("test", "maintenance", time.Hour))
+	asst.NotNil(err, "test CreateSilence() failed")
+	id, err := conn.CreateSilence(context.Background(), NewSilence("test", "maintenance", time.Hour, NewMatcher("alertname", "test")))
+	asst.Nil(err, "test CreateSilence() failed")
+	asst.Equal("s01", id, "test CreateSilence() failed")
+
+	silences, err := conn.GetActiveSilences(context.Background(), `alertname="test"`)
+	asst.Nil(err, "test GetActiveSilences() failed")
+	asst.Equal(1, len(silences), "test GetActiveSilences() failed")
+	asst.Equal("s01", silences[0].ID, "test GetActiveSilences() failed")
+
+	asst.Nil(conn.ExpireSilence(context.Background(), id), "test ExpireSilence() failed")
+	asst.True(IsNotFound(conn.ExpireSilence(context.Background(), "s03")), "test ExpireSilence() failed")
+}
+
+func TestConn_Alert(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer(t)
+	defer server.Close()
+	conn := NewConn(server.URL)
+
+	err := conn.PostAlerts(context.Background(), NewAlert("test").AddLabel("severity", "critical").AddAnnotation("summary", "test alert"))
+	asst.Nil(err, "test PostAlerts() failed")
+
+	active := true
+	alerts, err := conn.GetAlerts(context.Background(), &AlertFilter{Active: &active})
+	asst.Nil(err, "test GetAlerts() failed")
+	asst.Equal(1, len(alerts), "test GetAlerts() failed")
+	asst.Equal("f01", alerts[0].Fingerprint, "test GetAlerts() failed")
+
+	status, err := conn.GetStatus(context.Background())
+	asst.Nil(err, "test GetStatus() failed")
+	asst.Equal("0.21.0", status.VersionInfo.Version, "test GetStatus() failed")
+}
+
+func TestConn_GetAlertGroups(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer(t)
+	defer server.Close()
+	conn := NewConnWithConfig(NewConfigWithToken(server.URL, "test-token", DefaultTimeout))
+
+	groups, err := conn.GetAlertGroups(context.Background(), &AlertFilter{Matchers: []string{`severity="critical"`}})
+	asst.Nil(err, "test GetAlertGroups() failed")
+	asst.Equal(1, len(groups), "test GetAlertGroups() failed")
+	asst.Equal("ops", groups[0].Receiver.Name, "test GetAlertGroups() failed")
+	asst.Equal("f01", groups[0].Alerts[0].Fingerprint, "test GetAlertGroups() failed")
+}
@@ -0,0 +1,164 @@
+package alertmanager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	silencesPath = apiV2Prefix + "/silences"
+	silencePath  = apiV2Prefix + "/silence/"
+
+	SilenceStateActive  = "active"
+	SilenceStatePending = "pending"
+	SilenceStateExpired = "expired"
+)
+
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	// IsEqual is a pointer, because alertmanager treats the missing field as true
+	IsEqual *bool `json:"isEqual,omitempty"`
+}
+
+// NewMatcher returns a new *Matcher which matches the label equals to the value
+func NewMatcher(name, value string) *Matcher {
+	return &Matcher{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// NewRegexMatcher returns a new *Matcher which matches the label with the regular expression
+func NewRegexMatcher(name, value string) *Matcher {
+	return &Matcher{
+		Name:    name,
+		Value:   value,
+		IsRegex: true,
+	}
+}
+
+type SilenceStatus struct {
+	State string `json:"state"`
+}
+
+type Silence struct {
+	ID        string         `json:"id,omitempty"`
+	Matchers  []*Matcher     `json:"matchers"`
+	StartsAt  time.Time      `json:"startsAt"`
+	EndsAt    time.Time      `json:"endsAt"`
+	CreatedBy string         `json:"createdBy"`
+	Comment   string         `json:"comment"`
+	Status    *SilenceStatus `json:"status,omitempty"`
+	UpdatedAt *time.Time     `json:"updatedAt,omitempty"`
+}
+
+// NewSilence returns a new *Silence which starts now and lasts for given duration
+func NewSilence(createdBy, comment string, duration time.Duration, matchers ...*Matcher) *Silence {
+	now := time.Now()
+
+	return &Silence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: createdBy,
+		Comment:   comment,
+	}
+}
+
+// IsActive returns if the silence is active
+func (s *Silence) IsActive() bool {
+	return s.Status != nil && s.Status.State == SilenceStateActive
+}
+
+type createSilenceResult struct {
+	SilenceID string `json:"silenceID"`
+}
+
+// CreateSilence creates the silence and returns the id of it,
+// if the id of the silence is not empty, the existing silence will be updated
+func (conn *Conn) CreateSilence(ctx context.Context, silence *Silence) (string, error) {
+	if len(silence.Matchers) == constant.ZeroInt {
+		return constant.EmptyString, errors.New("silence must have at least one matcher")
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return constant.EmptyString, errors.New("end time of the silence must be after the start time")
+	}
+
+	result := &createSilenceResult{}
+
+	err := conn.do(ctx, http.MethodPost, silencesPath, silence, result)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return result.SilenceID, nil
+}
+
+// GetSilences returns the silences which match all the given filters,
+// the filter is a label matcher expression, for example: alertname="InstanceDown"
+func (conn *Conn) GetSilences(ctx context.Context, filters ...string) ([]*Silence, error) {
+	var silences []*Silence
+
+	err := conn.do(ctx, http.MethodGet, withFilters(silencesPath, nil, filters), nil, &silences)
+	if err != nil {
+		return nil, err
+	}
+
+	return silences, nil
+}
+
+// GetActiveSilences returns the active silences which match all the given filters
+func (conn *Conn) GetActiveSilences(ctx context.Context, filters ...string) ([]*Silence, error) {
+	silences, err := conn.GetSilences(ctx, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*Silence
+	for _, silence := range silences {
+		if silence.IsActive() {
+			active = append(active, silence)
+		}
+	}
+
+	return active, nil
+}
+
+// GetSilence gets the silence by id
+func (conn *Conn) GetSilence(ctx context.Context, id string) (*Silence, error) {
+	silence := &Silence{}
+
+	err := conn.do(ctx, http.MethodGet, silencePath+url.PathEscape(id), nil, silence)
+	if err != nil {
+		return nil, err
+	}
+
+	return silence, nil
+}
+
+// ExpireSilence expires the silence by id
+func (conn *Conn) ExpireSilence(ctx context.Context, id string) error {
+	return conn.do(ctx, http.MethodDelete, silencePath+url.PathEscape(id), nil, nil)
+}
+
+// withFilters appends the values and filters to the path as query parameters
+func withFilters(path string, values url.Values, filters []string) string {
+	if values == nil {
+		values = url.Values{}
+	}
+	for _, filter := range filters {
+		values.Add("filter", filter)
+	}
+	if len(values) == constant.ZeroInt {
+		return path
+	}
+
+	return path + "?" + values.Encode()
+}
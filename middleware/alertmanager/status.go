@@ -0,0 +1,67 @@
+package alertmanager
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	statusPath    = apiV2Prefix + "/status"
+	receiversPath = apiV2Prefix + "/receivers"
+)
+
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"buildUser"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+type PeerStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type ClusterStatus struct {
+	Name   string        `json:"name"`
+	Status string        `json:"status"`
+	Peers  []*PeerStatus `json:"peers"`
+}
+
+type ConfigStatus struct {
+	Original string `json:"original"`
+}
+
+type Status struct {
+	Cluster     ClusterStatus `json:"cluster"`
+	VersionInfo VersionInfo   `json:"versionInfo"`
+	Config      ConfigStatus  `json:"config"`
+	Uptime      time.Time     `json:"uptime"`
+}
+
+// GetStatus returns the status of the alertmanager, including version, cluster and configuration
+func (conn *Conn) GetStatus(ctx context.Context) (*Status, error) {
+	status := &Status{}
+
+	err := conn.do(ctx, http.MethodGet, statusPath, nil, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// GetReceivers returns all the receivers
+func (conn *Conn) GetReceivers(ctx context.Context) ([]*Receiver, error) {
+	var receivers []*Receiver
+
+	err := conn.do(ctx, http.MethodGet, receiversPath, nil, &receivers)
+	if err != nil {
+		return nil, err
+	}
+
+	return receivers, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+	"github.com/romberli/go-util/metrics"
 )
 
 const (
@@ -122,13 +125,21 @@ type Conn struct {
 	clickhouse.Clickhouse
 }
 
-// NewConnWithConfig returns connection to mysql database with given Config
+// NewConnWithConfig returns connection to mysql database with given Config, DBPass could be encrypted by the credential package
 func NewConnWithConfig(config Config) (*Conn, error) {
-	// connect to Clickhouse
-	client, err := clickhouse.OpenDirect(config.GetConnectionString())
+	// the connection string uses the decrypted password, and the config keeps the original one
+	connConfig := config
+	pass, err := credential.DecryptIfNeeded(config.DBPass)
 	if err != nil {
 		return nil, err
 	}
+	connConfig.DBPass = pass
+
+	// connect to Clickhouse
+	client, err := clickhouse.OpenDirect(connConfig.GetConnectionString())
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
 
 	return &Conn{
 		config,
@@ -192,7 +203,14 @@ func (conn *Conn) executeContext(ctx context.Context, command string, args ...in
 		return nil, err
 	}
 
-	return stmt.executeContext(ctx, args...)
+	start := time.Now()
+	result, err := stmt.executeContext(ctx, args...)
+	metrics.ObserveOperation(metrics.ComponentClickhouse, metrics.OperationExecute, start, err)
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+
+	return result, nil
 }
 
 // CheckInstanceStatus returns if instance is ok
@@ -0,0 +1,56 @@
+package clickhouse
+
+import (
+	"errors"
+
+	"github.com/ClickHouse/clickhouse-go"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware"
+)
+
+// the exception codes of clickhouse server
+const (
+	ExceptionCodeTimeoutExceeded            = 159
+	ExceptionCodeReadonly                   = 164
+	ExceptionCodeUnknownUser                = 192
+	ExceptionCodeWrongPassword              = 193
+	ExceptionCodeTooManySimultaneousQueries = 202
+	ExceptionCodeNoFreeConnection           = 203
+	ExceptionCodeSocketTimeout              = 209
+	ExceptionCodeNetworkError               = 210
+	ExceptionCodeTableIsReadOnly            = 242
+	ExceptionCodeQueryWasCancelled          = 394
+	ExceptionCodeAuthenticationFailed       = 516
+)
+
+// ClassifyError maps the clickhouse exception onto the classified middleware error,
+// it returns the original error if the error could not be classified
+func ClassifyError(err error) error {
+	return middleware.ClassifyError(err, classifyError)
+}
+
+// classifyError classifies the error with the clickhouse exception code
+func classifyError(err error) *config.ErrMessage {
+	var exception *clickhouse.Exception
+	if !errors.As(err, &exception) {
+		return nil
+	}
+
+	switch exception.Code {
+	case ExceptionCodeUnknownUser, ExceptionCodeWrongPassword, ExceptionCodeAuthenticationFailed:
+		return middleware.ErrAuthFailed
+	case ExceptionCodeTooManySimultaneousQueries, ExceptionCodeNoFreeConnection:
+		return middleware.ErrTooManyConnections
+	case ExceptionCodeReadonly, ExceptionCodeTableIsReadOnly:
+		return middleware.ErrNotLeader
+	case ExceptionCodeTimeoutExceeded, ExceptionCodeSocketTimeout:
+		return middleware.ErrTimeout
+	case ExceptionCodeQueryWasCancelled:
+		return middleware.ErrCanceled
+	case ExceptionCodeNetworkError:
+		return middleware.ErrUnavailable
+	}
+
+	return nil
+}
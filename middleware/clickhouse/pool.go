@@ -3,15 +3,12 @@ package clickhouse
 import (
 	"context"
 	"errors"
-	"fmt"
-	"sync"
 	"time"
 
-	"github.com/hashicorp/go-multierror"
-	"github.com/romberli/log"
-
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/metrics"
 	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/pool"
 )
 
 const (
@@ -131,16 +128,11 @@ func NewPoolConnWithPool(pool *Pool, addr, dbName, dbUser, dbPass string, debug
 
 // Close returns connection back to the pool
 func (pc *PoolConn) Close() error {
-	if pc.Pool.isClosed == true || pc.Pool == nil {
+	if pc.Pool == nil {
 		return pc.Disconnect()
 	}
 
-	pc.Pool.Lock()
-	defer pc.Pool.Unlock()
-
-	pc.Pool.put(pc)
-
-	return nil
+	return pc.Pool.pool.Put(pc)
 }
 
 // Disconnect disconnects from mysql, normally when using connection pool,
@@ -187,13 +179,8 @@ func (pc *PoolConn) executeContext(ctx context.Context, command string, args ...
 }
 
 type Pool struct {
-	sync.Mutex
 	PoolConfig
-	freeConnChan    chan *PoolConn
-	usedConnections int
-	expireTime      time.Time
-	keepAliveTime   time.Time
-	isClosed        bool
+	pool *pool.Pool
 }
 
 // NewPool returns a new *Pool
@@ -219,16 +206,17 @@ func NewPoolWithConfig(config Config, maxConnections, initConnections, maxIdleCo
 
 // NewPoolWithPoolConfig returns a new *Pool with a PoolConfig object
 func NewPoolWithPoolConfig(config PoolConfig) (*Pool, error) {
-	p := &Pool{
-		PoolConfig:      config,
-		freeConnChan:    make(chan *PoolConn, config.MaxConnections),
-		usedConnections: constant.ZeroInt,
-		expireTime:      time.Now().Add(time.Duration(config.MaxIdleTime) * time.Second),
-		keepAliveTime:   time.Now().Add(time.Duration(config.KeepAliveInterval) * time.Second),
-		isClosed:        false,
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
 	}
 
-	err := p.init()
+	p := &Pool{PoolConfig: config}
+	p.pool, err = pool.NewPool(
+		pool.NewConfig(config.MaxConnections, config.InitConnections, config.MaxIdleConnections, config.MaxIdleTime, config.KeepAliveInterval),
+		func() (pool.Conn, error) {
+			return NewPoolConnWithPool(p, p.Addr, p.DBName, p.DBUser, p.DBPass, p.Debug, p.ReadTimeout, p.WriteTimeout, p.AltHosts...)
+		})
 	if err != nil {
 		return nil, err
 	}
@@ -236,134 +224,65 @@ func NewPoolWithPoolConfig(config PoolConfig) (*Pool, error) {
 	return p, nil
 }
 
-// init initiates pool
-func (p *Pool) init() error {
-	// add sufficient connections to the pool
-	err := p.supply(p.InitConnections)
-	if err != nil {
-		return err
-	}
-
-	// start a new routine to maintain free connection channel
-	go p.maintainFreeChan()
-
-	return nil
-}
-
 // UsedConnections returns used connection number
 func (p *Pool) UsedConnections() int {
-	return p.usedConnections
+	return p.pool.UsedConnections()
+}
+
+// Stats returns the statistics of the pool
+func (p *Pool) Stats() pool.Stats {
+	return p.pool.Stats()
 }
 
 // IsClosed returns if pool had been closed
 func (p *Pool) IsClosed() bool {
-	return p.isClosed
+	return p.pool.IsClosed()
 }
 
-// Supply is an exported alias of supply() function with routine safe
+// Supply creates given number of connections and add them to the pool
 func (p *Pool) Supply(num int) error {
-	p.Lock()
-	defer p.Unlock()
-
-	return p.supply(num)
-}
-
-// supply creates given number of connections and add them to free connection channel
-func (p *Pool) supply(num int) error {
-	if p.isClosed {
-		return nil
-	}
-
-	merr := &multierror.Error{}
-
-	for i := 0; i < num; i++ {
-		if len(p.freeConnChan)+p.usedConnections < p.MaxConnections {
-			pc, err := NewPoolConnWithPool(p, p.Addr, p.DBName, p.DBUser, p.DBPass, p.Debug, p.ReadTimeout, p.WriteTimeout, p.AltHosts...)
-			if err != nil {
-				merr = multierror.Append(merr, err)
-				continue
-			}
-
-			p.addToFreeChan(pc)
-		}
-	}
-
-	return merr.ErrorOrNil()
+	return p.pool.Supply(num)
 }
 
 // Close releases each connection in the pool
 func (p *Pool) Close() error {
-	p.Lock()
-	defer p.Unlock()
-
-	p.isClosed = true
-	return p.release(len(p.freeConnChan))
-}
-
-// put puts given PoolConn back to the pool
-func (p *Pool) put(pc *PoolConn) {
-	p.addToFreeChan(pc)
-	p.usedConnections--
-}
-
-// getFromFreeChan gets a *PoolConn from free connection channel
-func (p *Pool) getFromFreeChan() (*PoolConn, bool) {
-	pc, ok := <-p.freeConnChan
-	return pc, ok
-}
-
-// addToFreeChan adds given *PoolConn to free connection channel
-func (p *Pool) addToFreeChan(pc *PoolConn) {
-	p.freeConnChan <- pc
+	return p.pool.Close()
 }
 
-// Get is an exported alias of get() function with routine safe
+// Get gets a connection from the pool
 func (p *Pool) Get() (middleware.PoolConn, error) {
-	p.Lock()
-	defer p.Unlock()
-
 	return p.get()
 }
 
 // get gets a connection from the pool and validate it,
 // if there is no valid connection in the pool, it will create a new connection
 func (p *Pool) get() (*PoolConn, error) {
-	if p.isClosed {
-		return nil, errors.New("pool had been closed")
+	start := time.Now()
+	conn, err := p.pool.Get()
+	metrics.ObserveAcquire(metrics.ComponentClickhouse, start, err)
+	if err != nil {
+		return nil, err
 	}
 
-	if p.usedConnections >= p.MaxConnections {
-		return nil, errors.New(
-			fmt.Sprintf("used connection(%d) had reached maximum connection(%d)",
-				p.usedConnections, p.MaxConnections))
-	}
+	return conn.(*PoolConn), nil
+}
 
-	freeChanLen := len(p.freeConnChan)
-	// try to get connection from free connection channel
-	for i := 0; i < freeChanLen; i++ {
-		pc, ok := p.getFromFreeChan()
-		// check if connection is still valid
-		if ok && pc.IsValid() {
-			p.usedConnections++
-			return pc, nil
-		}
-
-		err := pc.Disconnect()
-		if err != nil {
-			return nil, fmt.Errorf(
-				"disconnecting invalid connection failed when getting connection from the pool.%w",
-				err)
-		}
-	}
+// GetContext gets a connection from the pool, it waits until a connection is available or the context is done
+func (p *Pool) GetContext(ctx context.Context) (middleware.PoolConn, error) {
+	return p.getContext(ctx)
+}
 
-	// there is no valid connection in the free connection channel, therefore create a new one
-	pc, err := NewPoolConnWithPool(p, p.Addr, p.DBName, p.DBUser, p.DBPass, p.Debug, p.ReadTimeout, p.WriteTimeout, p.AltHosts...)
+// getContext gets a connection from the pool with context and validate it,
+// if there is no valid connection in the pool, it will create a new connection
+func (p *Pool) getContext(ctx context.Context) (*PoolConn, error) {
+	start := time.Now()
+	conn, err := p.pool.GetContext(ctx)
+	metrics.ObserveAcquire(metrics.ComponentClickhouse, start, err)
 	if err != nil {
 		return nil, err
 	}
 
-	p.usedConnections++
-	return pc, nil
+	return conn.(*PoolConn), nil
 }
 
 // Transaction simply returns *PoolConn, because it had implemented Transaction interface
@@ -371,117 +290,7 @@ func (p *Pool) Transaction() (middleware.Transaction, error) {
 	return p.get()
 }
 
-// maintainFreeChan maintains free connection channel, if there are insufficient connection in the free connection channel,
-// it will add some connections, otherwise it will release some.
-// for saving disk purpose, if there are errors when maintaining free channel,
-// it will log with debug level
-func (p *Pool) maintainFreeChan() {
-	var (
-		err error
-		num int
-	)
-
-	for {
-		if p.isClosed {
-			return
-		}
-
-		p.Lock()
-		now := time.Now()
-		// keep alive connections
-		if now.After(p.keepAliveTime) {
-			p.keepAliveTime = now.Add(time.Duration(p.KeepAliveInterval) * time.Second)
-			err = p.keepAlive(DefaultKeepAliveChunkSize)
-			if err != nil {
-				log.Debugf("got error when keeping alive connections of the pool. total: %d, failed: %d. nested error: %s",
-					DefaultKeepAliveChunkSize, err.(*multierror.Error).Len(), err.Error())
-			}
-		}
-		// supply enough connections
-		num = p.InitConnections - p.usedConnections - len(p.freeConnChan)
-		err = p.supply(num)
-		if err != nil {
-			log.Debugf("got error when supplying connections to the pool. total: %d, failed: %d. nested error: %s",
-				num, err.(*multierror.Error).Len(), err.Error())
-		}
-		// release excessive connections
-		if now.After(p.expireTime) {
-			p.expireTime = now.Add(time.Duration(p.MaxIdleTime) * time.Second)
-			num = len(p.freeConnChan) + p.usedConnections - p.MaxIdleConnections
-			err = p.release(num)
-			if err != nil {
-				log.Debugf("got error when releasing connections of the pool. total: %d, failed: %d. nested error: %s",
-					num, err.(*multierror.Error).Len(), err.Error())
-			}
-		}
-
-		p.Unlock()
-		time.Sleep(DefaultSleepTime * time.Second)
-	}
-}
-
-// keepAlive keeps alive given number of connections in the pool to avoid disconnected by server side automatically,
-// it also checks if connections are still valid
-func (p *Pool) keepAlive(num int) error {
-	if len(p.freeConnChan) == 0 {
-		return nil
-	}
-
-	merr := &multierror.Error{}
-
-	for i := 0; i < num; i++ {
-		select {
-		case pc, ok := <-p.freeConnChan:
-			if ok && pc.IsValid() {
-				p.addToFreeChan(pc)
-				continue
-			}
-
-			err := pc.Disconnect()
-			if err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		default:
-		}
-	}
-
-	return merr.ErrorOrNil()
-}
-
-// Release is an exported alias of release() function
+// Release releases given number of idle connections, each connection will disconnect with clickhouse
 func (p *Pool) Release(num int) error {
-	p.Lock()
-	defer p.Unlock()
-
-	return p.release(num)
-}
-
-// release releases given number of connections, each connection will disconnect with database
-func (p *Pool) release(num int) error {
-	merr := &multierror.Error{}
-
-	for i := 0; i < num; i++ {
-		if len(p.freeConnChan) == 0 {
-			return nil
-		}
-
-		// as we didn't lock between get length of free connection channel and get connection from channel,
-		// so this is possible to release less than given number of connections, to avoid this,
-		// you have to use lock before calling this function.
-		// actually, it's not a big deal to release a few less connections,
-		// as it will release again at next maintain cycle.
-		select {
-		case pc, ok := <-p.freeConnChan:
-			if ok {
-				// disconnect
-				err := pc.Disconnect()
-				if err != nil {
-					merr = multierror.Append(merr, err)
-				}
-			}
-		default:
-		}
-	}
-
-	return merr.ErrorOrNil()
+	return p.pool.Release(num)
 }
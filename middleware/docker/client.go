@@ -0,0 +1,212 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultHost       = "unix:///var/run/docker.sock"
+	DefaultAPIVersion = "v1.41"
+	DefaultTimeout    = 30 * time.Second
+
+	unixPrefix  = "unix://"
+	tcpPrefix   = "tcp://"
+	httpPrefix  = "http://"
+	httpsPrefix = "https://"
+	// unixBaseURL is a dummy url, the requests will be sent to the unix socket
+	unixBaseURL = "http://docker"
+
+	contentTypeHeader = "Content-Type"
+	jsonContentType   = "application/json"
+	pingPath          = "/_ping"
+)
+
+type Config struct {
+	// Host is the address of docker daemon, for example: unix:///var/run/docker.sock or tcp://192.168.1.1:2375
+	Host       string
+	APIVersion string
+	// Timeout is the timeout of the non-streaming requests, streaming requests are only controlled by the context
+	Timeout time.Duration
+}
+
+// NewConfig returns a new Config
+func NewConfig(host, apiVersion string, timeout time.Duration) Config {
+	return Config{
+		Host:       host,
+		APIVersion: apiVersion,
+		Timeout:    timeout,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with default values, it connects to the local unix socket
+func NewConfigWithDefault() Config {
+	return NewConfig(DefaultHost, DefaultAPIVersion, DefaultTimeout)
+}
+
+// APIError is returned when docker daemon responds with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("docker api returned an error. status code: %d, message: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns if given error is a docker api error with 404 status code
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+type Conn struct {
+	Config
+	baseURL string
+	// client is used for the non-streaming requests
+	client *http.Client
+	// streamClient has no timeout, it is used for pulling images, following logs and so on
+	streamClient *http.Client
+}
+
+// NewConn returns a new *Conn with given host
+func NewConn(host string) (*Conn, error) {
+	return NewConnWithConfig(NewConfig(host, DefaultAPIVersion, DefaultTimeout))
+}
+
+// NewConnWithConfig returns a new *Conn with given config
+func NewConnWithConfig(config Config) (*Conn, error) {
+	transport := &http.Transport{}
+	var baseURL string
+
+	host := config.Host
+	switch {
+	case strings.HasPrefix(host, unixPrefix):
+		socket := strings.TrimPrefix(host, unixPrefix)
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		}
+		baseURL = unixBaseURL
+	case strings.HasPrefix(host, tcpPrefix):
+		baseURL = httpPrefix + strings.TrimPrefix(host, tcpPrefix)
+	case strings.HasPrefix(host, httpPrefix), strings.HasPrefix(host, httpsPrefix):
+		baseURL = host
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported docker host. host: %s", host))
+	}
+
+	if config.APIVersion != constant.EmptyString {
+		baseURL = strings.TrimSuffix(baseURL, constant.SlashString) + constant.SlashString + config.APIVersion
+	}
+
+	return &Conn{
+		Config:       config,
+		baseURL:      baseURL,
+		client:       &http.Client{Transport: transport, Timeout: config.Timeout},
+		streamClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// Close closes the idle connections
+func (conn *Conn) Close() error {
+	conn.client.CloseIdleConnections()
+
+	return nil
+}
+
+// CheckInstanceStatus checks docker daemon status
+func (conn *Conn) CheckInstanceStatus() bool {
+	return conn.do(context.Background(), http.MethodGet, pingPath, nil, nil, nil) == nil
+}
+
+// do sends a non-streaming request, in will be marshaled as the request body if it is not nil,
+// and the response body will be unmarshaled to out if it is not nil
+func (conn *Conn) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	resp, err := conn.send(ctx, conn.client, method, path, query, in)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if out == nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return errors.New(fmt.Sprintf("unmarshal docker response failed. method: %s, path: %s. %s", method, path, err.Error()))
+	}
+
+	return nil
+}
+
+// stream sends a streaming request and returns the response body, caller must close the body
+func (conn *Conn) stream(ctx context.Context, method, path string, query url.Values, in interface{}) (io.ReadCloser, error) {
+	resp, err := conn.send(ctx, conn.streamClient, method, path, query, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// send sends the request with given client, it returns an *APIError if the status code is not 2xx or 304
+func (conn *Conn) send(ctx context.Context, client *http.Client, method, path string, query url.Values, in interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := conn.baseURL + path
+	if len(query) > constant.ZeroInt {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set(contentTypeHeader, jsonContentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if (resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices) && resp.StatusCode != http.StatusNotModified {
+		defer func() { _ = resp.Body.Close() }()
+
+		data, _ := ioutil.ReadAll(resp.Body)
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &msg) != nil || msg.Message == constant.EmptyString {
+			msg.Message = strings.TrimSpace(string(data))
+		}
+
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: msg.Message}
+	}
+
+	return resp, nil
+}
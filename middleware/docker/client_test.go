@@ -0,0 +1,156 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// frame returns a multiplexed stream frame
+func frame(streamType byte, data string) []byte {
+	header := make([]byte, streamHeaderLength)
+	header[0] = streamType
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+
+	return append(header, data...)
+}
+
+// newTestServer returns a fake docker daemon listening on a unix socket and the host of it
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	prefix := "/" + DefaultAPIVersion
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+pingPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	})
+	mux.HandleFunc(prefix+containersPath+"/json", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `{"label":["app=mysql"]}`, r.URL.Query().Get("filters"), "test ListContainers() failed")
+		_, _ = w.Write([]byte(`[{"Id": "c01", "Names": ["/mysql"], "State": "running"}]`))
+	})
+	mux.HandleFunc(prefix+containersPath+"/c01/json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Id": "c01", "Name": "/mysql", "State": {"Running": true}, "Config": {"Tty": false}}`))
+	})
+	mux.HandleFunc(prefix+containersPath+"/c01/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+	mux.HandleFunc(prefix+containersPath+"/c02/stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "No such container: c02"}`))
+	})
+	mux.HandleFunc(prefix+containersPath+"/c01/logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(frame(streamStdout, "out\n"))
+		_, _ = w.Write(frame(streamStderr, "err\n"))
+	})
+	mux.HandleFunc(prefix+containersPath+"/c01/exec", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Id": "e01"}`))
+	})
+	mux.HandleFunc(prefix+execPath+"e01/start", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(frame(streamStdout, "hello"))
+	})
+	mux.HandleFunc(prefix+execPath+"e01/json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Running": false, "ExitCode": 3}`))
+	})
+	mux.HandleFunc(prefix+imagesPath+"/create", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fromImage") == "notexist" {
+			_, _ = w.Write([]byte(`{"error": "manifest unknown"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "Pulling fs layer", "id": "l01"}
+{"status": "Downloading", "id": "l01", "progressDetail": {"current": 50, "total": 100}}
+{"status": "Status: Downloaded newer image for mysql:8.0"}`))
+	})
+
+	dir, err := ioutil.TempDir("", "docker_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(func() {
+		server.Close()
+		_ = os.RemoveAll(dir)
+	})
+
+	return server, unixPrefix + socket
+}
+
+func TestConn_Container(t *testing.T) {
+	asst := assert.New(t)
+
+	_, host := newTestServer(t)
+	conn, err := NewConn(host)
+	asst.Nil(err, "test NewConn() failed")
+	asst.True(conn.CheckInstanceStatus(), "test CheckInstanceStatus() failed")
+
+	containers, err := conn.ListContainers(context.Background(), true, map[string][]string{"label": {"app=mysql"}})
+	asst.Nil(err, "test ListContainers() failed")
+	asst.Equal(1, len(containers), "test ListContainers() failed")
+	asst.Equal("c01", containers[0].ID, "test ListContainers() failed")
+
+	detail, err := conn.InspectContainer(context.Background(), "c01")
+	asst.Nil(err, "test InspectContainer() failed")
+	asst.True(detail.State.Running, "test InspectContainer() failed")
+
+	asst.Nil(conn.StartContainer(context.Background(), "c01"), "test StartContainer() failed")
+	asst.True(IsNotFound(conn.StopContainer(context.Background(), "c02", 0)), "test StopContainer() failed")
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err = conn.ContainerLogs(context.Background(), "c01", &LogsOptions{Tail: 10}, stdout, stderr)
+	asst.Nil(err, "test ContainerLogs() failed")
+	asst.Equal("out\n", stdout.String(), "test ContainerLogs() failed")
+	asst.Equal("err\n", stderr.String(), "test ContainerLogs() failed")
+
+	result, err := conn.Exec(context.Background(), "c01", []string{"echo", "hello"}, nil, "")
+	asst.Nil(err, "test Exec() failed")
+	asst.Equal("hello", result.Stdout, "test Exec() failed")
+	asst.Equal(3, result.ExitCode, "test Exec() failed")
+}
+
+func TestConn_PullImage(t *testing.T) {
+	asst := assert.New(t)
+
+	_, host := newTestServer(t)
+	conn, err := NewConn(host)
+	asst.Nil(err, "test NewConn() failed")
+
+	var messages []*PullProgress
+	err = conn.PullImage(context.Background(), "mysql:8.0", func(progress *PullProgress) {
+		messages = append(messages, progress)
+	})
+	asst.Nil(err, "test PullImage() failed")
+	asst.Equal(3, len(messages), "test PullImage() failed")
+	asst.Equal(int64(100), messages[1].ProgressDetail.Total, "test PullImage() failed")
+
+	err = conn.PullImage(context.Background(), "notexist", nil)
+	asst.NotNil(err, "test PullImage() failed")
+}
+
+func TestParseImage(t *testing.T) {
+	asst := assert.New(t)
+
+	name, tag := parseImage("mysql")
+	asst.Equal("mysql", name, "test parseImage() failed")
+	asst.Equal(defaultImageTag, tag, "test parseImage() failed")
+	name, tag = parseImage("192.168.1.1:5000/mysql:8.0")
+	asst.Equal("192.168.1.1:5000/mysql", name, "test parseImage() failed")
+	asst.Equal("8.0", tag, "test parseImage() failed")
+	name, tag = parseImage("192.168.1.1:5000/mysql")
+	asst.Equal("192.168.1.1:5000/mysql", name, "test parseImage() failed")
+	asst.Equal(defaultImageTag, tag, "test parseImage() failed")
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const containersPath = "/containers"
+
+type Port struct {
+	IP          string `json:"IP"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort"`
+	Type        string `json:"Type"`
+}
+
+// Container is the summary of a container returned by ListContainers()
+type Container struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	Command string            `json:"Command"`
+	Created int64             `json:"Created"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Ports   []*Port           `json:"Ports"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+type ContainerState struct {
+	Status     string    `json:"Status"`
+	Running    bool      `json:"Running"`
+	Paused     bool      `json:"Paused"`
+	Restarting bool      `json:"Restarting"`
+	OOMKilled  bool      `json:"OOMKilled"`
+	Dead       bool      `json:"Dead"`
+	Pid        int       `json:"Pid"`
+	ExitCode   int       `json:"ExitCode"`
+	Error      string    `json:"Error"`
+	StartedAt  time.Time `json:"StartedAt"`
+	FinishedAt time.Time `json:"FinishedAt"`
+}
+
+type ContainerConfig struct {
+	Hostname string            `json:"Hostname"`
+	User     string            `json:"User"`
+	Tty      bool              `json:"Tty"`
+	Env      []string          `json:"Env"`
+	Cmd      []string          `json:"Cmd"`
+	Image    string            `json:"Image"`
+	Labels   map[string]string `json:"Labels"`
+}
+
+type Mount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	RW          bool   `json:"RW"`
+}
+
+// ContainerDetail is the low-level information of a container returned by InspectContainer()
+type ContainerDetail struct {
+	ID              string                 `json:"Id"`
+	Name            string                 `json:"Name"`
+	Created         time.Time              `json:"Created"`
+	Image           string                 `json:"Image"`
+	RestartCount    int                    `json:"RestartCount"`
+	State           *ContainerState        `json:"State"`
+	Config          *ContainerConfig       `json:"Config"`
+	Mounts          []*Mount               `json:"Mounts"`
+	NetworkSettings map[string]interface{} `json:"NetworkSettings"`
+}
+
+// ListContainers lists the containers, if all is false, only running containers will be returned,
+// filters are used to filter the containers, for example: {"label": ["app=mysql"], "status": ["running"]}
+func (conn *Conn) ListContainers(ctx context.Context, all bool, filters map[string][]string) ([]*Container, error) {
+	query := url.Values{}
+	query.Set("all", strconv.FormatBool(all))
+	if len(filters) > constant.ZeroInt {
+		data, err := json.Marshal(filters)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("filters", string(data))
+	}
+
+	var containers []*Container
+
+	err := conn.do(ctx, http.MethodGet, containersPath+"/json", query, nil, &containers)
+	if err != nil {
+		return nil, err
+	}
+
+	return containers, nil
+}
+
+// InspectContainer returns the low-level information of the container, id could be either container id or name
+func (conn *Conn) InspectContainer(ctx context.Context, id string) (*ContainerDetail, error) {
+	detail := &ContainerDetail{}
+
+	err := conn.do(ctx, http.MethodGet, containerPath(id, "/json"), nil, nil, detail)
+	if err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+// StartContainer starts the container, it returns nil if the container had already been started
+func (conn *Conn) StartContainer(ctx context.Context, id string) error {
+	return conn.do(ctx, http.MethodPost, containerPath(id, "/start"), nil, nil, nil)
+}
+
+// StopContainer stops the container, the container will be killed after timeout,
+// it returns nil if the container had already been stopped
+func (conn *Conn) StopContainer(ctx context.Context, id string, timeout time.Duration) error {
+	return conn.do(ctx, http.MethodPost, containerPath(id, "/stop"), timeoutQuery(timeout), nil, nil)
+}
+
+// RestartContainer restarts the container, the container will be killed after timeout
+func (conn *Conn) RestartContainer(ctx context.Context, id string, timeout time.Duration) error {
+	return conn.do(ctx, http.MethodPost, containerPath(id, "/restart"), timeoutQuery(timeout), nil, nil)
+}
+
+// RemoveContainer removes the container, if force is true, the running container will be killed and removed
+func (conn *Conn) RemoveContainer(ctx context.Context, id string, force, removeVolumes bool) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	query.Set("v", strconv.FormatBool(removeVolumes))
+
+	return conn.do(ctx, http.MethodDelete, containerPath(id, constant.EmptyString), query, nil, nil)
+}
+
+// containerPath returns the api path of the container
+func containerPath(id, suffix string) string {
+	return containersPath + constant.SlashString + url.PathEscape(id) + suffix
+}
+
+// timeoutQuery returns the query of the timeout in seconds
+func timeoutQuery(timeout time.Duration) url.Values {
+	query := url.Values{}
+	query.Set("t", strconv.Itoa(int(timeout.Seconds())))
+
+	return query
+}
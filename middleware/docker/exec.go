@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const execPath = "/exec/"
+
+type execConfig struct {
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Tty          bool     `json:"Tty"`
+	Env          []string `json:"Env,omitempty"`
+	User         string   `json:"User,omitempty"`
+	WorkingDir   string   `json:"WorkingDir,omitempty"`
+	Cmd          []string `json:"Cmd"`
+}
+
+type execStartConfig struct {
+	Detach bool `json:"Detach"`
+	Tty    bool `json:"Tty"`
+}
+
+type execCreateResult struct {
+	ID string `json:"Id"`
+}
+
+type execInspectResult struct {
+	Running  bool `json:"Running"`
+	ExitCode int  `json:"ExitCode"`
+}
+
+// ExecResult is the result of the command executed in the container
+type ExecResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// Exec executes the command in the running container and returns the output and exit code,
+// env is in the form of key=value, user could be empty which means the default user of the container
+func (conn *Conn) Exec(ctx context.Context, id string, cmd []string, env []string, user string) (*ExecResult, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	exitCode, err := conn.ExecWithWriter(ctx, id, cmd, env, user, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecResult{
+		ExitCode: exitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}, nil
+}
+
+// ExecWithWriter executes the command in the running container, writes the output to stdout and stderr,
+// and returns the exit code, it is useful when the output is large or should be streamed
+func (conn *Conn) ExecWithWriter(ctx context.Context, id string, cmd []string, env []string, user string, stdout, stderr io.Writer) (int, error) {
+	if len(cmd) == constant.ZeroInt {
+		return constant.ZeroInt, errors.New("command should not be empty")
+	}
+
+	created := &execCreateResult{}
+	err := conn.do(ctx, http.MethodPost, containerPath(id, "/exec"), nil, &execConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          env,
+		User:         user,
+		Cmd:          cmd,
+	}, created)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	body, err := conn.stream(ctx, http.MethodPost, execPath+url.PathEscape(created.ID)+"/start", nil, &execStartConfig{})
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	err = demuxStream(body, stdout, stderr)
+	_ = body.Close()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	inspected := &execInspectResult{}
+	err = conn.do(ctx, http.MethodGet, execPath+url.PathEscape(created.ID)+"/json", nil, nil, inspected)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return inspected.ExitCode, nil
+}
@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	imagesPath = "/images"
+
+	defaultImageTag = "latest"
+)
+
+type ProgressDetail struct {
+	Current int64 `json:"current"`
+	Total   int64 `json:"total"`
+}
+
+// PullProgress is one message of the pulling progress
+type PullProgress struct {
+	ID             string          `json:"id"`
+	Status         string          `json:"status"`
+	Progress       string          `json:"progress"`
+	ProgressDetail *ProgressDetail `json:"progressDetail"`
+	Error          string          `json:"error"`
+}
+
+// ProgressFunc is called every time a progress message is received
+type ProgressFunc func(progress *PullProgress)
+
+type Image struct {
+	ID          string            `json:"Id"`
+	ParentID    string            `json:"ParentId"`
+	RepoTags    []string          `json:"RepoTags"`
+	RepoDigests []string          `json:"RepoDigests"`
+	Created     int64             `json:"Created"`
+	Size        int64             `json:"Size"`
+	Labels      map[string]string `json:"Labels"`
+}
+
+// ListImages lists the images
+func (conn *Conn) ListImages(ctx context.Context) ([]*Image, error) {
+	var images []*Image
+
+	err := conn.do(ctx, http.MethodGet, imagesPath+"/json", nil, nil, &images)
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// PullImage pulls the image, image could be with or without tag, for example: mysql or mysql:8.0,
+// if progress is not nil, it will be called with every progress message.
+// it blocks until the image is pulled, the context could be used to cancel pulling
+func (conn *Conn) PullImage(ctx context.Context, image string, progress ProgressFunc) error {
+	name, tag := parseImage(image)
+	query := url.Values{}
+	query.Set("fromImage", name)
+	query.Set("tag", tag)
+
+	body, err := conn.stream(ctx, http.MethodPost, imagesPath+"/create", query, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	decoder := json.NewDecoder(body)
+	for {
+		p := &PullProgress{}
+		err = decoder.Decode(p)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// docker returns 200 even if pulling failed, the error is in the message
+		if p.Error != constant.EmptyString {
+			return errors.New(fmt.Sprintf("pull image failed. image: %s. %s", image, p.Error))
+		}
+		if progress != nil {
+			progress(p)
+		}
+	}
+}
+
+// RemoveImage removes the image
+func (conn *Conn) RemoveImage(ctx context.Context, image string, force bool) error {
+	query := url.Values{}
+	if force {
+		query.Set("force", "true")
+	}
+
+	return conn.do(ctx, http.MethodDelete, imagesPath+constant.SlashString+image, query, nil, nil)
+}
+
+// parseImage splits the image into name and tag, if there is no tag, latest will be used,
+// note that the registry may contain a port, for example: 192.168.1.1:5000/mysql:8.0
+func parseImage(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		// digest
+		return image, constant.EmptyString
+	}
+
+	slashIndex := strings.LastIndex(image, constant.SlashString)
+	colonIndex := strings.LastIndex(image, constant.ColonString)
+	if colonIndex > slashIndex {
+		return image[:colonIndex], image[colonIndex+1:]
+	}
+
+	return image, defaultImageTag
+}
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+type LogsOptions struct {
+	// Follow keeps the stream open until the context is done or the container stops
+	Follow     bool
+	Timestamps bool
+	// Tail is the number of lines from the end of the logs, 0 or negative means all
+	Tail  int
+	Since time.Time
+}
+
+// values returns the options as url values
+func (lo *LogsOptions) values() url.Values {
+	query := url.Values{}
+	query.Set("stdout", constant.TrueString)
+	query.Set("stderr", constant.TrueString)
+	query.Set("follow", strconv.FormatBool(lo.Follow))
+	query.Set("timestamps", strconv.FormatBool(lo.Timestamps))
+	if lo.Tail > constant.ZeroInt {
+		query.Set("tail", strconv.Itoa(lo.Tail))
+	}
+	if !lo.Since.IsZero() {
+		query.Set("since", strconv.FormatInt(lo.Since.Unix(), 10))
+	}
+
+	return query
+}
+
+// ContainerLogs writes the logs of the container to stdout and stderr,
+// if options.Follow is true, it blocks until the context is done or the container stops
+func (conn *Conn) ContainerLogs(ctx context.Context, id string, options *LogsOptions, stdout, stderr io.Writer) error {
+	if options == nil {
+		options = &LogsOptions{}
+	}
+
+	// the logs are multiplexed only if the container is not started with tty
+	detail, err := conn.InspectContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	body, err := conn.stream(ctx, http.MethodGet, containerPath(id, "/logs"), options.values(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	if detail.Config != nil && detail.Config.Tty {
+		if stdout == nil {
+			return nil
+		}
+		_, err = io.Copy(stdout, body)
+	} else {
+		err = demuxStream(body, stdout, stderr)
+	}
+	if err != nil && ctx.Err() != nil {
+		// the stream was interrupted by the context
+		return nil
+	}
+
+	return err
+}
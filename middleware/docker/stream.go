@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	streamHeaderLength = 8
+
+	streamStdin  = 0
+	streamStdout = 1
+	streamStderr = 2
+)
+
+// demuxStream copies the multiplexed stream to stdout and stderr,
+// when the container is not started with tty, docker multiplexes stdout and stderr into one stream,
+// each frame has an 8 bytes header, the first byte is the stream type and the last 4 bytes are the frame size in big endian.
+// if stdout or stderr is nil, the corresponding output will be discarded
+func demuxStream(r io.Reader, stdout, stderr io.Writer) error {
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	header := make([]byte, streamHeaderLength)
+	for {
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer
+		switch header[0] {
+		case streamStdin, streamStdout:
+			w = stdout
+		case streamStderr:
+			w = stderr
+		default:
+			return errors.New(fmt.Sprintf("unknown stream type. type: %d", header[0]))
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		_, err = io.CopyN(w, r, size)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware/pool"
+)
+
+const ErrHeader = "MIDDLEWARE"
+
+// the classified errors, each middleware maps its driver specific errors onto them,
+// so that the callers could handle the failures uniformly with errors.Is(),
+// the original error is kept as the cause, it could still be checked with errors.As()
+var (
+	ErrConnTimeout        = config.NewErrMessage(ErrHeader, 100001, "connecting to the middleware timed out. %s")
+	ErrConnRefused        = config.NewErrMessage(ErrHeader, 100002, "connection to the middleware was refused. %s")
+	ErrAuthFailed         = config.NewErrMessage(ErrHeader, 100003, "authentication failed. %s")
+	ErrTooManyConnections = config.NewErrMessage(ErrHeader, 100004, "too many connections. %s")
+	ErrNotLeader          = config.NewErrMessage(ErrHeader, 100005, "the server is not the leader or is read only. %s")
+	ErrUnavailable        = config.NewErrMessage(ErrHeader, 100006, "the middleware is unavailable. %s")
+	ErrTimeout            = config.NewErrMessage(ErrHeader, 100007, "the operation timed out. %s")
+	ErrCanceled           = config.NewErrMessage(ErrHeader, 100008, "the operation was canceled. %s")
+	ErrPoolClosed         = config.NewErrMessage(ErrHeader, 100009, "the pool had been closed. %s")
+	ErrPoolExhausted      = config.NewErrMessage(ErrHeader, 100010, "no free connection in the pool. %s")
+)
+
+// Classifier maps the driver specific error onto the classified error,
+// it returns nil if the error could not be classified
+type Classifier func(err error) *config.ErrMessage
+
+// ClassifyError classifies the error with the classifiers first, then with the common rules,
+// it returns the original error if the error could not be classified or had already been classified
+func ClassifyError(err error, classifiers ...Classifier) error {
+	if err == nil {
+		return nil
+	}
+
+	var errMessage *config.ErrMessage
+	if errors.As(err, &errMessage) && errMessage.Header == ErrHeader {
+		return err
+	}
+
+	chain := causeChain(err)
+	for _, classifier := range classifiers {
+		for _, e := range chain {
+			classified := classifier(e)
+			if classified != nil {
+				return classified.Wrap(err)
+			}
+		}
+	}
+
+	for _, e := range chain {
+		classified := classifyCommonError(e)
+		if classified != nil {
+			return classified.Wrap(err)
+		}
+	}
+
+	return err
+}
+
+// causeChain returns the error and its causes, some drivers wrap the errors with Cause() method instead of Unwrap(),
+// so errors.Is() and errors.As() could not find the original errors
+func causeChain(err error) []error {
+	chain := []error{err}
+	for {
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			return chain
+		}
+		cause := causer.Cause()
+		if cause == nil || cause == err {
+			return chain
+		}
+		chain = append(chain, cause)
+		err = cause
+	}
+}
+
+// classifyCommonError classifies the errors of the standard library and the pool
+func classifyCommonError(err error) *config.ErrMessage {
+	switch {
+	case errors.Is(err, pool.ErrPoolClosed):
+		return ErrPoolClosed
+	case errors.Is(err, pool.ErrPoolTimeout):
+		return ErrPoolExhausted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrConnRefused
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		if opErr.Timeout() {
+			return ErrConnTimeout
+		}
+
+		return ErrUnavailable
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware/pool"
+)
+
+func TestClassifyError(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Nil(ClassifyError(nil), "test ClassifyError() failed")
+	asst.True(errors.Is(ClassifyError(pool.ErrPoolClosed), ErrPoolClosed), "test ClassifyError() failed")
+	asst.True(errors.Is(ClassifyError(pool.ErrPoolTimeout), ErrPoolExhausted), "test ClassifyError() failed")
+	asst.True(errors.Is(ClassifyError(fmt.Errorf("query failed. %w", context.DeadlineExceeded)), ErrTimeout), "test ClassifyError() failed")
+
+	// dial to a closed port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	asst.Nil(err, "test ClassifyError() failed")
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	_, err = net.DialTimeout("tcp", addr, time.Second)
+	err = ClassifyError(err)
+	asst.True(errors.Is(err, ErrConnRefused), "test ClassifyError() failed")
+	var opErr *net.OpError
+	asst.True(errors.As(err, &opErr), "test ClassifyError() failed")
+
+	// the classifier takes precedence
+	cause := errors.New("too many connections")
+	err = ClassifyError(cause, func(err error) *config.ErrMessage { return ErrTooManyConnections })
+	asst.True(errors.Is(err, ErrTooManyConnections), "test ClassifyError() failed")
+	asst.True(errors.Is(err, cause), "test ClassifyError() failed")
+	// the classified error is not classified again
+	asst.Equal(err, ClassifyError(err, func(err error) *config.ErrMessage { return ErrAuthFailed }), "test ClassifyError() failed")
+
+	// unknown error is returned as is
+	asst.Equal(cause, ClassifyError(cause), "test ClassifyError() failed")
+}
@@ -35,7 +35,7 @@ func NewEtcdConn(endpoints []string) (*Conn, error) {
 
 	client, err := clientv3.New(cfg)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyError(err)
 	}
 
 	return &Conn{
@@ -55,7 +55,7 @@ func NewEtcdConnWithConnectTimeout(endpoints []string, timeout time.Duration) (*
 
 	client, err := clientv3.New(cfg)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyError(err)
 	}
 
 	return &Conn{
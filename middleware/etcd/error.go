@@ -0,0 +1,33 @@
+package etcd
+
+import (
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware"
+)
+
+// ClassifyError maps the etcd error onto the classified middleware error,
+// it returns the original error if the error could not be classified
+func ClassifyError(err error) error {
+	return middleware.ClassifyError(err, classifyError)
+}
+
+// classifyError classifies the error with the etcd server errors
+func classifyError(err error) *config.ErrMessage {
+	switch rpctypes.Error(err) {
+	case rpctypes.ErrNoLeader, rpctypes.ErrNotLeader, rpctypes.ErrLeaderChanged:
+		return middleware.ErrNotLeader
+	case rpctypes.ErrAuthFailed, rpctypes.ErrPermissionDenied, rpctypes.ErrInvalidAuthToken,
+		rpctypes.ErrInvalidAuthMgmt, rpctypes.ErrUserEmpty:
+		return middleware.ErrAuthFailed
+	case rpctypes.ErrTimeout, rpctypes.ErrTimeoutDueToLeaderFail, rpctypes.ErrTimeoutDueToConnectionLost:
+		return middleware.ErrTimeout
+	case rpctypes.ErrTooManyRequests:
+		return middleware.ErrTooManyConnections
+	case rpctypes.ErrStopped:
+		return middleware.ErrUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,453 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/dsn"
+	"github.com/romberli/go-util/middleware/clickhouse"
+	"github.com/romberli/go-util/middleware/etcd"
+	"github.com/romberli/go-util/middleware/grpc"
+	"github.com/romberli/go-util/middleware/kafka"
+	"github.com/romberli/go-util/middleware/memcached"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/go-util/middleware/pool"
+	"github.com/romberli/go-util/middleware/prometheus"
+	"github.com/romberli/go-util/middleware/pulsar"
+	"github.com/romberli/go-util/middleware/vault"
+)
+
+const (
+	TypeMySQL      = "mysql"
+	TypeClickhouse = "clickhouse"
+	TypePrometheus = "prometheus"
+	TypeKafka      = "kafka"
+	TypeEtcd       = "etcd"
+	TypeMemcached  = "memcached"
+	TypeGRPC       = "grpc"
+	TypePulsar     = "pulsar"
+	TypeVault      = "vault"
+
+	OptionDSN                = "dsn"
+	OptionAddr               = "addr"
+	OptionAddrs              = "addrs"
+	OptionDBName             = "db_name"
+	OptionDBUser             = "db_user"
+	OptionDBPass             = "db_pass"
+	OptionUser               = "user"
+	OptionPass               = "pass"
+	OptionToken              = "token"
+	OptionVersion            = "version"
+	OptionDebug              = "debug"
+	OptionTimeout            = "timeout"
+	OptionReadTimeout        = "read_timeout"
+	OptionWriteTimeout       = "write_timeout"
+	OptionMaxConnections     = "max_connections"
+	OptionInitConnections    = "init_connections"
+	OptionMaxIdleConnections = "max_idle_connections"
+	OptionMaxIdleTime        = "max_idle_time"
+	OptionKeepAliveInterval  = "keep_alive_interval"
+	OptionCAFile             = "ca_file"
+	OptionCertFile           = "cert_file"
+	OptionKeyFile            = "key_file"
+	OptionTLSTrustCertsFile  = "tls_trust_certs_file"
+	OptionRoleID             = "role_id"
+	OptionSecretID           = "secret_id"
+)
+
+func init() {
+	Register(TypeMySQL, buildMySQL)
+	Register(TypeClickhouse, buildClickhouse)
+	Register(TypePrometheus, buildPrometheus)
+	Register(TypeKafka, buildKafka)
+	Register(TypeEtcd, buildEtcd)
+	Register(TypeMemcached, buildMemcached)
+	Register(TypeGRPC, buildGRPC)
+	Register(TypePulsar, buildPulsar)
+	Register(TypeVault, buildVault)
+}
+
+// getPoolConfig returns the pool config of the options, the missing options use the default values
+func getPoolConfig(options Options) (pool.Config, error) {
+	cfg := pool.NewConfigWithDefault()
+
+	var err error
+	cfg.MaxConnections, err = options.GetInt(OptionMaxConnections, cfg.MaxConnections)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.InitConnections, err = options.GetInt(OptionInitConnections, cfg.InitConnections)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.MaxIdleConnections, err = options.GetInt(OptionMaxIdleConnections, cfg.MaxIdleConnections)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.MaxIdleTime, err = options.GetInt(OptionMaxIdleTime, cfg.MaxIdleTime)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.KeepAliveInterval, err = options.GetInt(OptionKeepAliveInterval, cfg.KeepAliveInterval)
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// getDBOptions returns the address, database name, user and password of the database options
+func getDBOptions(options Options, defaultDBName string) (string, string, string, string, error) {
+	addr, err := options.GetRequiredString(OptionAddr)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	dbName, err := options.GetString(OptionDBName, defaultDBName)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	dbUser, err := options.GetString(OptionDBUser, constant.EmptyString)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	dbPass, err := options.GetString(OptionDBPass, constant.EmptyString)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return addr, dbName, dbUser, dbPass, nil
+}
+
+// getDSN parses the dsn option with given type, it returns nil if the dsn option is not specified
+func getDSN(options Options, typ string) (*dsn.DSN, error) {
+	if !options.Has(OptionDSN) {
+		return nil, nil
+	}
+	s, err := options.GetRequiredString(OptionDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsn.Parse(typ, s)
+}
+
+// buildMySQL builds a *mysql.Pool, the connection could be specified by either dsn option or the database options
+func buildMySQL(options Options) (interface{}, error) {
+	d, err := getDSN(options, dsn.TypeMySQL)
+	if err != nil {
+		return nil, err
+	}
+
+	var addr, dbName, dbUser, dbPass string
+	if d != nil {
+		addr, dbName, dbUser, dbPass = d.Addr(), d.Database, d.User, d.Password
+	} else {
+		addr, dbName, dbUser, dbPass, err = getDBOptions(options, constant.EmptyString)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pc, err := getPoolConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return mysql.NewPoolWithConfig(mysql.NewConfig(addr, dbName, dbUser, dbPass),
+		pc.MaxConnections, pc.InitConnections, pc.MaxIdleConnections, pc.MaxIdleTime, pc.KeepAliveInterval)
+}
+
+// buildClickhouse builds a *clickhouse.Pool
+func buildClickhouse(options Options) (interface{}, error) {
+	addr, dbName, dbUser, dbPass, err := getDBOptions(options, clickhouse.DefaultDatabase)
+	if err != nil {
+		return nil, err
+	}
+	altHosts, err := options.GetStringSlice(OptionAddrs)
+	if err != nil {
+		return nil, err
+	}
+	config := clickhouse.NewConfigWithDefault(addr, dbName, dbUser, dbPass, altHosts...)
+	config.Debug, err = options.GetBool(OptionDebug, config.Debug)
+	if err != nil {
+		return nil, err
+	}
+	config.ReadTimeout, err = options.GetInt(OptionReadTimeout, config.ReadTimeout)
+	if err != nil {
+		return nil, err
+	}
+	config.WriteTimeout, err = options.GetInt(OptionWriteTimeout, config.WriteTimeout)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := getPoolConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return clickhouse.NewPoolWithConfig(config,
+		pc.MaxConnections, pc.InitConnections, pc.MaxIdleConnections, pc.MaxIdleTime, pc.KeepAliveInterval)
+}
+
+// buildPrometheus builds a *prometheus.Pool, if user is specified, basic auth will be used,
+// the address and the user could also be specified by dsn option
+func buildPrometheus(options Options) (interface{}, error) {
+	d, err := getDSN(options, dsn.TypePrometheus)
+	if err != nil {
+		return nil, err
+	}
+
+	var addr, user, pass string
+	if d != nil {
+		user, pass = d.User, d.Password
+		// the user is passed with basic auth, not in the address
+		d.User, d.Password = constant.EmptyString, constant.EmptyString
+		addr = d.String()
+	} else {
+		addr, err = options.GetRequiredString(OptionAddr)
+		if err != nil {
+			return nil, err
+		}
+		user, err = options.GetString(OptionUser, constant.EmptyString)
+		if err != nil {
+			return nil, err
+		}
+		pass, err = options.GetString(OptionPass, constant.EmptyString)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pc, err := getPoolConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := prometheus.NewConfig(addr, prometheus.DefaultRoundTripper)
+	if user != constant.EmptyString {
+		config, err = prometheus.NewConfigWithBasicAuth(addr, user, pass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prometheus.NewPoolWithConfig(config,
+		pc.MaxConnections, pc.InitConnections, pc.MaxIdleConnections, pc.MaxIdleTime, pc.KeepAliveInterval)
+}
+
+// buildKafka builds a *kafka.AsyncProducer, the brokers could be specified by either dsn option or addrs option
+func buildKafka(options Options) (interface{}, error) {
+	version, err := options.GetRequiredString(OptionVersion)
+	if err != nil {
+		return nil, err
+	}
+	d, err := getDSN(options, dsn.TypeKafka)
+	if err != nil {
+		return nil, err
+	}
+	if d != nil {
+		return kafka.NewAsyncProducer(version, d.Addrs())
+	}
+	addrs, err := options.GetRequiredStringSlice(OptionAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return kafka.NewAsyncProducer(version, addrs)
+}
+
+// buildEtcd builds a *etcd.Conn
+func buildEtcd(options Options) (interface{}, error) {
+	addrs, err := options.GetRequiredStringSlice(OptionAddrs)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := options.GetDuration(OptionTimeout, etcd.DefaultConnectTimeOut)
+	if err != nil {
+		return nil, err
+	}
+
+	return etcd.NewEtcdConnWithConnectTimeout(addrs, timeout)
+}
+
+// buildMemcached builds a *memcached.Conn
+func buildMemcached(options Options) (interface{}, error) {
+	addrs, err := options.GetRequiredStringSlice(OptionAddrs)
+	if err != nil {
+		return nil, err
+	}
+	config := memcached.NewConfigWithDefault(addrs...)
+	config.Timeout, err = options.GetDuration(OptionTimeout, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return memcached.NewConnWithConfig(config)
+}
+
+// buildGRPC builds a *grpc.Conn, if ca file is specified, tls will be used
+func buildGRPC(options Options) (interface{}, error) {
+	addr, err := options.GetRequiredString(OptionAddr)
+	if err != nil {
+		return nil, err
+	}
+	caFile, err := options.GetString(OptionCAFile, constant.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+
+	config := grpc.NewConfigWithDefault(addr)
+	if caFile != constant.EmptyString {
+		certFile, err := options.GetString(OptionCertFile, constant.EmptyString)
+		if err != nil {
+			return nil, err
+		}
+		keyFile, err := options.GetString(OptionKeyFile, constant.EmptyString)
+		if err != nil {
+			return nil, err
+		}
+		config, err = grpc.NewConfigWithTLS(addr, caFile, certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	config.DialTimeout, err = options.GetDuration(OptionTimeout, config.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.NewConnWithConfig(config)
+}
+
+// buildPulsar builds a *pulsar.Conn
+func buildPulsar(options Options) (interface{}, error) {
+	addr, err := options.GetRequiredString(OptionAddr)
+	if err != nil {
+		return nil, err
+	}
+	config := pulsar.NewConfigWithDefault(addr)
+	config.Token, err = options.GetString(OptionToken, constant.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	config.TLSTrustCertsFile, err = options.GetString(OptionTLSTrustCertsFile, constant.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	config.OperationTimeout, err = options.GetDuration(OptionTimeout, config.OperationTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return pulsar.NewConnWithConfig(config)
+}
+
+// buildVault builds a *vault.Conn, it logins with token or approle
+func buildVault(options Options) (interface{}, error) {
+	addr, err := options.GetRequiredString(OptionAddr)
+	if err != nil {
+		return nil, err
+	}
+	token, err := options.GetString(OptionToken, constant.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	roleID, err := options.GetString(OptionRoleID, constant.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	secretID, err := options.GetString(OptionSecretID, constant.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+
+	var config vault.Config
+	switch {
+	case token != constant.EmptyString:
+		config = vault.NewConfig(addr, token)
+	case roleID != constant.EmptyString:
+		config = vault.NewConfigWithAppRole(addr, roleID, secretID)
+	default:
+		return nil, errors.New(fmt.Sprintf("either %s or %s should be specified", OptionToken, OptionRoleID))
+	}
+	config.Timeout, err = options.GetDuration(OptionTimeout, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return vault.NewConnWithConfig(config)
+}
+
+// GetMySQLPool returns the *mysql.Pool of given name
+func (f *Factory) GetMySQLPool(name string) (*mysql.Pool, error) {
+	client, err := f.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := client.(*mysql.Pool)
+	if !ok {
+		return nil, newTypeMismatchError(name, TypeMySQL, client)
+	}
+
+	return p, nil
+}
+
+// GetClickhousePool returns the *clickhouse.Pool of given name
+func (f *Factory) GetClickhousePool(name string) (*clickhouse.Pool, error) {
+	client, err := f.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := client.(*clickhouse.Pool)
+	if !ok {
+		return nil, newTypeMismatchError(name, TypeClickhouse, client)
+	}
+
+	return p, nil
+}
+
+// GetPrometheusPool returns the *prometheus.Pool of given name
+func (f *Factory) GetPrometheusPool(name string) (*prometheus.Pool, error) {
+	client, err := f.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := client.(*prometheus.Pool)
+	if !ok {
+		return nil, newTypeMismatchError(name, TypePrometheus, client)
+	}
+
+	return p, nil
+}
+
+// GetKafkaProducer returns the *kafka.AsyncProducer of given name
+func (f *Factory) GetKafkaProducer(name string) (*kafka.AsyncProducer, error) {
+	client, err := f.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := client.(*kafka.AsyncProducer)
+	if !ok {
+		return nil, newTypeMismatchError(name, TypeKafka, client)
+	}
+
+	return p, nil
+}
+
+// GetEtcdConn returns the *etcd.Conn of given name
+func (f *Factory) GetEtcdConn(name string) (*etcd.Conn, error) {
+	client, err := f.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	conn, ok := client.(*etcd.Conn)
+	if !ok {
+		return nil, newTypeMismatchError(name, TypeEtcd, client)
+	}
+
+	return conn, nil
+}
+
+// newTypeMismatchError returns an error which means the client is not the expected type
+func newTypeMismatchError(name, expected string, client interface{}) error {
+	return errors.New(fmt.Sprintf("middleware client is not %s. name: %s, actual type: %T", expected, name, client))
+}
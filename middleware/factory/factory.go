@@ -0,0 +1,221 @@
+package factory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+// Builder builds the middleware client with given options
+type Builder func(options Options) (interface{}, error)
+
+var (
+	buildersMutex sync.RWMutex
+	builders      = make(map[string]Builder)
+)
+
+// Register registers the builder of given middleware type, the registered builder replaces the existing one,
+// so that the applications could add their own middleware types or override the builtin ones
+func Register(typ string, builder Builder) {
+	buildersMutex.Lock()
+	defer buildersMutex.Unlock()
+
+	builders[typ] = builder
+}
+
+// getBuilder returns the builder of given middleware type
+func getBuilder(typ string) (Builder, bool) {
+	buildersMutex.RLock()
+	defer buildersMutex.RUnlock()
+
+	builder, ok := builders[typ]
+
+	return builder, ok
+}
+
+// Section is the config of a middleware, the name is used to get the client from the factory
+type Section struct {
+	Name    string  `json:"name"`
+	Type    string  `json:"type"`
+	Options Options `json:"options"`
+}
+
+// NewSection returns a new Section
+func NewSection(name, typ string, options Options) Section {
+	return Section{
+		Name:    name,
+		Type:    typ,
+		Options: options,
+	}
+}
+
+// Validate validates the section
+func (s *Section) Validate() (bool, error) {
+	if s.Name == constant.EmptyString {
+		return false, errors.New("name of middleware section should not be empty")
+	}
+	_, ok := getBuilder(s.Type)
+	if !ok {
+		return false, errors.New(fmt.Sprintf("middleware type is not registered. name: %s, type: %s", s.Name, s.Type))
+	}
+
+	return true, nil
+}
+
+// Factory builds the middleware clients by the config sections, each client is built once when it is got at the first time
+type Factory struct {
+	mutex    sync.Mutex
+	sections map[string]Section
+	clients  map[string]interface{}
+}
+
+// NewFactory returns a new *Factory with given sections
+func NewFactory(sections ...Section) (*Factory, error) {
+	f := &Factory{
+		sections: make(map[string]Section, len(sections)),
+		clients:  make(map[string]interface{}, len(sections)),
+	}
+
+	for _, section := range sections {
+		ok, err := section.Validate()
+		if !ok {
+			return nil, err
+		}
+		_, exists := f.sections[section.Name]
+		if exists {
+			return nil, errors.New(fmt.Sprintf("middleware section name is duplicated. name: %s", section.Name))
+		}
+		f.sections[section.Name] = section
+	}
+
+	return f, nil
+}
+
+// NewFactoryWithJSON returns a new *Factory with the json data, the data should be an array of the sections, for example:
+// [{"name": "main", "type": "mysql", "options": {"addr": "127.0.0.1:3306", "db_name": "test", "db_user": "root", "db_pass": "root"}}]
+func NewFactoryWithJSON(data []byte) (*Factory, error) {
+	var sections []Section
+	err := json.Unmarshal(data, &sections)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unmarshal middleware sections failed. %s", err.Error()))
+	}
+
+	return NewFactory(sections...)
+}
+
+// NewFactoryWithFile returns a new *Factory with the json config file, see NewFactoryWithJSON() for the format
+func NewFactoryWithFile(path string) (*Factory, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFactoryWithJSON(data)
+}
+
+// Names returns the sorted names of all the sections
+func (f *Factory) Names() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	names := make([]string, 0, len(f.sections))
+	for name := range f.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the client of given name, the client will be built if it is got at the first time,
+// it is built without holding the lock, so that a slow middleware will not block getting the other clients,
+// if more than one callers build the same client at the same time, only the first one is kept and the others are closed
+func (f *Factory) Get(name string) (interface{}, error) {
+	f.mutex.Lock()
+	client, ok := f.clients[name]
+	section, exists := f.sections[name]
+	f.mutex.Unlock()
+
+	if ok {
+		return client, nil
+	}
+	if !exists {
+		return nil, errors.New(fmt.Sprintf("middleware section does not exist. name: %s", name))
+	}
+	builder, ok := getBuilder(section.Type)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("middleware type is not registered. name: %s, type: %s", name, section.Type))
+	}
+
+	client, err := builder(section.Options)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("build middleware client failed. name: %s, type: %s. %s", name, section.Type, err.Error()))
+	}
+
+	f.mutex.Lock()
+	existing, ok := f.clients[name]
+	if !ok {
+		f.clients[name] = client
+	}
+	f.mutex.Unlock()
+
+	if ok {
+		err = closeClient(client)
+		if err != nil {
+			log.Warnf("close duplicated middleware client failed. name: %s, type: %s. %s", name, section.Type, err.Error())
+		}
+
+		return existing, nil
+	}
+	log.Infof("middleware client is built. name: %s, type: %s", name, section.Type)
+
+	return client, nil
+}
+
+// Init builds all the clients, it is useful to find out the wrong configs when the application starts
+func (f *Factory) Init() error {
+	var merr *multierror.Error
+	for _, name := range f.Names() {
+		_, err := f.Get(name)
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// Close closes all the built clients which have Close() method, the factory could be used again after closed
+func (f *Factory) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var merr *multierror.Error
+	for name, client := range f.clients {
+		err := closeClient(client)
+		if err != nil {
+			merr = multierror.Append(merr, errors.New(fmt.Sprintf("close middleware client failed. name: %s. %s", name, err.Error())))
+		}
+		delete(f.clients, name)
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// closeClient closes the client if it has Close() method
+func closeClient(client interface{}) error {
+	closer, ok := client.(interface{ Close() error })
+	if ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package factory
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testType = "test"
+
+type testClient struct {
+	addr   string
+	closed bool
+}
+
+func (tc *testClient) Close() error {
+	tc.closed = true
+
+	return nil
+}
+
+func init() {
+	Register(testType, func(options Options) (interface{}, error) {
+		addr, err := options.GetRequiredString(OptionAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		return &testClient{addr: addr}, nil
+	})
+}
+
+func TestOptions(t *testing.T) {
+	asst := assert.New(t)
+
+	options := Options{
+		"str":      "a",
+		"int":      float64(10),
+		"float":    1.5,
+		"bool":     "true",
+		"duration": "5s",
+		"seconds":  float64(3),
+		"slice":    []interface{}{"a", "b"},
+	}
+	s, err := options.GetString("str", "b")
+	asst.Nil(err, "test GetString() failed")
+	asst.Equal("a", s, "test GetString() failed")
+	s, err = options.GetString("none", "b")
+	asst.Nil(err, "test GetString() failed")
+	asst.Equal("b", s, "test GetString() failed")
+	_, err = options.GetRequiredString("none")
+	asst.NotNil(err, "test GetRequiredString() failed")
+
+	i, err := options.GetInt("int", 0)
+	asst.Nil(err, "test GetInt() failed")
+	asst.Equal(10, i, "test GetInt() failed")
+	_, err = options.GetInt("float", 0)
+	asst.NotNil(err, "test GetInt() failed")
+
+	b, err := options.GetBool("bool", false)
+	asst.Nil(err, "test GetBool() failed")
+	asst.True(b, "test GetBool() failed")
+
+	d, err := options.GetDuration("duration", 0)
+	asst.Nil(err, "test GetDuration() failed")
+	asst.Equal(5*time.Second, d, "test GetDuration() failed")
+	d, err = options.GetDuration("seconds", 0)
+	asst.Nil(err, "test GetDuration() failed")
+	asst.Equal(3*time.Second, d, "test GetDuration() failed")
+
+	slice, err := options.GetStringSlice("slice")
+	asst.Nil(err, "test GetStringSlice() failed")
+	asst.Equal([]string{"a", "b"}, slice, "test GetStringSlice() failed")
+	_, err = options.GetRequiredStringSlice("none")
+	asst.NotNil(err, "test GetRequiredStringSlice() failed")
+}
+
+func TestFactory_Get(t *testing.T) {
+	asst := assert.New(t)
+
+	data := []byte(`[
+		{"name": "a", "type": "test", "options": {"addr": "127.0.0.1:1"}},
+		{"name": "b", "type": "test", "options": {}}
+	]`)
+	f, err := NewFactoryWithJSON(data)
+	asst.Nil(err, "test NewFactoryWithJSON() failed")
+	asst.Equal([]string{"a", "b"}, f.Names(), "test Names() failed")
+
+	client, err := f.Get("a")
+	asst.Nil(err, "test Get() failed")
+	asst.Equal("127.0.0.1:1", client.(*testClient).addr, "test Get() failed")
+	again, err := f.Get("a")
+	asst.Nil(err, "test Get() failed")
+	asst.True(client == again, "test Get() failed")
+
+	_, err = f.Get("b")
+	asst.NotNil(err, "test Get() failed")
+	_, err = f.Get("c")
+	asst.NotNil(err, "test Get() failed")
+	asst.NotNil(f.Init(), "test Init() failed")
+	_, err = f.GetMySQLPool("a")
+	asst.NotNil(err, "test GetMySQLPool() failed")
+
+	asst.Nil(f.Close(), "test Close() failed")
+	asst.True(client.(*testClient).closed, "test Close() failed")
+}
+
+func TestNewFactory(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := NewFactory(NewSection("a", "unknown", nil))
+	asst.NotNil(err, "test NewFactory() failed")
+	_, err = NewFactory(NewSection("a", testType, nil), NewSection("a", testType, nil))
+	asst.NotNil(err, "test NewFactory() failed")
+	_, err = NewFactory(NewSection("", testType, nil))
+	asst.NotNil(err, "test NewFactory() failed")
+
+	// the builtin builder reports the missing options without connecting
+	f, err := NewFactory(NewSection("mysql", TypeMySQL, Options{OptionDBName: "test"}))
+	asst.Nil(err, "test NewFactory() failed")
+	_, err = f.GetMySQLPool("mysql")
+	asst.NotNil(err, "test GetMySQLPool() failed")
+	f, err = NewFactory(NewSection("mysql", TypeMySQL, Options{OptionDSN: "root@unix(/tmp/mysql.sock)/test"}))
+	asst.Nil(err, "test NewFactory() failed")
+	_, err = f.GetMySQLPool("mysql")
+	asst.NotNil(err, "test GetMySQLPool() failed")
+
+	Register("error", func(options Options) (interface{}, error) {
+		return nil, errors.New("test")
+	})
+	f, err = NewFactory(NewSection("e", "error", nil))
+	asst.Nil(err, "test NewFactory() failed")
+	asst.NotNil(f.Init(), "test Init() failed")
+}
+
+func TestFactory_GetWithSlowBuilder(t *testing.T) {
+	asst := assert.New(t)
+
+	var (
+		mutex   sync.Mutex
+		clients []*testClient
+	)
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	Register("slow", func(options Options) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		client := &testClient{}
+		clients = append(clients, client)
+
+		return client, nil
+	})
+
+	f, err := NewFactory(NewSection("slow", "slow", nil), NewSection("a", testType, Options{OptionAddr: "127.0.0.1:1"}))
+	asst.Nil(err, "test NewFactory() failed")
+
+	results := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			client, _ := f.Get("slow")
+			results <- client
+		}()
+	}
+	<-started
+	<-started
+
+	// the slow builder should not block getting the other clients
+	done := make(chan struct{})
+	go func() {
+		_, err = f.Get("a")
+		close(done)
+	}()
+	select {
+	case <-done:
+		asst.Nil(err, "test Get() failed")
+	case <-time.After(time.Second):
+		asst.Fail("test Get() failed", "slow builder blocked getting the other clients")
+	}
+
+	close(release)
+	first, second := <-results, <-results
+	asst.True(first == second, "test Get() failed")
+	asst.Equal(2, len(clients), "test Get() failed")
+	asst.True(clients[0].closed != clients[1].closed, "test Get() failed")
+	asst.Nil(f.Close(), "test Close() failed")
+}
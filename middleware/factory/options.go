@@ -0,0 +1,168 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+// Options is the options of a middleware section, the values are decoded from the config file,
+// so the numbers may be float64 and the lists may be []interface{}
+type Options map[string]interface{}
+
+// Has returns if the options contains given key
+func (o Options) Has(key string) bool {
+	_, ok := o[key]
+
+	return ok
+}
+
+// GetString returns the string value of given key, if the key does not exist, it returns defaultValue
+func (o Options) GetString(key string, defaultValue string) (string, error) {
+	value, ok := o[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return constant.EmptyString, errors.New(fmt.Sprintf("option should be a string. key: %s, type: %T", key, value))
+	}
+}
+
+// GetRequiredString returns the string value of given key, it returns error if the key does not exist or is empty
+func (o Options) GetRequiredString(key string) (string, error) {
+	value, err := o.GetString(key, constant.EmptyString)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if value == constant.EmptyString {
+		return constant.EmptyString, errors.New(fmt.Sprintf("option is required. key: %s", key))
+	}
+
+	return value, nil
+}
+
+// GetInt returns the int value of given key, if the key does not exist, it returns defaultValue
+func (o Options) GetInt(key string, defaultValue int) (int, error) {
+	value, ok := o[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return constant.ZeroInt, errors.New(fmt.Sprintf("option should be an integer. key: %s, value: %v", key, v))
+		}
+		return int(v), nil
+	case string:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return constant.ZeroInt, errors.New(fmt.Sprintf("option should be an integer. key: %s, value: %s", key, v))
+		}
+		return i, nil
+	default:
+		return constant.ZeroInt, errors.New(fmt.Sprintf("option should be an integer. key: %s, type: %T", key, value))
+	}
+}
+
+// GetBool returns the bool value of given key, if the key does not exist, it returns defaultValue
+func (o Options) GetBool(key string, defaultValue bool) (bool, error) {
+	value, ok := o[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, errors.New(fmt.Sprintf("option should be a bool. key: %s, value: %s", key, v))
+		}
+		return b, nil
+	default:
+		return false, errors.New(fmt.Sprintf("option should be a bool. key: %s, type: %T", key, value))
+	}
+}
+
+// GetDuration returns the duration value of given key, if the key does not exist, it returns defaultValue,
+// the value could be a duration string such as 10s, or a number which means seconds
+func (o Options) GetDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, ok := o[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return constant.ZeroInt, errors.New(fmt.Sprintf("option should be a duration. key: %s, value: %s", key, v))
+		}
+		return d, nil
+	default:
+		return constant.ZeroInt, errors.New(fmt.Sprintf("option should be a duration. key: %s, type: %T", key, value))
+	}
+}
+
+// GetStringSlice returns the string slice value of given key, a single string will be converted to a slice with one element
+func (o Options) GetStringSlice(key string) ([]string, error) {
+	value, ok := o[key]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		result := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("option should be a string slice. key: %s, item type: %T", key, item))
+			}
+			result[i] = s
+		}
+		return result, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("option should be a string slice. key: %s, type: %T", key, value))
+	}
+}
+
+// GetRequiredStringSlice returns the string slice value of given key, it returns error if the slice is empty
+func (o Options) GetRequiredStringSlice(key string) ([]string, error) {
+	values, err := o.GetStringSlice(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == constant.ZeroInt {
+		return nil, errors.New(fmt.Sprintf("option is required. key: %s", key))
+	}
+
+	return values, nil
+}
@@ -0,0 +1,77 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const annotationPath = "/api/annotations"
+
+// Annotation is an event which will be shown on the graphs,
+// if DashboardUID is empty, it will be an organization wide annotation which could be filtered by tags
+type Annotation struct {
+	DashboardUID string
+	PanelID      int
+	Time         time.Time
+	TimeEnd      time.Time
+	Tags         []string
+	Text         string
+}
+
+// NewAnnotation returns a new *Annotation which happens now
+func NewAnnotation(text string, tags ...string) *Annotation {
+	return &Annotation{
+		Time: time.Now(),
+		Tags: tags,
+		Text: text,
+	}
+}
+
+type annotationRequest struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	PanelID      int      `json:"panelId,omitempty"`
+	Time         int64    `json:"time,omitempty"`
+	TimeEnd      int64    `json:"timeEnd,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Text         string   `json:"text"`
+}
+
+type annotationResult struct {
+	ID int `json:"id"`
+}
+
+// PostAnnotation posts the annotation and returns the id of it
+func (conn *Conn) PostAnnotation(ctx context.Context, annotation *Annotation) (int, error) {
+	if annotation.Text == constant.EmptyString {
+		return constant.ZeroInt, errors.New("annotation text should not be empty")
+	}
+
+	req := &annotationRequest{
+		DashboardUID: annotation.DashboardUID,
+		PanelID:      annotation.PanelID,
+		Tags:         annotation.Tags,
+		Text:         annotation.Text,
+	}
+	if !annotation.Time.IsZero() {
+		req.Time = toMillisecond(annotation.Time)
+	}
+	if !annotation.TimeEnd.IsZero() {
+		req.TimeEnd = toMillisecond(annotation.TimeEnd)
+	}
+	result := &annotationResult{}
+
+	err := conn.post(ctx, annotationPath, req, result)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return result.ID, nil
+}
+
+// toMillisecond returns the unix epoch in milliseconds, grafana uses milliseconds for annotation time
+func toMillisecond(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,222 @@
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+
+	defaultHTTPPrefix  = "http://"
+	defaultHTTPSPrefix = "https://"
+
+	authorizationHeader = "Authorization"
+	contentTypeHeader   = "Content-Type"
+	orgIDHeader         = "X-Grafana-Org-Id"
+	bearerPrefix        = "Bearer "
+	jsonContentType     = "application/json"
+	healthPath          = "/api/health"
+)
+
+type Config struct {
+	Addr string
+	// APIKey is the api key or service account token, if it is not empty, User and Pass will be ignored
+	APIKey string
+	User   string
+	Pass   string
+	// OrgID is the organization id, if it is 0, the default organization of the user will be used
+	OrgID   int
+	Timeout time.Duration
+}
+
+// NewConfig returns a new Config with given api key
+func NewConfig(addr, apiKey string, orgID int, timeout time.Duration) Config {
+	return Config{
+		Addr:    normalizeAddr(addr),
+		APIKey:  apiKey,
+		OrgID:   orgID,
+		Timeout: timeout,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with given api key and default values
+func NewConfigWithDefault(addr, apiKey string) Config {
+	return NewConfig(addr, apiKey, constant.ZeroInt, DefaultTimeout)
+}
+
+// NewConfigWithBasicAuth returns a new Config with given user and password
+func NewConfigWithBasicAuth(addr, user, pass string) Config {
+	return Config{
+		Addr:    normalizeAddr(addr),
+		User:    user,
+		Pass:    pass,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// normalizeAddr adds http prefix to the address if it does not have one and trims the trailing slash
+func normalizeAddr(addr string) string {
+	address := strings.ToLower(addr)
+	if !strings.HasPrefix(address, defaultHTTPPrefix) && !strings.HasPrefix(address, defaultHTTPSPrefix) {
+		addr = defaultHTTPPrefix + addr
+	}
+
+	return strings.TrimSuffix(addr, constant.SlashString)
+}
+
+// APIError is returned when grafana responds with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("grafana api returned an error. status code: %d, message: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns if given error is a grafana api error with 404 status code
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+type Conn struct {
+	Config
+	Client *http.Client
+}
+
+// NewConn returns a new *Conn with given api key
+func NewConn(addr, apiKey string) *Conn {
+	return NewConnWithConfig(NewConfigWithDefault(addr, apiKey))
+}
+
+// NewConnWithConfig returns a new *Conn with given config
+func NewConnWithConfig(config Config) *Conn {
+	return &Conn{
+		Config: config,
+		Client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// CheckInstanceStatus checks grafana instance status
+func (conn *Conn) CheckInstanceStatus() bool {
+	var health struct {
+		Database string `json:"database"`
+	}
+
+	err := conn.get(context.Background(), healthPath, &health)
+	if err != nil {
+		return false
+	}
+
+	return health.Database == "ok"
+}
+
+// get sends a GET request and unmarshals the response body to out
+func (conn *Conn) get(ctx context.Context, path string, out interface{}) error {
+	return conn.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// post sends a POST request with json body and unmarshals the response body to out
+func (conn *Conn) post(ctx context.Context, path string, in, out interface{}) error {
+	return conn.do(ctx, http.MethodPost, path, in, out)
+}
+
+// put sends a PUT request with json body and unmarshals the response body to out
+func (conn *Conn) put(ctx context.Context, path string, in, out interface{}) error {
+	return conn.do(ctx, http.MethodPut, path, in, out)
+}
+
+// delete sends a DELETE request
+func (conn *Conn) delete(ctx context.Context, path string) error {
+	return conn.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// do sends the request to grafana, in will be marshaled as the request body if it is not nil,
+// if out is a *[]byte, the raw response body will be copied to it, otherwise, the body will be unmarshaled to out
+func (conn *Conn) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, conn.Addr+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set(contentTypeHeader, jsonContentType)
+	}
+	if conn.APIKey != constant.EmptyString {
+		apiKey, err := credential.DecryptIfNeeded(conn.APIKey)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(authorizationHeader, bearerPrefix+apiKey)
+	} else if conn.User != constant.EmptyString {
+		pass, err := credential.DecryptIfNeeded(conn.Pass)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(conn.User, pass)
+	}
+	if conn.OrgID > constant.ZeroInt {
+		req.Header.Set(orgIDHeader, strconv.Itoa(conn.OrgID))
+	}
+
+	resp, err := conn.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &msg) != nil || msg.Message == constant.EmptyString {
+			msg.Message = string(data)
+		}
+
+		return &APIError{StatusCode: resp.StatusCode, Message: msg.Message}
+	}
+
+	if out == nil || len(data) == constant.ZeroInt {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return errors.New(fmt.Sprintf("unmarshal grafana response failed. method: %s, path: %s. %s", method, path, err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIKey = "test_key"
+
+// newTestServer returns a fake grafana server which only serves the apis used in the tests
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"database": "ok", "version": "8.0.0"}`))
+	})
+	mux.HandleFunc(dashboardUIDPath+"test", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard": {"id": 1, "uid": "test", "title": "test"}, "meta": {"folderUid": "f01", "version": 1}}`))
+	})
+	mux.HandleFunc(dashboardImportPath, func(w http.ResponseWriter, r *http.Request) {
+		var req importDashboardRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		resp, _ := json.Marshal(&ImportDashboardResult{UID: "test", FolderUID: req.FolderUID, Imported: true})
+		_, _ = w.Write(resp)
+	})
+	mux.HandleFunc(folderPath+"/f01", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "folder not found"}`))
+	})
+	mux.HandleFunc(folderPath, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req folderRequest
+		_ = json.Unmarshal(body, &req)
+		resp, _ := json.Marshal(&Folder{ID: 1, UID: req.UID, Title: req.Title})
+		_, _ = w.Write(resp)
+	})
+	mux.HandleFunc(annotationPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"message": "annotation added", "id": 10}`))
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(authorizationHeader) != bearerPrefix+testAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "invalid api key"}`))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestConn_CheckInstanceStatus(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer()
+	defer server.Close()
+
+	asst.True(NewConn(server.URL, testAPIKey).CheckInstanceStatus(), "test CheckInstanceStatus() failed")
+	asst.False(NewConn(server.URL, "wrong_key").CheckInstanceStatus(), "test CheckInstanceStatus() failed")
+}
+
+func TestConn_Dashboard(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer()
+	defer server.Close()
+	conn := NewConn(server.URL, testAPIKey)
+
+	dashboard, err := conn.GetDashboard(context.Background(), "test")
+	asst.Nil(err, "test GetDashboard() failed")
+	asst.Equal("test", dashboard.UID(), "test GetDashboard() failed")
+	asst.Equal("f01", dashboard.Meta.FolderUID, "test GetDashboard() failed")
+
+	data, err := conn.ExportDashboard(context.Background(), "test")
+	asst.Nil(err, "test ExportDashboard() failed")
+	asst.JSONEq(`{"uid": "test", "title": "test"}`, string(data), "test ExportDashboard() failed")
+
+	result, err := conn.ImportDashboard(context.Background(), data, "f02", true)
+	asst.Nil(err, "test ImportDashboard() failed")
+	asst.True(result.Imported, "test ImportDashboard() failed")
+	asst.Equal("f02", result.FolderUID, "test ImportDashboard() failed")
+
+	_, err = conn.ImportDashboard(context.Background(), []byte("{"), "f02", true)
+	asst.NotNil(err, "test ImportDashboard() failed")
+}
+
+func TestConn_EnsureFolder(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer()
+	defer server.Close()
+	conn := NewConn(server.URL, testAPIKey)
+
+	_, err := conn.GetFolder(context.Background(), "f01")
+	asst.True(IsNotFound(err), "test GetFolder() failed")
+
+	folder, err := conn.EnsureFolder(context.Background(), "f01", "folder01")
+	asst.Nil(err, "test EnsureFolder() failed")
+	asst.Equal("f01", folder.UID, "test EnsureFolder() failed")
+	asst.Equal("folder01", folder.Title, "test EnsureFolder() failed")
+}
+
+func TestConn_PostAnnotation(t *testing.T) {
+	asst := assert.New(t)
+
+	server := newTestServer()
+	defer server.Close()
+	conn := NewConn(server.URL, testAPIKey)
+
+	id, err := conn.PostAnnotation(context.Background(), NewAnnotation("deploy", "release"))
+	asst.Nil(err, "test PostAnnotation() failed")
+	asst.Equal(10, id, "test PostAnnotation() failed")
+
+	_, err = conn.PostAnnotation(context.Background(), NewAnnotation(""))
+	asst.NotNil(err, "test PostAnnotation() failed")
+}
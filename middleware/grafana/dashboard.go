@@ -0,0 +1,201 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	dashboardDBPath     = "/api/dashboards/db"
+	dashboardUIDPath    = "/api/dashboards/uid/"
+	dashboardImportPath = "/api/dashboards/import"
+	searchPath          = "/api/search"
+
+	searchTypeDashboard = "dash-db"
+)
+
+type DashboardMeta struct {
+	Slug        string `json:"slug"`
+	URL         string `json:"url"`
+	FolderID    int    `json:"folderId"`
+	FolderUID   string `json:"folderUid"`
+	FolderTitle string `json:"folderTitle"`
+	Version     int    `json:"version"`
+	Provisioned bool   `json:"provisioned"`
+}
+
+// Dashboard is the dashboard with its metadata, Model is the dashboard json model
+type Dashboard struct {
+	Model map[string]interface{} `json:"dashboard"`
+	Meta  DashboardMeta          `json:"meta"`
+}
+
+// UID returns the uid of the dashboard model
+func (d *Dashboard) UID() string {
+	uid, _ := d.Model["uid"].(string)
+
+	return uid
+}
+
+// Title returns the title of the dashboard model
+func (d *Dashboard) Title() string {
+	title, _ := d.Model["title"].(string)
+
+	return title
+}
+
+type SaveDashboardResult struct {
+	ID      int    `json:"id"`
+	UID     string `json:"uid"`
+	URL     string `json:"url"`
+	Status  string `json:"status"`
+	Version int    `json:"version"`
+	Slug    string `json:"slug"`
+}
+
+type SearchResult struct {
+	ID          int      `json:"id"`
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URI         string   `json:"uri"`
+	URL         string   `json:"url"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	FolderID    int      `json:"folderId"`
+	FolderUID   string   `json:"folderUid"`
+	FolderTitle string   `json:"folderTitle"`
+}
+
+type saveDashboardRequest struct {
+	Dashboard map[string]interface{} `json:"dashboard"`
+	FolderUID string                 `json:"folderUid,omitempty"`
+	Message   string                 `json:"message,omitempty"`
+	Overwrite bool                   `json:"overwrite"`
+}
+
+type importDashboardRequest struct {
+	Dashboard json.RawMessage        `json:"dashboard"`
+	FolderUID string                 `json:"folderUid,omitempty"`
+	Overwrite bool                   `json:"overwrite"`
+	Inputs    []ImportDashboardInput `json:"inputs,omitempty"`
+}
+
+// ImportDashboardInput is used to fill the ${DS_XXX} like inputs of an exported dashboard
+type ImportDashboardInput struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	PluginID string `json:"pluginId"`
+	Value    string `json:"value"`
+}
+
+type ImportDashboardResult struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	ImportedURL string `json:"importedUrl"`
+	FolderUID   string `json:"folderUid"`
+	Imported    bool   `json:"imported"`
+	Revision    int    `json:"revision"`
+}
+
+// GetDashboard gets the dashboard by uid
+func (conn *Conn) GetDashboard(ctx context.Context, uid string) (*Dashboard, error) {
+	dashboard := &Dashboard{}
+
+	err := conn.get(ctx, dashboardUIDPath+url.PathEscape(uid), dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// ExportDashboard returns the json model of the dashboard, the id will be removed,
+// so that the returned json could be imported to another grafana instance directly
+func (conn *Conn) ExportDashboard(ctx context.Context, uid string) ([]byte, error) {
+	dashboard, err := conn.GetDashboard(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	delete(dashboard.Model, "id")
+
+	return json.Marshal(dashboard.Model)
+}
+
+// SaveDashboard creates or updates the dashboard, if overwrite is true, the existing dashboard with the same uid or title will be overwritten
+func (conn *Conn) SaveDashboard(ctx context.Context, model map[string]interface{}, folderUID, message string, overwrite bool) (*SaveDashboardResult, error) {
+	if model == nil {
+		return nil, errors.New("dashboard model should not be nil")
+	}
+
+	req := &saveDashboardRequest{
+		Dashboard: model,
+		FolderUID: folderUID,
+		Message:   message,
+		Overwrite: overwrite,
+	}
+	result := &SaveDashboardResult{}
+
+	err := conn.post(ctx, dashboardDBPath, req, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ImportDashboard imports the dashboard json which is usually exported by ExportDashboard() or grafana ui,
+// inputs are used to replace the datasource placeholders of the exported json
+func (conn *Conn) ImportDashboard(ctx context.Context, data []byte, folderUID string, overwrite bool, inputs ...ImportDashboardInput) (*ImportDashboardResult, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("dashboard json is not valid")
+	}
+
+	req := &importDashboardRequest{
+		Dashboard: data,
+		FolderUID: folderUID,
+		Overwrite: overwrite,
+		Inputs:    inputs,
+	}
+	result := &ImportDashboardResult{}
+
+	err := conn.post(ctx, dashboardImportPath, req, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteDashboard deletes the dashboard by uid
+func (conn *Conn) DeleteDashboard(ctx context.Context, uid string) error {
+	return conn.delete(ctx, dashboardUIDPath+url.PathEscape(uid))
+}
+
+// SearchDashboards searches dashboards by query and tags, if folderUIDs is not empty, only dashboards in these folders will be returned
+func (conn *Conn) SearchDashboards(ctx context.Context, query string, tags []string, folderUIDs ...string) ([]*SearchResult, error) {
+	values := url.Values{}
+	values.Set("type", searchTypeDashboard)
+	if query != constant.EmptyString {
+		values.Set("query", query)
+	}
+	for _, tag := range tags {
+		values.Add("tag", tag)
+	}
+	for _, folderUID := range folderUIDs {
+		values.Add("folderUIDs", folderUID)
+	}
+
+	var results []*SearchResult
+
+	err := conn.get(ctx, fmt.Sprintf("%s?%s", searchPath, values.Encode()), &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
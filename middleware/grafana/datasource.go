@@ -0,0 +1,99 @@
+package grafana
+
+import (
+	"context"
+	"net/url"
+)
+
+const (
+	datasourcePath     = "/api/datasources"
+	datasourceNamePath = "/api/datasources/name/"
+	datasourceUIDPath  = "/api/datasources/uid/"
+)
+
+type DataSource struct {
+	ID             int                    `json:"id,omitempty"`
+	UID            string                 `json:"uid,omitempty"`
+	OrgID          int                    `json:"orgId,omitempty"`
+	Name           string                 `json:"name"`
+	Type           string                 `json:"type"`
+	Access         string                 `json:"access"`
+	URL            string                 `json:"url"`
+	Database       string                 `json:"database,omitempty"`
+	User           string                 `json:"user,omitempty"`
+	BasicAuth      bool                   `json:"basicAuth"`
+	BasicAuthUser  string                 `json:"basicAuthUser,omitempty"`
+	IsDefault      bool                   `json:"isDefault"`
+	JSONData       map[string]interface{} `json:"jsonData,omitempty"`
+	SecureJSONData map[string]string      `json:"secureJsonData,omitempty"`
+	Version        int                    `json:"version,omitempty"`
+	ReadOnly       bool                   `json:"readOnly,omitempty"`
+}
+
+type createDataSourceResult struct {
+	ID         int         `json:"id"`
+	Name       string      `json:"name"`
+	DataSource *DataSource `json:"datasource"`
+}
+
+// GetDataSources returns all the datasources of the organization
+func (conn *Conn) GetDataSources(ctx context.Context) ([]*DataSource, error) {
+	var dataSources []*DataSource
+
+	err := conn.get(ctx, datasourcePath, &dataSources)
+	if err != nil {
+		return nil, err
+	}
+
+	return dataSources, nil
+}
+
+// GetDataSourceByName gets the datasource by name
+func (conn *Conn) GetDataSourceByName(ctx context.Context, name string) (*DataSource, error) {
+	return conn.getDataSource(ctx, datasourceNamePath+url.PathEscape(name))
+}
+
+// GetDataSourceByUID gets the datasource by uid
+func (conn *Conn) GetDataSourceByUID(ctx context.Context, uid string) (*DataSource, error) {
+	return conn.getDataSource(ctx, datasourceUIDPath+url.PathEscape(uid))
+}
+
+// getDataSource gets the datasource with given path
+func (conn *Conn) getDataSource(ctx context.Context, path string) (*DataSource, error) {
+	ds := &DataSource{}
+
+	err := conn.get(ctx, path, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// CreateDataSource creates a datasource and returns the created one
+func (conn *Conn) CreateDataSource(ctx context.Context, ds *DataSource) (*DataSource, error) {
+	result := &createDataSourceResult{}
+
+	err := conn.post(ctx, datasourcePath, ds, result)
+	if err != nil {
+		return nil, err
+	}
+	if result.DataSource == nil {
+		// old versions of grafana only return id and name
+		created := *ds
+		created.ID = result.ID
+		result.DataSource = &created
+	}
+
+	return result.DataSource, nil
+}
+
+// UpdateDataSource updates the datasource with given uid
+func (conn *Conn) UpdateDataSource(ctx context.Context, uid string, ds *DataSource) error {
+	return conn.put(ctx, datasourceUIDPath+url.PathEscape(uid), ds, nil)
+}
+
+// DeleteDataSource deletes the datasource by uid
+func (conn *Conn) DeleteDataSource(ctx context.Context, uid string) error {
+	return conn.delete(ctx, datasourceUIDPath+url.PathEscape(uid))
+}
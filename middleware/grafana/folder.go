@@ -0,0 +1,89 @@
+package grafana
+
+import (
+	"context"
+	"net/url"
+)
+
+const folderPath = "/api/folders"
+
+type Folder struct {
+	ID      int    `json:"id"`
+	UID     string `json:"uid"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Version int    `json:"version"`
+}
+
+type folderRequest struct {
+	UID       string `json:"uid,omitempty"`
+	Title     string `json:"title"`
+	Version   int    `json:"version,omitempty"`
+	Overwrite bool   `json:"overwrite,omitempty"`
+}
+
+// GetFolders returns all the folders which the user has permission to view
+func (conn *Conn) GetFolders(ctx context.Context) ([]*Folder, error) {
+	var folders []*Folder
+
+	err := conn.get(ctx, folderPath, &folders)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// GetFolder gets the folder by uid
+func (conn *Conn) GetFolder(ctx context.Context, uid string) (*Folder, error) {
+	folder := &Folder{}
+
+	err := conn.get(ctx, folderPath+"/"+url.PathEscape(uid), folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// CreateFolder creates a folder, if uid is empty, grafana will generate one
+func (conn *Conn) CreateFolder(ctx context.Context, uid, title string) (*Folder, error) {
+	folder := &Folder{}
+
+	err := conn.post(ctx, folderPath, &folderRequest{UID: uid, Title: title}, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// UpdateFolder updates the title of the folder, the existing folder will be overwritten regardless of the version
+func (conn *Conn) UpdateFolder(ctx context.Context, uid, title string) (*Folder, error) {
+	folder := &Folder{}
+
+	err := conn.put(ctx, folderPath+"/"+url.PathEscape(uid), &folderRequest{Title: title, Overwrite: true}, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// EnsureFolder returns the folder with given uid, if it does not exist, it will be created
+func (conn *Conn) EnsureFolder(ctx context.Context, uid, title string) (*Folder, error) {
+	folder, err := conn.GetFolder(ctx, uid)
+	if err == nil {
+		return folder, nil
+	}
+	if !IsNotFound(err) {
+		return nil, err
+	}
+
+	return conn.CreateFolder(ctx, uid, title)
+}
+
+// DeleteFolder deletes the folder by uid, note that all the dashboards in the folder will be deleted too
+func (conn *Conn) DeleteFolder(ctx context.Context, uid string) error {
+	return conn.delete(ctx, folderPath+"/"+url.PathEscape(uid))
+}
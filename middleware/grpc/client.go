@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+)
+
+const (
+	DefaultDialTimeout      = 10 * time.Second
+	DefaultKeepAliveTime    = 30 * time.Second
+	DefaultKeepAliveTimeout = 10 * time.Second
+	DefaultCheckTimeout     = 3 * time.Second
+)
+
+type Config struct {
+	Addr               string
+	TLSConfig          *tls.Config
+	DialTimeout        time.Duration
+	KeepAliveTime      time.Duration
+	KeepAliveTimeout   time.Duration
+	UnaryInterceptors  []grpc.UnaryClientInterceptor
+	StreamInterceptors []grpc.StreamClientInterceptor
+}
+
+// NewConfig returns a new Config, if tlsConfig is nil, the connection will be insecure
+func NewConfig(addr string, tlsConfig *tls.Config, dialTimeout, keepAliveTime, keepAliveTimeout time.Duration) Config {
+	return Config{
+		Addr:             addr,
+		TLSConfig:        tlsConfig,
+		DialTimeout:      dialTimeout,
+		KeepAliveTime:    keepAliveTime,
+		KeepAliveTimeout: keepAliveTimeout,
+	}
+}
+
+// NewConfigWithDefault returns a new insecure Config with default values
+func NewConfigWithDefault(addr string) Config {
+	return NewConfig(addr, nil, DefaultDialTimeout, DefaultKeepAliveTime, DefaultKeepAliveTimeout)
+}
+
+// NewConfigWithTLS returns a new Config with tls enabled,
+// caFile is used to verify the server certificate,
+// certFile and keyFile are optional, if both of them are specified, mutual tls will be used
+func NewConfigWithTLS(addr, caFile, certFile, keyFile string) (Config, error) {
+	tlsConfig, err := NewTLSConfig(caFile, certFile, keyFile)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return NewConfig(addr, tlsConfig, DefaultDialTimeout, DefaultKeepAliveTime, DefaultKeepAliveTimeout), nil
+}
+
+// NewTLSConfig returns a new *tls.Config with given ca file, cert file and key file
+func NewTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if caFile != constant.EmptyString {
+		caBytes, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New(fmt.Sprintf("could not append ca certificates to cert pool. ca file: %s", caFile))
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if certFile != constant.EmptyString && keyFile != constant.EmptyString {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// AddUnaryInterceptors appends given unary interceptors to the config
+func (c *Config) AddUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) {
+	c.UnaryInterceptors = append(c.UnaryInterceptors, interceptors...)
+}
+
+// AddStreamInterceptors appends given stream interceptors to the config
+func (c *Config) AddStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) {
+	c.StreamInterceptors = append(c.StreamInterceptors, interceptors...)
+}
+
+// DialOptions returns grpc dial options which are generated by the config
+func (c *Config) DialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+
+	if c.TLSConfig == nil {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(c.TLSConfig)))
+	}
+
+	if c.KeepAliveTime > constant.ZeroInt {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.KeepAliveTime,
+			Timeout:             c.KeepAliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	if len(c.UnaryInterceptors) > constant.ZeroInt {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(c.UnaryInterceptors...))
+	}
+	if len(c.StreamInterceptors) > constant.ZeroInt {
+		opts = append(opts, grpc.WithChainStreamInterceptor(c.StreamInterceptors...))
+	}
+
+	return opts
+}
+
+type Conn struct {
+	Config
+	*grpc.ClientConn
+}
+
+// NewConn returns a new insecure *Conn with default config
+func NewConn(addr string) (*Conn, error) {
+	return NewConnWithConfig(NewConfigWithDefault(addr))
+}
+
+// NewConnWithConfig returns a new *Conn with given config,
+// if dial timeout is larger than 0, it will block until the connection is up or timed out
+func NewConnWithConfig(config Config) (*Conn, error) {
+	ctx := context.Background()
+	opts := config.DialOptions()
+
+	if config.DialTimeout > constant.ZeroInt {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.DialTimeout)
+		defer cancel()
+
+		opts = append(opts, grpc.WithBlock())
+	}
+
+	cc, err := grpc.DialContext(ctx, config.Addr, opts...)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, middleware.ErrConnTimeout.Wrap(err)
+		}
+
+		return nil, ClassifyError(err)
+	}
+
+	return &Conn{
+		Config:     config,
+		ClientConn: cc,
+	}, nil
+}
+
+// Close closes the underlying client connection
+func (conn *Conn) Close() error {
+	return conn.ClientConn.Close()
+}
+
+// CheckHealth checks the serving status of given service with grpc health checking protocol,
+// empty service name means the overall health of the server
+func (conn *Conn) CheckHealth(ctx context.Context, service string) (bool, error) {
+	resp, err := grpc_health_v1.NewHealthClient(conn.ClientConn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+	if err != nil {
+		return false, ClassifyError(err)
+	}
+
+	return resp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING, nil
+}
+
+// CheckInstanceStatus checks if the server is serving
+func (conn *Conn) CheckInstanceStatus() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCheckTimeout)
+	defer cancel()
+
+	ok, err := conn.CheckHealth(ctx, constant.EmptyString)
+	if err != nil {
+		return false
+	}
+
+	return ok
+}
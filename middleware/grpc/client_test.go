@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const testService = "test"
+
+// startHealthServer starts a grpc server which only serves the health service
+func startHealthServer() (string, *health.Server, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	hs := health.NewServer()
+	hs.SetServingStatus(testService, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, hs)
+	go func() { _ = server.Serve(listener) }()
+
+	return listener.Addr().String(), hs, server.Stop
+}
+
+func TestConn_CheckHealth(t *testing.T) {
+	asst := assert.New(t)
+
+	addr, hs, stop := startHealthServer()
+	defer stop()
+
+	config := NewConfigWithDefault(addr)
+	config.AddUnaryInterceptors(UnaryLoggingInterceptor(), NewMetrics("test").UnaryInterceptor())
+	conn, err := NewConnWithConfig(config)
+	asst.Nil(err, "test CheckHealth() failed")
+	defer func() { _ = conn.Close() }()
+
+	asst.True(conn.CheckInstanceStatus(), "test CheckHealth() failed")
+	ok, err := conn.CheckHealth(context.Background(), testService)
+	asst.Nil(err, "test CheckHealth() failed")
+	asst.False(ok, "test CheckHealth() failed")
+
+	hs.SetServingStatus(testService, grpc_health_v1.HealthCheckResponse_SERVING)
+	ok, err = conn.CheckHealth(context.Background(), testService)
+	asst.Nil(err, "test CheckHealth() failed")
+	asst.True(ok, "test CheckHealth() failed")
+}
+
+func TestReadinessGate_Wait(t *testing.T) {
+	asst := assert.New(t)
+
+	addr, hs, stop := startHealthServer()
+	defer stop()
+
+	conn, err := NewConn(addr)
+	asst.Nil(err, "test Wait() failed")
+	defer func() { _ = conn.Close() }()
+
+	rg := NewReadinessGate(conn, testService, 10*time.Millisecond)
+	defer rg.Stop()
+	asst.False(rg.IsReady(), "test Wait() failed")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		hs.SetServingStatus(testService, grpc_health_v1.HealthCheckResponse_SERVING)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err = rg.Wait(ctx)
+	asst.Nil(err, "test Wait() failed")
+	asst.True(rg.IsReady(), "test Wait() failed")
+}
@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware"
+)
+
+// ClassifyError maps the grpc status error onto the classified middleware error,
+// it returns the original error if the error could not be classified
+func ClassifyError(err error) error {
+	return middleware.ClassifyError(err, classifyError)
+}
+
+// classifyError classifies the error with the grpc status code
+func classifyError(err error) *config.ErrMessage {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	switch s.Code() {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return middleware.ErrAuthFailed
+	case codes.ResourceExhausted:
+		return middleware.ErrTooManyConnections
+	case codes.DeadlineExceeded:
+		return middleware.ErrTimeout
+	case codes.Canceled:
+		return middleware.ErrCanceled
+	case codes.Unavailable:
+		return middleware.ErrUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultCheckInterval = 5 * time.Second
+
+// ReadinessGate periodically checks the health of the server with grpc health checking protocol,
+// it is useful to hold requests until the backend service is ready
+type ReadinessGate struct {
+	sync.RWMutex
+	conn     *Conn
+	service  string
+	interval time.Duration
+	ready    bool
+	lastErr  error
+	stopChan chan struct{}
+}
+
+// NewReadinessGate returns a new *ReadinessGate, it starts checking the server in background immediately
+func NewReadinessGate(conn *Conn, service string, interval time.Duration) *ReadinessGate {
+	if interval <= constant.ZeroInt {
+		interval = DefaultCheckInterval
+	}
+
+	rg := &ReadinessGate{
+		conn:     conn,
+		service:  service,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+
+	rg.check()
+	go rg.loop()
+
+	return rg
+}
+
+// IsReady returns if the service was serving at the latest check
+func (rg *ReadinessGate) IsReady() bool {
+	rg.RLock()
+	defer rg.RUnlock()
+
+	return rg.ready
+}
+
+// LastError returns the error of the latest check
+func (rg *ReadinessGate) LastError() error {
+	rg.RLock()
+	defer rg.RUnlock()
+
+	return rg.lastErr
+}
+
+// Wait blocks until the service is ready or the context is done
+func (rg *ReadinessGate) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(rg.interval)
+	defer ticker.Stop()
+
+	for {
+		if rg.IsReady() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New(fmt.Sprintf("waiting for service to be ready failed. service: %s, addr: %s. %s",
+				rg.service, rg.conn.Addr, ctx.Err().Error()))
+		case <-rg.stopChan:
+			return errors.New("readiness gate had been stopped")
+		case <-ticker.C:
+			rg.check()
+		}
+	}
+}
+
+// Stop stops checking the server in background
+func (rg *ReadinessGate) Stop() {
+	select {
+	case <-rg.stopChan:
+	default:
+		close(rg.stopChan)
+	}
+}
+
+// loop checks the server periodically until the gate is stopped
+func (rg *ReadinessGate) loop() {
+	ticker := time.NewTicker(rg.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rg.stopChan:
+			return
+		case <-ticker.C:
+			rg.check()
+		}
+	}
+}
+
+// check checks the server once and saves the result
+func (rg *ReadinessGate) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCheckTimeout)
+	defer cancel()
+
+	ready, err := rg.conn.CheckHealth(ctx, rg.service)
+	if err != nil {
+		log.Debugf("checking health of grpc service failed. service: %s, addr: %s. %s", rg.service, rg.conn.Addr, err.Error())
+	}
+
+	rg.Lock()
+	defer rg.Unlock()
+
+	rg.ready = ready
+	rg.lastErr = err
+}
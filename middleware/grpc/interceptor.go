@@ -0,0 +1,203 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/romberli/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/romberli/go-util/breaker"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 100 * time.Millisecond
+
+	defaultMetricsNamespace = "grpc_client"
+)
+
+var (
+	// DefaultRetryCodes are the status codes which are considered as transient errors
+	DefaultRetryCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted}
+	// DefaultBreakerFailureCodes are the status codes which indicate the server is unhealthy
+	DefaultBreakerFailureCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown}
+)
+
+// UnaryLoggingInterceptor returns a unary client interceptor which logs method, latency and status code of each call
+func UnaryLoggingInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(cc.Target(), method, start, err)
+
+		return err
+	}
+}
+
+// StreamLoggingInterceptor returns a stream client interceptor which logs method, latency and status code of stream creation
+func StreamLoggingInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		logCall(cc.Target(), method, start, err)
+
+		return cs, err
+	}
+}
+
+// logCall logs the grpc call, it logs with debug level if the call succeeded, otherwise, it logs with error level
+func logCall(target, method string, start time.Time, err error) {
+	if err != nil {
+		log.Errorf("grpc call failed. target: %s, method: %s, latency: %s, code: %s. %s",
+			target, method, time.Since(start).String(), status.Code(err).String(), err.Error())
+		return
+	}
+
+	log.Debugf("grpc call completed. target: %s, method: %s, latency: %s, code: %s",
+		target, method, time.Since(start).String(), codes.OK.String())
+}
+
+// UnaryRetryInterceptor returns a unary client interceptor which retries the call
+// when the returned status code is one of given codes, if codes is empty, DefaultRetryCodes will be used.
+// note that retrying is only safe for idempotent methods
+func UnaryRetryInterceptor(maxRetries int, backoff time.Duration, retryCodes ...codes.Code) grpc.UnaryClientInterceptor {
+	if len(retryCodes) == constant.ZeroInt {
+		retryCodes = DefaultRetryCodes
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+
+		for i := 0; i <= maxRetries; i++ {
+			if i > constant.ZeroInt {
+				// linear backoff
+				select {
+				case <-ctx.Done():
+					return err
+				case <-time.After(backoff * time.Duration(i)):
+				}
+			}
+
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !isRetryable(err, retryCodes) {
+				return err
+			}
+
+			log.Debugf("grpc call failed, will retry. target: %s, method: %s, attempt: %d. %s",
+				cc.Target(), method, i+1, err.Error())
+		}
+
+		return err
+	}
+}
+
+// isRetryable checks if status code of given error is in the retry codes
+func isRetryable(err error, retryCodes []codes.Code) bool {
+	code := status.Code(err)
+	for _, c := range retryCodes {
+		if code == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBreakerFailure reports whether the error should be counted as a failure by the circuit breaker,
+// the errors caused by the client, such as codes.InvalidArgument and codes.NotFound, are not failures,
+// it could be used as breaker.Config.IsFailure
+func IsBreakerFailure(err error) bool {
+	return isRetryable(err, DefaultBreakerFailureCodes)
+}
+
+// UnaryBreakerInterceptor returns a unary client interceptor which protects the calls with the circuit breaker,
+// the calls are rejected with codes.Unavailable while the breaker is open
+func UnaryBreakerInterceptor(b *breaker.Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done, err := b.Allow()
+		if err != nil {
+			return status.Error(codes.Unavailable, fmt.Sprintf("grpc call rejected. target: %s, method: %s. %s", cc.Target(), method, err.Error()))
+		}
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(err)
+
+		return err
+	}
+}
+
+type Metrics struct {
+	RequestCounter   *prometheus.CounterVec
+	LatencyHistogram *prometheus.HistogramVec
+}
+
+// NewMetrics returns a new *Metrics, if namespace is empty, default namespace will be used
+func NewMetrics(namespace string) *Metrics {
+	if namespace == constant.EmptyString {
+		namespace = defaultMetricsNamespace
+	}
+
+	return &Metrics{
+		RequestCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "total number of grpc client calls",
+		}, []string{"target", "method", "code"}),
+		LatencyHistogram: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "latency of grpc client calls",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"target", "method"}),
+	}
+}
+
+// Register registers the collectors to given registerer
+func (m *Metrics) Register(registerer prometheus.Registerer) error {
+	err := registerer.Register(m.RequestCounter)
+	if err != nil {
+		return err
+	}
+
+	return registerer.Register(m.LatencyHistogram)
+}
+
+// observe records the result of a grpc call
+func (m *Metrics) observe(target, method string, start time.Time, err error) {
+	m.RequestCounter.WithLabelValues(target, method, status.Code(err).String()).Inc()
+	m.LatencyHistogram.WithLabelValues(target, method).Observe(time.Since(start).Seconds())
+}
+
+// UnaryInterceptor returns a unary client interceptor which records metrics of each call
+func (m *Metrics) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(cc.Target(), method, start, err)
+
+		return err
+	}
+}
+
+// StreamInterceptor returns a stream client interceptor which records metrics of stream creation
+func (m *Metrics) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		m.observe(cc.Target(), method, start, err)
+
+		return cs, err
+	}
+}
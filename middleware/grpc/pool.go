@@ -0,0 +1,215 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultMaxConnections = 4
+
+type PoolConfig struct {
+	Config
+	// MaxConnections is the maximum number of connections for each target
+	MaxConnections int
+}
+
+// NewPoolConfig returns a new PoolConfig
+func NewPoolConfig(config Config, maxConnections int) PoolConfig {
+	return PoolConfig{
+		Config:         config,
+		MaxConnections: maxConnections,
+	}
+}
+
+// NewPoolConfigWithDefault returns a new PoolConfig with default values
+func NewPoolConfigWithDefault() PoolConfig {
+	return NewPoolConfig(NewConfigWithDefault(constant.EmptyString), DefaultMaxConnections)
+}
+
+// Validate validates pool config
+func (cfg *PoolConfig) Validate() (bool, error) {
+	if cfg.MaxConnections <= constant.ZeroInt {
+		return false, errors.New("maximum connection argument should larger than 0")
+	}
+
+	return true, nil
+}
+
+// targetConns holds the connections of one target
+type targetConns struct {
+	conns []*Conn
+	index int
+	// pending is the number of the connections which are being created
+	pending int
+}
+
+// Pool maintains connections per target, as a grpc client connection could multiplex many calls,
+// the pool does not lend connections exclusively, it returns them in a round-robin way
+type Pool struct {
+	sync.Mutex
+	PoolConfig
+	// cond is signaled when a pending connection is created or failed to be created
+	cond     *sync.Cond
+	targets  map[string]*targetConns
+	isClosed bool
+}
+
+// NewPool returns a new *Pool with given config, the Addr of the config will be ignored
+func NewPool(config PoolConfig) (*Pool, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+
+	p := &Pool{
+		PoolConfig: config,
+		targets:    make(map[string]*targetConns),
+	}
+	p.cond = sync.NewCond(&p.Mutex)
+
+	return p, nil
+}
+
+// NewPoolWithDefault returns a new *Pool with default config
+func NewPoolWithDefault() (*Pool, error) {
+	return NewPool(NewPoolConfigWithDefault())
+}
+
+// IsClosed returns if pool had been closed
+func (p *Pool) IsClosed() bool {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.isClosed
+}
+
+// Get returns a connection of given target, it creates new connections lazily until reaching the maximum connections,
+// connections which had been shut down or in transient failure state will be replaced,
+// the connections are created without holding the lock, so that a slow target will not block the other targets
+func (p *Pool) Get(target string) (*Conn, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	for {
+		if p.isClosed {
+			return nil, errors.New("pool had been closed")
+		}
+
+		tc, ok := p.targets[target]
+		if !ok {
+			tc = &targetConns{}
+			p.targets[target] = tc
+		}
+
+		if len(tc.conns)+tc.pending < p.MaxConnections {
+			return p.addConn(target, tc)
+		}
+
+		if len(tc.conns) > constant.ZeroInt {
+			tc.index = (tc.index + 1) % len(tc.conns)
+			conn := tc.conns[tc.index]
+			state := conn.GetState()
+			if state == connectivity.Shutdown || state == connectivity.TransientFailure {
+				_ = conn.Close()
+				tc.conns = append(tc.conns[:tc.index], tc.conns[tc.index+1:]...)
+
+				return p.addConn(target, tc)
+			}
+
+			return conn, nil
+		}
+
+		// all the connections of the target are being created, wait for them
+		p.cond.Wait()
+	}
+}
+
+// addConn reserves a slot of given target and creates a new connection for it,
+// the lock must be held by the caller, it is released while creating the connection
+func (p *Pool) addConn(target string, tc *targetConns) (*Conn, error) {
+	tc.pending++
+	p.Unlock()
+
+	conn, err := p.newConn(target)
+
+	p.Lock()
+	tc.pending--
+	p.cond.Broadcast()
+
+	if err != nil {
+		return nil, err
+	}
+	if p.isClosed || p.targets[target] != tc {
+		_ = conn.Close()
+		return nil, errors.New(fmt.Sprintf("target had been removed from the pool while creating the connection. target: %s", target))
+	}
+	tc.conns = append(tc.conns, conn)
+
+	return conn, nil
+}
+
+// newConn creates a new connection to given target
+func (p *Pool) newConn(target string) (*Conn, error) {
+	cfg := p.Config
+	cfg.Addr = target
+
+	conn, err := NewConnWithConfig(cfg)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("create grpc connection failed. target: %s. %s", target, err.Error()))
+	}
+
+	return conn, nil
+}
+
+// Remove closes all connections of given target and removes the target from the pool
+func (p *Pool) Remove(target string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	tc, ok := p.targets[target]
+	if !ok {
+		return nil
+	}
+	delete(p.targets, target)
+
+	return closeConns(tc.conns)
+}
+
+// Close closes all connections in the pool
+func (p *Pool) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	merr := &multierror.Error{}
+
+	for target, tc := range p.targets {
+		err := closeConns(tc.conns)
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+		delete(p.targets, target)
+	}
+	p.isClosed = true
+
+	return merr.ErrorOrNil()
+}
+
+// closeConns closes given connections
+func closeConns(conns []*Conn) error {
+	merr := &multierror.Error{}
+
+	for _, conn := range conns {
+		err := conn.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
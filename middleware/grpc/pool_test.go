@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
+)
+
+func TestPool(t *testing.T) {
+	asst := assert.New(t)
+
+	addr, _, stop := startHealthServer()
+	defer stop()
+
+	pool, err := NewPool(NewPoolConfig(NewConfigWithDefault(addr), 2))
+	asst.Nil(err, "create pool failed")
+
+	conn1, err := pool.Get(addr)
+	asst.Nil(err, "get connection from pool failed")
+	conn2, err := pool.Get(addr)
+	asst.Nil(err, "get connection from pool failed")
+	asst.NotEqual(conn1, conn2, "pool should create new connection before reaching maximum connections")
+	conn3, err := pool.Get(addr)
+	asst.Nil(err, "get connection from pool failed")
+	asst.True(conn3 == conn1 || conn3 == conn2, "pool should reuse connections after reaching maximum connections")
+	asst.True(conn3.CheckInstanceStatus(), "connection from pool is not valid")
+
+	err = pool.Close()
+	asst.Nil(err, "close pool failed")
+	asst.True(pool.IsClosed(), "pool should be closed")
+	_, err = pool.Get(addr)
+	asst.NotNil(err, "get connection from closed pool should fail")
+}
+
+func TestPool_GetWithSlowTarget(t *testing.T) {
+	asst := assert.New(t)
+
+	addr, _, stop := startHealthServer()
+	defer stop()
+
+	// the slow target accepts the connections but never finishes the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	asst.Nil(err, "listen failed")
+	defer func() { _ = listener.Close() }()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := listener.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+	slowAddr := listener.Addr().String()
+
+	config := NewConfigWithDefault(constant.EmptyString)
+	config.DialTimeout = 2 * time.Second
+	pool, err := NewPool(NewPoolConfig(config, 1))
+	asst.Nil(err, "create pool failed")
+	defer func() { _ = pool.Close() }()
+
+	slowErr := make(chan error, 1)
+	go func() {
+		_, err := pool.Get(slowAddr)
+		slowErr <- err
+	}()
+	select {
+	case c := <-accepted:
+		defer func() { _ = c.Close() }()
+	case <-time.After(config.DialTimeout):
+		asst.FailNow("slow target did not receive the connection")
+	}
+
+	start := time.Now()
+	conn, err := pool.Get(addr)
+	asst.Nil(err, "get connection from pool failed")
+	asst.True(conn.CheckInstanceStatus(), "connection from pool is not valid")
+	asst.True(time.Since(start) < time.Second, "slow target should not block getting connection of the other targets")
+
+	asst.NotNil(<-slowErr, "get connection of slow target should fail")
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/tracing"
 )
 
 type DefaultConsumerGroupHandler struct{}
@@ -15,6 +17,7 @@ func (DefaultConsumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error
 
 func (h DefaultConsumerGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for message := range claim.Messages() {
+		span, _ := StartConsumeSpan(sess.Context(), message)
 		var headers []map[string]string
 		for _, header := range message.Headers {
 			headers = append(headers, ConvertHeaderToMap(*header))
@@ -23,6 +26,7 @@ func (h DefaultConsumerGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSessi
 		log.Infof("topic: %s, partition: %d, offset: %d, key: %s, value: %s, headers: %v",
 			message.Topic, message.Partition, message.Offset, string(message.Key), string(message.Value), headers)
 		sess.MarkMessage(message, "")
+		tracing.Finish(span, nil)
 	}
 
 	return nil
@@ -0,0 +1,42 @@
+package kafka
+
+import (
+	"errors"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware"
+)
+
+// ClassifyError maps the kafka error onto the classified middleware error,
+// it returns the original error if the error could not be classified
+func ClassifyError(err error) error {
+	return middleware.ClassifyError(err, classifyError)
+}
+
+// classifyError classifies the error with the kafka error code
+func classifyError(err error) *config.ErrMessage {
+	if errors.Is(err, sarama.ErrOutOfBrokers) || errors.Is(err, sarama.ErrClosedClient) {
+		return middleware.ErrUnavailable
+	}
+
+	var kErr sarama.KError
+	if !errors.As(err, &kErr) {
+		return nil
+	}
+
+	switch kErr {
+	case sarama.ErrNotLeaderForPartition, sarama.ErrLeaderNotAvailable, sarama.ErrNotController:
+		return middleware.ErrNotLeader
+	case sarama.ErrSASLAuthenticationFailed, sarama.ErrTopicAuthorizationFailed,
+		sarama.ErrGroupAuthorizationFailed, sarama.ErrClusterAuthorizationFailed:
+		return middleware.ErrAuthFailed
+	case sarama.ErrRequestTimedOut:
+		return middleware.ErrTimeout
+	case sarama.ErrBrokerNotAvailable, sarama.ErrNotEnoughReplicas, sarama.ErrNotEnoughReplicasAfterAppend:
+		return middleware.ErrUnavailable
+	}
+
+	return nil
+}
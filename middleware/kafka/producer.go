@@ -1,13 +1,18 @@
 package kafka
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/metrics"
+	"github.com/romberli/go-util/tracing"
 )
 
 type AsyncProducer struct {
@@ -34,7 +39,7 @@ func NewAsyncProducer(kafkaVersion string, brokerList []string) (p *AsyncProduce
 	// Start with a client
 	client, err := sarama.NewClient(brokerList, config)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyError(err)
 	}
 
 	// Start a new consumer group
@@ -78,7 +83,14 @@ func (p *AsyncProducer) BuildProducerMessage(topicName string, key string, messa
 	}
 }
 
-func (p *AsyncProducer) Produce(topicName string, message interface{}) (err error) {
+// Produce produces the message to given topic asynchronously, message must be either string type or *sarama.ProducerMessage type
+func (p *AsyncProducer) Produce(topicName string, message interface{}) error {
+	return p.ProduceContext(context.Background(), topicName, message)
+}
+
+// ProduceContext produces the message to given topic asynchronously with context,
+// it returns the error of the context if the context is done before the message is put into the input channel
+func (p *AsyncProducer) ProduceContext(ctx context.Context, topicName string, message interface{}) (err error) {
 	var (
 		producerMessage *sarama.ProducerMessage
 	)
@@ -98,6 +110,7 @@ func (p *AsyncProducer) Produce(topicName string, message interface{}) (err erro
 				}
 			case fail := <-p.Producer.Errors():
 				if fail != nil {
+					metrics.CountOperationError(metrics.ComponentKafka, metrics.OperationPublish)
 					log.Errorf("err: ", fail.Err)
 				}
 			}
@@ -115,8 +128,27 @@ func (p *AsyncProducer) Produce(topicName string, message interface{}) (err erro
 				reflect.TypeOf(message).Name()))
 	}
 
-	// Produce message to kafka
-	p.Producer.Input() <- producerMessage
+	// the message is sent asynchronously, so the span only records that the message is put into the input channel
+	span, _ := tracing.StartProducerSpan(ctx, tracing.ComponentKafka, joinBrokerList(p.BrokerList))
+	ext.MessageBusDestination.Set(span, producerMessage.Topic)
+	if tracing.IsEnabled() {
+		err = tracing.Inject(span, producerHeaderCarrier{producerMessage})
+		if err != nil {
+			log.Errorf("inject span context into message headers failed. topic: %s. %s", producerMessage.Topic, err.Error())
+		}
+	}
 
-	return nil
+	// Produce message to kafka, the latency only includes the time waiting for the input channel,
+	// the failures of sending will be counted asynchronously
+	start := time.Now()
+	select {
+	case p.Producer.Input() <- producerMessage:
+		err = nil
+	case <-ctx.Done():
+		err = ClassifyError(ctx.Err())
+	}
+	metrics.ObserveOperation(metrics.ComponentKafka, metrics.OperationPublish, start, err)
+	tracing.Finish(span, err)
+
+	return err
 }
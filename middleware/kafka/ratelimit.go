@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/romberli/go-util/ratelimit"
+)
+
+// RateLimitedConsumerGroupHandler passes the messages to the wrapped handler no faster than the limiter allows
+type RateLimitedConsumerGroupHandler struct {
+	sarama.ConsumerGroupHandler
+	Limiter ratelimit.Limiter
+}
+
+// NewRateLimitedConsumerGroupHandler returns a new *RateLimitedConsumerGroupHandler
+func NewRateLimitedConsumerGroupHandler(handler sarama.ConsumerGroupHandler, limiter ratelimit.Limiter) *RateLimitedConsumerGroupHandler {
+	return &RateLimitedConsumerGroupHandler{
+		ConsumerGroupHandler: handler,
+		Limiter:              limiter,
+	}
+}
+
+// ConsumeClaim implements sarama.ConsumerGroupHandler interface
+func (h *RateLimitedConsumerGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	return h.ConsumerGroupHandler.ConsumeClaim(sess, &rateLimitedClaim{
+		ConsumerGroupClaim: claim,
+		messages:           h.limit(sess.Context(), claim.Messages()),
+	})
+}
+
+// limit forwards the messages after waiting for the limiter, the returned channel will be closed
+// when the input channel is closed or the session is done
+func (h *RateLimitedConsumerGroupHandler) limit(ctx context.Context, in <-chan *sarama.ConsumerMessage) <-chan *sarama.ConsumerMessage {
+	out := make(chan *sarama.ConsumerMessage)
+	go func() {
+		defer close(out)
+
+		for message := range in {
+			err := h.Limiter.Wait(ctx)
+			if err != nil {
+				return
+			}
+
+			select {
+			case out <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+type rateLimitedClaim struct {
+	sarama.ConsumerGroupClaim
+	messages <-chan *sarama.ConsumerMessage
+}
+
+// Messages returns the rate limited messages channel
+func (c *rateLimitedClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
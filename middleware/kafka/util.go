@@ -1,7 +1,59 @@
 package kafka
 
-import "github.com/Shopify/sarama"
+import (
+	"context"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/tracing"
+)
 
 func ConvertHeaderToMap(header sarama.RecordHeader) map[string]string {
 	return map[string]string{string(header.Key): string(header.Value)}
 }
+
+// producerHeaderCarrier injects the span context into the headers of the producer message
+type producerHeaderCarrier struct {
+	message *sarama.ProducerMessage
+}
+
+// Set implements opentracing.TextMapWriter interface
+func (phc producerHeaderCarrier) Set(key, val string) {
+	phc.message.Headers = append(phc.message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(val)})
+}
+
+// consumerHeaderCarrier extracts the span context from the headers of the consumer message
+type consumerHeaderCarrier []*sarama.RecordHeader
+
+// ForeachKey implements opentracing.TextMapReader interface
+func (chc consumerHeaderCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, header := range chc {
+		if header == nil {
+			continue
+		}
+		err := handler(string(header.Key), string(header.Value))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartConsumeSpan starts a consumer span for the message, the span follows the producer span propagated by the headers,
+// custom consumer group handlers could use it to trace the message processing, the caller should finish the span
+func StartConsumeSpan(ctx context.Context, message *sarama.ConsumerMessage) (opentracing.Span, context.Context) {
+	span, ctx := tracing.StartConsumerSpan(ctx, tracing.ComponentKafka, constant.EmptyString, consumerHeaderCarrier(message.Headers))
+	ext.MessageBusDestination.Set(span, message.Topic)
+
+	return span, ctx
+}
+
+// joinBrokerList returns the broker list as the peer address of the spans
+func joinBrokerList(brokerList []string) string {
+	return strings.Join(brokerList, constant.CommaString)
+}
@@ -0,0 +1,154 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultQPS     = 20
+	DefaultBurst   = 50
+	DefaultTimeout = 30 * time.Second
+
+	kubeConfigEnv = "KUBECONFIG"
+)
+
+type Config struct {
+	// KubeConfigPath is the path of kubeconfig file, if it is empty and InCluster is false,
+	// $KUBECONFIG or ~/.kube/config will be used
+	KubeConfigPath string
+	// Context is the context in the kubeconfig file, empty means the current context
+	Context string
+	// InCluster means using the service account of the pod
+	InCluster bool
+	QPS       float32
+	Burst     int
+	Timeout   time.Duration
+}
+
+// NewConfig returns a new Config with given kubeconfig path and context
+func NewConfig(kubeConfigPath, context string) Config {
+	return Config{
+		KubeConfigPath: kubeConfigPath,
+		Context:        context,
+		QPS:            DefaultQPS,
+		Burst:          DefaultBurst,
+		Timeout:        DefaultTimeout,
+	}
+}
+
+// NewInClusterConfig returns a new Config which uses the service account of the pod
+func NewInClusterConfig() Config {
+	return Config{
+		InCluster: true,
+		QPS:       DefaultQPS,
+		Burst:     DefaultBurst,
+		Timeout:   DefaultTimeout,
+	}
+}
+
+// NewConfigWithDefault returns a new Config, if it is running in a pod, in-cluster config will be used,
+// otherwise, $KUBECONFIG or ~/.kube/config will be used
+func NewConfigWithDefault() Config {
+	if IsInCluster() {
+		return NewInClusterConfig()
+	}
+
+	return NewConfig(constant.EmptyString, constant.EmptyString)
+}
+
+// IsInCluster returns if it is running in a kubernetes pod
+func IsInCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != constant.EmptyString && os.Getenv("KUBERNETES_SERVICE_PORT") != constant.EmptyString
+}
+
+// RestConfig returns the *rest.Config which is used to create the clientset
+func (cfg Config) RestConfig() (*rest.Config, error) {
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+
+	if cfg.InCluster {
+		restConfig, err = rest.InClusterConfig()
+	} else {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = cfg.kubeConfigPath()
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: cfg.Context}
+		restConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig.QPS = cfg.QPS
+	restConfig.Burst = cfg.Burst
+	restConfig.Timeout = cfg.Timeout
+
+	return restConfig, nil
+}
+
+// kubeConfigPath returns the path of the kubeconfig file
+func (cfg Config) kubeConfigPath() string {
+	if cfg.KubeConfigPath != constant.EmptyString {
+		return cfg.KubeConfigPath
+	}
+	if os.Getenv(kubeConfigEnv) != constant.EmptyString {
+		// the loading rules will merge the files in $KUBECONFIG
+		return constant.EmptyString
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return constant.EmptyString
+	}
+
+	return filepath.Join(home, ".kube", "config")
+}
+
+type Conn struct {
+	Config
+	Clientset kubernetes.Interface
+}
+
+// NewConn returns a new *Conn with given kubeconfig path, empty path means the default kubeconfig
+func NewConn(kubeConfigPath string) (*Conn, error) {
+	return NewConnWithConfig(NewConfig(kubeConfigPath, constant.EmptyString))
+}
+
+// NewConnWithConfig returns a new *Conn with given config
+func NewConnWithConfig(config Config) (*Conn, error) {
+	restConfig, err := config.RestConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		Config:    config,
+		Clientset: clientset,
+	}, nil
+}
+
+// NewConnWithClientset returns a new *Conn with given clientset, it is useful for testing with the fake clientset
+func NewConnWithClientset(clientset kubernetes.Interface) *Conn {
+	return &Conn{Clientset: clientset}
+}
+
+// CheckInstanceStatus checks if the api server is reachable
+func (conn *Conn) CheckInstanceStatus() bool {
+	_, err := conn.Clientset.Discovery().ServerVersion()
+
+	return err == nil
+}
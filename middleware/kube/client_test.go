@@ -0,0 +1,123 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "test"
+
+func newTestConn() *Conn {
+	replicas := int32(3)
+
+	return NewConnWithClientset(fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql-0", Namespace: testNamespace, Labels: map[string]string{"app": "mysql"}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "proxy-0", Namespace: testNamespace, Labels: map[string]string{"app": "proxy"}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: testNamespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: testNamespace},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				Ports:     []corev1.EndpointPort{{Port: 3306}},
+			}},
+		},
+	))
+}
+
+func TestConn_Pod(t *testing.T) {
+	asst := assert.New(t)
+
+	conn := newTestConn()
+	pods, err := conn.ListPods(context.Background(), testNamespace, "app=mysql")
+	asst.Nil(err, "test ListPods() failed")
+	asst.Equal(1, len(pods), "test ListPods() failed")
+	asst.True(IsPodReady(&pods[0]), "test IsPodReady() failed")
+
+	pod, err := conn.GetPod(context.Background(), testNamespace, "proxy-0")
+	asst.Nil(err, "test GetPod() failed")
+	asst.False(IsPodReady(pod), "test IsPodReady() failed")
+
+	addrs, err := conn.GetServiceEndpoints(context.Background(), testNamespace, "mysql")
+	asst.Nil(err, "test GetServiceEndpoints() failed")
+	asst.Equal([]string{"10.0.0.1:3306"}, addrs, "test GetServiceEndpoints() failed")
+}
+
+func TestConn_ScaleDeployment(t *testing.T) {
+	asst := assert.New(t)
+
+	conn := newTestConn()
+	err := conn.ScaleDeployment(context.Background(), testNamespace, "proxy", 5)
+	asst.Nil(err, "test ScaleDeployment() failed")
+	deployment, err := conn.GetDeployment(context.Background(), testNamespace, "proxy")
+	asst.Nil(err, "test ScaleDeployment() failed")
+	asst.Equal(int32(5), *deployment.Spec.Replicas, "test ScaleDeployment() failed")
+
+	done, _, err := DeploymentRolloutStatus(deployment)
+	asst.Nil(err, "test DeploymentRolloutStatus() failed")
+	asst.False(done, "test DeploymentRolloutStatus() failed")
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 5, UpdatedReplicas: 5, AvailableReplicas: 5}
+	done, _, err = DeploymentRolloutStatus(deployment)
+	asst.Nil(err, "test DeploymentRolloutStatus() failed")
+	asst.True(done, "test DeploymentRolloutStatus() failed")
+}
+
+func TestWatchWithReconnect(t *testing.T) {
+	asst := assert.New(t)
+
+	var (
+		watchers []*watch.FakeWatcher
+		versions []string
+	)
+	watchFunc := func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+		versions = append(versions, opts.ResourceVersion)
+		w := watch.NewFake()
+		watchers = append(watchers, w)
+		n := len(watchers)
+		go func() {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mysql-0", ResourceVersion: "10"}}
+			if n == 1 {
+				w.Add(pod)
+				// close the watch to trigger reconnecting
+				w.Stop()
+				return
+			}
+			w.Modify(pod)
+		}()
+
+		return w, nil
+	}
+
+	var events []watch.EventType
+	stopErr := errors.New("stop")
+	err := WatchWithReconnect(context.Background(), watchFunc, metav1.ListOptions{}, func(event watch.Event) error {
+		events = append(events, event.Type)
+		if event.Type == watch.Modified {
+			return stopErr
+		}
+
+		return nil
+	}, time.Millisecond)
+	asst.NotNil(err, "test WatchWithReconnect() failed")
+	asst.Equal([]watch.EventType{watch.Added, watch.Modified}, events, "test WatchWithReconnect() failed")
+	asst.Equal([]string{"", "10"}, versions, "test WatchWithReconnect() failed")
+}
@@ -0,0 +1,103 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/romberli/go-util/constant"
+)
+
+type LogsOptions struct {
+	// Container is the container name, it could be empty if the pod has only one container
+	Container string
+	Follow    bool
+	Previous  bool
+	// TailLines is the number of lines from the end of the logs, 0 or negative means all
+	TailLines int64
+}
+
+// podLogOptions converts the options to *corev1.PodLogOptions
+func (lo *LogsOptions) podLogOptions() *corev1.PodLogOptions {
+	opts := &corev1.PodLogOptions{
+		Container: lo.Container,
+		Follow:    lo.Follow,
+		Previous:  lo.Previous,
+	}
+	if lo.TailLines > constant.ZeroInt {
+		tailLines := lo.TailLines
+		opts.TailLines = &tailLines
+	}
+
+	return opts
+}
+
+// ListPods lists the pods in the namespace which match the label selector, for example: app=mysql,tier!=proxy
+func (conn *Conn) ListPods(ctx context.Context, namespace, labelSelector string) ([]corev1.Pod, error) {
+	list, err := conn.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// GetPod gets the pod by name
+func (conn *Conn) GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+	return conn.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// DeletePod deletes the pod by name
+func (conn *Conn) DeletePod(ctx context.Context, namespace, name string) error {
+	return conn.Clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// StreamPodLogs returns the log stream of the pod, caller must close the stream,
+// if options.Follow is true, the stream will be kept open until the context is done or the container stops
+func (conn *Conn) StreamPodLogs(ctx context.Context, namespace, name string, options *LogsOptions) (io.ReadCloser, error) {
+	if options == nil {
+		options = &LogsOptions{}
+	}
+
+	return conn.Clientset.CoreV1().Pods(namespace).GetLogs(name, options.podLogOptions()).Stream(ctx)
+}
+
+// GetPodLogs returns the logs of the pod, options.Follow will be ignored
+func (conn *Conn) GetPodLogs(ctx context.Context, namespace, name string, options *LogsOptions) (string, error) {
+	opts := LogsOptions{}
+	if options != nil {
+		opts = *options
+	}
+	opts.Follow = false
+
+	stream, err := conn.StreamPodLogs(ctx, namespace, name, &opts)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	defer func() { _ = stream.Close() }()
+
+	buf := &bytes.Buffer{}
+	_, err = io.Copy(buf, stream)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return buf.String(), nil
+}
+
+// IsPodReady returns if the pod is running and all the containers are ready
+func IsPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
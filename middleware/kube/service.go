@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListServices lists the services in the namespace which match the label selector
+func (conn *Conn) ListServices(ctx context.Context, namespace, labelSelector string) ([]corev1.Service, error) {
+	list, err := conn.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// GetService gets the service by name
+func (conn *Conn) GetService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	return conn.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetServiceEndpoints returns the addresses of the ready endpoints of the service in the form of ip:port
+func (conn *Conn) GetServiceEndpoints(ctx context.Context, namespace, name string) ([]string, error) {
+	endpoints, err := conn.Clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			for _, port := range subset.Ports {
+				addrs = append(addrs, net.JoinHostPort(address.IP, strconv.Itoa(int(port.Port))))
+			}
+		}
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,121 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romberli/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultWatchBackoff = time.Second
+
+// WatchFunc starts a watch with given options, for example: conn.Clientset.CoreV1().Pods(namespace).Watch
+type WatchFunc func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+
+// EventHandler handles the watch event, if it returns an error, watching will be stopped and the error will be returned
+type EventHandler func(event watch.Event) error
+
+// WatchWithReconnect watches the resources and calls the handler with every event,
+// when the watch is closed by the api server, it reconnects from the last seen resource version,
+// if the resource version is too old, it restarts from the current state.
+// it blocks until the context is done or the handler returns an error
+func WatchWithReconnect(ctx context.Context, watchFunc WatchFunc, opts metav1.ListOptions, handler EventHandler, backoff time.Duration) error {
+	if backoff <= constant.ZeroInt {
+		backoff = DefaultWatchBackoff
+	}
+	opts.AllowWatchBookmarks = true
+
+	for {
+		w, err := watchFunc(ctx, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Debugf("start watching failed, will retry. resource version: %s. %s", opts.ResourceVersion, err.Error())
+		} else {
+			opts.ResourceVersion, err = consume(ctx, w, opts.ResourceVersion, handler)
+			if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// consume reads the events until the watch is closed or the context is done, it returns the last seen resource version
+func consume(ctx context.Context, w watch.Interface, resourceVersion string, handler EventHandler) (string, error) {
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+
+			switch event.Type {
+			case watch.Error:
+				status := apierrors.FromObject(event.Object)
+				if apierrors.IsResourceExpired(status) || apierrors.IsGone(status) {
+					// the resource version is too old, restart from the current state
+					return constant.EmptyString, nil
+				}
+				log.Debugf("watch returned an error, will reconnect. %s", status.Error())
+
+				return resourceVersion, nil
+			case watch.Bookmark:
+				resourceVersion = objectResourceVersion(event, resourceVersion)
+				continue
+			}
+
+			resourceVersion = objectResourceVersion(event, resourceVersion)
+			err := handler(event)
+			if err != nil {
+				return resourceVersion, errors.New(fmt.Sprintf("handle watch event failed. type: %s. %s", event.Type, err.Error()))
+			}
+		}
+	}
+}
+
+// objectResourceVersion returns the resource version of the event object, if it could not be got, the default version will be returned
+func objectResourceVersion(event watch.Event, defaultVersion string) string {
+	accessor, err := meta.Accessor(event.Object)
+	if err != nil || accessor.GetResourceVersion() == constant.EmptyString {
+		return defaultVersion
+	}
+
+	return accessor.GetResourceVersion()
+}
+
+// WatchPods watches the pods in the namespace which match the label selector with reconnecting
+func (conn *Conn) WatchPods(ctx context.Context, namespace, labelSelector string, handler EventHandler) error {
+	return WatchWithReconnect(ctx, conn.Clientset.CoreV1().Pods(namespace).Watch,
+		metav1.ListOptions{LabelSelector: labelSelector}, handler, DefaultWatchBackoff)
+}
+
+// WatchDeployments watches the deployments in the namespace which match the label selector with reconnecting
+func (conn *Conn) WatchDeployments(ctx context.Context, namespace, labelSelector string, handler EventHandler) error {
+	return WatchWithReconnect(ctx, conn.Clientset.AppsV1().Deployments(namespace).Watch,
+		metav1.ListOptions{LabelSelector: labelSelector}, handler, DefaultWatchBackoff)
+}
+
+// WatchStatefulSets watches the statefulsets in the namespace which match the label selector with reconnecting
+func (conn *Conn) WatchStatefulSets(ctx context.Context, namespace, labelSelector string, handler EventHandler) error {
+	return WatchWithReconnect(ctx, conn.Clientset.AppsV1().StatefulSets(namespace).Watch,
+		metav1.ListOptions{LabelSelector: labelSelector}, handler, DefaultWatchBackoff)
+}
@@ -0,0 +1,195 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultRolloutCheckInterval = 2 * time.Second
+
+// ListDeployments lists the deployments in the namespace which match the label selector
+func (conn *Conn) ListDeployments(ctx context.Context, namespace, labelSelector string) ([]appsv1.Deployment, error) {
+	list, err := conn.Clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// GetDeployment gets the deployment by name
+func (conn *Conn) GetDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	return conn.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ScaleDeployment sets the replicas of the deployment, it retries when the deployment was modified concurrently
+func (conn *Conn) ScaleDeployment(ctx context.Context, namespace, name string, replicas int32) error {
+	client := conn.Clientset.AppsV1().Deployments(namespace)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deployment, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deployment.Spec.Replicas = &replicas
+		_, err = client.Update(ctx, deployment, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ListStatefulSets lists the statefulsets in the namespace which match the label selector
+func (conn *Conn) ListStatefulSets(ctx context.Context, namespace, labelSelector string) ([]appsv1.StatefulSet, error) {
+	list, err := conn.Clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// GetStatefulSet gets the statefulset by name
+func (conn *Conn) GetStatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error) {
+	return conn.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ScaleStatefulSet sets the replicas of the statefulset, it retries when the statefulset was modified concurrently
+func (conn *Conn) ScaleStatefulSet(ctx context.Context, namespace, name string, replicas int32) error {
+	client := conn.Clientset.AppsV1().StatefulSets(namespace)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		statefulSet, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		statefulSet.Spec.Replicas = &replicas
+		_, err = client.Update(ctx, statefulSet, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// DeploymentRolloutStatus returns if the rollout of the deployment is done and a message describing the status,
+// it follows the logic of kubectl rollout status
+func DeploymentRolloutStatus(deployment *appsv1.Deployment) (bool, string, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false, "waiting for deployment spec update to be observed", nil
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return false, constant.EmptyString, errors.New(fmt.Sprintf("deployment %s exceeded its progress deadline", deployment.Name))
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas:
+		return false, fmt.Sprintf("%d out of %d new replicas have been updated", status.UpdatedReplicas, replicas), nil
+	case status.Replicas > status.UpdatedReplicas:
+		return false, fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas), nil
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return false, fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), nil
+	}
+
+	return true, fmt.Sprintf("deployment %s successfully rolled out", deployment.Name), nil
+}
+
+// StatefulSetRolloutStatus returns if the rollout of the statefulset is done and a message describing the status,
+// it follows the logic of kubectl rollout status
+func StatefulSetRolloutStatus(statefulSet *appsv1.StatefulSet) (bool, string, error) {
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return true, constant.EmptyString, errors.New("rollout status is only available for statefulsets with RollingUpdate strategy")
+	}
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return false, "waiting for statefulset spec update to be observed", nil
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	if status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d pods are ready", status.ReadyReplicas, replicas), nil
+	}
+
+	partition := int32(constant.ZeroInt)
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		partition = *rollingUpdate.Partition
+	}
+	if partition > constant.ZeroInt {
+		if status.UpdatedReplicas < replicas-partition {
+			return false, fmt.Sprintf("%d of %d updated pods are ready", status.UpdatedReplicas, replicas-partition), nil
+		}
+
+		return true, fmt.Sprintf("partitioned roll out complete: %d new pods have been updated", status.UpdatedReplicas), nil
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return false, fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s",
+			status.UpdatedReplicas, status.UpdateRevision), nil
+	}
+
+	return true, fmt.Sprintf("statefulset rolling update complete %d pods at revision %s", status.CurrentReplicas, status.CurrentRevision), nil
+}
+
+// WaitForDeploymentRollout blocks until the rollout of the deployment is done or the context is done
+func (conn *Conn) WaitForDeploymentRollout(ctx context.Context, namespace, name string, interval time.Duration) error {
+	return waitForRollout(ctx, interval, func() (bool, string, error) {
+		deployment, err := conn.GetDeployment(ctx, namespace, name)
+		if err != nil {
+			return false, constant.EmptyString, err
+		}
+
+		return DeploymentRolloutStatus(deployment)
+	})
+}
+
+// WaitForStatefulSetRollout blocks until the rollout of the statefulset is done or the context is done
+func (conn *Conn) WaitForStatefulSetRollout(ctx context.Context, namespace, name string, interval time.Duration) error {
+	return waitForRollout(ctx, interval, func() (bool, string, error) {
+		statefulSet, err := conn.GetStatefulSet(ctx, namespace, name)
+		if err != nil {
+			return false, constant.EmptyString, err
+		}
+
+		return StatefulSetRolloutStatus(statefulSet)
+	})
+}
+
+// waitForRollout checks the rollout status periodically until it is done, fails or the context is done
+func waitForRollout(ctx context.Context, interval time.Duration, statusFunc func() (bool, string, error)) error {
+	if interval <= constant.ZeroInt {
+		interval = DefaultRolloutCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, msg, err := statusFunc()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New(fmt.Sprintf("waiting for rollout failed. last status: %s. %s", msg, ctx.Err().Error()))
+		case <-ticker.C:
+		}
+	}
+}
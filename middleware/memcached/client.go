@@ -0,0 +1,246 @@
+package memcached
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultTimeout      = 100 * time.Millisecond
+	DefaultMaxIdleConns = 2
+	DefaultBatchSize    = 100
+	// MaxRelativeExpiry is the maximum expiry which memcached treats as relative seconds,
+	// larger value will be treated as an absolute unix timestamp
+	MaxRelativeExpiry = 30 * 24 * time.Hour
+)
+
+// ErrCacheMiss means that a Get failed because the item wasn't present
+var ErrCacheMiss = memcache.ErrCacheMiss
+
+type Config struct {
+	// Addrs are the initial servers of the connection, use Conn.GetAddrs() to get the current servers
+	Addrs        []string
+	Timeout      time.Duration
+	MaxIdleConns int
+	VirtualNodes int
+	BatchSize    int
+}
+
+// NewConfig returns a new Config
+func NewConfig(timeout time.Duration, maxIdleConns, virtualNodes, batchSize int, addrs ...string) Config {
+	return Config{
+		Addrs:        addrs,
+		Timeout:      timeout,
+		MaxIdleConns: maxIdleConns,
+		VirtualNodes: virtualNodes,
+		BatchSize:    batchSize,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with default values
+func NewConfigWithDefault(addrs ...string) Config {
+	return NewConfig(DefaultTimeout, DefaultMaxIdleConns, DefaultVirtualNodes, DefaultBatchSize, addrs...)
+}
+
+type Conn struct {
+	Config
+	Selector *ConsistentHashSelector
+	Client   *memcache.Client
+	Metrics  *Metrics
+}
+
+// NewConn returns a new *Conn with default config
+func NewConn(addrs ...string) (*Conn, error) {
+	return NewConnWithConfig(NewConfigWithDefault(addrs...))
+}
+
+// NewConnWithConfig returns a new *Conn with given config
+func NewConnWithConfig(config Config) (*Conn, error) {
+	if len(config.Addrs) == constant.ZeroInt {
+		return nil, errors.New("memcached addresses should not be empty")
+	}
+	if config.BatchSize <= constant.ZeroInt {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	selector, err := NewConsistentHashSelector(config.VirtualNodes, config.Addrs...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := memcache.NewFromSelector(selector)
+	client.Timeout = config.Timeout
+	client.MaxIdleConns = config.MaxIdleConns
+
+	return &Conn{
+		Config:   config,
+		Selector: selector,
+		Client:   client,
+	}, nil
+}
+
+// SetMetrics sets the metrics of the connection, set nil to disable metrics
+func (conn *Conn) SetMetrics(metrics *Metrics) {
+	conn.Metrics = metrics
+}
+
+// SetServers changes the servers of the connection, only a small part of keys will be remapped,
+// it is safe to be called concurrently with the other methods
+func (conn *Conn) SetServers(addrs ...string) error {
+	return conn.Selector.SetServers(addrs...)
+}
+
+// GetAddrs returns the current servers of the connection
+func (conn *Conn) GetAddrs() []string {
+	return conn.Selector.Servers()
+}
+
+// CheckInstanceStatus checks if all the servers are available
+func (conn *Conn) CheckInstanceStatus() bool {
+	return conn.Client.Ping() == nil
+}
+
+// Get returns the value of given key, if the key does not exist, it returns ErrCacheMiss
+func (conn *Conn) Get(key string) ([]byte, error) {
+	start := time.Now()
+	item, err := conn.Client.Get(key)
+	conn.observe(opGet, start, 1, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return item.Value, nil
+}
+
+// GetString returns the string value of given key, if the key does not exist, it returns ErrCacheMiss
+func (conn *Conn) GetString(key string) (string, error) {
+	value, err := conn.Get(key)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return string(value), nil
+}
+
+// GetMulti returns the values of given keys, keys not existing will not be in the returned map,
+// keys will be split into batches by batch size to avoid too large requests
+func (conn *Conn) GetMulti(keys ...string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+
+	for start := 0; start < len(keys); start += conn.BatchSize {
+		end := start + conn.BatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		begin := time.Now()
+		items, err := conn.Client.GetMulti(keys[start:end])
+		conn.observe(opGetMulti, begin, end-start, err)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, item := range items {
+			result[key] = item.Value
+		}
+		conn.observeMisses(end - start - len(items))
+	}
+
+	return result, nil
+}
+
+// Set sets the value of given key with expiry, zero expiry means no expiration
+func (conn *Conn) Set(key string, value []byte, expiry time.Duration) error {
+	start := time.Now()
+	err := conn.Client.Set(&memcache.Item{
+		Key:        key,
+		Value:      value,
+		Expiration: convertExpiry(expiry),
+	})
+	conn.observe(opSet, start, 1, err)
+
+	return err
+}
+
+// SetString sets the string value of given key with expiry, zero expiry means no expiration
+func (conn *Conn) SetString(key, value string, expiry time.Duration) error {
+	return conn.Set(key, []byte(value), expiry)
+}
+
+// Add sets the value of given key only if the key does not exist, otherwise, it returns memcache.ErrNotStored
+func (conn *Conn) Add(key string, value []byte, expiry time.Duration) error {
+	start := time.Now()
+	err := conn.Client.Add(&memcache.Item{
+		Key:        key,
+		Value:      value,
+		Expiration: convertExpiry(expiry),
+	})
+	conn.observe(opAdd, start, 1, err)
+
+	return err
+}
+
+// Touch updates the expiry of given key
+func (conn *Conn) Touch(key string, expiry time.Duration) error {
+	start := time.Now()
+	err := conn.Client.Touch(key, convertExpiry(expiry))
+	conn.observe(opTouch, start, 1, err)
+
+	return err
+}
+
+// Delete deletes given key, if the key does not exist, it returns ErrCacheMiss
+func (conn *Conn) Delete(key string) error {
+	start := time.Now()
+	err := conn.Client.Delete(key)
+	conn.observe(opDelete, start, 1, err)
+
+	return err
+}
+
+// Increment increases the value of given key by delta, the value must be a decimal number
+func (conn *Conn) Increment(key string, delta uint64) (uint64, error) {
+	start := time.Now()
+	value, err := conn.Client.Increment(key, delta)
+	conn.observe(opIncrement, start, 1, err)
+
+	return value, err
+}
+
+// observe records the metrics of an operation if metrics is enabled
+func (conn *Conn) observe(op string, start time.Time, keys int, err error) {
+	if conn.Metrics == nil {
+		return
+	}
+
+	conn.Metrics.observe(op, start, keys, err)
+}
+
+// observeMisses records the cache misses if metrics is enabled
+func (conn *Conn) observeMisses(misses int) {
+	if conn.Metrics == nil || misses <= constant.ZeroInt {
+		return
+	}
+
+	conn.Metrics.MissCounter.Add(float64(misses))
+}
+
+// convertExpiry converts expiry to memcached expiration,
+// expiry which is longer than 30 days will be converted to an absolute unix timestamp
+func convertExpiry(expiry time.Duration) int32 {
+	if expiry <= constant.ZeroInt {
+		return constant.ZeroInt
+	}
+	if expiry > MaxRelativeExpiry {
+		return int32(time.Now().Add(expiry).Unix())
+	}
+	if expiry < time.Second {
+		return 1
+	}
+
+	return int32(expiry / time.Second)
+}
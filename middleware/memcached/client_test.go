@@ -0,0 +1,65 @@
+package memcached
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultAddrs = []string{"192.168.10.219:11211", "192.168.10.220:11211"}
+
+func TestConn(t *testing.T) {
+	asst := assert.New(t)
+
+	conn, err := NewConn(defaultAddrs...)
+	asst.Nil(err, "create connection failed")
+	conn.SetMetrics(NewMetrics(""))
+
+	err = conn.SetString("key1", "value1", time.Minute)
+	asst.Nil(err, "test Set() failed")
+	err = conn.Set("key2", []byte("value2"), time.Minute)
+	asst.Nil(err, "test Set() failed")
+
+	value, err := conn.GetString("key1")
+	asst.Nil(err, "test Get() failed")
+	asst.Equal("value1", value, "test Get() failed")
+
+	values, err := conn.GetMulti("key1", "key2", "key3")
+	asst.Nil(err, "test GetMulti() failed")
+	asst.Equal(2, len(values), "test GetMulti() failed")
+
+	err = conn.Delete("key1")
+	asst.Nil(err, "test Delete() failed")
+	_, err = conn.Get("key1")
+	asst.Equal(ErrCacheMiss, err, "test Delete() failed")
+}
+
+func TestConn_SetServers(t *testing.T) {
+	asst := assert.New(t)
+
+	conn, err := NewConn(defaultAddrs...)
+	asst.Nil(err, "create connection failed")
+
+	// run with -race to check if the servers are changed safely
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			asst.Nil(conn.SetServers(defaultAddrs[:1]...), "test SetServers() failed")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = conn.GetAddrs()
+			_, _ = conn.Selector.PickServer("key")
+		}
+	}()
+	wg.Wait()
+
+	asst.Equal(defaultAddrs[:1], conn.GetAddrs(), "test SetServers() failed")
+	asst.Equal(defaultAddrs, conn.Addrs, "test SetServers() failed")
+}
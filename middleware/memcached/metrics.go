@@ -0,0 +1,86 @@
+package memcached
+
+import (
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	opGet       = "get"
+	opGetMulti  = "get_multi"
+	opSet       = "set"
+	opAdd       = "add"
+	opTouch     = "touch"
+	opDelete    = "delete"
+	opIncrement = "increment"
+
+	defaultMetricsNamespace = "memcached_client"
+)
+
+type Metrics struct {
+	RequestCounter   *prometheus.CounterVec
+	ErrorCounter     *prometheus.CounterVec
+	MissCounter      prometheus.Counter
+	LatencyHistogram *prometheus.HistogramVec
+}
+
+// NewMetrics returns a new *Metrics, if namespace is empty, default namespace will be used
+func NewMetrics(namespace string) *Metrics {
+	if namespace == constant.EmptyString {
+		namespace = defaultMetricsNamespace
+	}
+
+	return &Metrics{
+		RequestCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "total number of memcached operations",
+		}, []string{"operation"}),
+		ErrorCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "total number of failed memcached operations, cache misses are not included",
+		}, []string{"operation"}),
+		MissCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "misses_total",
+			Help:      "total number of cache misses",
+		}),
+		LatencyHistogram: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "latency of memcached operations",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+	}
+}
+
+// Register registers the collectors to given registerer
+func (m *Metrics) Register(registerer prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.RequestCounter, m.ErrorCounter, m.MissCounter, m.LatencyHistogram} {
+		err := registerer.Register(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// observe records the result of an operation
+func (m *Metrics) observe(op string, start time.Time, keys int, err error) {
+	m.RequestCounter.WithLabelValues(op).Inc()
+	m.LatencyHistogram.WithLabelValues(op).Observe(time.Since(start).Seconds())
+
+	switch err {
+	case nil:
+	case memcache.ErrCacheMiss:
+		m.MissCounter.Add(float64(keys))
+	default:
+		m.ErrorCounter.WithLabelValues(op).Inc()
+	}
+}
@@ -0,0 +1,135 @@
+package memcached
+
+import (
+	"errors"
+	"hash/crc32"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultVirtualNodes = 160
+
+var _ memcache.ServerSelector = (*ConsistentHashSelector)(nil)
+
+// ConsistentHashSelector picks server with consistent hashing,
+// therefore only a small part of keys will be remapped when adding or removing servers
+type ConsistentHashSelector struct {
+	sync.RWMutex
+	virtualNodes int
+	hashes       []uint32
+	nodes        map[uint32]net.Addr
+	addrs        []net.Addr
+	servers      []string
+}
+
+// NewConsistentHashSelector returns a new *ConsistentHashSelector,
+// if virtualNodes is not larger than 0, DefaultVirtualNodes will be used
+func NewConsistentHashSelector(virtualNodes int, servers ...string) (*ConsistentHashSelector, error) {
+	if virtualNodes <= constant.ZeroInt {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	chs := &ConsistentHashSelector{virtualNodes: virtualNodes}
+	err := chs.SetServers(servers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return chs, nil
+}
+
+// SetServers changes the servers of the selector, the server could be a tcp address(host:port) or a unix socket path
+func (chs *ConsistentHashSelector) SetServers(servers ...string) error {
+	addrs := make([]net.Addr, len(servers))
+	nodes := make(map[uint32]net.Addr, len(servers)*chs.virtualNodes)
+	hashes := make([]uint32, constant.ZeroInt, len(servers)*chs.virtualNodes)
+
+	for i, server := range servers {
+		addr, err := resolveAddr(server)
+		if err != nil {
+			return err
+		}
+		addrs[i] = addr
+
+		for j := 0; j < chs.virtualNodes; j++ {
+			hash := crc32.ChecksumIEEE([]byte(server + constant.DashString + strconv.Itoa(j)))
+			if _, ok := nodes[hash]; ok {
+				continue
+			}
+			nodes[hash] = addr
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+
+	chs.Lock()
+	defer chs.Unlock()
+
+	chs.servers = append([]string(nil), servers...)
+	chs.addrs = addrs
+	chs.nodes = nodes
+	chs.hashes = hashes
+
+	return nil
+}
+
+// Servers returns the current servers of the selector
+func (chs *ConsistentHashSelector) Servers() []string {
+	chs.RLock()
+	defer chs.RUnlock()
+
+	return append([]string(nil), chs.servers...)
+}
+
+// PickServer returns the server address that given key should be stored on
+func (chs *ConsistentHashSelector) PickServer(key string) (net.Addr, error) {
+	chs.RLock()
+	defer chs.RUnlock()
+
+	if len(chs.hashes) == constant.ZeroInt {
+		return nil, memcache.ErrNoServers
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+	index := sort.Search(len(chs.hashes), func(i int) bool { return chs.hashes[i] >= hash })
+	if index == len(chs.hashes) {
+		index = constant.ZeroInt
+	}
+
+	return chs.nodes[chs.hashes[index]], nil
+}
+
+// Each iterates over each server calling the given function
+func (chs *ConsistentHashSelector) Each(f func(net.Addr) error) error {
+	chs.RLock()
+	defer chs.RUnlock()
+
+	for _, addr := range chs.addrs {
+		err := f(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveAddr resolves given server to net.Addr
+func resolveAddr(server string) (net.Addr, error) {
+	if server == constant.EmptyString {
+		return nil, errors.New("server address should not be empty")
+	}
+
+	if strings.Contains(server, constant.SlashString) {
+		return net.ResolveUnixAddr("unix", server)
+	}
+
+	return net.ResolveTCPAddr("tcp", server)
+}
@@ -0,0 +1,44 @@
+package memcached
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHashSelector_PickServer(t *testing.T) {
+	asst := assert.New(t)
+
+	servers := []string{"127.0.0.1:11211", "127.0.0.1:11212", "127.0.0.1:11213"}
+	chs, err := NewConsistentHashSelector(DefaultVirtualNodes, servers...)
+	asst.Nil(err, "test PickServer() failed")
+
+	keyNum := 1000
+	before := make(map[string]string, keyNum)
+	for i := 0; i < keyNum; i++ {
+		key := fmt.Sprintf("key%d", i)
+		addr, err := chs.PickServer(key)
+		asst.Nil(err, "test PickServer() failed")
+		before[key] = addr.String()
+	}
+
+	// adding a server should only remap a part of the keys
+	err = chs.SetServers(append(servers, "127.0.0.1:11214")...)
+	asst.Nil(err, "test PickServer() failed")
+	asst.Equal(append(servers, "127.0.0.1:11214"), chs.Servers(), "test Servers() failed")
+	moved := 0
+	for key, server := range before {
+		addr, err := chs.PickServer(key)
+		asst.Nil(err, "test PickServer() failed")
+		if addr.String() != server {
+			moved++
+		}
+	}
+	asst.True(moved < keyNum/2, "too many keys were remapped: %d", moved)
+
+	err = chs.SetServers()
+	asst.Nil(err, "test PickServer() failed")
+	_, err = chs.PickServer("key")
+	asst.NotNil(err, "test PickServer() failed")
+}
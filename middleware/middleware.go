@@ -58,6 +58,8 @@ type Pool interface {
 	IsClosed() bool
 	// Get gets a connection from the pool
 	Get() (PoolConn, error)
+	// GetContext gets a connection from the pool, it waits until a connection is available or the context is done
+	GetContext(ctx context.Context) (PoolConn, error)
 	// Transaction returns a connection that could run multiple statements in the same transaction
 	Transaction() (Transaction, error)
 	// Supply creates given number of connections and add them to the pool
@@ -0,0 +1,256 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	SelectMaxAllowedPacketSQL = "select @@max_allowed_packet"
+	// MaxPlaceholderNum is the maximum number of the placeholders of a prepared statement
+	MaxPlaceholderNum = 65535
+
+	insertSQL           = "insert into %s(%s) values "
+	insertIgnoreSQL     = "insert ignore into %s(%s) values "
+	onDuplicateKeySQL   = " on duplicate key update "
+	updateValuesSQL     = "%s = values(%s)"
+	placeholderString   = "?"
+	commaSpaceString    = ", "
+	defaultValueSize    = 8
+	stringValueOverhead = 9
+	timeValueSize       = 12
+	batchPacketReserved = 1024
+)
+
+type BatchOptions struct {
+	// Ignore uses insert ignore statement, the rows which conflict with the existing rows will be ignored
+	Ignore bool
+	// UpdateColumns are the columns which will be updated with the new values when the rows conflict with the existing rows,
+	// it uses insert ... on duplicate key update statement
+	UpdateColumns []string
+	// MaxPacketSize is the maximum size of each statement, if it is zero, @@max_allowed_packet of the server will be used
+	MaxPacketSize int
+}
+
+// NewBatchOptions returns a new *BatchOptions
+func NewBatchOptions(ignore bool, updateColumns []string, maxPacketSize int) *BatchOptions {
+	return &BatchOptions{
+		Ignore:        ignore,
+		UpdateColumns: updateColumns,
+		MaxPacketSize: maxPacketSize,
+	}
+}
+
+// BatchInsert inserts the rows into the table with multi-row insert statements,
+// the rows will be split into several statements by the max packet size and the maximum number of the placeholders,
+// each element of the rows is a row which values are in the same order as the columns,
+// it returns the total affected rows of the statements,
+// note that the statements are not atomic, use it in a transaction if needed
+func (conn *Conn) BatchInsert(table string, columns []string, rows [][]interface{}, opts *BatchOptions) (int, error) {
+	return conn.BatchInsertContext(context.Background(), table, columns, rows, opts)
+}
+
+// BatchInsertContext inserts the rows into the table with multi-row insert statements with context,
+// see BatchInsert() for more details
+func (conn *Conn) BatchInsertContext(ctx context.Context, table string, columns []string, rows [][]interface{}, opts *BatchOptions) (int, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+
+	maxPacketSize := opts.MaxPacketSize
+	if maxPacketSize <= constant.ZeroInt {
+		var err error
+		maxPacketSize, err = conn.getMaxAllowedPacket(ctx)
+		if err != nil {
+			return constant.ZeroInt, err
+		}
+	}
+
+	statements, err := buildBatchInsert(table, columns, rows, opts, maxPacketSize)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	var affectedRows int
+	for _, statement := range statements {
+		result, err := conn.executeContext(ctx, statement.sql, statement.args...)
+		if err != nil {
+			return affectedRows, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return affectedRows, err
+		}
+		affectedRows += affected
+	}
+
+	return affectedRows, nil
+}
+
+// BatchUpsert inserts the rows into the table, and updates the update columns of the rows which conflict with the existing rows,
+// if updateColumns is empty, all the columns will be updated, see BatchInsert() for more details
+func (conn *Conn) BatchUpsert(table string, columns []string, rows [][]interface{}, updateColumns []string) (int, error) {
+	if len(updateColumns) == constant.ZeroInt {
+		updateColumns = columns
+	}
+
+	return conn.BatchInsert(table, columns, rows, NewBatchOptions(false, updateColumns, constant.ZeroInt))
+}
+
+// getMaxAllowedPacket returns @@max_allowed_packet of the server
+func (conn *Conn) getMaxAllowedPacket(ctx context.Context) (int, error) {
+	result, err := conn.executeContext(ctx, SelectMaxAllowedPacketSQL)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return result.GetInt(constant.ZeroInt, constant.ZeroInt)
+}
+
+type batchStatement struct {
+	sql  string
+	args []interface{}
+}
+
+// buildBatchInsert builds the multi-row insert statements, each statement will not exceed the max packet size
+func buildBatchInsert(table string, columns []string, rows [][]interface{}, opts *BatchOptions, maxPacketSize int) ([]*batchStatement, error) {
+	if len(columns) == constant.ZeroInt {
+		return nil, errors.New("columns should not be empty")
+	}
+	if opts.Ignore && len(opts.UpdateColumns) > constant.ZeroInt {
+		return nil, errors.New("ignore and update columns could not be specified at the same time")
+	}
+	if len(columns) > MaxPlaceholderNum {
+		return nil, errors.New(fmt.Sprintf("number of columns(%d) exceeds the maximum number of the placeholders(%d)", len(columns), MaxPlaceholderNum))
+	}
+
+	prefix, suffix, err := buildBatchInsertClauses(table, columns, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rowPlaceholders := constant.LeftParenthesis + strings.TrimSuffix(strings.Repeat(placeholderString+commaSpaceString, len(columns)), commaSpaceString) + constant.RightParenthesis
+	limit := maxPacketSize - len(prefix) - len(suffix) - batchPacketReserved
+	maxRows := MaxPlaceholderNum / len(columns)
+
+	var (
+		statements []*batchStatement
+		values     []string
+		args       []interface{}
+		size       int
+	)
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, errors.New(fmt.Sprintf("number of values(%d) of row %d is not equal to number of columns(%d)", len(row), i, len(columns)))
+		}
+
+		rowSize := len(rowPlaceholders) + len(commaSpaceString)
+		for _, value := range row {
+			rowSize += valueSize(value)
+		}
+		if rowSize > limit {
+			return nil, errors.New(fmt.Sprintf("size of row %d(%d) exceeds the max packet size(%d)", i, rowSize, maxPacketSize))
+		}
+
+		if len(values) > constant.ZeroInt && (size+rowSize > limit || len(values) >= maxRows) {
+			statements = append(statements, &batchStatement{sql: prefix + strings.Join(values, commaSpaceString) + suffix, args: args})
+			values = nil
+			args = nil
+			size = constant.ZeroInt
+		}
+
+		values = append(values, rowPlaceholders)
+		args = append(args, row...)
+		size += rowSize
+	}
+	if len(values) > constant.ZeroInt {
+		statements = append(statements, &batchStatement{sql: prefix + strings.Join(values, commaSpaceString) + suffix, args: args})
+	}
+
+	return statements, nil
+}
+
+// buildBatchInsertClauses returns the insert clause and the on duplicate key update clause
+func buildBatchInsertClauses(table string, columns []string, opts *BatchOptions) (string, string, error) {
+	quotedTable, err := quoteTableName(table)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, err
+	}
+	quotedColumns, err := quoteIdentifiers(columns)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, err
+	}
+
+	prefix := fmt.Sprintf(insertSQL, quotedTable, strings.Join(quotedColumns, commaSpaceString))
+	if opts.Ignore {
+		prefix = fmt.Sprintf(insertIgnoreSQL, quotedTable, strings.Join(quotedColumns, commaSpaceString))
+	}
+	if len(opts.UpdateColumns) == constant.ZeroInt {
+		return prefix, constant.EmptyString, nil
+	}
+
+	quotedUpdateColumns, err := quoteIdentifiers(opts.UpdateColumns)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, err
+	}
+	updates := make([]string, len(quotedUpdateColumns))
+	for i, column := range quotedUpdateColumns {
+		updates[i] = fmt.Sprintf(updateValuesSQL, column, column)
+	}
+
+	return prefix, onDuplicateKeySQL + strings.Join(updates, commaSpaceString), nil
+}
+
+// valueSize returns the estimated size of the value in the packet
+func valueSize(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return constant.ZeroInt
+	case string:
+		return len(v) + stringValueOverhead
+	case []byte:
+		return len(v) + stringValueOverhead
+	case time.Time:
+		return timeValueSize
+	default:
+		return defaultValueSize
+	}
+}
+
+// quoteTableName quotes the table name with backticks, the table name could be in db.table format
+func quoteTableName(table string) (string, error) {
+	parts := strings.Split(table, constant.DotString)
+	if len(parts) > 2 {
+		return constant.EmptyString, errors.New(fmt.Sprintf("table name must be in table or db.table format. table: %s", table))
+	}
+
+	quoted, err := quoteIdentifiers(parts)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return strings.Join(quoted, constant.DotString), nil
+}
+
+// quoteIdentifiers quotes each identifier with backticks
+func quoteIdentifiers(names []string) ([]string, error) {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		if name == constant.EmptyString {
+			return nil, errors.New("identifier should not be empty")
+		}
+		quoted[i] = quoteIdentifier(name)
+	}
+
+	return quoted, nil
+}
+
+// quoteIdentifier quotes the identifier with backticks, the backticks in the identifier will be escaped
+func quoteIdentifier(name string) string {
+	return backtickString + strings.ReplaceAll(name, backtickString, escapedBacktickString) + backtickString
+}
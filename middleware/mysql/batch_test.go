@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBatchInsert(t *testing.T) {
+	asst := assert.New(t)
+
+	columns := []string{"id", "name"}
+	rows := [][]interface{}{{1, "a"}, {2, "b"}, {3, "c"}}
+
+	statements, err := buildBatchInsert("test.t01", columns, rows, &BatchOptions{}, 1<<20)
+	asst.Nil(err, "test buildBatchInsert() failed")
+	asst.Equal(1, len(statements), "test buildBatchInsert() failed")
+	asst.Equal("insert into `test`.`t01`(`id`, `name`) values (?, ?), (?, ?), (?, ?)", statements[0].sql, "test buildBatchInsert() failed")
+	asst.Equal([]interface{}{1, "a", 2, "b", 3, "c"}, statements[0].args, "test buildBatchInsert() failed")
+
+	statements, err = buildBatchInsert("t01", columns, rows, NewBatchOptions(false, []string{"name"}, 0), 1<<20)
+	asst.Nil(err, "test buildBatchInsert() failed")
+	asst.Equal("insert into `t01`(`id`, `name`) values (?, ?), (?, ?), (?, ?) on duplicate key update `name` = values(`name`)", statements[0].sql, "test buildBatchInsert() failed")
+
+	statements, err = buildBatchInsert("t01", columns, rows, NewBatchOptions(true, nil, 0), 1<<20)
+	asst.Nil(err, "test buildBatchInsert() failed")
+	asst.Equal("insert ignore into `t01`(`id`, `name`) values (?, ?), (?, ?), (?, ?)", statements[0].sql, "test buildBatchInsert() failed")
+
+	// each statement could only hold two rows
+	rowSize := len("(?, ?)") + len(commaSpaceString) + defaultValueSize + 1 + stringValueOverhead
+	maxPacketSize := len("insert into `t01`(`id`, `name`) values ") + batchPacketReserved + 2*rowSize
+	statements, err = buildBatchInsert("t01", columns, rows, &BatchOptions{}, maxPacketSize)
+	asst.Nil(err, "test buildBatchInsert() failed")
+	asst.Equal(2, len(statements), "test buildBatchInsert() failed")
+	asst.Equal([]interface{}{3, "c"}, statements[1].args, "test buildBatchInsert() failed")
+
+	_, err = buildBatchInsert("t01", columns, [][]interface{}{{1}}, &BatchOptions{}, 1<<20)
+	asst.NotNil(err, "test buildBatchInsert() failed")
+	_, err = buildBatchInsert("t01", columns, rows, NewBatchOptions(true, []string{"name"}, 0), 1<<20)
+	asst.NotNil(err, "test buildBatchInsert() failed")
+	_, err = buildBatchInsert("a.b.c", columns, rows, &BatchOptions{}, 1<<20)
+	asst.NotNil(err, "test buildBatchInsert() failed")
+	_, err = buildBatchInsert("t01", columns, rows, &BatchOptions{}, batchPacketReserved)
+	asst.NotNil(err, "test buildBatchInsert() failed")
+}
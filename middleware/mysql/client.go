@@ -2,12 +2,22 @@ package mysql
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"time"
 
 	"github.com/go-mysql-org/go-mysql/client"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/romberli/log"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+	"github.com/romberli/go-util/metrics"
+	"github.com/romberli/go-util/tracing"
 )
 
 type ReplicationRole string
@@ -20,6 +30,11 @@ const (
 	ShowSlaveStatusSQL   = "show slave status"
 	ShowReplicaStatusSQL = "show replica status"
 	ShowSlaveHostsSQL    = "show slave hosts"
+	KillQuerySQL         = "kill query %d"
+
+	// DefaultKillQueryTimeout is the extra time after the deadline of the context,
+	// the network connection will be timed out if the query could not be killed within this time
+	DefaultKillQueryTimeout = 3 * time.Second
 
 	// ReplicationSource represents mysql master, it's an alternative name
 	ReplicationSource ReplicationRole = "source"
@@ -34,6 +49,10 @@ type Config struct {
 	DBName string
 	DBUser string
 	DBPass string
+	// TLSConfig is used to connect to mysql with tls, if it is nil, the connection will be insecure
+	TLSConfig *tls.Config
+	// Charset is the character set of the connection, if it is empty, DefaultCharSet will be used
+	Charset string
 }
 
 // NewConfig returns a new Config
@@ -46,29 +65,108 @@ func NewConfig(addr string, dbName string, dbUser string, dbPass string) Config
 	}
 }
 
+// NewConfigWithTLS returns a new Config with tls enabled, it is required when the server enables require_secure_transport,
+// as the rsa public key of the server is retrieved automatically, there is no need to specify it for caching_sha2_password
+func NewConfigWithTLS(addr string, dbName string, dbUser string, dbPass string, tlsConfig *tls.Config) Config {
+	config := NewConfig(addr, dbName, dbUser, dbPass)
+	config.TLSConfig = tlsConfig
+
+	return config
+}
+
+// NewTLSConfig returns a new *tls.Config with given ca file, cert file and key file,
+// caFile is used to verify the server certificate, if it is empty, the system root certificates will be used,
+// certFile and keyFile are optional, if both of them are specified, the client certificate will be sent to the server,
+// serverName is used to verify the hostname of the server certificate, it is useful when connecting with ip address,
+// insecureSkipVerify skips verifying the server certificate, it should only be used in testing
+func NewTLSConfig(caFile, certFile, keyFile, serverName string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caFile != constant.EmptyString {
+		caBytes, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New(fmt.Sprintf("could not append ca certificates to cert pool. ca file: %s", caFile))
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if certFile != constant.EmptyString && keyFile != constant.EmptyString {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// connect connects to mysql with the config, the user and password are used to log in, dbName may be empty
+func (c Config) connect(dbName string) (*client.Conn, error) {
+	pass, err := credential.DecryptIfNeeded(c.DBPass)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []func(*client.Conn)
+	if c.TLSConfig != nil {
+		options = append(options, func(conn *client.Conn) { conn.SetTLSConfig(c.TLSConfig) })
+	}
+
+	return client.Connect(c.Addr, c.DBUser, pass, dbName, options...)
+}
+
+// getCharset returns the character set of the connection
+func (c Config) getCharset() string {
+	if c.Charset == constant.EmptyString {
+		return DefaultCharSet
+	}
+
+	return c.Charset
+}
+
 type Conn struct {
 	Config
 	*client.Conn
+	// stmtCache caches the prepared statements of PrepareCached()
+	stmtCache *stmtCache
+	// hooks are invoked after each statement finishes
+	hooks []Hook
+	// poolHooks are the hooks of the pool which created the connection, they are invoked before the hooks of the connection,
+	// it is nil if the connection is not pooled
+	poolHooks *hookSet
 }
 
-// NewConn returns connection to mysql database, be aware that addr is host:port style, default charset is utf8mb4
+// NewConn returns connection to mysql database, be aware that addr is host:port style, default charset is utf8mb4,
+// dbPass could be encrypted by the credential package
 func NewConn(addr string, dbName string, dbUser string, dbPass string) (*Conn, error) {
-	config := NewConfig(addr, dbName, dbUser, dbPass)
+	return NewConnWithConfig(NewConfig(addr, dbName, dbUser, dbPass))
+}
 
+// NewConnWithConfig returns connection to mysql database with given config, see NewConn() for more details
+func NewConnWithConfig(config Config) (*Conn, error) {
 	// connect to mysql
-	conn, err := client.Connect(addr, dbUser, dbPass, dbName)
+	conn, err := config.connect(config.DBName)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyError(err)
 	}
 
 	// set connection charset
-	err = conn.SetCharset(DefaultCharSet)
+	err = conn.SetCharset(config.getCharset())
 	if err != nil {
 		return nil, err
 	}
 
 	// use db
-	err = conn.UseDB(dbName)
+	err = conn.UseDB(config.DBName)
 	if err != nil {
 		return nil, err
 	}
@@ -91,12 +189,21 @@ func (conn *Conn) PrepareContext(ctx context.Context, command string) (*Statemen
 
 // prepareContext prepares a statement with context and returns a *Statement
 func (conn *Conn) prepareContext(ctx context.Context, command string) (*Statement, error) {
+	stop, err := conn.watchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 	stmt, err := conn.Conn.Prepare(command)
+	err = conn.contextError(ctx, stop(), err)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewStatement(stmt), nil
+	statement := NewStatement(stmt)
+	statement.conn = conn
+	statement.command = command
+
+	return statement, nil
 }
 
 // Execute executes given sql and placeholders and returns a result
@@ -104,7 +211,10 @@ func (conn *Conn) Execute(command string, args ...interface{}) (*Result, error)
 	return conn.executeContext(context.Background(), command, args...)
 }
 
-// ExecuteContext executes given sql and placeholders with context and returns a result
+// ExecuteContext executes given sql and placeholders with context and returns a result,
+// if the context is done before the query finishes, the query will be killed by "kill query" with another connection,
+// and the error will be middleware.ErrTimeout or middleware.ErrCanceled, which could be checked with errors.Is(),
+// even if the killed query returns without error, such as select sleep()
 func (conn *Conn) ExecuteContext(ctx context.Context, command string, args ...interface{}) (*Result, error) {
 	return conn.executeContext(ctx, command, args...)
 }
@@ -116,12 +226,116 @@ func (conn *Conn) executeContext(ctx context.Context, command string, args ...in
 		return nil, err
 	}
 
+	span, _ := tracing.StartSpan(ctx, tracing.ComponentMySQL, tracing.OperationExecute, conn.Addr)
+	ext.DBType.Set(span, tracing.ComponentMySQL)
+	ext.DBInstance.Set(span, conn.DBName)
+	ext.DBStatement.Set(span, command)
+	start := time.Now()
+	stop, err := conn.watchContext(ctx)
+	if err != nil {
+		tracing.Finish(span, err)
+		return nil, err
+	}
 	result, err := conn.Conn.Execute(command, args...)
+	err = conn.contextError(ctx, stop(), err)
+	metrics.ObserveOperation(metrics.ComponentMySQL, metrics.OperationExecute, start, err)
+	tracing.Finish(span, err)
 	if err != nil {
+		conn.runHooks(ctx, command, args, start, constant.ZeroInt, err)
 		return nil, err
 	}
 
-	return NewResult(result), nil
+	r := NewResult(result)
+	conn.runHooks(ctx, command, args, start, resultRows(r), nil)
+
+	return r, nil
+}
+
+// watchContext propagates the context to the running command,
+// when the context is done, the running query will be killed with another connection,
+// if the context has a deadline, the network connection will also be timed out after the deadline and DefaultKillQueryTimeout
+// in case the query could not be killed, the returned function must be called after the command completes,
+// it returns the error of resetting the deadline of the network connection,
+// or the error of the context if the query had been killed
+func (conn *Conn) watchContext(ctx context.Context) (func() error, error) {
+	if ctx.Done() == nil {
+		return func() error { return nil }, nil
+	}
+	err := ctx.Err()
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		err = conn.Conn.SetDeadline(deadline.Add(DefaultKillQueryTimeout))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var killed bool
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			killed = true
+			killErr := conn.killQuery()
+			if killErr != nil {
+				log.Errorf("mysql: kill query of connection %d failed. %s", conn.GetConnectionID(), killErr.Error())
+			}
+		}
+	}()
+
+	return func() error {
+		close(done)
+		// wait for the killing, so that it will not affect the next command
+		<-finished
+		if hasDeadline {
+			err := conn.Conn.SetDeadline(time.Time{})
+			if err != nil {
+				return err
+			}
+		}
+		if killed {
+			// the killed query may return without error, for example, select sleep() returns 1,
+			// so the error of the context should be returned, otherwise, the caller would take the partial result as complete
+			return ClassifyError(ctx.Err())
+		}
+
+		return nil
+	}, nil
+}
+
+// contextError returns the error of the command, if the context is done while the command is running,
+// the error of the context will be returned instead of the error of the interrupted query,
+// if the query had been killed but returned without error, stopErr holds the error of the context
+func (conn *Conn) contextError(ctx context.Context, stopErr, err error) error {
+	if err == nil {
+		return stopErr
+	}
+	if ctx.Err() != nil {
+		return ClassifyError(ctx.Err())
+	}
+
+	return ClassifyError(err)
+}
+
+// killQuery kills the running query of the connection with a new connection
+func (conn *Conn) killQuery() error {
+	killer, err := conn.Config.connect(constant.EmptyString)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = killer.Close() }()
+
+	_, err = killer.Execute(fmt.Sprintf(KillQuerySQL, conn.GetConnectionID()))
+
+	return err
 }
 
 // GetVersion returns mysql version
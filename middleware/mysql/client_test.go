@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -130,3 +132,34 @@ func TestMySQLConnection(t *testing.T) {
 	err = dropTable()
 	asst.Nil(err, "execute drop sql failed")
 }
+
+func TestNewTLSConfig(t *testing.T) {
+	asst := assert.New(t)
+
+	tlsConfig, err := NewTLSConfig(constant.EmptyString, constant.EmptyString, constant.EmptyString, "mysql.test", true)
+	asst.Nil(err, "test NewTLSConfig() failed")
+	asst.Equal("mysql.test", tlsConfig.ServerName, "test NewTLSConfig() failed")
+	asst.True(tlsConfig.InsecureSkipVerify, "test NewTLSConfig() failed")
+	config := NewConfigWithTLS("127.0.0.1:3306", "test", "root", "root", tlsConfig)
+	asst.True(config.TLSConfig == tlsConfig, "test NewConfigWithTLS() failed")
+
+	_, err = NewTLSConfig("/not/exists/ca.pem", constant.EmptyString, constant.EmptyString, constant.EmptyString, false)
+	asst.NotNil(err, "test NewTLSConfig() failed")
+	_, err = NewTLSConfig(constant.EmptyString, "/not/exists/cert.pem", "/not/exists/key.pem", constant.EmptyString, false)
+	asst.NotNil(err, "test NewTLSConfig() failed")
+}
+
+func TestConn_ExecuteContextTimeout(t *testing.T) {
+	asst := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := conn.ExecuteContext(ctx, `select sleep(5);`)
+	asst.True(errors.Is(err, middleware.ErrTimeout), "test ExecuteContext() failed")
+	asst.True(time.Since(start) < 5*time.Second, "test ExecuteContext() failed")
+	// the query had been killed, the connection should still be usable
+	_, err = conn.Execute(`select 1;`)
+	asst.Nil(err, "test ExecuteContext() failed")
+}
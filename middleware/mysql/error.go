@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"errors"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+
+	"github.com/romberli/go-util/config"
+	"github.com/romberli/go-util/middleware"
+)
+
+// ErQueryTimeout is the error code of max_execution_time exceeded, it is not defined in the driver
+const ErQueryTimeout = 3024
+
+// ClassifyError maps the mysql error onto the classified middleware error,
+// it returns the original error if the error could not be classified
+func ClassifyError(err error) error {
+	return middleware.ClassifyError(err, classifyError)
+}
+
+// classifyError classifies the error with the mysql error code
+func classifyError(err error) *config.ErrMessage {
+	if errors.Is(err, mysql.ErrBadConn) {
+		// the connection had been lost, normally the server is down or the network is broken
+		return middleware.ErrUnavailable
+	}
+
+	var myErr *mysql.MyError
+	if !errors.As(err, &myErr) {
+		return nil
+	}
+
+	switch myErr.Code {
+	case mysql.ER_CON_COUNT_ERROR, mysql.ER_TOO_MANY_USER_CONNECTIONS:
+		return middleware.ErrTooManyConnections
+	case mysql.ER_ACCESS_DENIED_ERROR, mysql.ER_DBACCESS_DENIED_ERROR:
+		return middleware.ErrAuthFailed
+	case mysql.ER_OPTION_PREVENTS_STATEMENT, mysql.ER_READ_ONLY_MODE:
+		// the server is running with read_only or super_read_only, normally it is a replica
+		return middleware.ErrNotLeader
+	case mysql.ER_QUERY_INTERRUPTED, mysql.ER_LOCK_WAIT_TIMEOUT, ErQueryTimeout:
+		return middleware.ErrTimeout
+	case mysql.ER_SERVER_SHUTDOWN:
+		return middleware.ErrUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	perrors "github.com/pingcap/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+func TestClassifyError(t *testing.T) {
+	asst := assert.New(t)
+
+	err := ClassifyError(mysql.NewError(mysql.ER_CON_COUNT_ERROR, "Too many connections"))
+	asst.True(errors.Is(err, middleware.ErrTooManyConnections), "test ClassifyError() failed")
+	err = ClassifyError(mysql.NewError(mysql.ER_ACCESS_DENIED_ERROR, "Access denied"))
+	asst.True(errors.Is(err, middleware.ErrAuthFailed), "test ClassifyError() failed")
+	err = ClassifyError(mysql.NewError(mysql.ER_OPTION_PREVENTS_STATEMENT, "read-only"))
+	asst.True(errors.Is(err, middleware.ErrNotLeader), "test ClassifyError() failed")
+	var myErr *mysql.MyError
+	asst.True(errors.As(err, &myErr), "test ClassifyError() failed")
+	asst.Equal(uint16(mysql.ER_OPTION_PREVENTS_STATEMENT), myErr.Code, "test ClassifyError() failed")
+
+	// the driver wraps the errors with Cause() method
+	err = ClassifyError(perrors.Trace(mysql.NewError(mysql.ER_ACCESS_DENIED_ERROR, "Access denied")))
+	asst.True(errors.Is(err, middleware.ErrAuthFailed), "test ClassifyError() failed")
+
+	err = mysql.NewError(mysql.ER_NO_SUCH_TABLE, "Table doesn't exist")
+	asst.Equal(err, ClassifyError(err), "test ClassifyError() failed")
+}
@@ -0,0 +1,191 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/failover"
+	"github.com/romberli/go-util/healthcheck"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/pool"
+)
+
+const (
+	DefaultReconnectMaxRetries = 3
+	DefaultReconnectBackoff    = 500 * time.Millisecond
+	DefaultReconnectMaxBackoff = 5 * time.Second
+
+	SelectReadOnlySQL = "select @@read_only"
+	failoverPoolName  = "mysql"
+)
+
+type ReconnectPolicy struct {
+	// MaxRetries is the maximum number of the retries after the first failed connecting, 0 means no retry
+	MaxRetries int
+	// Backoff is the wait time before the first retry, it doubles after each retry until reaching MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewReconnectPolicy returns a new *ReconnectPolicy
+func NewReconnectPolicy(maxRetries int, backoff, maxBackoff time.Duration) *ReconnectPolicy {
+	return &ReconnectPolicy{
+		MaxRetries: maxRetries,
+		Backoff:    backoff,
+		MaxBackoff: maxBackoff,
+	}
+}
+
+// NewReconnectPolicyWithDefault returns a new *ReconnectPolicy with default values
+func NewReconnectPolicyWithDefault() *ReconnectPolicy {
+	return NewReconnectPolicy(DefaultReconnectMaxRetries, DefaultReconnectBackoff, DefaultReconnectMaxBackoff)
+}
+
+// Validate validates the reconnect policy
+func (rp *ReconnectPolicy) Validate() (bool, error) {
+	if rp.MaxRetries < constant.ZeroInt {
+		return false, errors.New("max retries must not be negative")
+	}
+	if rp.Backoff < constant.ZeroInt || rp.MaxBackoff < constant.ZeroInt {
+		return false, errors.New("backoff and max backoff must not be negative")
+	}
+
+	return true, nil
+}
+
+// IsConnectionError returns if the error means the mysql instance could not be connected or the connection had been lost
+func IsConnectionError(err error) bool {
+	return errors.Is(err, middleware.ErrUnavailable) || errors.Is(err, middleware.ErrConnRefused) || errors.Is(err, middleware.ErrConnTimeout)
+}
+
+// SetReconnectPolicy sets the reconnect policy of the pool, when getting connection from the pool fails with connection error,
+// it will be retried with backoff, so that the callers could survive a short outage such as a primary switchover,
+// if policy is nil, the pool will not reconnect, it should be called before the pool is used
+func (p *Pool) SetReconnectPolicy(policy *ReconnectPolicy) error {
+	if policy == nil {
+		p.reconnectPolicy = nil
+		return nil
+	}
+
+	ok, err := policy.Validate()
+	if !ok {
+		return err
+	}
+
+	p.reconnectPolicy = policy
+
+	return nil
+}
+
+// withReconnect calls getFunc and retries it with backoff if it fails with connection error
+func (p *Pool) withReconnect(ctx context.Context, getFunc func() (pool.Conn, error)) (pool.Conn, error) {
+	conn, err := getFunc()
+	if p.reconnectPolicy == nil {
+		return conn, err
+	}
+
+	backoff := p.reconnectPolicy.Backoff
+	for i := 0; i < p.reconnectPolicy.MaxRetries && err != nil && IsConnectionError(ClassifyError(err)); i++ {
+		log.Warnf("mysql: connect failed, will retry after %s. addr: %s, retry: %d. %s", backoff.String(), p.getAddr(), i+1, err.Error())
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ClassifyError(ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > p.reconnectPolicy.MaxBackoff {
+			backoff = p.reconnectPolicy.MaxBackoff
+		}
+		conn, err = getFunc()
+	}
+
+	return conn, err
+}
+
+// instance is a mysql instance of the candidate hosts, it connects to the instance on each check
+type instance struct {
+	Config
+}
+
+// CheckInstanceStatus connects to the instance and checks if it is available
+func (i *instance) CheckInstanceStatus() bool {
+	conn, err := NewConnWithConfig(i.Config)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.CheckInstanceStatus()
+}
+
+// isPrimary returns if the instance is writable
+func (i *instance) isPrimary(ctx context.Context) (bool, error) {
+	conn, err := NewConnWithConfig(i.Config)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	result, err := conn.ExecuteContext(ctx, SelectReadOnlySQL)
+	if err != nil {
+		return false, err
+	}
+	readOnly, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return false, err
+	}
+
+	return readOnly == constant.ZeroInt, nil
+}
+
+// NewFailoverPool returns a pool which connects to the primary of the candidate hosts, for example, the instances behind MHA or Orchestrator,
+// the primary is the writable instance, the Addr of the config will be ignored,
+// it also returns the failover manager which re-targets the pool to the new primary after switchover,
+// the caller should start the manager to check the candidates periodically, the pool uses the default reconnect policy
+func NewFailoverPool(config PoolConfig, candidates []string, timeout time.Duration) (*Pool, *failover.Manager, error) {
+	if len(candidates) == constant.ZeroInt {
+		return nil, nil, errors.New("candidate hosts should not be empty")
+	}
+
+	instances := make(map[string]*instance, len(candidates))
+	endpoints := make([]*failover.Endpoint, len(candidates))
+	for i, addr := range candidates {
+		cfg := config.Config
+		cfg.Addr = addr
+		instances[addr] = &instance{cfg}
+		endpoints[i] = failover.NewEndpoint(addr, addr, i, healthcheck.NewStatusChecker(instances[addr]))
+	}
+
+	source := failover.NewQuerySource(func(ctx context.Context, endpoint *failover.Endpoint) (bool, error) {
+		return instances[endpoint.Addr].isPrimary(ctx)
+	})
+	manager, err := failover.NewManager(timeout, source, endpoints...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = manager.Refresh(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config.Addr = manager.Primary().Addr
+	p, err := NewPoolWithPoolConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.reconnectPolicy = NewReconnectPolicyWithDefault()
+	manager.AddRetargeter(failoverPoolName, p)
+
+	return p, manager, nil
+}
@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/middleware/pool"
+)
+
+func TestPool_WithReconnect(t *testing.T) {
+	asst := assert.New(t)
+
+	p := &Pool{}
+	err := p.SetReconnectPolicy(NewReconnectPolicy(-1, time.Millisecond, time.Millisecond))
+	asst.NotNil(err, "test SetReconnectPolicy() failed")
+	err = p.SetReconnectPolicy(NewReconnectPolicy(2, time.Millisecond, 2*time.Millisecond))
+	asst.Nil(err, "test SetReconnectPolicy() failed")
+
+	// connection errors should be retried
+	var calls int
+	_, err = p.withReconnect(context.Background(), func() (pool.Conn, error) {
+		calls++
+		return nil, mysql.ErrBadConn
+	})
+	asst.True(IsConnectionError(ClassifyError(err)), "test withReconnect() failed")
+	asst.Equal(3, calls, "test withReconnect() failed")
+
+	// the other errors should not be retried
+	calls = 0
+	_, err = p.withReconnect(context.Background(), func() (pool.Conn, error) {
+		calls++
+		return nil, errors.New("test error")
+	})
+	asst.NotNil(err, "test withReconnect() failed")
+	asst.Equal(1, calls, "test withReconnect() failed")
+
+	// it should succeed after reconnecting
+	calls = 0
+	_, err = p.withReconnect(context.Background(), func() (pool.Conn, error) {
+		calls++
+		if calls == 1 {
+			return nil, mysql.ErrBadConn
+		}
+		return &PoolConn{}, nil
+	})
+	asst.Nil(err, "test withReconnect() failed")
+	asst.Equal(2, calls, "test withReconnect() failed")
+}
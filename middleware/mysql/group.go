@@ -0,0 +1,316 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+)
+
+const (
+	DefaultMaxReplicationLag = 10 * time.Second
+
+	secondsBehindMasterColumn = "Seconds_Behind_Master"
+	secondsBehindSourceColumn = "Seconds_Behind_Source"
+)
+
+var readSQLPrefixes = []string{"select", "show", "desc", "explain"}
+
+// readerStatus holds a reader pool and its latest checked status
+type readerStatus struct {
+	pool      *Pool
+	available bool
+	lag       time.Duration
+}
+
+// Group holds one writer pool and several reader pools of a replication topology,
+// the select statements are routed to the available readers in a round-robin way,
+// the readers whose replication is broken or lags behind more than the max replication lag
+// will be excluded after each refreshing, if there is no available reader, the writer will be used
+type Group struct {
+	writer *Pool
+	maxLag time.Duration
+
+	mutex    sync.Mutex
+	readers  []*readerStatus
+	index    int
+	stopChan chan struct{}
+}
+
+// NewGroup returns a new *Group with the writer and reader pools,
+// all the readers are considered as available until the first refreshing
+func NewGroup(maxLag time.Duration, writer *Pool, readers ...*Pool) (*Group, error) {
+	if writer == nil {
+		return nil, errors.New("writer pool should not be nil")
+	}
+	if maxLag <= constant.ZeroInt {
+		maxLag = DefaultMaxReplicationLag
+	}
+
+	g := &Group{
+		writer: writer,
+		maxLag: maxLag,
+	}
+	for _, reader := range readers {
+		g.readers = append(g.readers, &readerStatus{pool: reader, available: true})
+	}
+
+	return g, nil
+}
+
+// NewGroupWithDefault returns a new *Group, it creates the pools with default configuration
+func NewGroupWithDefault(writerAddr string, readerAddrs []string, dbName, dbUser, dbPass string) (*Group, error) {
+	writer, err := NewPoolWithDefault(writerAddr, dbName, dbUser, dbPass)
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]*Pool, len(readerAddrs))
+	for i, addr := range readerAddrs {
+		readers[i], err = NewPoolWithDefault(addr, dbName, dbUser, dbPass)
+		if err != nil {
+			for _, reader := range readers[:i] {
+				_ = reader.Close()
+			}
+			_ = writer.Close()
+			return nil, err
+		}
+	}
+
+	return NewGroup(DefaultMaxReplicationLag, writer, readers...)
+}
+
+// Writer returns the writer pool
+func (g *Group) Writer() *Pool {
+	return g.writer
+}
+
+// Reader returns the next available reader pool, if there is no available reader, it returns the writer pool
+func (g *Group) Reader() *Pool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for range g.readers {
+		g.index = (g.index + 1) % len(g.readers)
+		reader := g.readers[g.index]
+		if reader.available && !reader.pool.IsClosed() {
+			return reader.pool
+		}
+	}
+
+	return g.writer
+}
+
+// AvailableReaders returns the addresses of the available readers
+func (g *Group) AvailableReaders() []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	var addrs []string
+	for _, reader := range g.readers {
+		if reader.available {
+			addrs = append(addrs, reader.pool.getAddr())
+		}
+	}
+
+	return addrs
+}
+
+// String returns the readable description of the group
+func (g *Group) String() string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	readers := make([]string, len(g.readers))
+	for i, reader := range g.readers {
+		readers[i] = fmt.Sprintf("%s(available: %t, lag: %s)", reader.pool.getAddr(), reader.available, reader.lag.String())
+	}
+
+	return fmt.Sprintf("writer: %s, readers: [%s]", g.writer.getAddr(), strings.Join(readers, constant.CommaString))
+}
+
+// Execute executes given sql and placeholders, the read-only statements are executed on a reader,
+// and the others are executed on the writer, use Writer() directly if the read must see the latest writes
+func (g *Group) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return g.ExecuteContext(context.Background(), command, args...)
+}
+
+// ExecuteContext executes given sql and placeholders with context, see Execute() for the routing rules
+func (g *Group) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	p := g.writer
+	if IsReadSQL(command) {
+		p = g.Reader()
+	}
+
+	pc, err := p.getContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = pc.Close() }()
+
+	return pc.ExecuteContext(ctx, command, args...)
+}
+
+// Refresh checks the replication status of the readers, the readers which could not be connected,
+// whose replication threads are not running or which lag behind more than the max replication lag are marked as unavailable
+func (g *Group) Refresh(ctx context.Context) {
+	g.mutex.Lock()
+	readers := make([]*readerStatus, len(g.readers))
+	copy(readers, g.readers)
+	g.mutex.Unlock()
+
+	for _, reader := range readers {
+		lag, err := checkReplicationLag(ctx, reader.pool)
+		available := err == nil && lag <= g.maxLag
+		if err != nil {
+			log.Warnf("mysql: check replication lag of reader failed. addr: %s. %s", reader.pool.getAddr(), err.Error())
+		} else if !available {
+			log.Warnf("mysql: replication lag of reader exceeds the maximum. addr: %s, lag: %s, max lag: %s",
+				reader.pool.getAddr(), lag.String(), g.maxLag.String())
+		}
+
+		g.mutex.Lock()
+		if reader.available != available {
+			log.Infof("mysql: availability of reader changed. addr: %s, available: %t", reader.pool.getAddr(), available)
+		}
+		reader.available = available
+		reader.lag = lag
+		g.mutex.Unlock()
+	}
+}
+
+// Start refreshes the readers periodically in the background
+func (g *Group) Start(interval time.Duration) {
+	g.mutex.Lock()
+	if g.stopChan != nil {
+		g.mutex.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	g.stopChan = stopChan
+	g.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			g.Refresh(context.Background())
+
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the background refreshing
+func (g *Group) Stop() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.stopChan != nil {
+		close(g.stopChan)
+		g.stopChan = nil
+	}
+}
+
+// Close stops the background refreshing and closes all the pools
+func (g *Group) Close() error {
+	g.Stop()
+
+	var merr error
+	err := g.writer.Close()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	for _, reader := range g.readers {
+		err = reader.pool.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr
+}
+
+// checkReplicationLag returns the replication lag of the mysql instance of the pool
+func checkReplicationLag(ctx context.Context, p *Pool) (time.Duration, error) {
+	pc, err := p.getContext(ctx)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	defer func() { _ = pc.Close() }()
+
+	result, err := pc.Conn.executeContext(ctx, ShowSlaveStatusSQL)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return constant.ZeroInt, errors.New("replication is not configured")
+	}
+
+	column := secondsBehindMasterColumn
+	if !result.ColumnExists(column) {
+		column = secondsBehindSourceColumn
+	}
+	isNull, err := result.IsNullByName(constant.ZeroInt, column)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	if isNull {
+		return constant.ZeroInt, errors.New("replication threads are not running")
+	}
+	seconds, err := result.GetIntByName(constant.ZeroInt, column)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// IsReadSQL returns if the sql is a read-only statement which could be executed on a replica,
+// the locking reads such as select ... for update are not read-only statements
+func IsReadSQL(sql string) bool {
+	s := strings.ToLower(strings.TrimSpace(trimLeadingComments(sql)))
+	for _, prefix := range readSQLPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return !strings.Contains(s, " for update") && !strings.Contains(s, " lock in share mode") && !strings.Contains(s, " for share")
+		}
+	}
+
+	return false
+}
+
+// trimLeadingComments removes the leading /* */ and -- comments of the sql
+func trimLeadingComments(sql string) string {
+	for {
+		sql = strings.TrimSpace(sql)
+		switch {
+		case strings.HasPrefix(sql, "/*"):
+			end := strings.Index(sql, "*/")
+			if end < constant.ZeroInt {
+				return constant.EmptyString
+			}
+			sql = sql[end+2:]
+		case strings.HasPrefix(sql, "--"), strings.HasPrefix(sql, "#"):
+			end := strings.Index(sql, "\n")
+			if end < constant.ZeroInt {
+				return constant.EmptyString
+			}
+			sql = sql[end+1:]
+		default:
+			return sql
+		}
+	}
+}
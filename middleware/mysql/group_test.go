@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsReadSQL(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.True(IsReadSQL("select * from t01"), "test IsReadSQL() failed")
+	asst.True(IsReadSQL("  SELECT 1"), "test IsReadSQL() failed")
+	asst.True(IsReadSQL("/* hint */ select 1"), "test IsReadSQL() failed")
+	asst.True(IsReadSQL("-- comment\nshow tables"), "test IsReadSQL() failed")
+	asst.False(IsReadSQL("select * from t01 where id = 1 for update"), "test IsReadSQL() failed")
+	asst.False(IsReadSQL("select * from t01 lock in share mode"), "test IsReadSQL() failed")
+	asst.False(IsReadSQL("insert into t01 select * from t02"), "test IsReadSQL() failed")
+	asst.False(IsReadSQL("/* select */ update t01 set id = 1"), "test IsReadSQL() failed")
+}
+
+func TestGroup_Reader(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := NewGroup(DefaultMaxReplicationLag, nil)
+	asst.NotNil(err, "test NewGroup() failed")
+
+	// the writer should be used if there is no reader
+	writer := &Pool{}
+	g, err := NewGroup(DefaultMaxReplicationLag, writer)
+	asst.Nil(err, "test NewGroup() failed")
+	asst.True(g.Reader() == writer, "test Reader() failed")
+}
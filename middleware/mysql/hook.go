@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/percona/go-mysql/query"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultSlowQueryThreshold = time.Second
+
+// QueryEvent describes an executed statement, it is passed to the hooks after the statement finishes
+type QueryEvent struct {
+	Addr   string
+	DBName string
+	SQL    string
+	Args   []interface{}
+	Start  time.Time
+	// Duration is the execution time of the statement, for the streaming query, it includes the time of reading all the rows
+	Duration time.Duration
+	// Rows is the number of the returned rows of the select statement, or the affected rows of the other statements
+	Rows int
+	Err  error
+}
+
+// Fingerprint returns the fingerprint of the sql, the literals are replaced with "?",
+// so that the same statements with different values could be aggregated, and the sensitive values will not be logged
+func (qe *QueryEvent) Fingerprint() string {
+	return query.Fingerprint(qe.SQL)
+}
+
+// Hook is invoked after each statement of the connection finishes, it runs synchronously, so it should return quickly
+type Hook interface {
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// HookFunc is an adapter to allow the use of ordinary functions as Hook
+type HookFunc func(ctx context.Context, event *QueryEvent)
+
+// AfterQuery calls f(ctx, event)
+func (f HookFunc) AfterQuery(ctx context.Context, event *QueryEvent) {
+	f(ctx, event)
+}
+
+// NewSlowQueryHook returns a Hook which logs the statements whose execution time is not less than the threshold,
+// if fingerprint is true, the fingerprint of the sql will be logged instead of the sql and the arguments
+func NewSlowQueryHook(threshold time.Duration, fingerprint bool) Hook {
+	if threshold <= constant.ZeroInt {
+		threshold = DefaultSlowQueryThreshold
+	}
+
+	return HookFunc(func(ctx context.Context, event *QueryEvent) {
+		if event.Duration < threshold {
+			return
+		}
+
+		errMessage := constant.EmptyString
+		if event.Err != nil {
+			errMessage = event.Err.Error()
+		}
+		if fingerprint {
+			log.Warnf("mysql: slow query. addr: %s, db: %s, duration: %s, rows: %d, fingerprint: %s, error: %s",
+				event.Addr, event.DBName, event.Duration.String(), event.Rows, event.Fingerprint(), errMessage)
+			return
+		}
+		log.Warnf("mysql: slow query. addr: %s, db: %s, duration: %s, rows: %d, sql: %s, args: %v, error: %s",
+			event.Addr, event.DBName, event.Duration.String(), event.Rows, event.SQL, event.Args, errMessage)
+	})
+}
+
+// AddHook adds the hook which is invoked after each statement of the connection finishes
+func (conn *Conn) AddHook(hook Hook) {
+	conn.hooks = append(conn.hooks, hook)
+}
+
+// AddHook adds the hook which is invoked after each statement of all the connections of the pool finishes,
+// including the connections which had been created
+func (p *Pool) AddHook(hook Hook) {
+	p.hooks.add(hook)
+}
+
+// hookSet holds the hooks which are shared by the connections of a pool, the hooks could be added while the connections are in use
+type hookSet struct {
+	mutex sync.RWMutex
+	hooks []Hook
+}
+
+// add adds the hook
+func (hs *hookSet) add(hook Hook) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	// copy on write, so that the hooks being invoked will not be affected
+	hooks := make([]Hook, len(hs.hooks), len(hs.hooks)+1)
+	copy(hooks, hs.hooks)
+	hs.hooks = append(hooks, hook)
+}
+
+// get returns the hooks, it returns nil if the hook set is nil
+func (hs *hookSet) get() []Hook {
+	if hs == nil {
+		return nil
+	}
+
+	hs.mutex.RLock()
+	defer hs.mutex.RUnlock()
+
+	return hs.hooks
+}
+
+// runHooks invokes the hooks of the pool and the connection with the statement
+func (conn *Conn) runHooks(ctx context.Context, command string, args []interface{}, start time.Time, rows int, err error) {
+	poolHooks := conn.poolHooks.get()
+	if len(poolHooks) == constant.ZeroInt && len(conn.hooks) == constant.ZeroInt {
+		return
+	}
+
+	event := &QueryEvent{
+		Addr:     conn.Addr,
+		DBName:   conn.DBName,
+		SQL:      command,
+		Args:     args,
+		Start:    start,
+		Duration: time.Since(start),
+		Rows:     rows,
+		Err:      err,
+	}
+	for _, hook := range poolHooks {
+		hook.AfterQuery(ctx, event)
+	}
+	for _, hook := range conn.hooks {
+		hook.AfterQuery(ctx, event)
+	}
+}
+
+// resultRows returns the number of the returned rows of the select statement, or the affected rows of the other statements
+func resultRows(result *Result) int {
+	if result == nil {
+		return constant.ZeroInt
+	}
+	if result.Raw.Resultset != nil {
+		return result.RowNumber()
+	}
+
+	return int(result.Raw.AffectedRows)
+}
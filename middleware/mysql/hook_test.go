@@ -0,0 +1,52 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConn_RunHooks(t *testing.T) {
+	asst := assert.New(t)
+
+	c := &Conn{Config: NewConfig("127.0.0.1:3306", "test", "root", "root")}
+	var events []*QueryEvent
+	c.AddHook(HookFunc(func(ctx context.Context, event *QueryEvent) {
+		events = append(events, event)
+	}))
+	c.AddHook(NewSlowQueryHook(time.Nanosecond, true))
+
+	start := time.Now().Add(-time.Second)
+	c.runHooks(context.Background(), "select * from t01 where id = 1", []interface{}{1}, start, 1, nil)
+	c.runHooks(context.Background(), "update t01 set name = 'a'", nil, start, 0, errors.New("test error"))
+	asst.Equal(2, len(events), "test runHooks() failed")
+	asst.Equal("127.0.0.1:3306", events[0].Addr, "test runHooks() failed")
+	asst.Equal(1, events[0].Rows, "test runHooks() failed")
+	asst.True(events[0].Duration >= time.Second, "test runHooks() failed")
+	asst.Equal("select * from t01 where id = ?", events[0].Fingerprint(), "test Fingerprint() failed")
+	asst.NotNil(events[1].Err, "test runHooks() failed")
+}
+
+func TestPool_AddHook(t *testing.T) {
+	asst := assert.New(t)
+
+	p := &Pool{}
+	// the connections are created before adding the hook
+	c1 := &Conn{poolHooks: &p.hooks}
+	c2 := &Conn{poolHooks: &p.hooks}
+	var poolEvents, connEvents int
+	p.AddHook(HookFunc(func(ctx context.Context, event *QueryEvent) {
+		poolEvents++
+	}))
+	c1.AddHook(HookFunc(func(ctx context.Context, event *QueryEvent) {
+		connEvents++
+	}))
+
+	c1.runHooks(context.Background(), "select 1", nil, time.Now(), 1, nil)
+	c2.runHooks(context.Background(), "select 1", nil, time.Now(), 1, nil)
+	asst.Equal(2, poolEvents, "test AddHook() failed")
+	asst.Equal(1, connEvents, "test AddHook() failed")
+}
@@ -0,0 +1,202 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	ShowCreateTableSQL = "show create table %s"
+
+	createStatementColumn = 1
+)
+
+type Table struct {
+	TableSchema    string `middleware:"table_schema"`
+	TableName      string `middleware:"table_name"`
+	TableType      string `middleware:"table_type"`
+	Engine         string `middleware:"engine"`
+	RowFormat      string `middleware:"row_format"`
+	TableRows      int    `middleware:"table_rows"`
+	DataLength     int    `middleware:"data_length"`
+	IndexLength    int    `middleware:"index_length"`
+	AutoIncrement  int    `middleware:"auto_increment"`
+	TableCollation string `middleware:"table_collation"`
+	TableComment   string `middleware:"table_comment"`
+}
+
+type Column struct {
+	TableSchema     string `middleware:"table_schema"`
+	TableName       string `middleware:"table_name"`
+	ColumnName      string `middleware:"column_name"`
+	OrdinalPosition int    `middleware:"ordinal_position"`
+	// ColumnDefault is nil if the column has no default value
+	ColumnDefault *string `middleware:"column_default"`
+	Nullable      bool    `middleware:"nullable"`
+	DataType      string  `middleware:"data_type"`
+	ColumnType    string  `middleware:"column_type"`
+	CharacterSet  string  `middleware:"character_set_name"`
+	Collation     string  `middleware:"collation_name"`
+	ColumnKey     string  `middleware:"column_key"`
+	Extra         string  `middleware:"extra"`
+	ColumnComment string  `middleware:"column_comment"`
+}
+
+type Index struct {
+	TableSchema string
+	TableName   string
+	IndexName   string
+	Unique      bool
+	IndexType   string
+	// Columns are the column names of the index in order, the column name is empty for the functional key part
+	Columns      []string
+	IndexComment string
+}
+
+// indexColumn is a row of information_schema.statistics, which is a column of an index
+type indexColumn struct {
+	IndexName    string `middleware:"index_name"`
+	NonUnique    bool   `middleware:"non_unique"`
+	ColumnName   string `middleware:"column_name"`
+	IndexType    string `middleware:"index_type"`
+	IndexComment string `middleware:"index_comment"`
+}
+
+// Inspector reads the schema information of the mysql instance,
+// it saves the callers such as schema-diff and audit tools from parsing information_schema by themselves
+type Inspector struct {
+	conn *Conn
+}
+
+// NewInspector returns a new *Inspector with given connection
+func NewInspector(conn *Conn) *Inspector {
+	return &Inspector{conn: conn}
+}
+
+// GetTables returns the tables and views of given database ordered by the table name
+func (i *Inspector) GetTables(dbName string) ([]*Table, error) {
+	sql := `
+		select table_schema as table_schema, table_name as table_name, table_type as table_type,
+			ifnull(engine, '') as engine, ifnull(row_format, '') as row_format, ifnull(table_rows, 0) as table_rows,
+			ifnull(data_length, 0) as data_length, ifnull(index_length, 0) as index_length,
+			ifnull(auto_increment, 0) as auto_increment, ifnull(table_collation, '') as table_collation,
+			table_comment as table_comment
+		from information_schema.tables
+		where table_schema = ?
+		order by table_name;
+	`
+	result, err := i.conn.Execute(sql, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]*Table, result.RowNumber())
+	for j := range tables {
+		tables[j] = &Table{}
+	}
+
+	err = result.MapToStructSlice(tables, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// GetCreateTable returns the create statement of given table, it also works for the view
+func (i *Inspector) GetCreateTable(dbName, tableName string) (string, error) {
+	quoted, err := quoteIdentifiers([]string{dbName, tableName})
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	result, err := i.conn.Execute(fmt.Sprintf(ShowCreateTableSQL, quoted[0]+constant.DotString+quoted[1]))
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return constant.EmptyString, errors.New(fmt.Sprintf("table does not exist. db: %s, table: %s", dbName, tableName))
+	}
+
+	return result.GetString(constant.ZeroInt, createStatementColumn)
+}
+
+// GetColumns returns the columns of given table ordered by the ordinal position
+func (i *Inspector) GetColumns(dbName, tableName string) ([]*Column, error) {
+	sql := `
+		select table_schema as table_schema, table_name as table_name, column_name as column_name,
+			ordinal_position as ordinal_position, column_default as column_default, is_nullable = 'YES' as nullable,
+			data_type as data_type, column_type as column_type, ifnull(character_set_name, '') as character_set_name,
+			ifnull(collation_name, '') as collation_name, column_key as column_key, extra as extra,
+			column_comment as column_comment
+		from information_schema.columns
+		where table_schema = ? and table_name = ?
+		order by ordinal_position;
+	`
+	result, err := i.conn.Execute(sql, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*Column, result.RowNumber())
+	for j := range columns {
+		columns[j] = &Column{}
+	}
+
+	err = result.MapToStructSlice(columns, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// GetIndexes returns the indexes of given table ordered by the index name
+func (i *Inspector) GetIndexes(dbName, tableName string) ([]*Index, error) {
+	sql := `
+		select index_name as index_name, non_unique as non_unique, ifnull(column_name, '') as column_name,
+			index_type as index_type, index_comment as index_comment
+		from information_schema.statistics
+		where table_schema = ? and table_name = ?
+		order by index_name, seq_in_index;
+	`
+	result, err := i.conn.Execute(sql, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexColumns := make([]*indexColumn, result.RowNumber())
+	for j := range indexColumns {
+		indexColumns[j] = &indexColumn{}
+	}
+
+	err = result.MapToStructSlice(indexColumns, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupIndexColumns(dbName, tableName, indexColumns), nil
+}
+
+// groupIndexColumns groups the index columns by the index name, the index columns must be ordered by the index name
+func groupIndexColumns(dbName, tableName string, indexColumns []*indexColumn) []*Index {
+	var indexes []*Index
+	for _, ic := range indexColumns {
+		if len(indexes) == constant.ZeroInt || indexes[len(indexes)-1].IndexName != ic.IndexName {
+			indexes = append(indexes, &Index{
+				TableSchema:  dbName,
+				TableName:    tableName,
+				IndexName:    ic.IndexName,
+				Unique:       !ic.NonUnique,
+				IndexType:    ic.IndexType,
+				IndexComment: ic.IndexComment,
+			})
+		}
+		index := indexes[len(indexes)-1]
+		index.Columns = append(index.Columns, ic.ColumnName)
+	}
+
+	return indexes
+}
@@ -0,0 +1,64 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspector_GetSchema(t *testing.T) {
+	asst := assert.New(t)
+
+	err := createTable()
+	asst.Nil(err, "test GetSchema failed")
+	defer func() {
+		err = dropTable()
+		asst.Nil(err, "test GetSchema failed")
+	}()
+
+	inspector := NewInspector(conn)
+	tables, err := inspector.GetTables("test")
+	asst.Nil(err, "test GetTables() failed")
+	var found bool
+	for _, table := range tables {
+		if table.TableName == "t05" {
+			found = true
+			asst.Equal("InnoDB", table.Engine, "test GetTables() failed")
+		}
+	}
+	asst.True(found, "test GetTables() failed")
+
+	createStatement, err := inspector.GetCreateTable("test", "t05")
+	asst.Nil(err, "test GetCreateTable() failed")
+	asst.Contains(createStatement, "CREATE TABLE `t05`", "test GetCreateTable() failed")
+
+	columns, err := inspector.GetColumns("test", "t05")
+	asst.Nil(err, "test GetColumns() failed")
+	asst.Equal(5, len(columns), "test GetColumns() failed")
+	asst.Equal("id", columns[0].ColumnName, "test GetColumns() failed")
+	asst.False(columns[0].Nullable, "test GetColumns() failed")
+	asst.Nil(columns[0].ColumnDefault, "test GetColumns() failed")
+	asst.True(columns[1].Nullable, "test GetColumns() failed")
+
+	indexes, err := inspector.GetIndexes("test", "t05")
+	asst.Nil(err, "test GetIndexes() failed")
+	asst.Equal(1, len(indexes), "test GetIndexes() failed")
+	asst.Equal("PRIMARY", indexes[0].IndexName, "test GetIndexes() failed")
+	asst.True(indexes[0].Unique, "test GetIndexes() failed")
+	asst.Equal([]string{"id"}, indexes[0].Columns, "test GetIndexes() failed")
+}
+
+func TestGroupIndexColumns(t *testing.T) {
+	asst := assert.New(t)
+
+	indexes := groupIndexColumns("test", "t01", []*indexColumn{
+		{IndexName: "PRIMARY", ColumnName: "id", IndexType: "BTREE"},
+		{IndexName: "idx01_name_age", NonUnique: true, ColumnName: "name", IndexType: "BTREE"},
+		{IndexName: "idx01_name_age", NonUnique: true, ColumnName: "age", IndexType: "BTREE"},
+	})
+	asst.Equal(2, len(indexes), "test groupIndexColumns() failed")
+	asst.True(indexes[0].Unique, "test groupIndexColumns() failed")
+	asst.False(indexes[1].Unique, "test groupIndexColumns() failed")
+	asst.Equal([]string{"name", "age"}, indexes[1].Columns, "test groupIndexColumns() failed")
+	asst.Equal("t01", indexes[1].TableName, "test groupIndexColumns() failed")
+}
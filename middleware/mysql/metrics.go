@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/romberli/go-util/metrics"
+)
+
+const (
+	metricsSubsystem = "mysql"
+	poolLabel        = "pool"
+	fingerprintLabel = "fingerprint"
+)
+
+var _ prometheus.Collector = (*PoolCollector)(nil)
+
+// PoolCollector collects the statistics of the pool when being scraped
+type PoolCollector struct {
+	pool *Pool
+
+	maxConnections *prometheus.Desc
+	inUse          *prometheus.Desc
+	idle           *prometheus.Desc
+	created        *prometheus.Desc
+	closed         *prometheus.Desc
+	waitCount      *prometheus.Desc
+	waitDuration   *prometheus.Desc
+	timeouts       *prometheus.Desc
+}
+
+// NewPoolCollector returns a new *PoolCollector, name is used as the pool label to distinguish the pools,
+// the collector should be registered to the prometheus registerer by the caller
+func NewPoolCollector(name string, p *Pool) *PoolCollector {
+	constLabels := prometheus.Labels{poolLabel: name}
+	newDesc := func(metricName, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, metricsSubsystem, metricName), help, nil, constLabels)
+	}
+
+	return &PoolCollector{
+		pool:           p,
+		maxConnections: newDesc("pool_max_connections", "Maximum number of the connections of the pool."),
+		inUse:          newDesc("pool_in_use_connections", "Number of the connections which are in use."),
+		idle:           newDesc("pool_idle_connections", "Number of the idle connections."),
+		created:        newDesc("pool_created_connections_total", "Number of the connections created by the pool."),
+		closed:         newDesc("pool_closed_connections_total", "Number of the connections closed by the pool."),
+		waitCount:      newDesc("pool_wait_total", "Number of the times waiting for a free connection."),
+		waitDuration:   newDesc("pool_wait_duration_seconds_total", "Total time spent on waiting for a free connection."),
+		timeouts:       newDesc("pool_wait_timeouts_total", "Number of the times timed out while waiting for a free connection."),
+	}
+}
+
+// Describe implements prometheus.Collector interface
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxConnections
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.created
+	ch <- c.closed
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.timeouts
+}
+
+// Collect implements prometheus.Collector interface
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxConnections, prometheus.GaugeValue, float64(stats.MaxConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.created, prometheus.CounterValue, float64(stats.Created))
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.Closed))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+}
+
+// NewQueryMetricsHook returns a Hook which records the query count, latency and errors labeled by the statement fingerprint,
+// the collectors are registered to given registerer, if registerer is nil, prometheus.DefaultRegisterer will be used,
+// as each distinct fingerprint is a new series, it should only be used when the statements are not generated dynamically
+func NewQueryMetricsHook(registerer prometheus.Registerer) (Hook, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	queryDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystem,
+		Name:      "query_duration_seconds",
+		Help:      "Latency of the mysql statements by fingerprint.",
+		Buckets:   metrics.DefaultBuckets,
+	}, []string{fingerprintLabel})
+	queryErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystem,
+		Name:      "query_errors_total",
+		Help:      "Number of the failed mysql statements by fingerprint.",
+	}, []string{fingerprintLabel})
+
+	err := registerer.Register(queryDuration)
+	if err != nil {
+		return nil, err
+	}
+	err = registerer.Register(queryErrors)
+	if err != nil {
+		registerer.Unregister(queryDuration)
+		return nil, err
+	}
+
+	return HookFunc(func(ctx context.Context, event *QueryEvent) {
+		fingerprint := event.Fingerprint()
+		queryDuration.WithLabelValues(fingerprint).Observe(event.Duration.Seconds())
+		if event.Err != nil {
+			queryErrors.WithLabelValues(fingerprint).Inc()
+		}
+	}), nil
+}
@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/metrics"
+	"github.com/romberli/go-util/middleware/pool"
+)
+
+func TestPoolCollector(t *testing.T) {
+	asst := assert.New(t)
+
+	p, err := pool.NewPool(pool.NewConfig(10, 0, 5, 60, 60), func() (pool.Conn, error) {
+		return nil, errors.New("test error")
+	})
+	asst.Nil(err, "test NewPoolCollector() failed")
+	defer func() { _ = p.Close() }()
+
+	registry := prometheus.NewRegistry()
+	asst.Nil(registry.Register(NewPoolCollector("test", &Pool{pool: p})), "test NewPoolCollector() failed")
+	count, err := testutil.GatherAndCount(registry, metrics.Namespace+"_mysql_pool_max_connections")
+	asst.Nil(err, "test Collect() failed")
+	asst.Equal(1, count, "test Collect() failed")
+}
+
+func TestNewQueryMetricsHook(t *testing.T) {
+	asst := assert.New(t)
+
+	registry := prometheus.NewRegistry()
+	hook, err := NewQueryMetricsHook(registry)
+	asst.Nil(err, "test NewQueryMetricsHook() failed")
+	_, err = NewQueryMetricsHook(registry)
+	asst.NotNil(err, "test NewQueryMetricsHook() failed")
+
+	hook.AfterQuery(context.Background(), &QueryEvent{SQL: "select * from t01 where id = 1", Duration: time.Millisecond})
+	hook.AfterQuery(context.Background(), &QueryEvent{SQL: "select * from t01 where id = 2", Duration: time.Millisecond, Err: errors.New("test error")})
+	count, err := testutil.GatherAndCount(registry, metrics.Namespace+"_mysql_query_duration_seconds")
+	asst.Nil(err, "test AfterQuery() failed")
+	asst.Equal(1, count, "test AfterQuery() failed")
+	count, err = testutil.GatherAndCount(registry, metrics.Namespace+"_mysql_query_errors_total")
+	asst.Nil(err, "test AfterQuery() failed")
+	asst.Equal(1, count, "test AfterQuery() failed")
+}
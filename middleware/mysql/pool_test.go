@@ -65,3 +65,18 @@ func TestPool(t *testing.T) {
 	err = pool.Close()
 	asst.Nil(err, "close pool failed")
 }
+
+func TestPoolConfig_WaitTimeout(t *testing.T) {
+	asst := assert.New(t)
+
+	cfg := NewPoolConfig("127.0.0.1:3306", "test", "root", "root", 1, 0, 1, DefaultMaxIdleTime, DefaultKeepAliveInterval)
+	cfg.WaitTimeout = -time.Second
+	_, err := NewPoolWithPoolConfig(cfg)
+	asst.NotNil(err, "test NewPoolWithPoolConfig() failed")
+
+	cfg.WaitTimeout = 20 * time.Millisecond
+	p, err := NewPoolWithPoolConfig(cfg)
+	asst.Nil(err, "test NewPoolWithPoolConfig() failed")
+	asst.Equal(cfg.WaitTimeout, p.pool.WaitTimeout, "test NewPoolWithPoolConfig() failed")
+	asst.Nil(p.Close(), "test Close() failed")
+}
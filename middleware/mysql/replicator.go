@@ -0,0 +1,508 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+)
+
+const (
+	SelectGTIDExecutedSQL = "select @@global.gtid_executed"
+	SelectColumnNamesSQL  = "select column_name from information_schema.columns where table_schema = ? and table_name = ? order by ordinal_position"
+
+	RowActionInsert RowAction = "insert"
+	RowActionUpdate RowAction = "update"
+	RowActionDelete RowAction = "delete"
+
+	beginStatement     = "BEGIN"
+	commitStatement    = "COMMIT"
+	rollbackStatement  = "ROLLBACK"
+	savepointStatement = "SAVEPOINT"
+	xaStatement        = "XA"
+	checkpointFileMode = 0644
+	checkpointTmpExt   = ".tmp"
+)
+
+type RowAction string
+
+// RowChange is a changed row of the rows event, the values are keyed by the column names
+type RowChange struct {
+	Schema string
+	Table  string
+	Action RowAction
+	// Before is the row before changing, it is nil for insert
+	Before map[string]interface{}
+	// After is the row after changing, it is nil for delete
+	After map[string]interface{}
+	// Timestamp is the time when the event was written to the binlog
+	Timestamp time.Time
+}
+
+// ReplicationHandler handles the binlog events of the replicator, if any method returns an error, the replicator stops
+type ReplicationHandler interface {
+	// OnRows is called with the changed rows of each rows event
+	OnRows(ctx context.Context, changes []*RowChange) error
+	// OnDDL is called with the ddl statement and the default schema of it
+	OnDDL(ctx context.Context, schema, statement string) error
+	// OnCommit is called after each transaction commits with the executed gtid set,
+	// the checkpoint will be saved only if it returns without error
+	OnCommit(ctx context.Context, gtidSet string) error
+}
+
+var _ ReplicationHandler = NopReplicationHandler{}
+
+// NopReplicationHandler does nothing, it could be embedded to implement part of the methods of ReplicationHandler
+type NopReplicationHandler struct{}
+
+// OnRows implements ReplicationHandler interface
+func (NopReplicationHandler) OnRows(ctx context.Context, changes []*RowChange) error {
+	return nil
+}
+
+// OnDDL implements ReplicationHandler interface
+func (NopReplicationHandler) OnDDL(ctx context.Context, schema, statement string) error {
+	return nil
+}
+
+// OnCommit implements ReplicationHandler interface
+func (NopReplicationHandler) OnCommit(ctx context.Context, gtidSet string) error {
+	return nil
+}
+
+// CheckpointStorage stores the executed gtid set of the replicator, so that it could resume after restarting
+type CheckpointStorage interface {
+	// Load returns the saved gtid set, it returns empty string if there is no checkpoint
+	Load() (string, error)
+	// Save saves the gtid set
+	Save(gtidSet string) error
+}
+
+var (
+	_ CheckpointStorage = (*MemoryCheckpoint)(nil)
+	_ CheckpointStorage = (*FileCheckpoint)(nil)
+)
+
+// MemoryCheckpoint stores the checkpoint in memory, it is lost after restarting
+type MemoryCheckpoint struct {
+	mutex   sync.Mutex
+	gtidSet string
+}
+
+// NewMemoryCheckpoint returns a new *MemoryCheckpoint with the initial gtid set, which may be empty
+func NewMemoryCheckpoint(gtidSet string) *MemoryCheckpoint {
+	return &MemoryCheckpoint{gtidSet: gtidSet}
+}
+
+// Load implements CheckpointStorage interface
+func (mc *MemoryCheckpoint) Load() (string, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.gtidSet, nil
+}
+
+// Save implements CheckpointStorage interface
+func (mc *MemoryCheckpoint) Save(gtidSet string) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	mc.gtidSet = gtidSet
+
+	return nil
+}
+
+// FileCheckpoint stores the checkpoint in a local file
+type FileCheckpoint struct {
+	path string
+}
+
+// NewFileCheckpoint returns a new *FileCheckpoint
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+// Load implements CheckpointStorage interface, it returns empty string if the file does not exist
+func (fc *FileCheckpoint) Load() (string, error) {
+	data, err := ioutil.ReadFile(fc.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return constant.EmptyString, nil
+		}
+		return constant.EmptyString, err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Save implements CheckpointStorage interface, the file is replaced atomically,
+// so that the checkpoint will not be corrupted if the process crashes while saving
+func (fc *FileCheckpoint) Save(gtidSet string) error {
+	tmpPath := fc.path + checkpointTmpExt
+	err := ioutil.WriteFile(tmpPath, []byte(gtidSet), checkpointFileMode)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, fc.path)
+}
+
+// Replicator connects to mysql as a replica and streams the binlog events to the handler,
+// the row events are decoded to maps with the column names, which are read from the binlog if binlog_row_metadata is FULL,
+// otherwise, they are read from information_schema, so the column names may be wrong if the table was altered after the event,
+// it requires gtid_mode=ON and binlog_format=ROW, and the user needs replication slave and replication client privileges
+type Replicator struct {
+	config   Config
+	serverID uint32
+	storage  CheckpointStorage
+	handler  ReplicationHandler
+
+	// conn is used to read the table metadata, it is created lazily
+	conn   *Conn
+	tables map[string][]string
+
+	mutex   sync.Mutex
+	gtidSet string
+}
+
+// NewReplicator returns a new *Replicator, serverID must be unique among all the replicas of the mysql instance,
+// the replicator starts from the gtid set of the storage, if there is no checkpoint, it starts from the current gtid_executed
+func NewReplicator(config Config, serverID uint32, storage CheckpointStorage, handler ReplicationHandler) (*Replicator, error) {
+	if serverID == constant.ZeroInt {
+		return nil, errors.New("server id should not be zero")
+	}
+	if storage == nil || handler == nil {
+		return nil, errors.New("checkpoint storage and replication handler should not be nil")
+	}
+
+	return &Replicator{
+		config:   config,
+		serverID: serverID,
+		storage:  storage,
+		handler:  handler,
+		tables:   make(map[string][]string),
+	}, nil
+}
+
+// GTIDSet returns the executed gtid set of the latest committed transaction
+func (r *Replicator) GTIDSet() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.gtidSet
+}
+
+// Run streams the binlog events until the context is done or an error occurs,
+// it returns nil if the context is done, the broken connection will be reconnected by the underlying syncer
+func (r *Replicator) Run(ctx context.Context) error {
+	defer r.closeConn()
+
+	gtidSet, err := r.storage.Load()
+	if err != nil {
+		return err
+	}
+	if gtidSet == constant.EmptyString {
+		gtidSet, err = r.getGTIDExecuted(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	gset, err := mysql.ParseMysqlGTIDSet(gtidSet)
+	if err != nil {
+		return err
+	}
+	r.setGTIDSet(gset.String())
+
+	syncerConfig, err := r.syncerConfig()
+	if err != nil {
+		return err
+	}
+	syncer := replication.NewBinlogSyncer(syncerConfig)
+	defer syncer.Close()
+
+	streamer, err := syncer.StartSyncGTID(gset)
+	if err != nil {
+		return ClassifyError(err)
+	}
+	log.Infof("mysql: replicator started. addr: %s, server id: %d, gtid set: %s", r.config.Addr, r.serverID, gset.String())
+
+	for {
+		event, err := streamer.GetEvent(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return ClassifyError(err)
+		}
+
+		err = r.handleEvent(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// syncerConfig returns the config of the binlog syncer
+func (r *Replicator) syncerConfig() (replication.BinlogSyncerConfig, error) {
+	host, portStr, err := net.SplitHostPort(r.config.Addr)
+	if err != nil {
+		return replication.BinlogSyncerConfig{}, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return replication.BinlogSyncerConfig{}, err
+	}
+	pass, err := credential.DecryptIfNeeded(r.config.DBPass)
+	if err != nil {
+		return replication.BinlogSyncerConfig{}, err
+	}
+
+	return replication.BinlogSyncerConfig{
+		ServerID:  r.serverID,
+		Flavor:    mysql.MySQLFlavor,
+		Host:      host,
+		Port:      uint16(port),
+		User:      r.config.DBUser,
+		Password:  pass,
+		Charset:   r.config.getCharset(),
+		TLSConfig: r.config.TLSConfig,
+		ParseTime: true,
+	}, nil
+}
+
+// handleEvent dispatches the binlog event to the handler
+func (r *Replicator) handleEvent(ctx context.Context, event *replication.BinlogEvent) error {
+	switch e := event.Event.(type) {
+	case *replication.RowsEvent:
+		changes, err := r.decodeRows(ctx, event.Header, e)
+		if err != nil {
+			return err
+		}
+		return r.handler.OnRows(ctx, changes)
+	case *replication.XIDEvent:
+		return r.commit(ctx, e.GSet)
+	case *replication.QueryEvent:
+		statement := string(e.Query)
+		switch {
+		case strings.EqualFold(statement, beginStatement), isTransactionControl(statement):
+			return nil
+		case strings.EqualFold(statement, commitStatement):
+			// the transaction of the non-transactional tables such as myisam ends with commit instead of xid event
+			return r.commit(ctx, e.GSet)
+		}
+		// the columns may be changed by the ddl statement
+		r.tables = make(map[string][]string)
+		err := r.handler.OnDDL(ctx, string(e.Schema), statement)
+		if err != nil {
+			return err
+		}
+		// ddl statement commits implicitly
+		return r.commit(ctx, e.GSet)
+	}
+
+	return nil
+}
+
+// commit calls the handler with the executed gtid set and saves the checkpoint
+func (r *Replicator) commit(ctx context.Context, gset mysql.GTIDSet) error {
+	if gset == nil {
+		return nil
+	}
+
+	gtidSet := gset.String()
+	err := r.handler.OnCommit(ctx, gtidSet)
+	if err != nil {
+		return err
+	}
+	err = r.storage.Save(gtidSet)
+	if err != nil {
+		return err
+	}
+	r.setGTIDSet(gtidSet)
+
+	return nil
+}
+
+// decodeRows decodes the rows event to the row changes
+func (r *Replicator) decodeRows(ctx context.Context, header *replication.EventHeader, event *replication.RowsEvent) ([]*RowChange, error) {
+	var action RowAction
+	switch header.EventType {
+	case replication.WRITE_ROWS_EVENTv0, replication.WRITE_ROWS_EVENTv1, replication.WRITE_ROWS_EVENTv2:
+		action = RowActionInsert
+	case replication.UPDATE_ROWS_EVENTv0, replication.UPDATE_ROWS_EVENTv1, replication.UPDATE_ROWS_EVENTv2:
+		action = RowActionUpdate
+	case replication.DELETE_ROWS_EVENTv0, replication.DELETE_ROWS_EVENTv1, replication.DELETE_ROWS_EVENTv2:
+		action = RowActionDelete
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported rows event type. event type: %s", header.EventType.String()))
+	}
+
+	columns, err := r.getColumns(ctx, event.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := string(event.Table.Schema)
+	table := string(event.Table.Table)
+	timestamp := time.Unix(int64(header.Timestamp), constant.ZeroInt)
+
+	step := 1
+	if action == RowActionUpdate {
+		// the rows of the update event are the pairs of the before and after images
+		step = 2
+		if len(event.Rows)%step != constant.ZeroInt {
+			return nil, errors.New(fmt.Sprintf("number of the rows of the update event should be even. schema: %s, table: %s, rows: %d", schema, table, len(event.Rows)))
+		}
+	}
+
+	changes := make([]*RowChange, constant.ZeroInt, len(event.Rows)/step)
+	for i := 0; i < len(event.Rows); i += step {
+		change := &RowChange{
+			Schema:    schema,
+			Table:     table,
+			Action:    action,
+			Timestamp: timestamp,
+		}
+		switch action {
+		case RowActionInsert:
+			change.After = rowToMap(columns, event.Rows[i])
+		case RowActionUpdate:
+			change.Before = rowToMap(columns, event.Rows[i])
+			change.After = rowToMap(columns, event.Rows[i+1])
+		case RowActionDelete:
+			change.Before = rowToMap(columns, event.Rows[i])
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// getColumns returns the column names of the table, they are read from the table map event if binlog_row_metadata is FULL,
+// otherwise, they are read from information_schema and cached until the next ddl statement
+func (r *Replicator) getColumns(ctx context.Context, table *replication.TableMapEvent) ([]string, error) {
+	if len(table.ColumnName) > constant.ZeroInt {
+		return table.ColumnNameString(), nil
+	}
+
+	schema := string(table.Schema)
+	tableName := string(table.Table)
+	key := schema + constant.DotString + tableName
+	columns, ok := r.tables[key]
+	if !ok {
+		conn, err := r.getConn()
+		if err != nil {
+			return nil, err
+		}
+		result, err := conn.executeContext(ctx, SelectColumnNamesSQL, schema, tableName)
+		if err != nil {
+			return nil, err
+		}
+		columns = make([]string, result.RowNumber())
+		for i := range columns {
+			columns[i], err = result.GetString(i, constant.ZeroInt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.tables[key] = columns
+	}
+
+	if uint64(len(columns)) != table.ColumnCount {
+		return nil, errors.New(fmt.Sprintf(
+			"number of the columns of the table does not match the binlog, set binlog_row_metadata to FULL to avoid this. schema: %s, table: %s, columns: %d, binlog columns: %d",
+			schema, tableName, len(columns), table.ColumnCount))
+	}
+
+	return columns, nil
+}
+
+// getGTIDExecuted returns the executed gtid set of the mysql instance
+func (r *Replicator) getGTIDExecuted(ctx context.Context) (string, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	result, err := conn.executeContext(ctx, SelectGTIDExecutedSQL)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	gtidSet, err := result.GetString(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	// the gtid set may contain new lines when it is long
+	return strings.Replace(gtidSet, "\n", constant.EmptyString, -1), nil
+}
+
+// getConn returns the connection which is used to read the metadata
+func (r *Replicator) getConn() (*Conn, error) {
+	if r.conn != nil {
+		return r.conn, nil
+	}
+
+	conn, err := NewConnWithConfig(r.config)
+	if err != nil {
+		return nil, err
+	}
+	r.conn = conn
+
+	return conn, nil
+}
+
+// closeConn closes the connection which is used to read the metadata
+func (r *Replicator) closeConn() {
+	if r.conn != nil {
+		_ = r.conn.Close()
+		r.conn = nil
+	}
+}
+
+// setGTIDSet sets the executed gtid set
+func (r *Replicator) setGTIDSet(gtidSet string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.gtidSet = gtidSet
+}
+
+// isTransactionControl returns if the statement is rollback, savepoint or xa statement, which should not be handled as ddl
+func isTransactionControl(statement string) bool {
+	fields := strings.Fields(statement)
+	if len(fields) == constant.ZeroInt {
+		return false
+	}
+
+	switch strings.ToUpper(fields[constant.ZeroInt]) {
+	case rollbackStatement, savepointStatement, xaStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+// rowToMap converts the row to a map keyed by the column names
+func rowToMap(columns []string, row []interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if i < len(row) {
+			m[column] = row[i]
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGTIDSet = "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"
+
+type testReplicationHandler struct {
+	NopReplicationHandler
+	changes    []*RowChange
+	statements []string
+	commits    []string
+}
+
+func (h *testReplicationHandler) OnDDL(ctx context.Context, schema, statement string) error {
+	h.statements = append(h.statements, statement)
+	return nil
+}
+
+func (h *testReplicationHandler) OnRows(ctx context.Context, changes []*RowChange) error {
+	h.changes = append(h.changes, changes...)
+	return nil
+}
+
+func (h *testReplicationHandler) OnCommit(ctx context.Context, gtidSet string) error {
+	h.commits = append(h.commits, gtidSet)
+	return nil
+}
+
+func TestFileCheckpoint(t *testing.T) {
+	asst := assert.New(t)
+
+	dir, err := ioutil.TempDir(os.TempDir(), "checkpoint")
+	asst.Nil(err, "test FileCheckpoint failed")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	fc := NewFileCheckpoint(filepath.Join(dir, "gtid"))
+	gtidSet, err := fc.Load()
+	asst.Nil(err, "test Load() failed")
+	asst.Empty(gtidSet, "test Load() failed")
+	asst.Nil(fc.Save(testGTIDSet), "test Save() failed")
+	gtidSet, err = fc.Load()
+	asst.Nil(err, "test Load() failed")
+	asst.Equal(testGTIDSet, gtidSet, "test Load() failed")
+}
+
+func TestReplicator_HandleEvent(t *testing.T) {
+	asst := assert.New(t)
+
+	handler := &testReplicationHandler{}
+	storage := NewMemoryCheckpoint(testGTIDSet)
+	_, err := NewReplicator(Config{}, 0, storage, handler)
+	asst.NotNil(err, "test NewReplicator() failed")
+	r, err := NewReplicator(Config{}, 1001, storage, handler)
+	asst.Nil(err, "test NewReplicator() failed")
+
+	table := &replication.TableMapEvent{
+		Schema:      []byte("test"),
+		Table:       []byte("t01"),
+		ColumnCount: 2,
+		ColumnName:  [][]byte{[]byte("id"), []byte("name")},
+	}
+	err = r.handleEvent(context.Background(), &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.UPDATE_ROWS_EVENTv2},
+		Event: &replication.RowsEvent{
+			Table: table,
+			Rows:  [][]interface{}{{int32(1), "a"}, {int32(1), "b"}},
+		},
+	})
+	asst.Nil(err, "test handleEvent() failed")
+	asst.Equal(1, len(handler.changes), "test handleEvent() failed")
+	asst.Equal(RowActionUpdate, handler.changes[0].Action, "test handleEvent() failed")
+	asst.Equal("a", handler.changes[0].Before["name"], "test handleEvent() failed")
+	asst.Equal("b", handler.changes[0].After["name"], "test handleEvent() failed")
+
+	// the cached columns are used when binlog_row_metadata is not FULL
+	r.tables["test.t02"] = []string{"id"}
+	err = r.handleEvent(context.Background(), &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.DELETE_ROWS_EVENTv2},
+		Event: &replication.RowsEvent{
+			Table: &replication.TableMapEvent{Schema: []byte("test"), Table: []byte("t02"), ColumnCount: 1},
+			Rows:  [][]interface{}{{int32(2)}},
+		},
+	})
+	asst.Nil(err, "test handleEvent() failed")
+	asst.Equal(int32(2), handler.changes[1].Before["id"], "test handleEvent() failed")
+	asst.Nil(handler.changes[1].After, "test handleEvent() failed")
+
+	gset, err := mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-6")
+	asst.Nil(err, "test handleEvent() failed")
+	err = r.handleEvent(context.Background(), &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.XID_EVENT},
+		Event:  &replication.XIDEvent{GSet: gset},
+	})
+	asst.Nil(err, "test handleEvent() failed")
+	asst.Equal([]string{gset.String()}, handler.commits, "test handleEvent() failed")
+	asst.Equal(gset.String(), r.GTIDSet(), "test GTIDSet() failed")
+	gtidSet, err := storage.Load()
+	asst.Nil(err, "test handleEvent() failed")
+	asst.Equal(gset.String(), gtidSet, "test handleEvent() failed")
+
+	// the transaction control statements are not ddl, and the commit of the non-transactional tables is a commit
+	gset, err = mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-7")
+	asst.Nil(err, "test handleEvent() failed")
+	for _, statement := range []string{"BEGIN", "ROLLBACK", "SAVEPOINT sp01", "XA START 'xa01'", "COMMIT", "create table t03(id int)"} {
+		err = r.handleEvent(context.Background(), &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+			Event:  &replication.QueryEvent{Schema: []byte("test"), Query: []byte(statement), GSet: gset},
+		})
+		asst.Nil(err, "test handleEvent() failed")
+	}
+	asst.Equal([]string{"create table t03(id int)"}, handler.statements, "test handleEvent() failed")
+	asst.Equal(3, len(handler.commits), "test handleEvent() failed")
+	asst.Equal(gset.String(), r.GTIDSet(), "test handleEvent() failed")
+}
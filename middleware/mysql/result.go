@@ -2,14 +2,26 @@ package mysql
 
 import (
 	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/go-util/middleware/result"
 
 	"github.com/go-mysql-org/go-mysql/mysql"
 )
 
-const middlewareType = "mysql"
+const (
+	middlewareType = "mysql"
+
+	dateLayout     = "2006-01-02"
+	zeroDateString = "0000-00-00"
+)
 
 var _ middleware.Result = (*Result)(nil)
 
@@ -56,3 +68,194 @@ func (r *Result) RowsAffected() (int, error) {
 func (r *Result) GetRaw() interface{} {
 	return r.Raw
 }
+
+// GetTime returns time.Time type value of given row and column number,
+// the datetime, timestamp and date values are parsed in local time zone,
+// the time values are returned as the time of 0000-01-01 plus the duration, use GetDuration() for the time columns,
+// the zero date such as 0000-00-00 00:00:00 and null value are returned as zero time,
+// use IsNull() to distinguish null value from zero date
+func (r *Result) GetTime(row, column int) (time.Time, error) {
+	value, err := r.GetValue(row, column)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parseTime(value)
+}
+
+// GetTimeByName returns time.Time type value of given row number and column name, see GetTime() for more details
+func (r *Result) GetTimeByName(row int, name string) (time.Time, error) {
+	column, err := r.NameIndex(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return r.GetTime(row, column)
+}
+
+// GetDuration returns time.Duration type value of given row and column number, it is used for the time columns,
+// which may be negative or larger than 24 hours, null value is returned as 0
+func (r *Result) GetDuration(row, column int) (time.Duration, error) {
+	value, err := r.GetValue(row, column)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return parseDuration(value)
+}
+
+// GetDurationByName returns time.Duration type value of given row number and column name, see GetDuration() for more details
+func (r *Result) GetDurationByName(row int, name string) (time.Duration, error) {
+	column, err := r.NameIndex(name)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return r.GetDuration(row, column)
+}
+
+// GetDecimal returns the decimal value of given row and column number as string,
+// so that the precision will not be lost as converting to float64, null value is returned as empty string
+func (r *Result) GetDecimal(row, column int) (string, error) {
+	value, err := r.GetValue(row, column)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if value == nil {
+		return constant.EmptyString, nil
+	}
+
+	s, err := common.ConvertToString(value)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	_, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return constant.EmptyString, errors.New(fmt.Sprintf("value is not a valid decimal. value: %s", s))
+	}
+
+	return s, nil
+}
+
+// GetDecimalByName returns the decimal value of given row number and column name as string, see GetDecimal() for more details
+func (r *Result) GetDecimalByName(row int, name string) (string, error) {
+	column, err := r.NameIndex(name)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return r.GetDecimal(row, column)
+}
+
+// GetBool returns bool type value of given row and column number,
+// it supports tinyint(1) and bit(1) columns, null value is returned as false
+func (r *Result) GetBool(row, column int) (bool, error) {
+	value, err := r.GetValue(row, column)
+	if err != nil {
+		return false, err
+	}
+
+	return parseBool(value)
+}
+
+// GetBoolByName returns bool type value of given row number and column name, see GetBool() for more details
+func (r *Result) GetBoolByName(row int, name string) (bool, error) {
+	column, err := r.NameIndex(name)
+	if err != nil {
+		return false, err
+	}
+
+	return r.GetBool(row, column)
+}
+
+// parseTime parses the temporal value of mysql
+func parseTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	}
+
+	s, err := common.ConvertToString(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if s == constant.EmptyString || strings.HasPrefix(s, zeroDateString) {
+		return time.Time{}, nil
+	}
+	if len(s) == len(dateLayout) {
+		return time.ParseInLocation(dateLayout, s, time.Local)
+	}
+	if isTimeValue(s) {
+		d, err := parseDuration(s)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return time.Date(constant.ZeroInt, time.January, 1, constant.ZeroInt, constant.ZeroInt, constant.ZeroInt, constant.ZeroInt, time.Local).Add(d), nil
+	}
+
+	// the fractional seconds are optional when parsing with the layout of seconds
+	return time.ParseInLocation(constant.TimeLayoutSecond, s, time.Local)
+}
+
+// parseDuration parses the time value of mysql, which is in the style of [-][H]HH:MM:SS[.fraction],
+// the hours may be larger than 24, the range is from -838:59:59 to 838:59:59
+func parseDuration(value interface{}) (time.Duration, error) {
+	if value == nil {
+		return constant.ZeroInt, nil
+	}
+
+	s, err := common.ConvertToString(value)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	if s == constant.EmptyString {
+		return constant.ZeroInt, nil
+	}
+	if !isTimeValue(s) {
+		return constant.ZeroInt, errors.New(fmt.Sprintf("value is not a valid time. value: %s", s))
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	parts := strings.Split(strings.TrimPrefix(s, "-"), constant.ColonString)
+	d, err := time.ParseDuration(fmt.Sprintf("%sh%sm%ss", parts[0], parts[1], parts[2]))
+	if err != nil {
+		return constant.ZeroInt, errors.New(fmt.Sprintf("value is not a valid time. value: %s", s))
+	}
+	if negative {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// isTimeValue returns if the string is a time value but not a datetime value
+func isTimeValue(s string) bool {
+	return strings.Count(s, constant.ColonString) == 2 && !strings.Contains(strings.TrimPrefix(s, "-"), "-")
+}
+
+// parseBool parses the bool value of mysql, the bit value is returned as bytes
+func parseBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case []byte:
+		if len(v) == 1 && v[0] <= 1 {
+			// bit(1)
+			return v[0] == 1, nil
+		}
+		return parseBool(string(v))
+	case string:
+		switch strings.ToLower(v) {
+		case "1", "true":
+			return true, nil
+		case "0", "false":
+			return false, nil
+		}
+		return false, errors.New(fmt.Sprintf("can not convert to a valid bool value, %s is not valid", v))
+	}
+
+	return common.ConvertToBool(value)
+}
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"database/sql/driver"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/result"
 )
 
 type EnvInfo struct {
@@ -60,3 +62,60 @@ func TestResult(t *testing.T) {
 	err = result.MapToStructSlice(envInfoList, constant.DefaultMiddlewareTag)
 	asst.Nil(err, "map to struct failed")
 }
+
+func TestResult_TypedGetters(t *testing.T) {
+	asst := assert.New(t)
+
+	r := &Result{
+		Rows: result.NewRows(
+			[]string{"create_time", "birthday", "zero_time", "amount", "del_flag", "is_active", "deleted_time", "update_time", "elapsed"},
+			map[string]int{"create_time": 0, "birthday": 1, "zero_time": 2, "amount": 3, "del_flag": 4, "is_active": 5, "deleted_time": 6, "update_time": 7, "elapsed": 8},
+			[][]driver.Value{{
+				[]byte("2021-01-02 03:04:05.123456"), []byte("2021-01-02"), []byte("0000-00-00 00:00:00"),
+				[]byte("12345678901234567890.0123456789"), int64(1), []byte{0}, nil, []byte("2021-01-02 03:04:05"), []byte("-100:02:03.5"),
+			}},
+		),
+	}
+
+	createTime, err := r.GetTimeByName(0, "create_time")
+	asst.Nil(err, "test GetTimeByName() failed")
+	asst.Equal(time.Date(2021, 1, 2, 3, 4, 5, 123456000, time.Local), createTime, "test GetTimeByName() failed")
+	birthday, err := r.GetTimeByName(0, "birthday")
+	asst.Nil(err, "test GetTimeByName() failed")
+	asst.Equal(time.Date(2021, 1, 2, 0, 0, 0, 0, time.Local), birthday, "test GetTimeByName() failed")
+	zeroTime, err := r.GetTimeByName(0, "zero_time")
+	asst.Nil(err, "test GetTimeByName() failed")
+	asst.True(zeroTime.IsZero(), "test GetTimeByName() failed")
+	deletedTime, err := r.GetTimeByName(0, "deleted_time")
+	asst.Nil(err, "test GetTimeByName() failed")
+	asst.True(deletedTime.IsZero(), "test GetTimeByName() failed")
+	updateTime, err := r.GetTimeByName(0, "update_time")
+	asst.Nil(err, "test GetTimeByName() failed")
+	asst.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.Local), updateTime, "test GetTimeByName() failed")
+	elapsed, err := r.GetDurationByName(0, "elapsed")
+	asst.Nil(err, "test GetDurationByName() failed")
+	asst.Equal(-(100*time.Hour + 2*time.Minute + 3500*time.Millisecond), elapsed, "test GetDurationByName() failed")
+	elapsedTime, err := r.GetTimeByName(0, "elapsed")
+	asst.Nil(err, "test GetTimeByName() failed")
+	asst.Equal(elapsed, elapsedTime.Sub(time.Date(0, time.January, 1, 0, 0, 0, 0, time.Local)), "test GetTimeByName() failed")
+	_, err = r.GetDurationByName(0, "create_time")
+	asst.NotNil(err, "test GetDurationByName() failed")
+	isNull, err := r.IsNullByName(0, "deleted_time")
+	asst.Nil(err, "test IsNullByName() failed")
+	asst.True(isNull, "test IsNullByName() failed")
+
+	amount, err := r.GetDecimalByName(0, "amount")
+	asst.Nil(err, "test GetDecimalByName() failed")
+	asst.Equal("12345678901234567890.0123456789", amount, "test GetDecimalByName() failed")
+	_, err = r.GetDecimalByName(0, "birthday")
+	asst.NotNil(err, "test GetDecimalByName() failed")
+
+	delFlag, err := r.GetBoolByName(0, "del_flag")
+	asst.Nil(err, "test GetBoolByName() failed")
+	asst.True(delFlag, "test GetBoolByName() failed")
+	isActive, err := r.GetBoolByName(0, "is_active")
+	asst.Nil(err, "test GetBoolByName() failed")
+	asst.False(isActive, "test GetBoolByName() failed")
+	_, err = r.GetBoolByName(0, "amount")
+	asst.NotNil(err, "test GetBoolByName() failed")
+}
@@ -2,8 +2,11 @@ package mysql
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-mysql-org/go-mysql/client"
+
+	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
 )
 
@@ -11,11 +14,15 @@ var _ middleware.Statement = (*Statement)(nil)
 
 type Statement struct {
 	*client.Stmt
+	// conn is the connection which prepared the statement, it is used to propagate the context and invoke the hooks
+	conn *Conn
+	// command is the sql of the statement
+	command string
 }
 
 // NewStatement returns a new *Statement with given *client.Stmt
 func NewStatement(stmt *client.Stmt) *Statement {
-	return &Statement{stmt}
+	return &Statement{Stmt: stmt}
 }
 
 // Execute executes given sql and placeholders and returns a result
@@ -28,12 +35,32 @@ func (stmt *Statement) ExecuteContext(ctx context.Context, args ...interface{})
 	return stmt.executeContext(ctx, args...)
 }
 
-// executeContext executes given sql and placeholders with context and returns a result
+// executeContext executes given sql and placeholders with context and returns a result,
+// the context is propagated only if the statement is prepared by *Conn
 func (stmt *Statement) executeContext(ctx context.Context, args ...interface{}) (*Result, error) {
+	if stmt.conn == nil {
+		r, err := stmt.Stmt.Execute(args...)
+		if err != nil {
+			return nil, ClassifyError(err)
+		}
+
+		return NewResult(r), nil
+	}
+
+	start := time.Now()
+	stop, err := stmt.conn.watchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 	r, err := stmt.Stmt.Execute(args...)
+	err = stmt.conn.contextError(ctx, stop(), err)
 	if err != nil {
+		stmt.conn.runHooks(ctx, stmt.command, args, start, constant.ZeroInt, err)
 		return nil, err
 	}
 
-	return NewResult(r), nil
+	result := NewResult(r)
+	stmt.conn.runHooks(ctx, stmt.command, args, start, resultRows(result), nil)
+
+	return result, nil
 }
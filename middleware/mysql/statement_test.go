@@ -11,6 +11,7 @@ import (
 func TestStatementAll(t *testing.T) {
 	TestStatement_Execute(t)
 	TestStatement_ExecuteContext(t)
+	TestConn_PrepareCached(t)
 }
 
 func TestStatement_Execute(t *testing.T) {
@@ -65,3 +66,36 @@ func TestStatement_ExecuteContext(t *testing.T) {
 	err = dropTable()
 	asst.Nil(err, "test ExecuteContext() failed")
 }
+
+func TestConn_PrepareCached(t *testing.T) {
+	asst := assert.New(t)
+	ctx := context.Background()
+
+	// create table
+	err := createTable()
+	asst.Nil(err, "test PrepareCached() failed")
+	// the same sql should share the same statement
+	sql := `select id, name, col1, col2 from t10 where id = ?;`
+	stmt, err := conn.PrepareCached(ctx, sql)
+	asst.Nil(err, "test PrepareCached() failed")
+	cached, err := conn.PrepareCached(ctx, sql)
+	asst.Nil(err, "test PrepareCached() failed")
+	asst.True(stmt == cached, "test PrepareCached() failed")
+	_, err = cached.ExecuteContext(ctx, 1)
+	asst.Nil(err, "test PrepareCached() failed")
+	// the oldest statement should be evicted when the cache is full
+	err = conn.SetStatementCacheSize(1)
+	asst.Nil(err, "test PrepareCached() failed")
+	stmt, err = conn.PrepareCached(ctx, sql)
+	asst.Nil(err, "test PrepareCached() failed")
+	_, err = conn.PrepareCached(ctx, `select count(*) from t10;`)
+	asst.Nil(err, "test PrepareCached() failed")
+	cached, err = conn.PrepareCached(ctx, sql)
+	asst.Nil(err, "test PrepareCached() failed")
+	asst.False(stmt == cached, "test PrepareCached() failed")
+	err = conn.CloseStatements()
+	asst.Nil(err, "test PrepareCached() failed")
+	// drop table
+	err = dropTable()
+	asst.Nil(err, "test PrepareCached() failed")
+}
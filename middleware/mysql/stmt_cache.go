@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/romberli/go-util/constant"
+)
+
+// DefaultStatementCacheSize is the default maximum number of the cached prepared statements of a connection
+const DefaultStatementCacheSize = 100
+
+// stmtCache caches the prepared statements of a connection, the oldest statement will be closed
+// when the number of the statements exceeds the capacity
+type stmtCache struct {
+	mutex    sync.Mutex
+	capacity int
+	stmts    map[string]*Statement
+	// keys keeps the order of the statements being cached
+	keys []string
+}
+
+// newStmtCache returns a new *stmtCache
+func newStmtCache(capacity int) *stmtCache {
+	if capacity <= constant.ZeroInt {
+		capacity = DefaultStatementCacheSize
+	}
+
+	return &stmtCache{
+		capacity: capacity,
+		stmts:    make(map[string]*Statement),
+	}
+}
+
+// SetStatementCacheSize sets the maximum number of the cached prepared statements of the connection,
+// it closes the cached statements, so it should be called before the connection is used
+func (conn *Conn) SetStatementCacheSize(size int) error {
+	var err error
+	if conn.stmtCache != nil {
+		err = conn.stmtCache.closeAll()
+	}
+	conn.stmtCache = newStmtCache(size)
+
+	return err
+}
+
+// PrepareCached returns the cached prepared statement of given sql, if it is missing, the sql will be prepared and cached,
+// so that the hot-path sqls are parsed by the server only once, the returned statement must not be closed by the caller,
+// it will be closed when it is evicted from the cache or CloseStatements() is called
+func (conn *Conn) PrepareCached(ctx context.Context, command string) (*Statement, error) {
+	if conn.stmtCache == nil {
+		conn.stmtCache = newStmtCache(DefaultStatementCacheSize)
+	}
+
+	return conn.stmtCache.getOrPrepare(command, func() (*Statement, error) {
+		return conn.prepareContext(ctx, command)
+	})
+}
+
+// CloseStatements closes all the cached prepared statements of the connection
+func (conn *Conn) CloseStatements() error {
+	if conn.stmtCache == nil {
+		return nil
+	}
+
+	return conn.stmtCache.closeAll()
+}
+
+// getOrPrepare returns the cached statement of given sql, or prepares and caches it
+func (sc *stmtCache) getOrPrepare(command string, prepare func() (*Statement, error)) (*Statement, error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	stmt, ok := sc.stmts[command]
+	if ok {
+		return stmt, nil
+	}
+
+	stmt, err := prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sc.keys) >= sc.capacity {
+		oldest := sc.keys[constant.ZeroInt]
+		sc.keys = sc.keys[1:]
+		_ = sc.stmts[oldest].Close()
+		delete(sc.stmts, oldest)
+	}
+	sc.stmts[command] = stmt
+	sc.keys = append(sc.keys, command)
+
+	return stmt, nil
+}
+
+// closeAll closes all the cached statements and clears the cache
+func (sc *stmtCache) closeAll() error {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	var merr error
+	for _, stmt := range sc.stmts {
+		err := stmt.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	sc.stmts = make(map[string]*Statement)
+	sc.keys = nil
+
+	return merr
+}
@@ -0,0 +1,240 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/metrics"
+	"github.com/romberli/go-util/middleware/result"
+	"github.com/romberli/go-util/tracing"
+)
+
+// Stream is a row-at-a-time iterator of the result of a select statement,
+// the rows are read from the network on demand instead of being materialized in memory,
+// so it is suitable for exporting large tables. the connection must not be used by others until the stream is closed.
+//
+// Example:
+//
+//	stream, err := conn.ExecuteStream(ctx, "select id, name from t01")
+//	if err != nil {
+//		return err
+//	}
+//	defer func() { _ = stream.Close() }()
+//
+//	for stream.Next() {
+//		var (
+//			id   int
+//			name string
+//		)
+//		err = stream.Scan(&id, &name)
+//		if err != nil {
+//			return err
+//		}
+//	}
+//
+//	return stream.Err()
+type Stream struct {
+	rows   chan []driver.Value
+	closed chan struct{}
+	once   sync.Once
+
+	// mutex protects the columns and err which are set by the reading goroutine
+	mutex    sync.Mutex
+	columns  []string
+	fieldMap map[string]int
+	err      error
+
+	current *result.Rows
+}
+
+// ExecuteStream executes given select statement and returns a stream of the result,
+// the stream must be closed after using, otherwise, the connection could not be used anymore,
+// if the context is done while streaming, the running query will be killed
+func (conn *Conn) ExecuteStream(ctx context.Context, command string) (*Stream, error) {
+	span, _ := tracing.StartSpan(ctx, tracing.ComponentMySQL, tracing.OperationExecute, conn.Addr)
+	ext.DBType.Set(span, tracing.ComponentMySQL)
+	ext.DBInstance.Set(span, conn.DBName)
+	ext.DBStatement.Set(span, command)
+	start := time.Now()
+	stop, err := conn.watchContext(ctx)
+	if err != nil {
+		tracing.Finish(span, err)
+		return nil, err
+	}
+
+	stream := &Stream{
+		rows:   make(chan []driver.Value),
+		closed: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(stream.rows)
+
+		var (
+			r    mysql.Result
+			rows int
+		)
+		err := conn.Conn.ExecuteSelectStreaming(command, &r, func(row []mysql.FieldValue) error {
+			rows++
+			stream.setColumns(r.Fields)
+			if stream.isDiscarding(ctx) {
+				// the remaining rows must be read from the network, so that the connection could be reused
+				return nil
+			}
+
+			select {
+			case stream.rows <- copyFieldValues(row):
+			case <-stream.closed:
+			case <-ctx.Done():
+			}
+
+			return nil
+		})
+		stream.setColumns(r.Fields)
+
+		err = conn.contextError(ctx, stop(), err)
+		metrics.ObserveOperation(metrics.ComponentMySQL, metrics.OperationExecute, start, err)
+		tracing.Finish(span, err)
+		conn.runHooks(ctx, command, nil, start, rows, err)
+		stream.setErr(err)
+	}()
+
+	return stream, nil
+}
+
+// Next prepares the next row for reading, it returns false if there are no more rows or an error occurred,
+// Err() should be checked after Next() returns false
+func (s *Stream) Next() bool {
+	row, ok := <-s.rows
+	if !ok {
+		s.current = nil
+		return false
+	}
+
+	s.mutex.Lock()
+	s.current = result.NewRows(s.columns, s.fieldMap, [][]driver.Value{row})
+	s.mutex.Unlock()
+
+	return true
+}
+
+// Columns returns the column names of the result, it should be called after Next()
+func (s *Stream) Columns() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.columns
+}
+
+// Row returns the current row as a *result.Rows which contains only one row,
+// so the getters of result.Rows could be used with row number 0
+func (s *Stream) Row() (*result.Rows, error) {
+	if s.current == nil {
+		return nil, errors.New("there is no current row, Next() must be called before reading the row")
+	}
+
+	return s.current, nil
+}
+
+// Scan copies the columns of the current row into the values pointed at by dest,
+// the number of dest must be equal to the number of the columns, see result.Get() for the supported types of dest
+func (s *Stream) Scan(dest ...interface{}) error {
+	row, err := s.Row()
+	if err != nil {
+		return err
+	}
+	if len(dest) != row.ColumnNumber() {
+		return errors.New(fmt.Sprintf("number of dest(%d) is not equal to number of columns(%d)", len(dest), row.ColumnNumber()))
+	}
+
+	for i := range dest {
+		err = result.Get(row, constant.ZeroInt, i, dest[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Err returns the error of the query, it should be called after Next() returns false
+func (s *Stream) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.err
+}
+
+// Close discards the remaining rows and waits for the query to finish, it returns the error of the query,
+// discarding the rows still needs to read them from the network, cancel the context to kill a long-running query
+func (s *Stream) Close() error {
+	s.once.Do(func() {
+		close(s.closed)
+	})
+	for range s.rows {
+	}
+	s.current = nil
+
+	return s.Err()
+}
+
+// isDiscarding returns if the rows should be discarded
+func (s *Stream) isDiscarding(ctx context.Context) bool {
+	select {
+	case <-s.closed:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// setColumns sets the column names with the fields of the result, it only takes effect at the first time
+func (s *Stream) setColumns(fields []*mysql.Field) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.columns != nil {
+		return
+	}
+
+	s.columns = make([]string, len(fields))
+	s.fieldMap = make(map[string]int, len(fields))
+	for i, field := range fields {
+		s.columns[i] = string(field.Name)
+		s.fieldMap[string(field.Name)] = i
+	}
+}
+
+// setErr sets the error of the query
+func (s *Stream) setErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.err = err
+}
+
+// copyFieldValues converts the field values to driver values,
+// the bytes are copied because they will be reused by the next row
+func copyFieldValues(row []mysql.FieldValue) []driver.Value {
+	values := make([]driver.Value, len(row))
+	for i := range row {
+		value := row[i].Value()
+		b, ok := value.([]byte)
+		if ok {
+			value = append([]byte(nil), b...)
+		}
+		values[i] = value
+	}
+
+	return values
+}
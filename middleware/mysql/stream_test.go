@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
+)
+
+func TestConn_ExecuteStream(t *testing.T) {
+	asst := assert.New(t)
+	ctx := context.Background()
+
+	// create table
+	err := createTable()
+	asst.Nil(err, "test ExecuteStream() failed")
+	// insert data
+	sql := `insert into t05(name, col1, col2) values(?, ?, ?), (?, ?, ?);`
+	_, err = conn.Execute(sql, "a", 1, 1.1, "b", 2, 2.2)
+	asst.Nil(err, "test ExecuteStream() failed")
+	// stream all the rows
+	stream, err := conn.ExecuteStream(ctx, `select name, col1 from t05 order by id;`)
+	asst.Nil(err, "test ExecuteStream() failed")
+	var names []string
+	for stream.Next() {
+		var (
+			name string
+			col1 int
+		)
+		err = stream.Scan(&name, &col1)
+		asst.Nil(err, "test ExecuteStream() failed")
+		names = append(names, name)
+	}
+	asst.Nil(stream.Err(), "test ExecuteStream() failed")
+	asst.Equal([]string{"name", "col1"}, stream.Columns(), "test ExecuteStream() failed")
+	asst.Equal([]string{"a", "b"}, names, "test ExecuteStream() failed")
+	asst.Nil(stream.Close(), "test ExecuteStream() failed")
+	// close the stream before reading all the rows, the connection should still be usable
+	stream, err = conn.ExecuteStream(ctx, `select name from t05 order by id;`)
+	asst.Nil(err, "test ExecuteStream() failed")
+	asst.True(stream.Next(), "test ExecuteStream() failed")
+	row, err := stream.Row()
+	asst.Nil(err, "test ExecuteStream() failed")
+	name, err := row.GetStringByName(constant.ZeroInt, "name")
+	asst.Nil(err, "test ExecuteStream() failed")
+	asst.Equal("a", name, "test ExecuteStream() failed")
+	asst.Nil(stream.Close(), "test ExecuteStream() failed")
+	_, err = conn.Execute(`select 1;`)
+	asst.Nil(err, "test ExecuteStream() failed")
+	// drop table
+	err = dropTable()
+	asst.Nil(err, "test ExecuteStream() failed")
+}
@@ -0,0 +1,205 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+type IsolationLevel string
+
+const (
+	// IsolationDefault uses the isolation level of the session
+	IsolationDefault         IsolationLevel = ""
+	IsolationReadUncommitted IsolationLevel = "READ UNCOMMITTED"
+	IsolationReadCommitted   IsolationLevel = "READ COMMITTED"
+	IsolationRepeatableRead  IsolationLevel = "REPEATABLE READ"
+	IsolationSerializable    IsolationLevel = "SERIALIZABLE"
+
+	SetIsolationLevelSQL   = "set transaction isolation level %s"
+	StartTransactionSQL    = "start transaction"
+	StartReadOnlySQL       = "start transaction read only"
+	SavepointSQL           = "savepoint %s"
+	RollbackToSavepointSQL = "rollback to savepoint %s"
+	ReleaseSavepointSQL    = "release savepoint %s"
+	backtickString         = "`"
+	escapedBacktickString  = "``"
+	savepointNameMaxLength = 64
+)
+
+// ErrTxDone is returned when operating on a transaction which had been committed or rolled back
+var ErrTxDone = errors.New("transaction had already been committed or rolled back")
+
+type TxOptions struct {
+	Isolation IsolationLevel
+	ReadOnly  bool
+}
+
+// NewTxOptions returns a new *TxOptions
+func NewTxOptions(isolation IsolationLevel, readOnly bool) *TxOptions {
+	return &TxOptions{
+		Isolation: isolation,
+		ReadOnly:  readOnly,
+	}
+}
+
+// beginSQLs returns the sqls which should be executed to begin the transaction
+func (opts *TxOptions) beginSQLs() ([]string, error) {
+	if opts == nil {
+		return []string{StartTransactionSQL}, nil
+	}
+
+	var sqls []string
+	switch opts.Isolation {
+	case IsolationDefault:
+	case IsolationReadUncommitted, IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable:
+		sqls = append(sqls, fmt.Sprintf(SetIsolationLevelSQL, opts.Isolation))
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported isolation level: %s", opts.Isolation))
+	}
+
+	if opts.ReadOnly {
+		return append(sqls, StartReadOnlySQL), nil
+	}
+
+	return append(sqls, StartTransactionSQL), nil
+}
+
+// Tx is a transaction of the connection, the connection should not be used by others until the transaction finishes
+type Tx struct {
+	conn *Conn
+	ctx  context.Context
+
+	// mutex serializes the statements of the transaction and the rollback triggered by the context
+	mutex    sync.Mutex
+	finished bool
+	done     chan struct{}
+}
+
+// BeginTx begins a transaction with given options, if opts is nil, the default options of the session will be used,
+// if the context is done before the transaction finishes, the transaction will be rolled back automatically
+func (conn *Conn) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	sqls, err := opts.beginSQLs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sql := range sqls {
+		_, err = conn.executeContext(ctx, sql)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx := &Tx{
+		conn: conn,
+		ctx:  ctx,
+		done: make(chan struct{}),
+	}
+	if ctx.Done() != nil {
+		go tx.watch()
+	}
+
+	return tx, nil
+}
+
+// watch rolls back the transaction when the context is done
+func (tx *Tx) watch() {
+	select {
+	case <-tx.done:
+	case <-tx.ctx.Done():
+		err := tx.finish(false)
+		if err != nil && err != ErrTxDone {
+			log.Errorf("mysql: rollback transaction after the context is done failed. addr: %s. %s", tx.conn.Addr, err.Error())
+		}
+	}
+}
+
+// Execute executes given sql and placeholders in the transaction with the context of the transaction
+func (tx *Tx) Execute(command string, args ...interface{}) (*Result, error) {
+	return tx.ExecuteContext(tx.ctx, command, args...)
+}
+
+// ExecuteContext executes given sql and placeholders in the transaction with given context
+func (tx *Tx) ExecuteContext(ctx context.Context, command string, args ...interface{}) (*Result, error) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.finished {
+		return nil, ErrTxDone
+	}
+
+	return tx.conn.executeContext(ctx, command, args...)
+}
+
+// Commit commits the transaction
+func (tx *Tx) Commit() error {
+	return tx.finish(true)
+}
+
+// Rollback rollbacks the transaction, it returns ErrTxDone if the transaction had been finished
+func (tx *Tx) Rollback() error {
+	return tx.finish(false)
+}
+
+// finish commits or rollbacks the transaction
+func (tx *Tx) finish(commit bool) error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.finished {
+		return ErrTxDone
+	}
+	tx.finished = true
+	close(tx.done)
+
+	var err error
+	if commit {
+		err = tx.conn.Conn.Commit()
+	} else {
+		err = tx.conn.Conn.Rollback()
+	}
+
+	return ClassifyError(err)
+}
+
+// Savepoint creates a savepoint with given name in the transaction
+func (tx *Tx) Savepoint(name string) error {
+	return tx.executeSavepoint(SavepointSQL, name)
+}
+
+// RollbackTo rollbacks the transaction to the savepoint of given name, the transaction is still active after that
+func (tx *Tx) RollbackTo(name string) error {
+	return tx.executeSavepoint(RollbackToSavepointSQL, name)
+}
+
+// ReleaseSavepoint removes the savepoint of given name from the transaction
+func (tx *Tx) ReleaseSavepoint(name string) error {
+	return tx.executeSavepoint(ReleaseSavepointSQL, name)
+}
+
+// executeSavepoint executes the savepoint sql with the quoted savepoint name
+func (tx *Tx) executeSavepoint(sql, name string) error {
+	quoted, err := quoteSavepointName(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Execute(fmt.Sprintf(sql, quoted))
+
+	return err
+}
+
+// quoteSavepointName quotes the savepoint name with backticks
+func quoteSavepointName(name string) (string, error) {
+	if name == constant.EmptyString || len(name) > savepointNameMaxLength {
+		return constant.EmptyString, errors.New(fmt.Sprintf("savepoint name should not be empty or longer than %d characters. name: %s", savepointNameMaxLength, name))
+	}
+
+	return quoteIdentifier(name), nil
+}
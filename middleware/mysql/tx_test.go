@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxOptions_beginSQLs(t *testing.T) {
+	asst := assert.New(t)
+
+	var opts *TxOptions
+	sqls, err := opts.beginSQLs()
+	asst.Nil(err, "test beginSQLs() failed")
+	asst.Equal([]string{StartTransactionSQL}, sqls, "test beginSQLs() failed")
+
+	sqls, err = NewTxOptions(IsolationReadCommitted, true).beginSQLs()
+	asst.Nil(err, "test beginSQLs() failed")
+	asst.Equal([]string{"set transaction isolation level READ COMMITTED", StartReadOnlySQL}, sqls, "test beginSQLs() failed")
+
+	_, err = NewTxOptions("SNAPSHOT", false).beginSQLs()
+	asst.NotNil(err, "test beginSQLs() failed")
+}
+
+func TestQuoteSavepointName(t *testing.T) {
+	asst := assert.New(t)
+
+	quoted, err := quoteSavepointName("sp`1")
+	asst.Nil(err, "test quoteSavepointName() failed")
+	asst.Equal("`sp``1`", quoted, "test quoteSavepointName() failed")
+	_, err = quoteSavepointName("")
+	asst.NotNil(err, "test quoteSavepointName() failed")
+}
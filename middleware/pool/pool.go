@@ -0,0 +1,511 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultMaxConnections     = 20
+	DefaultInitConnections    = 5
+	DefaultMaxIdleConnections = 10
+	DefaultMaxIdleTime        = 1800 // seconds
+	DefaultKeepAliveInterval  = 300  // seconds
+	DefaultKeepAliveChunkSize = 5
+	DefaultMaintainInterval   = time.Second
+)
+
+var (
+	ErrPoolClosed  = errors.New("pool had been closed")
+	ErrPoolTimeout = errors.New("waiting for a free connection timed out")
+)
+
+// Conn is the connection managed by the pool, each middleware wraps its own connection to implement it
+type Conn interface {
+	// Disconnect disconnects from the middleware
+	Disconnect() error
+	// IsValid validates if connection is valid
+	IsValid() bool
+}
+
+// Factory creates a new connection
+type Factory func() (Conn, error)
+
+type Config struct {
+	MaxConnections     int
+	InitConnections    int
+	MaxIdleConnections int
+	// MaxIdleTime is in seconds, idle connections exceeding MaxIdleConnections will be released after this time
+	MaxIdleTime int
+	// KeepAliveInterval is in seconds, idle connections will be validated at this interval
+	KeepAliveInterval int
+	// WaitTimeout is the time to wait for a free connection when used connections reached maximum,
+	// 0 means returning an error immediately
+	WaitTimeout time.Duration
+}
+
+// NewConfig returns a new Config
+func NewConfig(maxConnections, initConnections, maxIdleConnections, maxIdleTime, keepAliveInterval int) Config {
+	return Config{
+		MaxConnections:     maxConnections,
+		InitConnections:    initConnections,
+		MaxIdleConnections: maxIdleConnections,
+		MaxIdleTime:        maxIdleTime,
+		KeepAliveInterval:  keepAliveInterval,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with default values
+func NewConfigWithDefault() Config {
+	return NewConfig(DefaultMaxConnections, DefaultInitConnections, DefaultMaxIdleConnections, DefaultMaxIdleTime, DefaultKeepAliveInterval)
+}
+
+// Validate validates pool config
+func (cfg *Config) Validate() (bool, error) {
+	// validate MaxConnections
+	if cfg.MaxConnections <= constant.ZeroInt {
+		return false, errors.New("maximum connection argument should larger than 0")
+	}
+	// validate InitConnections
+	if cfg.InitConnections < constant.ZeroInt {
+		return false, errors.New("init connection argument should not be smaller than 0")
+	}
+	if cfg.InitConnections > cfg.MaxConnections {
+		return false, errors.New("init connection argument should not be larger than maximum connection argument")
+	}
+	// validate MaxIdleConnections
+	if cfg.MaxIdleConnections < constant.ZeroInt {
+		return false, errors.New("maximum idle connection argument should not be smaller than 0")
+	}
+	if cfg.MaxIdleConnections > cfg.MaxConnections {
+		return false, errors.New("maximum idle connection argument should not be larger than maximum connection argument")
+	}
+	// validate MaxIdleTime
+	if cfg.MaxIdleTime <= constant.ZeroInt {
+		return false, errors.New("maximum idle time argument should be larger than 0")
+	}
+	// validate KeepAliveInterval
+	if cfg.KeepAliveInterval <= constant.ZeroInt {
+		return false, errors.New("keep alive interval argument should be larger than 0")
+	}
+	// validate WaitTimeout
+	if cfg.WaitTimeout < constant.ZeroInt {
+		return false, errors.New("wait timeout argument should not be smaller than 0")
+	}
+
+	return true, nil
+}
+
+// Stats is the statistics of the pool
+type Stats struct {
+	MaxConnections int
+	// InUse is the number of connections which are being used
+	InUse int
+	// Idle is the number of connections in the pool
+	Idle int
+	// Created is the total number of connections created
+	Created int64
+	// Closed is the total number of connections closed
+	Closed int64
+	// WaitCount is the total number of times waiting for a free connection
+	WaitCount int64
+	// WaitDuration is the total time waiting for a free connection
+	WaitDuration time.Duration
+	// Timeouts is the total number of times timed out when waiting for a free connection
+	Timeouts int64
+}
+
+// idleConn is the connection in the pool with the time it was put back
+type idleConn struct {
+	conn       Conn
+	returnedAt time.Time
+}
+
+// Pool is a goroutine-safe connection pool, it does not know about the middleware,
+// the connections are created by the factory and validated by Conn.IsValid()
+type Pool struct {
+	sync.Mutex
+	Config
+	factory Factory
+	// tokens limits the number of connections in use
+	tokens        chan struct{}
+	idleConns     []*idleConn
+	stats         Stats
+	keepAliveTime time.Time
+	isClosed      bool
+	stopChan      chan struct{}
+}
+
+// NewPool returns a new *Pool, it creates initial connections and starts maintaining the pool in background
+func NewPool(config Config, factory Factory) (*Pool, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+	if factory == nil {
+		return nil, errors.New("factory should not be nil")
+	}
+
+	p := &Pool{
+		Config:        config,
+		factory:       factory,
+		tokens:        make(chan struct{}, config.MaxConnections),
+		keepAliveTime: time.Now().Add(time.Duration(config.KeepAliveInterval) * time.Second),
+		stopChan:      make(chan struct{}),
+	}
+	p.stats.MaxConnections = config.MaxConnections
+
+	err = p.Supply(config.InitConnections)
+	if err != nil {
+		return nil, err
+	}
+
+	go p.maintain()
+
+	return p, nil
+}
+
+// IsClosed returns if pool had been closed
+func (p *Pool) IsClosed() bool {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.isClosed
+}
+
+// UsedConnections returns used connection number
+func (p *Pool) UsedConnections() int {
+	return len(p.tokens)
+}
+
+// Stats returns the statistics of the pool
+func (p *Pool) Stats() Stats {
+	p.Lock()
+	defer p.Unlock()
+
+	stats := p.stats
+	stats.InUse = len(p.tokens)
+	stats.Idle = len(p.idleConns)
+
+	return stats
+}
+
+// Get gets a connection from the pool, if used connections reached maximum,
+// it waits for WaitTimeout or returns an error immediately if WaitTimeout is 0
+func (p *Pool) Get() (Conn, error) {
+	if p.WaitTimeout <= constant.ZeroInt {
+		return p.GetContext(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.WaitTimeout)
+	defer cancel()
+
+	return p.GetContext(ctx)
+}
+
+// GetContext gets a connection from the pool, if used connections reached maximum,
+// it waits until a connection is put back or the context is done,
+// if the context has no deadline and WaitTimeout is 0, it returns an error immediately
+func (p *Pool) GetContext(ctx context.Context) (Conn, error) {
+	if p.IsClosed() {
+		return nil, ErrPoolClosed
+	}
+
+	err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := p.getIdleOrCreate()
+	if err != nil {
+		<-p.tokens
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// acquire acquires a token, it blocks if there is no free token
+func (p *Pool) acquire(ctx context.Context) error {
+	select {
+	case p.tokens <- struct{}{}:
+		return nil
+	default:
+	}
+
+	_, hasDeadline := ctx.Deadline()
+	if !hasDeadline && ctx.Done() == nil {
+		return errors.New(fmt.Sprintf("used connection(%d) had reached maximum connection(%d)", len(p.tokens), p.MaxConnections))
+	}
+
+	start := time.Now()
+	select {
+	case p.tokens <- struct{}{}:
+		p.recordWait(start, false)
+		return nil
+	case <-ctx.Done():
+		p.recordWait(start, true)
+		return ErrPoolTimeout
+	case <-p.stopChan:
+		return ErrPoolClosed
+	}
+}
+
+// recordWait records the waiting statistics
+func (p *Pool) recordWait(start time.Time, timeout bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.stats.WaitCount++
+	p.stats.WaitDuration += time.Since(start)
+	if timeout {
+		p.stats.Timeouts++
+	}
+}
+
+// getIdleOrCreate returns a valid idle connection, if there is no one, it creates a new connection
+func (p *Pool) getIdleOrCreate() (Conn, error) {
+	for {
+		p.Lock()
+		if p.isClosed {
+			p.Unlock()
+			return nil, ErrPoolClosed
+		}
+		if len(p.idleConns) == constant.ZeroInt {
+			p.Unlock()
+			break
+		}
+		// the most recently used connection is more likely to be valid
+		ic := p.idleConns[len(p.idleConns)-1]
+		p.idleConns = p.idleConns[:len(p.idleConns)-1]
+		p.Unlock()
+
+		if ic.conn.IsValid() {
+			return ic.conn, nil
+		}
+		p.disconnect(ic.conn)
+	}
+
+	return p.create()
+}
+
+// create creates a new connection with the factory
+func (p *Pool) create() (Conn, error) {
+	conn, err := p.factory()
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	p.stats.Created++
+	p.Unlock()
+
+	return conn, nil
+}
+
+// disconnect disconnects the connection and records the statistics
+func (p *Pool) disconnect(conn Conn) error {
+	p.Lock()
+	p.stats.Closed++
+	p.Unlock()
+
+	return conn.Disconnect()
+}
+
+// Put puts the connection back to the pool, if the pool had been closed, the connection will be disconnected
+func (p *Pool) Put(conn Conn) error {
+	p.Lock()
+	if p.isClosed {
+		p.Unlock()
+		p.release()
+		return p.disconnect(conn)
+	}
+
+	p.idleConns = append(p.idleConns, &idleConn{conn: conn, returnedAt: time.Now()})
+	p.Unlock()
+	p.release()
+
+	return nil
+}
+
+// Discard disconnects the connection instead of putting it back to the pool, it should be used when the connection is broken
+func (p *Pool) Discard(conn Conn) error {
+	p.release()
+
+	return p.disconnect(conn)
+}
+
+// release releases a token
+func (p *Pool) release() {
+	select {
+	case <-p.tokens:
+	default:
+	}
+}
+
+// Supply creates given number of connections and adds them to the pool,
+// total connections will not exceed maximum connections
+func (p *Pool) Supply(num int) error {
+	merr := &multierror.Error{}
+
+	for i := 0; i < num; i++ {
+		p.Lock()
+		if p.isClosed || len(p.tokens)+len(p.idleConns) >= p.MaxConnections {
+			p.Unlock()
+			break
+		}
+		p.Unlock()
+
+		conn, err := p.create()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+			continue
+		}
+
+		p.Lock()
+		p.idleConns = append(p.idleConns, &idleConn{conn: conn, returnedAt: time.Now()})
+		p.Unlock()
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// Release disconnects given number of idle connections, the least recently used connections will be released first
+func (p *Pool) Release(num int) error {
+	p.Lock()
+	if num > len(p.idleConns) {
+		num = len(p.idleConns)
+	}
+	conns := make([]*idleConn, num)
+	copy(conns, p.idleConns[:num])
+	p.idleConns = p.idleConns[num:]
+	p.Unlock()
+
+	return p.disconnectAll(conns)
+}
+
+// Close disconnects all idle connections, connections in use will be disconnected when they are put back
+func (p *Pool) Close() error {
+	p.Lock()
+	if p.isClosed {
+		p.Unlock()
+		return nil
+	}
+	p.isClosed = true
+	close(p.stopChan)
+	conns := p.idleConns
+	p.idleConns = nil
+	p.Unlock()
+
+	return p.disconnectAll(conns)
+}
+
+// disconnectAll disconnects given idle connections
+func (p *Pool) disconnectAll(conns []*idleConn) error {
+	merr := &multierror.Error{}
+
+	for _, ic := range conns {
+		err := p.disconnect(ic.conn)
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// maintain maintains the pool periodically until the pool is closed,
+// it keeps alive idle connections, supplies connections to InitConnections,
+// and releases connections exceeding MaxIdleConnections which had been idle for MaxIdleTime.
+// for saving disk purpose, if there are errors when maintaining the pool, it will log with debug level
+func (p *Pool) maintain() {
+	ticker := time.NewTicker(DefaultMaintainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		if now.After(p.keepAliveTime) {
+			p.keepAliveTime = now.Add(time.Duration(p.KeepAliveInterval) * time.Second)
+			err := p.keepAlive(DefaultKeepAliveChunkSize)
+			if err != nil {
+				log.Debugf("got error when keeping alive connections of the pool. %s", err.Error())
+			}
+		}
+
+		p.Lock()
+		num := p.InitConnections - len(p.tokens) - len(p.idleConns)
+		p.Unlock()
+		if num > constant.ZeroInt {
+			err := p.Supply(num)
+			if err != nil {
+				log.Debugf("got error when supplying connections to the pool. total: %d. %s", num, err.Error())
+			}
+		}
+
+		err := p.releaseExpired(now)
+		if err != nil {
+			log.Debugf("got error when releasing connections of the pool. %s", err.Error())
+		}
+	}
+}
+
+// keepAlive validates given number of least recently used idle connections, invalid connections will be disconnected
+func (p *Pool) keepAlive(num int) error {
+	p.Lock()
+	if num > len(p.idleConns) {
+		num = len(p.idleConns)
+	}
+	conns := make([]*idleConn, num)
+	copy(conns, p.idleConns[:num])
+	p.idleConns = p.idleConns[num:]
+	p.Unlock()
+
+	var valid, invalid []*idleConn
+	for _, ic := range conns {
+		if ic.conn.IsValid() {
+			valid = append(valid, ic)
+			continue
+		}
+		invalid = append(invalid, ic)
+	}
+
+	p.Lock()
+	if p.isClosed {
+		invalid = append(invalid, valid...)
+	} else {
+		// put the valid connections back to the head, so that the least recently used connections could still be released first
+		p.idleConns = append(valid, p.idleConns...)
+	}
+	p.Unlock()
+
+	return p.disconnectAll(invalid)
+}
+
+// releaseExpired releases the idle connections exceeding MaxIdleConnections which had been idle for MaxIdleTime
+func (p *Pool) releaseExpired(now time.Time) error {
+	maxIdleTime := time.Duration(p.MaxIdleTime) * time.Second
+
+	p.Lock()
+	var expired []*idleConn
+	for len(p.idleConns) > p.MaxIdleConnections && now.Sub(p.idleConns[0].returnedAt) >= maxIdleTime {
+		expired = append(expired, p.idleConns[0])
+		p.idleConns = p.idleConns[1:]
+	}
+	p.Unlock()
+
+	return p.disconnectAll(expired)
+}
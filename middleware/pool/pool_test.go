@@ -0,0 +1,116 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConn struct {
+	valid        int32
+	disconnected int32
+}
+
+func (tc *testConn) Disconnect() error {
+	atomic.StoreInt32(&tc.disconnected, 1)
+
+	return nil
+}
+
+func (tc *testConn) IsValid() bool {
+	return atomic.LoadInt32(&tc.valid) == 1
+}
+
+func testFactory() (Conn, error) {
+	return &testConn{valid: 1}, nil
+}
+
+func TestPool_Get(t *testing.T) {
+	asst := assert.New(t)
+
+	p, err := NewPool(NewConfig(2, 1, 1, 1, 1), testFactory)
+	asst.Nil(err, "test NewPool() failed")
+	defer func() { _ = p.Close() }()
+	asst.Equal(1, p.Stats().Idle, "test NewPool() failed")
+
+	c1, err := p.Get()
+	asst.Nil(err, "test Get() failed")
+	c2, err := p.Get()
+	asst.Nil(err, "test Get() failed")
+	_, err = p.Get()
+	asst.NotNil(err, "test Get() failed")
+	asst.Equal(2, p.UsedConnections(), "test Get() failed")
+
+	// invalid connection will not be reused
+	c1.(*testConn).valid = 0
+	asst.Nil(p.Put(c1), "test Put() failed")
+	asst.Nil(p.Put(c2), "test Put() failed")
+	c3, err := p.Get()
+	asst.Nil(err, "test Get() failed")
+	asst.Equal(c2, c3, "test Get() failed")
+	c4, err := p.Get()
+	asst.Nil(err, "test Get() failed")
+	asst.NotEqual(c1, c4, "test Get() failed")
+	asst.Equal(int32(1), c1.(*testConn).disconnected, "test Get() failed")
+
+	asst.Nil(p.Discard(c4), "test Discard() failed")
+	asst.Equal(1, p.UsedConnections(), "test Discard() failed")
+
+	stats := p.Stats()
+	asst.Equal(3, int(stats.Created), "test Stats() failed")
+	asst.Equal(2, int(stats.Closed), "test Stats() failed")
+}
+
+func TestPool_GetContext(t *testing.T) {
+	asst := assert.New(t)
+
+	config := NewConfig(1, 0, 1, 1, 1)
+	config.WaitTimeout = 20 * time.Millisecond
+	p, err := NewPool(config, testFactory)
+	asst.Nil(err, "test NewPool() failed")
+	defer func() { _ = p.Close() }()
+
+	c1, err := p.Get()
+	asst.Nil(err, "test Get() failed")
+	_, err = p.Get()
+	asst.Equal(ErrPoolTimeout, err, "test Get() failed")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = p.Put(c1)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c2, err := p.GetContext(ctx)
+	asst.Nil(err, "test GetContext() failed")
+	asst.Equal(c1, c2, "test GetContext() failed")
+
+	stats := p.Stats()
+	asst.Equal(2, int(stats.WaitCount), "test Stats() failed")
+	asst.Equal(1, int(stats.Timeouts), "test Stats() failed")
+}
+
+func TestPool_Close(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := NewPool(NewConfig(1, 1, 1, 1, 1), func() (Conn, error) { return nil, errors.New("test") })
+	asst.NotNil(err, "test NewPool() failed")
+
+	p, err := NewPool(NewConfig(2, 2, 2, 1, 1), testFactory)
+	asst.Nil(err, "test NewPool() failed")
+	c1, err := p.Get()
+	asst.Nil(err, "test Get() failed")
+	asst.Nil(p.Release(1), "test Release() failed")
+	asst.Equal(0, p.Stats().Idle, "test Release() failed")
+
+	asst.Nil(p.Close(), "test Close() failed")
+	asst.True(p.IsClosed(), "test Close() failed")
+	_, err = p.Get()
+	asst.Equal(ErrPoolClosed, err, "test Get() failed")
+	asst.Nil(p.Put(c1), "test Put() failed")
+	asst.Equal(int32(1), c1.(*testConn).disconnected, "test Put() failed")
+}
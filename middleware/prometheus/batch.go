@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultBatchConcurrency = 10
+
+type Query struct {
+	// Name is the key of the result in the batch, if it is empty, the command will be used as the key
+	Name    string
+	Command string
+	// Args are the same as the arguments of Execute()
+	Args []interface{}
+}
+
+// NewQuery returns a new Query
+func NewQuery(name, command string, args ...interface{}) Query {
+	return Query{
+		Name:    name,
+		Command: command,
+		Args:    args,
+	}
+}
+
+// Key returns the key of the query in the batch
+func (q Query) Key() string {
+	if q.Name == constant.EmptyString {
+		return q.Command
+	}
+
+	return q.Name
+}
+
+type BatchResult struct {
+	Query  Query
+	Result *Result
+	Err    error
+}
+
+// ExecuteBatch executes the queries concurrently with DefaultBatchConcurrency workers,
+// see ExecuteBatchWithConcurrency() for more details
+func (conn *Conn) ExecuteBatch(ctx context.Context, queries []Query) (map[string]*BatchResult, error) {
+	return conn.ExecuteBatchWithConcurrency(ctx, DefaultBatchConcurrency, queries)
+}
+
+// ExecuteBatchWithConcurrency executes the queries concurrently with at most concurrency workers,
+// it returns the results keyed by Query.Key(), the failure of one query does not affect the others,
+// so the error of each query should be checked with BatchResult.Err,
+// it returns error only if the keys of the queries are duplicate
+func (conn *Conn) ExecuteBatchWithConcurrency(ctx context.Context, concurrency int, queries []Query) (map[string]*BatchResult, error) {
+	results := make(map[string]*BatchResult, len(queries))
+	for _, query := range queries {
+		key := query.Key()
+		_, exists := results[key]
+		if exists {
+			return nil, errors.New(fmt.Sprintf("duplicate query key in the batch. key: %s", key))
+		}
+		results[key] = &BatchResult{Query: query}
+	}
+
+	if concurrency <= constant.ZeroInt {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > len(queries) {
+		concurrency = len(queries)
+	}
+
+	queryChan := make(chan *BatchResult, len(queries))
+	for _, query := range queries {
+		queryChan <- results[query.Key()]
+	}
+	close(queryChan)
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+
+			for br := range queryChan {
+				if ctx.Err() != nil {
+					br.Err = ctx.Err()
+					continue
+				}
+				br.Result, br.Err = conn.ExecuteContext(ctx, br.Query.Command, br.Query.Args...)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
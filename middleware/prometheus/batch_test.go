@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
+)
+
+func TestConn_ExecuteBatch(t *testing.T) {
+	asst := assert.New(t)
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		query := r.FormValue("query")
+		if query == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"success","data":{"resultType":"scalar","result":[1600000000,"%s"]}}`, query)))
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test ExecuteBatch() failed")
+
+	queries := []Query{NewQuery("bad", "bad")}
+	for i := 0; i < 6; i++ {
+		queries = append(queries, NewQuery(constant.EmptyString, fmt.Sprintf("%d", i)))
+	}
+	results, err := c.ExecuteBatchWithConcurrency(context.Background(), 2, queries)
+	asst.Nil(err, "test ExecuteBatch() failed")
+	asst.Equal(len(queries), len(results), "test ExecuteBatch() failed")
+	asst.NotNil(results["bad"].Err, "test ExecuteBatch() failed")
+	for i := 0; i < 6; i++ {
+		br := results[fmt.Sprintf("%d", i)]
+		asst.Nil(br.Err, "test ExecuteBatch() failed")
+		value, err := br.Result.GetInt(constant.ZeroInt, constant.ZeroInt)
+		asst.Nil(err, "test ExecuteBatch() failed")
+		asst.Equal(i, value, "test ExecuteBatch() failed")
+	}
+	asst.True(atomic.LoadInt32(&maxInFlight) <= 2, "test ExecuteBatch() failed")
+
+	_, err = c.ExecuteBatch(context.Background(), []Query{NewQuery("q", "1"), NewQuery("q", "2")})
+	asst.NotNil(err, "test ExecuteBatch() failed")
+}
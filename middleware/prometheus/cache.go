@@ -0,0 +1,171 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/cache"
+	"github.com/romberli/go-util/constant"
+)
+
+// QueryCache caches the query results of the connection, it is useful when the same expensive queries
+// are executed frequently, for example, by the dashboards, note that the queries without time arguments
+// use the current time, so the cached results may be stale up to ttl
+type QueryCache struct {
+	conn     *Conn
+	cache    *cache.LRU
+	ttl      time.Duration
+	staleTTL time.Duration
+
+	mutex      sync.Mutex
+	refreshing map[string]struct{}
+}
+
+// cachedResult is the cached result with the time when it becomes stale
+type cachedResult struct {
+	result     *Result
+	freshUntil time.Time
+}
+
+// NewQueryCache returns a new *QueryCache which holds at most capacity results, each result expires after ttl
+func NewQueryCache(conn *Conn, capacity int, ttl time.Duration) *QueryCache {
+	return NewQueryCacheWithStale(conn, capacity, ttl, constant.ZeroInt)
+}
+
+// NewQueryCacheWithStale returns a new *QueryCache which holds at most capacity results,
+// each result is fresh in ttl, and after that, it is still served for staleTTL while it is refreshed in the background,
+// so that the callers do not wait for the expensive queries when the result becomes stale
+func NewQueryCacheWithStale(conn *Conn, capacity int, ttl, staleTTL time.Duration) *QueryCache {
+	return &QueryCache{
+		conn:       conn,
+		cache:      cache.NewLRU(capacity, ttl+staleTTL),
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		refreshing: make(map[string]struct{}),
+	}
+}
+
+// Execute executes given command with arguments and returns a result, the result may be cached
+func (qc *QueryCache) Execute(command string, args ...interface{}) (*Result, error) {
+	return qc.ExecuteContext(context.Background(), command, args...)
+}
+
+// ExecuteContext executes given command with arguments and returns a result, the result may be cached,
+// the concurrent executions of the same query share one request to prometheus
+func (qc *QueryCache) ExecuteContext(ctx context.Context, command string, args ...interface{}) (*Result, error) {
+	key := qc.cacheKey(command, args...)
+
+	value, ok := qc.cache.Get(key)
+	if ok {
+		cr := value.(*cachedResult)
+		if time.Now().After(cr.freshUntil) {
+			qc.refresh(key, command, args...)
+		}
+
+		return cr.result, nil
+	}
+
+	value, err := qc.cache.GetOrLoad(ctx, key, func(ctx context.Context, key string) (interface{}, error) {
+		return qc.load(ctx, command, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*cachedResult).result, nil
+}
+
+// Invalidate removes all the cached results
+func (qc *QueryCache) Invalidate() {
+	qc.cache.Purge()
+}
+
+// load executes the query and returns the result which will be cached
+func (qc *QueryCache) load(ctx context.Context, command string, args ...interface{}) (*cachedResult, error) {
+	result, err := qc.conn.ExecuteContext(ctx, command, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedResult{
+		result:     result,
+		freshUntil: time.Now().Add(qc.ttl),
+	}, nil
+}
+
+// refresh reloads the stale result in the background, there is at most one refreshing of the same key at the same time,
+// if the refreshing fails, the stale result will be served until it expires
+func (qc *QueryCache) refresh(key, command string, args ...interface{}) {
+	qc.mutex.Lock()
+	_, ok := qc.refreshing[key]
+	if ok {
+		qc.mutex.Unlock()
+		return
+	}
+	qc.refreshing[key] = struct{}{}
+	qc.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			qc.mutex.Lock()
+			delete(qc.refreshing, key)
+			qc.mutex.Unlock()
+		}()
+
+		cr, err := qc.load(context.Background(), command, args...)
+		if err != nil {
+			log.Warnf("refresh stale prometheus query result failed. command: %s. %s", command, err.Error())
+			return
+		}
+		qc.cache.Set(key, cr)
+	}()
+}
+
+// cacheKey returns the cache key of the query, the time arguments are aligned before formatting as unix nanoseconds,
+// so that the queries which are built from time.Now() repeatedly, for example, by the dashboards, share the same key,
+// the time ranges are aligned to the step, and the other time values are aligned to the ttl
+func (qc *QueryCache) cacheKey(command string, args ...interface{}) string {
+	parts := make([]string, len(args)+1)
+	parts[constant.ZeroInt] = command
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			parts[i+1] = fmt.Sprintf("%d", alignTime(v, qc.ttl))
+		case TimeRange:
+			parts[i+1] = qc.rangeKey(v.GetRange())
+		case apiv1.Range:
+			parts[i+1] = qc.rangeKey(v)
+		default:
+			parts[i+1] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// rangeKey returns the cache key of the range, the start and end are aligned to the step
+func (qc *QueryCache) rangeKey(r apiv1.Range) string {
+	interval := r.Step
+	if interval <= constant.ZeroInt {
+		interval = qc.ttl
+	}
+
+	return fmt.Sprintf("%d-%d-%d", alignTime(r.Start, interval), alignTime(r.End, interval), r.Step)
+}
+
+// alignTime returns the unix nanoseconds of the time truncated to the multiple of the interval,
+// if the interval is not larger than 0, the time will not be aligned,
+// the monotonic clock reading of the time value does not affect the result
+func alignTime(t time.Time, interval time.Duration) int64 {
+	if interval > constant.ZeroInt {
+		t = t.Truncate(interval)
+	}
+
+	return t.UnixNano()
+}
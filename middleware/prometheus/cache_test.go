@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
+)
+
+func TestQueryCache_Execute(t *testing.T) {
+	asst := assert.New(t)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"success","data":{"resultType":"scalar","result":[1600000000,"%d"]}}`, n)))
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test Execute() failed")
+	qc := NewQueryCacheWithStale(c, 10, 50*time.Millisecond, time.Minute)
+
+	getValue := func() int {
+		result, err := qc.Execute("up")
+		asst.Nil(err, "test Execute() failed")
+		value, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+		asst.Nil(err, "test Execute() failed")
+		return value
+	}
+
+	asst.Equal(1, getValue(), "test Execute() failed")
+	asst.Equal(1, getValue(), "test Execute() failed")
+
+	// the stale result is served while it is refreshed in the background
+	time.Sleep(60 * time.Millisecond)
+	asst.Equal(1, getValue(), "test Execute() failed")
+	for i := 0; i < 100 && atomic.LoadInt32(&requests) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	asst.Equal(2, getValue(), "test Execute() failed")
+	asst.Equal(int32(2), atomic.LoadInt32(&requests), "test Execute() failed")
+
+	now := time.Now()
+	asst.Equal(qc.cacheKey("up", now), qc.cacheKey("up", now.Round(0)), "test cacheKey() failed")
+	asst.Equal(qc.cacheKey("up", NewTimeRange(now, now, time.Minute)), qc.cacheKey("up", NewTimeRange(now.Round(0), now.Round(0), time.Minute)), "test cacheKey() failed")
+
+	// the ranges built from time.Now() a few milliseconds apart share the same key,
+	// the end is not close to the boundary of the step, so that the test is stable
+	qc = NewQueryCache(c, 10, time.Minute)
+	atomic.StoreInt32(&requests, 0)
+	end := time.Now().Truncate(time.Minute).Add(30 * time.Second)
+	for i := 0; i < 2; i++ {
+		_, err = qc.Execute("up", NewTimeRange(end.Add(-time.Hour), end, time.Minute))
+		asst.Nil(err, "test Execute() failed")
+		time.Sleep(5 * time.Millisecond)
+		end = end.Add(5 * time.Millisecond)
+	}
+	asst.Equal(int32(1), atomic.LoadInt32(&requests), "test Execute() failed")
+	asst.NotEqual(qc.cacheKey("up", end), qc.cacheKey("up", end.Add(time.Minute)), "test cacheKey() failed")
+}
@@ -2,18 +2,24 @@ package prometheus
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/opentracing/opentracing-go/ext"
 	client "github.com/prometheus/client_golang/api"
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+	"github.com/romberli/go-util/metrics"
+	"github.com/romberli/go-util/tracing"
 )
 
 const (
@@ -25,6 +31,11 @@ const (
 
 	defaultHTTPPrefix  = "http://"
 	defaultHTTPSPrefix = "https://"
+	bearerAuthType     = "Bearer"
+
+	queryPath      = "/api/v1/query"
+	queryRangePath = "/api/v1/query_range"
+	timeoutParam   = "timeout"
 )
 
 type Config struct {
@@ -72,23 +83,104 @@ func NewConfigWithDefaultRoundTripper(addr string) Config {
 	}
 }
 
-// NewConfigWithBasicAuth returns a new client.Config with given address, user and password
-func NewConfigWithBasicAuth(addr, user, pass string) Config {
-	address := strings.ToLower(addr)
-	if !strings.HasPrefix(address, defaultHTTPPrefix) && !strings.HasPrefix(address, defaultHTTPSPrefix) {
-		addr = defaultHTTPPrefix + addr
+// NewConfigWithBasicAuth returns a new client.Config with given address, user and password,
+// the password could be encrypted by the credential package, it returns error if decrypting the password failed,
+// so that the encrypted password will not be sent to the server
+func NewConfigWithBasicAuth(addr, user, pass string) (Config, error) {
+	decrypted, err := credential.DecryptIfNeeded(pass)
+	if err != nil {
+		return Config{}, errors.New(fmt.Sprintf("decrypt prometheus password failed. %s", err.Error()))
 	}
 
 	return Config{
 		client.Config{
-			Address:      addr,
-			RoundTripper: config.NewBasicAuthRoundTripper(user, config.Secret(pass), constant.EmptyString, DefaultRoundTripper),
+			Address:      addHTTPPrefix(addr, defaultHTTPPrefix),
+			RoundTripper: config.NewBasicAuthRoundTripper(user, config.Secret(decrypted), constant.EmptyString, DefaultRoundTripper),
+		},
+	}, nil
+}
+
+// NewConfigWithToken returns a new client.Config with given address and bearer token,
+// the token could be encrypted by the credential package, it returns error if decrypting the token failed
+func NewConfigWithToken(addr, token string) (Config, error) {
+	rt, err := NewBearerRoundTripper(token, DefaultRoundTripper)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		client.Config{
+			Address:      addHTTPPrefix(addr, defaultHTTPPrefix),
+			RoundTripper: rt,
 		},
+	}, nil
+}
+
+// NewConfigWithTLS returns a new client.Config with given address and tls config,
+// the address uses https scheme if it does not have one
+func NewConfigWithTLS(addr string, tlsConfig *tls.Config) Config {
+	return Config{
+		client.Config{
+			Address:      addHTTPPrefix(addr, defaultHTTPSPrefix),
+			RoundTripper: NewTLSRoundTripper(tlsConfig),
+		},
+	}
+}
+
+// NewConfigWithTLSFiles returns a new client.Config with given address and tls files,
+// caFile is used to verify the server certificate, certFile and keyFile are the client certificate of mutual tls,
+// any of them could be empty, serverName is used to verify the hostname of the server certificate if it is not empty
+func NewConfigWithTLSFiles(addr, caFile, certFile, keyFile, serverName string) (Config, error) {
+	tlsConfig, err := config.NewTLSConfig(&config.TLSConfig{
+		CAFile:     caFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: serverName,
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	return NewConfigWithTLS(addr, tlsConfig), nil
+}
+
+// NewBearerRoundTripper returns a http.RoundTripper which sets the bearer token to the authorization header of each request,
+// the token could be encrypted by the credential package, it returns error if decrypting the token failed,
+// so that the encrypted token will not be sent to the server
+func NewBearerRoundTripper(token string, rt http.RoundTripper) (http.RoundTripper, error) {
+	decrypted, err := credential.DecryptIfNeeded(token)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("decrypt prometheus token failed. %s", err.Error()))
+	}
+
+	return config.NewAuthorizationCredentialsRoundTripper(bearerAuthType, config.Secret(decrypted), rt), nil
+}
+
+// NewTLSRoundTripper returns a http.RoundTripper which has the same settings as DefaultRoundTripper and uses given tls config
+func NewTLSRoundTripper(tlsConfig *tls.Config) http.RoundTripper {
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: DefaultDialTimeout, KeepAlive: DefaultKeepAlive}).DialContext,
+		TLSHandshakeTimeout: DefaultTLSHandshakeTimeout,
+		TLSClientConfig:     tlsConfig,
 	}
 }
 
+// addHTTPPrefix adds the prefix to the address if it does not have a http or https scheme
+func addHTTPPrefix(addr, prefix string) string {
+	address := strings.ToLower(addr)
+	if !strings.HasPrefix(address, defaultHTTPPrefix) && !strings.HasPrefix(address, defaultHTTPSPrefix) {
+		return prefix + addr
+	}
+
+	return addr
+}
+
 type Conn struct {
+	Addr string
 	apiv1.API
+	client      client.Client
+	retryPolicy RetryPolicy
 }
 
 // NewConn returns a new *Conn with given address and round tripper
@@ -96,14 +188,51 @@ func NewConn(addr string, rt http.RoundTripper) (*Conn, error) {
 	return NewConnWithConfig(NewConfig(addr, rt))
 }
 
-// NewConnWithConfig returns a new *Conn with given config
+// NewConnWithConfig returns a new *Conn with given config,
+// the deadline of the context will be propagated to prometheus server as the timeout of the query
 func NewConnWithConfig(config Config) (*Conn, error) {
-	cli, err := client.NewClient(config.Config)
+	cfg := config.Config
+	if cfg.RoundTripper == nil {
+		cfg.RoundTripper = DefaultRoundTripper
+	}
+	cfg.RoundTripper = &deadlineRoundTripper{rt: cfg.RoundTripper}
+
+	cli, err := client.NewClient(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Conn{apiv1.NewAPI(cli)}, nil
+	return &Conn{
+		Addr:   config.Address,
+		API:    apiv1.NewAPI(cli),
+		client: cli,
+	}, nil
+}
+
+// deadlineRoundTripper sets the timeout parameter of the query apis with the deadline of the request context,
+// so that prometheus server stops evaluating the query when the caller gives up
+type deadlineRoundTripper struct {
+	rt http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (d *deadlineRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	deadline, ok := req.Context().Deadline()
+	if !ok || !(strings.HasSuffix(req.URL.Path, queryPath) || strings.HasSuffix(req.URL.Path, queryRangePath)) {
+		return d.rt.RoundTrip(req)
+	}
+
+	timeout := time.Until(deadline)
+	if timeout <= constant.ZeroInt {
+		return nil, context.DeadlineExceeded
+	}
+
+	r := req.Clone(req.Context())
+	query := r.URL.Query()
+	query.Set(timeoutParam, strconv.FormatFloat(timeout.Seconds(), 'f', 3, 64))
+	r.URL.RawQuery = query.Encode()
+
+	return d.rt.RoundTrip(r)
 }
 
 // CheckInstanceStatus checks prometheus instance status
@@ -132,18 +261,37 @@ func (conn *Conn) ExecuteContext(ctx context.Context, command string, args ...in
 	return conn.executeContext(ctx, command, args...)
 }
 
-// executeContext executes given command with arguments and returns a result.
+// executeContext executes given command with arguments and returns a result, it is traced if tracing is enabled,
+// the query is retried according to the retry policy, and the warnings are logged and kept in the result
+func (conn *Conn) executeContext(ctx context.Context, command string, args ...interface{}) (*Result, error) {
+	span, ctx := tracing.StartSpan(ctx, tracing.ComponentPrometheus, tracing.OperationExecute, conn.Addr)
+	ext.DBType.Set(span, tracing.ComponentPrometheus)
+	ext.DBStatement.Set(span, command)
+	start := time.Now()
+	result, err := conn.queryWithRetry(ctx, command, args...)
+	metrics.ObserveOperation(metrics.ComponentPrometheus, metrics.OperationExecute, start, err)
+	tracing.Finish(span, err)
+	if err != nil {
+		return nil, err
+	}
+	conn.logWarnings(result.GetWarnings())
+
+	return result, nil
+}
+
+// query executes given command with arguments and returns a result.
 // if args length is 0:
 // 		it uses time.Now() as the time series
 // if args length is 1:
 // 		argument type must be either time.Time, TimeRange or apiv1.Range
 // if args length is 2:
-// 		argument types must be time.Time and time.Time, represent start time and end time, it uses 1 minute as step
+// 		argument types must be time.Time and time.Time, represent start time and end time, the step is selected by NewTimeRangeWithAutoStep()
 // if args length is 3:
 //		argument types muse be in order of time.Time, time.Time and time.Duration, represent start time, end time and step
 // if args length is larger than 3:
 // 		it returns error
-func (conn *Conn) executeContext(ctx context.Context, command string, args ...interface{}) (*Result, error) {
+// the range queries which exceed MaxPointsPerQuery will be split into chunks automatically
+func (conn *Conn) query(ctx context.Context, command string, args ...interface{}) (*Result, error) {
 	var (
 		arg      interface{}
 		value    model.Value
@@ -164,7 +312,7 @@ func (conn *Conn) executeContext(ctx context.Context, command string, args ...in
 				"args length is 2, both of them should be time.Time, represent start time, end time")
 		}
 
-		arg = NewTimeRange(start, end, DefaultStep)
+		arg = NewTimeRangeWithAutoStep(start, end)
 	case 3:
 		start, startOK := args[0].(time.Time)
 		end, endOK := args[1].(time.Time)
@@ -186,12 +334,12 @@ func (conn *Conn) executeContext(ctx context.Context, command string, args ...in
 			return nil, err
 		}
 	case TimeRange:
-		value, warnings, err = conn.QueryRange(ctx, command, in.GetRange())
+		value, warnings, err = conn.queryRange(ctx, command, in.GetRange())
 		if err != nil {
 			return nil, err
 		}
 	case apiv1.Range:
-		value, warnings, err = conn.QueryRange(ctx, command, in)
+		value, warnings, err = conn.queryRange(ctx, command, in)
 		if err != nil {
 			return nil, err
 		}
@@ -1,7 +1,12 @@
 package prometheus
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,7 +24,11 @@ const (
 var conn = initConn()
 
 func initConn() *Conn {
-	config := NewConfigWithBasicAuth(defaultAddr, defaultUser, defaultPass)
+	config, err := NewConfigWithBasicAuth(defaultAddr, defaultUser, defaultPass)
+	if err != nil {
+		log.Error(fmt.Sprintf("initAppRepo() failed.\n%s", err.Error()))
+		return nil
+	}
 	c, err := NewConnWithConfig(config)
 	if err != nil {
 		log.Error(fmt.Sprintf("initAppRepo() failed.\n%s", err.Error()))
@@ -98,3 +107,93 @@ func TestConn_Execute(t *testing.T) {
 	asst.Nil(err, "test Execute() failed")
 	t.Log(result)
 }
+
+func TestConn_ExecuteContext(t *testing.T) {
+	asst := assert.New(t)
+
+	var timeout string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout = r.FormValue(timeoutParam)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test ExecuteContext() failed")
+
+	_, err = c.Execute("up")
+	asst.Nil(err, "test ExecuteContext() failed")
+	asst.Equal(constant.EmptyString, timeout, "test ExecuteContext() failed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = c.ExecuteContext(ctx, "up")
+	asst.Nil(err, "test ExecuteContext() failed")
+	seconds, err := strconv.ParseFloat(timeout, 64)
+	asst.Nil(err, "test ExecuteContext() failed")
+	asst.True(seconds > 0 && seconds <= 10, "test ExecuteContext() failed")
+}
+
+func TestNewConfigWithTokenAndTLS(t *testing.T) {
+	asst := assert.New(t)
+
+	var authorization string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	config, err := NewConfigWithToken(server.URL, "test-token")
+	asst.Nil(err, "test NewConfigWithToken() failed")
+	c, err := NewConnWithConfig(config)
+	asst.Nil(err, "test NewConfigWithToken() failed")
+	_, err = c.Execute("up")
+	asst.Nil(err, "test NewConfigWithToken() failed")
+	asst.Equal("Bearer test-token", authorization, "test NewConfigWithToken() failed")
+	// the token which could not be decrypted should not be sent as is
+	_, err = NewConfigWithToken(server.URL, "ENC(invalid)")
+	asst.NotNil(err, "test NewConfigWithToken() failed")
+
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+	tlsConfig := tlsServer.Client().Transport.(*http.Transport).TLSClientConfig
+	c, err = NewConnWithConfig(NewConfigWithTLS(strings.TrimPrefix(tlsServer.URL, "https://"), tlsConfig))
+	asst.Nil(err, "test NewConfigWithTLS() failed")
+	_, err = c.Execute("up")
+	asst.Nil(err, "test NewConfigWithTLS() failed")
+
+	_, err = NewConfigWithTLSFiles(tlsServer.URL, "/path/not/exists/ca.pem", constant.EmptyString, constant.EmptyString, constant.EmptyString)
+	asst.NotNil(err, "test NewConfigWithTLSFiles() failed")
+}
+
+func TestNewConfigWithBasicAuth(t *testing.T) {
+	asst := assert.New(t)
+
+	var (
+		user string
+		pass string
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ = r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	config, err := NewConfigWithBasicAuth(server.URL, defaultUser, defaultPass)
+	asst.Nil(err, "test NewConfigWithBasicAuth() failed")
+	c, err := NewConnWithConfig(config)
+	asst.Nil(err, "test NewConfigWithBasicAuth() failed")
+	_, err = c.Execute("up")
+	asst.Nil(err, "test NewConfigWithBasicAuth() failed")
+	asst.Equal(defaultUser, user, "test NewConfigWithBasicAuth() failed")
+	asst.Equal(defaultPass, pass, "test NewConfigWithBasicAuth() failed")
+	// the password which could not be decrypted should not be sent as is
+	_, err = NewConfigWithBasicAuth(server.URL, defaultUser, "ENC(invalid)")
+	asst.NotNil(err, "test NewConfigWithBasicAuth() failed")
+}
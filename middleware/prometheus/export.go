@@ -0,0 +1,99 @@
+package prometheus
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+type exportRow struct {
+	Labels    map[string]string `json:"labels"`
+	Timestamp time.Time         `json:"timestamp"`
+	// Value is encoded as string like the http api of prometheus, as json does not support NaN and Inf
+	Value string `json:"value"`
+}
+
+// ToCSV writes the result to w as csv, the header contains the label names in alphabetical order
+// followed by the timestamp and value columns, each point of each series is a row,
+// the missing labels of a series are written as empty strings
+func (r *Result) ToCSV(w io.Writer) error {
+	series, err := r.GetSeries()
+	if err != nil {
+		return err
+	}
+
+	labelNames := labelNamesOf(series)
+	writer := csv.NewWriter(w)
+	err = writer.Write(append(append([]string{}, labelNames...), timestampColumn, valueColumn))
+	if err != nil {
+		return err
+	}
+
+	record := make([]string, len(labelNames)+defaultColumnNum)
+	for _, s := range series {
+		for i, name := range labelNames {
+			record[i] = s.Labels[name]
+		}
+		for _, point := range s.Points {
+			record[len(labelNames)] = point.Timestamp.Format(constant.DefaultTimeLayout)
+			record[len(labelNames)+1] = formatValue(point.Value)
+			err = writer.Write(record)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// ToJSON returns the result as a json array, each point of each series is an element
+// which contains the labels, timestamp and value, the value is a string, for example, "1.5", "NaN" and "+Inf"
+func (r *Result) ToJSON() ([]byte, error) {
+	series, err := r.GetSeries()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*exportRow{}
+	for _, s := range series {
+		for _, point := range s.Points {
+			rows = append(rows, &exportRow{
+				Labels:    s.Labels,
+				Timestamp: point.Timestamp,
+				Value:     formatValue(point.Value),
+			})
+		}
+	}
+
+	return json.Marshal(rows)
+}
+
+// formatValue formats the sample value in the same way as the http api of prometheus
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// labelNamesOf returns the sorted union of the label names of the series
+func labelNamesOf(series []*Series) []string {
+	nameMap := make(map[string]struct{})
+	for _, s := range series {
+		for name := range s.Labels {
+			nameMap[name] = struct{}{}
+		}
+	}
+
+	names := make([]string, constant.ZeroInt, len(nameMap))
+	for name := range nameMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -19,7 +19,8 @@ func TestGlobalPool(t *testing.T) {
 	asst := assert.New(t)
 
 	// create pool
-	config := NewConfigWithBasicAuth(defaultAddr, defaultUser, defaultPass)
+	config, err := NewConfigWithBasicAuth(defaultAddr, defaultUser, defaultPass)
+	asst.Nil(err, "test NewConfigWithBasicAuth() failed")
 	err = InitGlobalPoolWithConfig(config, DefaultMaxConnections, DefaultInitConnections, DefaultMaxIdleConnections, DefaultMaxIdleTime, DefaultKeepAliveInterval)
 	asst.Nil(err, "create pool failed. addr: %s, user: %s, pass: %s", defaultAddr, defaultUser, defaultPass)
 
@@ -0,0 +1,97 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	healthyPath = "/-/healthy"
+	readyPath   = "/-/ready"
+)
+
+type HealthReport struct {
+	Addr string `json:"addr"`
+	// Healthy means the process is running, Ready means the process is ready to serve the queries
+	Healthy  bool   `json:"healthy"`
+	Ready    bool   `json:"ready"`
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	// Latency is the total time of the checks
+	Latency time.Duration `json:"latency"`
+	// Errors are the error messages of the failed checks
+	Errors []string `json:"errors,omitempty"`
+}
+
+// IsOK returns if the instance is healthy and ready
+func (hr *HealthReport) IsOK() bool {
+	return hr.Healthy && hr.Ready
+}
+
+// CheckHealth checks the healthy and ready endpoints and the build info of the instance,
+// the failed checks are recorded in the report instead of returning an error,
+// so that the caller could get the whole picture of the instance
+func (conn *Conn) CheckHealth(ctx context.Context) *HealthReport {
+	report := &HealthReport{Addr: conn.Addr}
+	start := time.Now()
+
+	err := conn.checkEndpoint(ctx, healthyPath)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	} else {
+		report.Healthy = true
+	}
+
+	err = conn.checkEndpoint(ctx, readyPath)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	} else {
+		report.Ready = true
+	}
+
+	buildInfo, err := conn.Buildinfo(ctx)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("get build info failed. %s", err.Error()))
+	} else {
+		report.Version = buildInfo.Version
+		report.Revision = buildInfo.Revision
+	}
+
+	report.Latency = time.Since(start)
+
+	return report
+}
+
+// Check implements healthcheck.Checker interface, it returns error if the instance is not healthy or not ready
+func (conn *Conn) Check(ctx context.Context) error {
+	report := conn.CheckHealth(ctx)
+	if !report.IsOK() {
+		return errors.New(fmt.Sprintf("prometheus instance is not ok. addr: %s. %s", conn.Addr, strings.Join(report.Errors, constant.CommaString)))
+	}
+
+	return nil
+}
+
+// checkEndpoint sends a get request to given path, it returns error if the status code is not 200
+func (conn *Conn) checkEndpoint(ctx context.Context, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, conn.client.URL(path, nil).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, body, err := conn.client.Do(ctx, req)
+	if err != nil {
+		return errors.New(fmt.Sprintf("check %s failed. %s", path, err.Error()))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(fmt.Sprintf("check %s failed. status code: %d, message: %s", path, resp.StatusCode, strings.TrimSpace(string(body))))
+	}
+
+	return nil
+}
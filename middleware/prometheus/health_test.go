@@ -0,0 +1,55 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/healthcheck"
+)
+
+var _ healthcheck.Checker = (*Conn)(nil)
+
+func TestConn_CheckHealth(t *testing.T) {
+	asst := assert.New(t)
+
+	ready := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/prometheus/-/healthy":
+			_, _ = w.Write([]byte("Prometheus is Healthy."))
+		case "/prometheus/-/ready":
+			if !ready {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte("Service Unavailable"))
+				return
+			}
+			_, _ = w.Write([]byte("Prometheus is Ready."))
+		case "/prometheus/api/v1/status/buildinfo":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"success","data":{"version":"2.26.0","revision":"abc"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL+"/prometheus", nil)
+	asst.Nil(err, "test CheckHealth() failed")
+
+	report := c.CheckHealth(context.Background())
+	asst.True(report.Healthy, "test CheckHealth() failed")
+	asst.False(report.Ready, "test CheckHealth() failed")
+	asst.Equal("2.26.0", report.Version, "test CheckHealth() failed")
+	asst.Equal(1, len(report.Errors), "test CheckHealth() failed")
+	asst.NotNil(c.Check(context.Background()), "test Check() failed")
+
+	ready = true
+	report = c.CheckHealth(context.Background())
+	asst.True(report.IsOK(), "test CheckHealth() failed")
+	asst.True(report.Latency > 0, "test CheckHealth() failed")
+	asst.Nil(c.Check(context.Background()), "test Check() failed")
+}
@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+// GetLabelNames returns the label names of the series which match any of the matchers in given time range,
+// zero time means no limit, if there is no matcher, all the label names will be returned
+func (conn *Conn) GetLabelNames(ctx context.Context, start, end time.Time, matches ...string) ([]string, error) {
+	names, warnings, err := conn.LabelNames(ctx, matches, start, end)
+	if err != nil {
+		return nil, err
+	}
+	conn.logWarnings(warnings)
+
+	return names, nil
+}
+
+// GetLabelValues returns the values of given label of the series which match any of the matchers in given time range,
+// zero time means no limit, if there is no matcher, all the values of the label will be returned
+func (conn *Conn) GetLabelValues(ctx context.Context, label string, start, end time.Time, matches ...string) ([]string, error) {
+	values, warnings, err := conn.LabelValues(ctx, label, matches, start, end)
+	if err != nil {
+		return nil, err
+	}
+	conn.logWarnings(warnings)
+
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = string(value)
+	}
+
+	return result, nil
+}
+
+// GetSeriesLabels returns the label sets of the series which match any of the matchers in given time range,
+// zero time means no limit, at least one matcher is required
+func (conn *Conn) GetSeriesLabels(ctx context.Context, start, end time.Time, matches ...string) ([]map[string]string, error) {
+	if len(matches) == constant.ZeroInt {
+		return nil, errors.New("at least one series matcher is required")
+	}
+
+	labelSets, warnings, err := conn.Series(ctx, matches, start, end)
+	if err != nil {
+		return nil, err
+	}
+	conn.logWarnings(warnings)
+
+	result := make([]map[string]string, len(labelSets))
+	for i, labelSet := range labelSets {
+		result[i] = make(map[string]string, len(labelSet))
+		for name, value := range labelSet {
+			result[i][string(name)] = string(value)
+		}
+	}
+
+	return result, nil
+}
+
+// GetTSDBStats returns the cardinality statistics of the tsdb
+func (conn *Conn) GetTSDBStats(ctx context.Context) (apiv1.TSDBResult, error) {
+	return conn.TSDB(ctx)
+}
+
+// logWarnings logs the warnings returned by prometheus
+func (conn *Conn) logWarnings(warnings apiv1.Warnings) {
+	for _, warning := range warnings {
+		log.Warnf("prometheus returned warning. addr: %s, warning: %s", conn.Addr, warning)
+	}
+}
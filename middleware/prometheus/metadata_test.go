@@ -0,0 +1,54 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConn_Metadata(t *testing.T) {
+	asst := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/labels":
+			_, _ = w.Write([]byte(`{"status":"success","data":["__name__","instance","job"]}`))
+		case "/api/v1/label/job/values":
+			_, _ = w.Write([]byte(`{"status":"success","data":["node","mysql"]}`))
+		case "/api/v1/series":
+			_, _ = w.Write([]byte(`{"status":"success","data":[{"__name__":"up","job":"node"}]}`))
+		case "/api/v1/status/tsdb":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"seriesCountByMetricName":[{"name":"up","value":2}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test NewConn() failed")
+	ctx := context.Background()
+
+	names, err := c.GetLabelNames(ctx, time.Time{}, time.Time{})
+	asst.Nil(err, "test GetLabelNames() failed")
+	asst.Equal([]string{"__name__", "instance", "job"}, names, "test GetLabelNames() failed")
+
+	values, err := c.GetLabelValues(ctx, "job", time.Time{}, time.Time{})
+	asst.Nil(err, "test GetLabelValues() failed")
+	asst.Equal([]string{"node", "mysql"}, values, "test GetLabelValues() failed")
+
+	_, err = c.GetSeriesLabels(ctx, time.Time{}, time.Time{})
+	asst.NotNil(err, "test GetSeriesLabels() failed")
+	series, err := c.GetSeriesLabels(ctx, time.Now().Add(-time.Hour), time.Now(), "up")
+	asst.Nil(err, "test GetSeriesLabels() failed")
+	asst.Equal([]map[string]string{{"__name__": "up", "job": "node"}}, series, "test GetSeriesLabels() failed")
+
+	stats, err := c.GetTSDBStats(ctx)
+	asst.Nil(err, "test GetTSDBStats() failed")
+	asst.Equal(uint64(2), stats.SeriesCountByMetricName[0].Value, "test GetTSDBStats() failed")
+}
@@ -22,7 +22,8 @@ func TestPool(t *testing.T) {
 	log.SetLevel(zapcore.DebugLevel)
 
 	// create pool
-	config := NewConfigWithBasicAuth(defaultAddr, defaultUser, defaultPass)
+	config, err := NewConfigWithBasicAuth(defaultAddr, defaultUser, defaultPass)
+	asst.Nil(err, "test NewConfigWithBasicAuth() failed")
 	pool, err = NewPoolWithConfig(config, DefaultMaxConnections, DefaultInitConnections, DefaultMaxIdleConnections, DefaultMaxIdleTime, DefaultKeepAliveInterval)
 	asst.Nil(err, "create pool failed. addr: %s, user: %s, pass: %s", defaultAddr, defaultAddr, defaultAddr)
 
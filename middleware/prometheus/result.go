@@ -160,3 +160,12 @@ func NewEmptyResult() *Result {
 func (r *Result) GetRaw() interface{} {
 	return r.Raw
 }
+
+// GetWarnings returns the warnings returned by prometheus, for example, the query hits the limit of the samples
+func (r *Result) GetWarnings() apiv1.Warnings {
+	if r.Raw == nil {
+		return nil
+	}
+
+	return r.Raw.GetWarnings()
+}
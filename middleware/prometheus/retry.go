@@ -0,0 +1,128 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultMaxRetries      = 3
+	DefaultRetryBackoff    = 200 * time.Millisecond
+	DefaultMaxRetryBackoff = 5 * time.Second
+
+	errUnavailable apiv1.ErrorType = "unavailable"
+)
+
+// RetryPolicy is the retry and timeout policy of the queries, the zero value means no retry and no timeout
+type RetryPolicy struct {
+	// MaxRetries is the maximum retry times of a query, 0 means no retry
+	MaxRetries int
+	// Backoff is the initial backoff between retries, it doubles after each retry until MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout is the total timeout of a query including the retries, 0 means no timeout
+	Timeout time.Duration
+}
+
+// NewRetryPolicy returns a new RetryPolicy
+func NewRetryPolicy(maxRetries int, backoff, maxBackoff, timeout time.Duration) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: maxRetries,
+		Backoff:    backoff,
+		MaxBackoff: maxBackoff,
+		Timeout:    timeout,
+	}
+}
+
+// NewRetryPolicyWithDefault returns a new RetryPolicy with default values and given timeout
+func NewRetryPolicyWithDefault(timeout time.Duration) RetryPolicy {
+	return NewRetryPolicy(DefaultMaxRetries, DefaultRetryBackoff, DefaultMaxRetryBackoff, timeout)
+}
+
+// backoff returns the backoff before given retry, retry starts from 0
+func (rp RetryPolicy) backoff(retry int) time.Duration {
+	backoff := rp.Backoff
+	for i := 0; i < retry && (rp.MaxBackoff <= constant.ZeroInt || backoff < rp.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if rp.MaxBackoff > constant.ZeroInt && backoff > rp.MaxBackoff {
+		return rp.MaxBackoff
+	}
+
+	return backoff
+}
+
+// SetRetryPolicy sets the retry and timeout policy of the queries,
+// it is not concurrency safe, so it should be called before the connection is used
+func (conn *Conn) SetRetryPolicy(policy RetryPolicy) {
+	conn.retryPolicy = policy
+}
+
+// IsTransientError returns if given error is a transient error which could be resolved by retrying,
+// only the connection errors, 5xx and 429 status codes are transient
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// the errors of the connection, the http client returns *url.Error, which also implements net.Error
+	var (
+		netErr net.Error
+		urlErr *url.Error
+	)
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return true
+	}
+
+	var apiErr *apiv1.Error
+	if !errors.As(err, &apiErr) {
+		// the other errors are returned locally, for example, the invalid arguments, retrying will not help
+		return false
+	}
+
+	switch apiErr.Type {
+	case apiv1.ErrServer, errUnavailable:
+		return true
+	case apiv1.ErrClient:
+		return strings.HasSuffix(apiErr.Msg, strconv.Itoa(http.StatusTooManyRequests))
+	default:
+		return false
+	}
+}
+
+// queryWithRetry executes the query with the retry policy of the connection
+func (conn *Conn) queryWithRetry(ctx context.Context, command string, args ...interface{}) (*Result, error) {
+	policy := conn.retryPolicy
+	if policy.Timeout > constant.ZeroInt {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	for retry := 0; ; retry++ {
+		result, err := conn.query(ctx, command, args...)
+		if err == nil || retry >= policy.MaxRetries || !IsTransientError(err) {
+			return result, err
+		}
+
+		backoff := policy.backoff(retry)
+		log.Debugf("prometheus query failed, will retry. addr: %s, retry: %d, backoff: %s. %s", conn.Addr, retry+1, backoff.String(), err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+	}
+}
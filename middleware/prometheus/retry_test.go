@@ -0,0 +1,87 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConn_Retry(t *testing.T) {
+	asst := assert.New(t)
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.FormValue("query") {
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		case "flapping":
+			if attempt == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"status":"error","errorType":"unavailable","error":"not ready"}`))
+				return
+			}
+			if attempt == 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"success","warnings":["too many samples"],"data":{"resultType":"scalar","result":[1600000000,"1"]}}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test SetRetryPolicy() failed")
+
+	_, err = c.Execute("flapping")
+	asst.NotNil(err, "test SetRetryPolicy() failed")
+	asst.Equal(int32(1), atomic.SwapInt32(&attempts, 0), "test SetRetryPolicy() failed")
+
+	c.SetRetryPolicy(NewRetryPolicy(DefaultMaxRetries, time.Millisecond, 10*time.Millisecond, 10*time.Second))
+	result, err := c.Execute("flapping")
+	asst.Nil(err, "test SetRetryPolicy() failed")
+	asst.Equal(int32(3), atomic.SwapInt32(&attempts, 0), "test SetRetryPolicy() failed")
+	asst.Equal(apiv1.Warnings{"too many samples"}, result.GetWarnings(), "test SetRetryPolicy() failed")
+
+	_, err = c.Execute("bad")
+	asst.NotNil(err, "test SetRetryPolicy() failed")
+	asst.Equal(int32(1), atomic.SwapInt32(&attempts, 0), "test SetRetryPolicy() failed")
+
+	// the invalid arguments are not retried
+	c.SetRetryPolicy(NewRetryPolicy(DefaultMaxRetries, time.Second, time.Second, 10*time.Second))
+	start := time.Now()
+	_, err = c.Execute("flapping", 1)
+	asst.NotNil(err, "test SetRetryPolicy() failed")
+	asst.True(time.Since(start) < time.Second, "test SetRetryPolicy() failed")
+	asst.Equal(int32(0), atomic.LoadInt32(&attempts), "test SetRetryPolicy() failed")
+}
+
+func TestIsTransientError(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.False(IsTransientError(nil), "test IsTransientError() failed")
+	asst.False(IsTransientError(context.DeadlineExceeded), "test IsTransientError() failed")
+	asst.True(IsTransientError(&url.Error{Op: "Post", URL: "http://127.0.0.1:9090", Err: errors.New("connection reset by peer")}), "test IsTransientError() failed")
+	asst.True(IsTransientError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), "test IsTransientError() failed")
+	asst.False(IsTransientError(errors.New("unsupported argument data type: int")), "test IsTransientError() failed")
+	asst.True(IsTransientError(&apiv1.Error{Type: apiv1.ErrServer, Msg: "server error: 502"}), "test IsTransientError() failed")
+	asst.True(IsTransientError(&apiv1.Error{Type: apiv1.ErrClient, Msg: "client error: 429"}), "test IsTransientError() failed")
+	asst.False(IsTransientError(&apiv1.Error{Type: apiv1.ErrClient, Msg: "client error: 404"}), "test IsTransientError() failed")
+	asst.False(IsTransientError(&apiv1.Error{Type: apiv1.ErrBadData, Msg: "parse error"}), "test IsTransientError() failed")
+
+	policy := NewRetryPolicy(DefaultMaxRetries, time.Second, 3*time.Second, 0)
+	asst.Equal(time.Second, policy.backoff(0), "test backoff() failed")
+	asst.Equal(2*time.Second, policy.backoff(1), "test backoff() failed")
+	asst.Equal(3*time.Second, policy.backoff(5), "test backoff() failed")
+}
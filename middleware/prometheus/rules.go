@@ -0,0 +1,218 @@
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	RuleTypeAlerting  = "alerting"
+	RuleTypeRecording = "recording"
+)
+
+type Target struct {
+	Labels     map[string]string `json:"labels"`
+	ScrapeURL  string            `json:"scrape_url"`
+	Health     string            `json:"health"`
+	LastError  string            `json:"last_error"`
+	LastScrape time.Time         `json:"last_scrape"`
+}
+
+// IsUp returns if the last scrape of the target succeeded
+func (t *Target) IsUp() bool {
+	return t.Health == string(apiv1.HealthGood)
+}
+
+type RuleAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	Value       string            `json:"value"`
+	ActiveAt    time.Time         `json:"active_at"`
+}
+
+type Rule struct {
+	Group     string            `json:"group"`
+	File      string            `json:"file"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Query     string            `json:"query"`
+	Labels    map[string]string `json:"labels"`
+	Health    string            `json:"health"`
+	LastError string            `json:"last_error"`
+	// Alerts are the alerts of the alerting rule, it is always empty for the recording rule
+	Alerts []*RuleAlert `json:"alerts"`
+}
+
+// IsHealthy returns if the last evaluation of the rule succeeded
+func (r *Rule) IsHealthy() bool {
+	return r.Health == apiv1.RuleHealthGood
+}
+
+// IsFiring returns if any alert of the rule is firing
+func (r *Rule) IsFiring() bool {
+	for _, alert := range r.Alerts {
+		if alert.State == string(apiv1.AlertStateFiring) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type HealthSummary struct {
+	Total     int `json:"total"`
+	Healthy   int `json:"healthy"`
+	Unhealthy int `json:"unhealthy"`
+}
+
+// GetTargets returns the active targets
+func (conn *Conn) GetTargets(ctx context.Context) ([]*Target, error) {
+	result, err := conn.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*Target, len(result.Active))
+	for i, at := range result.Active {
+		targets[i] = &Target{
+			Labels:     labelSetToMap(at.Labels),
+			ScrapeURL:  at.ScrapeURL,
+			Health:     string(at.Health),
+			LastError:  at.LastError,
+			LastScrape: at.LastScrape,
+		}
+	}
+
+	return targets, nil
+}
+
+// GetDownTargets returns the active targets whose last scrape failed or has not been done yet
+func (conn *Conn) GetDownTargets(ctx context.Context) ([]*Target, error) {
+	targets, err := conn.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var down []*Target
+	for _, target := range targets {
+		if !target.IsUp() {
+			down = append(down, target)
+		}
+	}
+
+	return down, nil
+}
+
+// GetTargetsHealthSummary returns the health summary of the active targets
+func (conn *Conn) GetTargetsHealthSummary(ctx context.Context) (*HealthSummary, error) {
+	targets, err := conn.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &HealthSummary{Total: len(targets)}
+	for _, target := range targets {
+		if target.IsUp() {
+			summary.Healthy++
+			continue
+		}
+		summary.Unhealthy++
+	}
+
+	return summary, nil
+}
+
+// GetRules returns the alerting and recording rules of all the rule groups
+func (conn *Conn) GetRules(ctx context.Context) ([]*Rule, error) {
+	result, err := conn.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*Rule
+	for _, group := range result.Groups {
+		for _, r := range group.Rules {
+			rule := &Rule{Group: group.Name, File: group.File}
+			switch v := r.(type) {
+			case apiv1.AlertingRule:
+				rule.Type = RuleTypeAlerting
+				rule.Name = v.Name
+				rule.Query = v.Query
+				rule.Labels = labelSetToMap(v.Labels)
+				rule.Health = string(v.Health)
+				rule.LastError = v.LastError
+				for _, alert := range v.Alerts {
+					rule.Alerts = append(rule.Alerts, &RuleAlert{
+						Labels:      labelSetToMap(alert.Labels),
+						Annotations: labelSetToMap(alert.Annotations),
+						State:       string(alert.State),
+						Value:       alert.Value,
+						ActiveAt:    alert.ActiveAt,
+					})
+				}
+			case apiv1.RecordingRule:
+				rule.Type = RuleTypeRecording
+				rule.Name = v.Name
+				rule.Query = v.Query
+				rule.Labels = labelSetToMap(v.Labels)
+				rule.Health = string(v.Health)
+				rule.LastError = v.LastError
+			default:
+				continue
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// GetFiringRules returns the alerting rules which have firing alerts
+func (conn *Conn) GetFiringRules(ctx context.Context) ([]*Rule, error) {
+	rules, err := conn.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var firing []*Rule
+	for _, rule := range rules {
+		if rule.IsFiring() {
+			firing = append(firing, rule)
+		}
+	}
+
+	return firing, nil
+}
+
+// GetRulesHealthSummary returns the health summary of the rules, the rules which have not been evaluated are unhealthy
+func (conn *Conn) GetRulesHealthSummary(ctx context.Context) (*HealthSummary, error) {
+	rules, err := conn.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &HealthSummary{Total: len(rules)}
+	for _, rule := range rules {
+		if rule.IsHealthy() {
+			summary.Healthy++
+			continue
+		}
+		summary.Unhealthy++
+	}
+
+	return summary, nil
+}
+
+// labelSetToMap converts the label set to map
+func labelSetToMap(labelSet model.LabelSet) map[string]string {
+	m := make(map[string]string, len(labelSet))
+	for name, value := range labelSet {
+		m[string(name)] = string(value)
+	}
+
+	return m
+}
@@ -0,0 +1,58 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConn_RulesAndTargets(t *testing.T) {
+	asst := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/targets":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"activeTargets":[
+				{"labels":{"job":"node","instance":"a"},"scrapeUrl":"http://a/metrics","health":"up","lastError":""},
+				{"labels":{"job":"node","instance":"b"},"scrapeUrl":"http://b/metrics","health":"down","lastError":"connection refused"}
+			],"droppedTargets":[]}}`))
+		case "/api/v1/rules":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"groups":[{"name":"node","file":"node.yml","interval":15,"rules":[
+				{"type":"alerting","name":"InstanceDown","query":"up == 0","duration":60,"labels":{"severity":"critical"},"annotations":{},
+					"alerts":[{"labels":{"instance":"b"},"annotations":{},"state":"firing","activeAt":"2021-01-01T00:00:00Z","value":"0"}],"health":"ok"},
+				{"type":"alerting","name":"HighLoad","query":"load1 > 10","duration":60,"labels":{},"annotations":{},"alerts":[],"health":"ok"},
+				{"type":"recording","name":"job:up:sum","query":"sum(up) by (job)","health":"err","lastError":"bad query"}
+			]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test NewConn() failed")
+	ctx := context.Background()
+
+	down, err := c.GetDownTargets(ctx)
+	asst.Nil(err, "test GetDownTargets() failed")
+	asst.Equal(1, len(down), "test GetDownTargets() failed")
+	asst.Equal("b", down[0].Labels["instance"], "test GetDownTargets() failed")
+	asst.Equal("connection refused", down[0].LastError, "test GetDownTargets() failed")
+	summary, err := c.GetTargetsHealthSummary(ctx)
+	asst.Nil(err, "test GetTargetsHealthSummary() failed")
+	asst.Equal(&HealthSummary{Total: 2, Healthy: 1, Unhealthy: 1}, summary, "test GetTargetsHealthSummary() failed")
+
+	firing, err := c.GetFiringRules(ctx)
+	asst.Nil(err, "test GetFiringRules() failed")
+	asst.Equal(1, len(firing), "test GetFiringRules() failed")
+	asst.Equal("InstanceDown", firing[0].Name, "test GetFiringRules() failed")
+	asst.Equal("node", firing[0].Group, "test GetFiringRules() failed")
+	asst.Equal("b", firing[0].Alerts[0].Labels["instance"], "test GetFiringRules() failed")
+	summary, err = c.GetRulesHealthSummary(ctx)
+	asst.Nil(err, "test GetRulesHealthSummary() failed")
+	asst.Equal(&HealthSummary{Total: 3, Healthy: 2, Unhealthy: 1}, summary, "test GetRulesHealthSummary() failed")
+}
@@ -0,0 +1,135 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/romberli/go-util/constant"
+)
+
+type Point struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+type Series struct {
+	Labels map[string]string `json:"labels"`
+	Points []Point           `json:"points"`
+}
+
+// newSeries returns a new *Series with given metric
+func newSeries(metric model.Metric, points []Point) *Series {
+	labels := make(map[string]string, len(metric))
+	for name, value := range metric {
+		labels[string(name)] = string(value)
+	}
+
+	return &Series{
+		Labels: labels,
+		Points: points,
+	}
+}
+
+// Name returns the metric name of the series
+func (s *Series) Name() string {
+	return s.Labels[model.MetricNameLabel]
+}
+
+// Match returns if the series has all the given labels
+func (s *Series) Match(labels map[string]string) bool {
+	for name, value := range labels {
+		v, ok := s.Labels[name]
+		if !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Latest returns the last point of the series, it returns false if the series has no point
+func (s *Series) Latest() (Point, bool) {
+	if len(s.Points) == constant.ZeroInt {
+		return Point{}, false
+	}
+
+	return s.Points[len(s.Points)-1], true
+}
+
+// GetSeries converts the raw data to series, unlike the rows of the Result, all the series of the matrix are kept,
+// scalar is converted to a series without labels, string is not supported
+func (rd *RawData) GetSeries() ([]*Series, error) {
+	switch v := rd.GetValue().(type) {
+	case nil:
+		return nil, nil
+	case *model.Scalar:
+		if v == nil {
+			return nil, nil
+		}
+		return []*Series{newSeries(nil, []Point{{Timestamp: v.Timestamp.Time(), Value: float64(v.Value)}})}, nil
+	case model.Vector:
+		series := make([]*Series, len(v))
+		for i, sample := range v {
+			series[i] = newSeries(sample.Metric, []Point{{Timestamp: sample.Timestamp.Time(), Value: float64(sample.Value)}})
+		}
+		return series, nil
+	case model.Matrix:
+		series := make([]*Series, len(v))
+		for i, stream := range v {
+			points := make([]Point, len(stream.Values))
+			for j, pair := range stream.Values {
+				points[j] = Point{Timestamp: pair.Timestamp.Time(), Value: float64(pair.Value)}
+			}
+			series[i] = newSeries(stream.Metric, points)
+		}
+		return series, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("%T could not be converted to series", v))
+	}
+}
+
+// GetSeries returns the typed series of the result
+func (r *Result) GetSeries() ([]*Series, error) {
+	if r.Raw == nil {
+		return nil, nil
+	}
+
+	return r.Raw.GetSeries()
+}
+
+// GetSeriesByLabel returns the series which have all the given labels
+func (r *Result) GetSeriesByLabel(labels map[string]string) ([]*Series, error) {
+	series, err := r.GetSeries()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*Series
+	for _, s := range series {
+		if s.Match(labels) {
+			matched = append(matched, s)
+		}
+	}
+
+	return matched, nil
+}
+
+// GetFloatByLabel returns the latest value of the first series which has all the given labels
+func (r *Result) GetFloatByLabel(labels map[string]string) (float64, error) {
+	series, err := r.GetSeriesByLabel(labels)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	for _, s := range series {
+		point, ok := s.Latest()
+		if ok {
+			return point.Value, nil
+		}
+	}
+
+	return constant.ZeroInt, errors.New(fmt.Sprintf("no series matches the labels. labels: %v", labels))
+}
@@ -0,0 +1,87 @@
+package prometheus
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
+)
+
+func TestResult_GetSeries(t *testing.T) {
+	asst := assert.New(t)
+
+	matrix := model.Matrix{
+		{
+			Metric: model.Metric{model.MetricNameLabel: "up", "instance": "a"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 0}, {Timestamp: 2000, Value: 1}},
+		},
+		{
+			Metric: model.Metric{model.MetricNameLabel: "up", "instance": "b"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 1}},
+		},
+	}
+	result := NewResult(matrix, nil)
+
+	series, err := result.GetSeries()
+	asst.Nil(err, "test GetSeries() failed")
+	asst.Equal(2, len(series), "test GetSeries() failed")
+	asst.Equal("up", series[0].Name(), "test GetSeries() failed")
+	asst.Equal(2, len(series[0].Points), "test GetSeries() failed")
+	asst.Equal(int64(2), series[0].Points[1].Timestamp.Unix(), "test GetSeries() failed")
+
+	value, err := result.GetFloatByLabel(map[string]string{"instance": "a"})
+	asst.Nil(err, "test GetFloatByLabel() failed")
+	asst.Equal(1.0, value, "test GetFloatByLabel() failed")
+	value, err = result.GetFloatByLabel(map[string]string{"instance": "b"})
+	asst.Nil(err, "test GetFloatByLabel() failed")
+	asst.Equal(1.0, value, "test GetFloatByLabel() failed")
+	_, err = result.GetFloatByLabel(map[string]string{"instance": "c"})
+	asst.NotNil(err, "test GetFloatByLabel() failed")
+
+	result = NewResult(&model.Scalar{Timestamp: 1000, Value: 3}, nil)
+	series, err = result.GetSeries()
+	asst.Nil(err, "test GetSeries() failed")
+	asst.Equal(1, len(series), "test GetSeries() failed")
+	asst.Equal(3.0, series[0].Points[0].Value, "test GetSeries() failed")
+}
+
+func TestResult_Export(t *testing.T) {
+	asst := assert.New(t)
+
+	vector := model.Vector{
+		{Metric: model.Metric{"instance": "a"}, Timestamp: 1000, Value: 1.5},
+		{Metric: model.Metric{"instance": "b", "job": "node"}, Timestamp: 1000, Value: 2},
+	}
+	result := NewResult(vector, nil)
+
+	var buf bytes.Buffer
+	err := result.ToCSV(&buf)
+	asst.Nil(err, "test ToCSV() failed")
+	ts := time.Unix(1, 0).Format(constant.DefaultTimeLayout)
+	asst.Equal("instance,job,timestamp,value\na,,"+ts+",1.5\nb,node,"+ts+",2\n", buf.String(), "test ToCSV() failed")
+
+	data, err := result.ToJSON()
+	asst.Nil(err, "test ToJSON() failed")
+	var rows []map[string]interface{}
+	asst.Nil(json.Unmarshal(data, &rows), "test ToJSON() failed")
+	asst.Equal(2, len(rows), "test ToJSON() failed")
+	asst.Equal("2", rows[1]["value"], "test ToJSON() failed")
+	asst.Equal(map[string]interface{}{"instance": "b", "job": "node"}, rows[1]["labels"], "test ToJSON() failed")
+
+	// json does not support NaN and Inf, they are encoded as strings
+	result = NewResult(model.Vector{
+		{Metric: model.Metric{"instance": "a"}, Timestamp: 1000, Value: model.SampleValue(math.NaN())},
+		{Metric: model.Metric{"instance": "b"}, Timestamp: 1000, Value: model.SampleValue(math.Inf(1))},
+	}, nil)
+	data, err = result.ToJSON()
+	asst.Nil(err, "test ToJSON() failed")
+	asst.Nil(json.Unmarshal(data, &rows), "test ToJSON() failed")
+	asst.Equal("NaN", rows[0]["value"], "test ToJSON() failed")
+	asst.Equal("+Inf", rows[1]["value"], "test ToJSON() failed")
+}
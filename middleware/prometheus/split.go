@@ -0,0 +1,113 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/romberli/go-util/constant"
+)
+
+// MaxPointsPerQuery is the maximum resolution of a range query which is allowed by prometheus server,
+// the longer range queries will be split into chunks
+const MaxPointsPerQuery = 11000
+
+// splitRange splits the range into chunks, each chunk contains at most maxPoints points,
+// the chunks do not overlap, so the points of them could be concatenated directly
+func splitRange(r apiv1.Range, maxPoints int) []apiv1.Range {
+	if r.Step <= constant.ZeroInt || maxPoints <= constant.ZeroInt || !r.End.After(r.Start) {
+		return []apiv1.Range{r}
+	}
+
+	chunk := time.Duration(maxPoints-1) * r.Step
+	var ranges []apiv1.Range
+	for start := r.Start; !start.After(r.End); start = start.Add(chunk + r.Step) {
+		end := start.Add(chunk)
+		if end.After(r.End) {
+			end = r.End
+		}
+		ranges = append(ranges, apiv1.Range{Start: start, End: end, Step: r.Step})
+	}
+
+	return ranges
+}
+
+// queryRange executes the range query, if the range exceeds MaxPointsPerQuery,
+// it will be split into chunks which are queried concurrently, and the results are stitched back into one matrix
+func (conn *Conn) queryRange(ctx context.Context, command string, r apiv1.Range) (model.Value, apiv1.Warnings, error) {
+	ranges := splitRange(r, MaxPointsPerQuery)
+	if len(ranges) == 1 {
+		return conn.QueryRange(ctx, command, r)
+	}
+
+	values := make([]model.Value, len(ranges))
+	warnings := make([]apiv1.Warnings, len(ranges))
+	errs := make([]error, len(ranges))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, DefaultBatchConcurrency)
+	for i := range ranges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			values[i], warnings[i], errs[i] = conn.QueryRange(ctx, command, ranges[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var allWarnings apiv1.Warnings
+	for i := range ranges {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
+		}
+		allWarnings = append(allWarnings, warnings[i]...)
+	}
+
+	value, err := mergeMatrix(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return value, allWarnings, nil
+}
+
+// mergeMatrix stitches the matrices of the chunks back into one matrix, the values must be in the order of the chunks
+func mergeMatrix(values []model.Value) (model.Matrix, error) {
+	streams := make(map[model.Fingerprint]*model.SampleStream)
+	var fingerprints []model.Fingerprint
+
+	for _, value := range values {
+		matrix, ok := value.(model.Matrix)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("type assertion failed, %T could not be converted to model.Matrix", value))
+		}
+		for _, stream := range matrix {
+			fingerprint := stream.Metric.Fingerprint()
+			merged, exists := streams[fingerprint]
+			if !exists {
+				merged = &model.SampleStream{Metric: stream.Metric}
+				streams[fingerprint] = merged
+				fingerprints = append(fingerprints, fingerprint)
+			}
+			merged.Values = append(merged.Values, stream.Values...)
+		}
+	}
+
+	matrix := make(model.Matrix, len(fingerprints))
+	for i, fingerprint := range fingerprints {
+		matrix[i] = streams[fingerprint]
+	}
+	sort.Sort(matrix)
+
+	return matrix, nil
+}
@@ -0,0 +1,72 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitRange(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Unix(0, 0)
+	ranges := splitRange(apiv1.Range{Start: start, End: start.Add(24999 * time.Second), Step: time.Second}, MaxPointsPerQuery)
+	asst.Equal(3, len(ranges), "test splitRange() failed")
+	asst.Equal(start.Add(10999*time.Second), ranges[0].End, "test splitRange() failed")
+	asst.Equal(start.Add(11000*time.Second), ranges[1].Start, "test splitRange() failed")
+	asst.Equal(start.Add(24999*time.Second), ranges[2].End, "test splitRange() failed")
+
+	ranges = splitRange(apiv1.Range{Start: start, End: start.Add(time.Hour), Step: time.Minute}, MaxPointsPerQuery)
+	asst.Equal(1, len(ranges), "test splitRange() failed")
+}
+
+func TestConn_ExecuteSplitRange(t *testing.T) {
+	asst := assert.New(t)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		start, _ := strconv.ParseFloat(r.FormValue("start"), 64)
+		end, _ := strconv.ParseFloat(r.FormValue("end"), 64)
+		step, _ := strconv.ParseFloat(r.FormValue("step"), 64)
+
+		var values [][]interface{}
+		for ts := start; ts <= end; ts += step {
+			values = append(values, []interface{}{ts, strconv.FormatFloat(ts, 'f', -1, 64)})
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "matrix",
+				"result":     []interface{}{map[string]interface{}{"metric": map[string]string{"__name__": "up"}, "values": values}},
+			},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	c, err := NewConn(server.URL, nil)
+	asst.Nil(err, "test Execute() failed")
+
+	start := time.Unix(1600000000, 0)
+	result, err := c.Execute("up", start, start.Add(24999*time.Second), time.Second)
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(int32(3), atomic.LoadInt32(&requests), "test Execute() failed")
+	asst.Equal(25000, result.RowNumber(), "test Execute() failed")
+	for i := 0; i < result.RowNumber(); i++ {
+		value, err := result.GetInt(i, 0)
+		asst.Nil(err, "test Execute() failed")
+		if value != int(start.Unix())+i {
+			asst.Fail("points are not in order", "test Execute() failed")
+			break
+		}
+	}
+}
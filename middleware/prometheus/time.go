@@ -30,3 +30,46 @@ func NewTimeRangeWithRange(r apiv1.Range) TimeRange {
 func (tr TimeRange) GetRange() apiv1.Range {
 	return tr.Range
 }
+
+// DefaultMaxPoints is the default maximum number of points of a range query when the step is selected automatically
+const DefaultMaxPoints = 1000
+
+// niceSteps are the candidates of the automatically selected step, they are aligned to the common scrape intervals
+var niceSteps = []time.Duration{
+	time.Second, 5 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// AutoStep returns the smallest nice step which makes the range query return at most maxPoints points,
+// if the range needs a step larger than one day, the step will be rounded up to whole days
+func AutoStep(start, end time.Time, maxPoints int) time.Duration {
+	if maxPoints <= 1 {
+		maxPoints = DefaultMaxPoints
+	}
+	if !end.After(start) {
+		return niceSteps[0]
+	}
+
+	min := end.Sub(start) / time.Duration(maxPoints-1)
+	for _, step := range niceSteps {
+		if step >= min {
+			return step
+		}
+	}
+
+	day := niceSteps[len(niceSteps)-1]
+
+	return (min + day - 1) / day * day
+}
+
+// NewTimeRangeWithAutoStep returns a new TimeRange whose step is selected by AutoStep() with DefaultMaxPoints,
+// the step is at least DefaultStep
+func NewTimeRangeWithAutoStep(start, end time.Time) TimeRange {
+	step := AutoStep(start, end, DefaultMaxPoints)
+	if step < DefaultStep {
+		step = DefaultStep
+	}
+
+	return NewTimeRange(start, end, step)
+}
@@ -0,0 +1,21 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoStep(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Unix(1600000000, 0)
+	asst.Equal(5*time.Second, AutoStep(start, start.Add(time.Hour), DefaultMaxPoints), "test AutoStep() failed")
+	asst.Equal(time.Hour, AutoStep(start, start.Add(30*24*time.Hour), DefaultMaxPoints), "test AutoStep() failed")
+	asst.Equal(2*24*time.Hour, AutoStep(start, start.Add(1000*24*time.Hour), DefaultMaxPoints), "test AutoStep() failed")
+	asst.Equal(time.Second, AutoStep(start, start, DefaultMaxPoints), "test AutoStep() failed")
+
+	asst.Equal(DefaultStep, NewTimeRangeWithAutoStep(start, start.Add(time.Hour)).Step, "test NewTimeRangeWithAutoStep() failed")
+	asst.Equal(time.Hour, NewTimeRangeWithAutoStep(start, start.Add(30*24*time.Hour)).Step, "test NewTimeRangeWithAutoStep() failed")
+}
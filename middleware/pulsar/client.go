@@ -0,0 +1,96 @@
+package pulsar
+
+import (
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+)
+
+const (
+	DefaultConnectionTimeout = 10 * time.Second
+	DefaultOperationTimeout  = 30 * time.Second
+)
+
+type Config struct {
+	URL               string
+	ConnectionTimeout time.Duration
+	OperationTimeout  time.Duration
+	Token             string
+	TLSTrustCertsFile string
+}
+
+// NewConfig returns a new Config
+func NewConfig(url string, connectionTimeout, operationTimeout time.Duration, token, tlsTrustCertsFile string) Config {
+	return Config{
+		URL:               url,
+		ConnectionTimeout: connectionTimeout,
+		OperationTimeout:  operationTimeout,
+		Token:             token,
+		TLSTrustCertsFile: tlsTrustCertsFile,
+	}
+}
+
+// NewConfigWithDefault returns a new Config with default values,
+// url should be like pulsar://host:6650 or pulsar+ssl://host:6651
+func NewConfigWithDefault(url string) Config {
+	return Config{
+		URL:               url,
+		ConnectionTimeout: DefaultConnectionTimeout,
+		OperationTimeout:  DefaultOperationTimeout,
+	}
+}
+
+// ClientOptions converts the config to pulsar.ClientOptions
+func (c Config) ClientOptions() pulsar.ClientOptions {
+	opts := pulsar.ClientOptions{
+		URL:                   c.URL,
+		ConnectionTimeout:     c.ConnectionTimeout,
+		OperationTimeout:      c.OperationTimeout,
+		TLSTrustCertsFilePath: c.TLSTrustCertsFile,
+	}
+	if c.Token != constant.EmptyString {
+		opts.Authentication = pulsar.NewAuthenticationToken(c.Token)
+	}
+
+	return opts
+}
+
+type Conn struct {
+	Config
+	pulsar.Client
+}
+
+// NewConn returns a new *Conn with default config
+func NewConn(url string) (*Conn, error) {
+	return NewConnWithConfig(NewConfigWithDefault(url))
+}
+
+// NewConnWithConfig returns a new *Conn with given config, the token could be encrypted by the credential package
+func NewConnWithConfig(config Config) (*Conn, error) {
+	connConfig := config
+	token, err := credential.DecryptIfNeeded(config.Token)
+	if err != nil {
+		return nil, err
+	}
+	connConfig.Token = token
+
+	client, err := pulsar.NewClient(connConfig.ClientOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		Config: config,
+		Client: client,
+	}, nil
+}
+
+// Close closes the client and all producers and consumers created by it
+func (conn *Conn) Close() error {
+	conn.Client.Close()
+
+	return nil
+}
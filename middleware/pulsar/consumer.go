@@ -0,0 +1,152 @@
+package pulsar
+
+import (
+	"context"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultNackRedeliveryDelay = time.Minute
+
+// Handler handles the received messages, if Handle() returns nil, the message will be acknowledged,
+// otherwise, the message will be negatively acknowledged and redelivered later,
+// if a dead letter policy is specified, the message will be sent to the dead letter topic
+// after reaching the maximum delivery times
+type Handler interface {
+	Handle(ctx context.Context, message pulsar.Message) error
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as message handlers
+type HandlerFunc func(ctx context.Context, message pulsar.Message) error
+
+// Handle calls f(ctx, message)
+func (f HandlerFunc) Handle(ctx context.Context, message pulsar.Message) error {
+	return f(ctx, message)
+}
+
+type DefaultHandler struct{}
+
+// Handle logs the message
+func (DefaultHandler) Handle(ctx context.Context, message pulsar.Message) error {
+	log.Infof("topic: %s, id: %v, key: %s, payload: %s, properties: %v",
+		message.Topic(), message.ID(), message.Key(), string(message.Payload()), message.Properties())
+
+	return nil
+}
+
+type ConsumerConfig struct {
+	Topics           []string
+	SubscriptionName string
+	SubscriptionType pulsar.SubscriptionType
+	InitialPosition  pulsar.SubscriptionInitialPosition
+	// MaxDeliveries and DeadLetterTopic make up the dead letter policy,
+	// if MaxDeliveries is 0, dead letter policy will be disabled
+	MaxDeliveries       uint32
+	DeadLetterTopic     string
+	NackRedeliveryDelay time.Duration
+	Schema              pulsar.Schema
+}
+
+// NewConsumerConfig returns a new ConsumerConfig
+func NewConsumerConfig(subscriptionName string, subscriptionType pulsar.SubscriptionType, topics ...string) ConsumerConfig {
+	return ConsumerConfig{
+		Topics:              topics,
+		SubscriptionName:    subscriptionName,
+		SubscriptionType:    subscriptionType,
+		InitialPosition:     pulsar.SubscriptionPositionLatest,
+		NackRedeliveryDelay: DefaultNackRedeliveryDelay,
+	}
+}
+
+// WithDeadLetterPolicy sets the dead letter policy of the config
+func (cc ConsumerConfig) WithDeadLetterPolicy(maxDeliveries uint32, deadLetterTopic string) ConsumerConfig {
+	cc.MaxDeliveries = maxDeliveries
+	cc.DeadLetterTopic = deadLetterTopic
+
+	return cc
+}
+
+// WithSchema sets the schema of the config
+func (cc ConsumerConfig) WithSchema(schema pulsar.Schema) ConsumerConfig {
+	cc.Schema = schema
+
+	return cc
+}
+
+// ConsumerOptions converts the config to pulsar.ConsumerOptions
+func (cc ConsumerConfig) ConsumerOptions() pulsar.ConsumerOptions {
+	opts := pulsar.ConsumerOptions{
+		Topics:                      cc.Topics,
+		SubscriptionName:            cc.SubscriptionName,
+		Type:                        cc.SubscriptionType,
+		SubscriptionInitialPosition: cc.InitialPosition,
+		NackRedeliveryDelay:         cc.NackRedeliveryDelay,
+		Schema:                      cc.Schema,
+	}
+	if cc.MaxDeliveries > constant.ZeroInt {
+		opts.DLQ = &pulsar.DLQPolicy{
+			MaxDeliveries:   cc.MaxDeliveries,
+			DeadLetterTopic: cc.DeadLetterTopic,
+		}
+	}
+
+	return opts
+}
+
+type Consumer struct {
+	Conn     *Conn
+	Config   ConsumerConfig
+	Consumer pulsar.Consumer
+}
+
+// NewConsumer returns a new *Consumer which subscribes given topics
+func NewConsumer(conn *Conn, config ConsumerConfig) (*Consumer, error) {
+	consumer, err := conn.Subscribe(config.ConsumerOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Consumer{
+		Conn:     conn,
+		Config:   config,
+		Consumer: consumer,
+	}, nil
+}
+
+// Close closes the consumer
+func (c *Consumer) Close() error {
+	if c.Consumer != nil {
+		c.Consumer.Close()
+	}
+
+	return nil
+}
+
+// Consume receives messages and passes them to the handler until the context is done,
+// it acknowledges the message if handler returns nil, otherwise, it negatively acknowledges the message
+func (c *Consumer) Consume(ctx context.Context, handler Handler) error {
+	for {
+		message, err := c.Consumer.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		err = handler.Handle(ctx, message)
+		if err != nil {
+			log.Errorf("handle message failed, will redeliver later. subscription: %s, topic: %s, id: %v. %s",
+				c.Config.SubscriptionName, message.Topic(), message.ID(), err.Error())
+			c.Consumer.Nack(message)
+			continue
+		}
+
+		c.Consumer.Ack(message)
+	}
+}
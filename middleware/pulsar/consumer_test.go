@@ -0,0 +1,30 @@
+package pulsar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsume(t *testing.T) {
+	asst := assert.New(t)
+
+	conn, err := NewConn(defaultURL)
+	asst.Nil(err, "create connection failed")
+	defer func() { _ = conn.Close() }()
+
+	config := NewConsumerConfig("sub001", pulsar.KeyShared, defaultTopic).
+		WithDeadLetterPolicy(3, defaultTopic+"-dlq")
+	c, err := NewConsumer(conn, config)
+	asst.Nil(err, "create consumer failed. topic: %s", defaultTopic)
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = c.Consume(ctx, DefaultHandler{})
+	asst.Nil(err, "consume failed. topic: %s", defaultTopic)
+}
@@ -0,0 +1,112 @@
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+)
+
+type Producer struct {
+	Conn     *Conn
+	Topic    string
+	Producer pulsar.Producer
+}
+
+// NewProducer returns a new *Producer of given topic, schema is optional, if schema is nil, messages will be sent as raw bytes
+func NewProducer(conn *Conn, topic string, schema pulsar.Schema) (*Producer, error) {
+	return NewProducerWithOptions(conn, pulsar.ProducerOptions{
+		Topic:  topic,
+		Schema: schema,
+	})
+}
+
+// NewProducerWithOptions returns a new *Producer with given pulsar.ProducerOptions
+func NewProducerWithOptions(conn *Conn, opts pulsar.ProducerOptions) (*Producer, error) {
+	producer, err := conn.CreateProducer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Producer{
+		Conn:     conn,
+		Topic:    opts.Topic,
+		Producer: producer,
+	}, nil
+}
+
+// Close flushes pending messages and closes the producer
+func (p *Producer) Close() error {
+	if p.Producer != nil {
+		p.Producer.Close()
+	}
+
+	return nil
+}
+
+// BuildProducerMessage returns a new *pulsar.ProducerMessage, key is used for routing and key-shared subscriptions
+func (p *Producer) BuildProducerMessage(key string, payload []byte, properties map[string]string) *pulsar.ProducerMessage {
+	return &pulsar.ProducerMessage{
+		Key:        key,
+		Payload:    payload,
+		Properties: properties,
+	}
+}
+
+// BuildProducerMessageWithValue returns a new *pulsar.ProducerMessage with given value,
+// the value will be encoded by the schema of the producer
+func (p *Producer) BuildProducerMessageWithValue(key string, value interface{}, properties map[string]string) *pulsar.ProducerMessage {
+	return &pulsar.ProducerMessage{
+		Key:        key,
+		Value:      value,
+		Properties: properties,
+	}
+}
+
+// Produce sends the message synchronously and returns the message id,
+// message must be either string, []byte or *pulsar.ProducerMessage type
+func (p *Producer) Produce(ctx context.Context, message interface{}) (pulsar.MessageID, error) {
+	msg, err := p.convertMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Producer.Send(ctx, msg)
+}
+
+// ProduceAsync sends the message asynchronously, callback will be called when the message is acknowledged by the broker,
+// message must be either string, []byte or *pulsar.ProducerMessage type
+func (p *Producer) ProduceAsync(ctx context.Context, message interface{},
+	callback func(pulsar.MessageID, *pulsar.ProducerMessage, error)) error {
+	msg, err := p.convertMessage(message)
+	if err != nil {
+		return err
+	}
+
+	p.Producer.SendAsync(ctx, msg, callback)
+
+	return nil
+}
+
+// Flush flushes all the messages buffered in the client
+func (p *Producer) Flush() error {
+	return p.Producer.Flush()
+}
+
+// convertMessage converts given message to *pulsar.ProducerMessage
+func (p *Producer) convertMessage(message interface{}) (*pulsar.ProducerMessage, error) {
+	switch m := message.(type) {
+	case string:
+		return &pulsar.ProducerMessage{Payload: []byte(m)}, nil
+	case []byte:
+		return &pulsar.ProducerMessage{Payload: m}, nil
+	case *pulsar.ProducerMessage:
+		return m, nil
+	default:
+		return nil, errors.New(
+			fmt.Sprintf("message must be string, []byte or *pulsar.ProducerMessage type, but got %s",
+				reflect.TypeOf(message).String()))
+	}
+}
@@ -0,0 +1,49 @@
+package pulsar
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/romberli/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultURL   = "pulsar://192.168.10.219:6650"
+	defaultTopic = "persistent://public/default/test001"
+)
+
+func TestProduce(t *testing.T) {
+	asst := assert.New(t)
+
+	conn, err := NewConn(defaultURL)
+	asst.Nil(err, "create connection failed")
+	defer func() { _ = conn.Close() }()
+
+	p, err := NewProducer(conn, defaultTopic, nil)
+	asst.Nil(err, "create producer failed. topic: %s", defaultTopic)
+	defer func() {
+		err = p.Close()
+		if err != nil {
+			log.Errorf("close producer failed. topic: %s, message: %s", defaultTopic, err.Error())
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 10; i++ {
+		ts := time.Now().String()
+		if i%2 == 0 {
+			_, err = p.Produce(ctx, ts)
+			asst.Nil(err, "produce string message failed. topic: %s, message: %s", defaultTopic, ts)
+		} else {
+			message := p.BuildProducerMessage(strconv.Itoa(i), []byte(ts), map[string]string{"index": fmt.Sprintf("%d", i)})
+			_, err = p.Produce(ctx, message)
+			asst.Nil(err, "produce producer message failed. topic: %s, message: %v", defaultTopic, message)
+		}
+	}
+}
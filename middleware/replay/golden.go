@@ -0,0 +1,269 @@
+package replay
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/percona/go-mysql/query"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	cellTypeNull   = "null"
+	cellTypeInt    = "int"
+	cellTypeUint   = "uint"
+	cellTypeFloat  = "float"
+	cellTypeBool   = "bool"
+	cellTypeString = "string"
+	cellTypeBytes  = "bytes"
+	cellTypeTime   = "time"
+
+	defaultFileMode = 0644
+	defaultDirMode  = 0755
+)
+
+// Cell is the json representation of a driver.Value, the type is kept so that the value could be restored exactly
+type Cell struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+// newCell returns a new Cell of given value
+func newCell(value driver.Value) Cell {
+	switch v := value.(type) {
+	case nil:
+		return Cell{Type: cellTypeNull}
+	case int:
+		return Cell{Type: cellTypeInt, Value: strconv.FormatInt(int64(v), 10)}
+	case int8:
+		return Cell{Type: cellTypeInt, Value: strconv.FormatInt(int64(v), 10)}
+	case int16:
+		return Cell{Type: cellTypeInt, Value: strconv.FormatInt(int64(v), 10)}
+	case int32:
+		return Cell{Type: cellTypeInt, Value: strconv.FormatInt(int64(v), 10)}
+	case int64:
+		return Cell{Type: cellTypeInt, Value: strconv.FormatInt(v, 10)}
+	case uint:
+		return Cell{Type: cellTypeUint, Value: strconv.FormatUint(uint64(v), 10)}
+	case uint8:
+		return Cell{Type: cellTypeUint, Value: strconv.FormatUint(uint64(v), 10)}
+	case uint16:
+		return Cell{Type: cellTypeUint, Value: strconv.FormatUint(uint64(v), 10)}
+	case uint32:
+		return Cell{Type: cellTypeUint, Value: strconv.FormatUint(uint64(v), 10)}
+	case uint64:
+		return Cell{Type: cellTypeUint, Value: strconv.FormatUint(v, 10)}
+	case float32:
+		return Cell{Type: cellTypeFloat, Value: strconv.FormatFloat(float64(v), 'g', -1, 32)}
+	case float64:
+		return Cell{Type: cellTypeFloat, Value: strconv.FormatFloat(v, 'g', -1, 64)}
+	case bool:
+		return Cell{Type: cellTypeBool, Value: strconv.FormatBool(v)}
+	case []byte:
+		return Cell{Type: cellTypeBytes, Value: base64.StdEncoding.EncodeToString(v)}
+	case time.Time:
+		return Cell{Type: cellTypeTime, Value: v.Format(time.RFC3339Nano)}
+	case string:
+		return Cell{Type: cellTypeString, Value: v}
+	default:
+		return Cell{Type: cellTypeString, Value: fmt.Sprintf("%v", v)}
+	}
+}
+
+// driverValue restores the driver.Value of the cell
+func (c Cell) driverValue() (driver.Value, error) {
+	switch c.Type {
+	case cellTypeNull:
+		return nil, nil
+	case cellTypeInt:
+		return strconv.ParseInt(c.Value, 10, 64)
+	case cellTypeUint:
+		return strconv.ParseUint(c.Value, 10, 64)
+	case cellTypeFloat:
+		return strconv.ParseFloat(c.Value, 64)
+	case cellTypeBool:
+		return strconv.ParseBool(c.Value)
+	case cellTypeBytes:
+		return base64.StdEncoding.DecodeString(c.Value)
+	case cellTypeTime:
+		return time.Parse(time.RFC3339Nano, c.Value)
+	case cellTypeString:
+		return c.Value, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported cell type: %s", c.Type))
+	}
+}
+
+// Entry is a recorded call of Execute()
+type Entry struct {
+	Command     string   `json:"command"`
+	Args        []string `json:"args,omitempty"`
+	Fingerprint string   `json:"fingerprint"`
+	Fields      []string `json:"fields,omitempty"`
+	Values      [][]Cell `json:"values,omitempty"`
+	// LastInsertID and RowsAffected are nil if the middleware does not support metadata
+	LastInsertID *int `json:"last_insert_id,omitempty"`
+	RowsAffected *int `json:"rows_affected,omitempty"`
+	// Error is the message of the error returned by the middleware, the result is not recorded if it is not empty
+	Error string `json:"error,omitempty"`
+}
+
+// Fingerprint returns the fingerprint of the command, the literals are replaced with placeholders
+// and the whitespaces are collapsed, so that the commands which differ only in values share the same fingerprint
+func Fingerprint(command string) string {
+	return query.Fingerprint(command)
+}
+
+// formatArgs returns the string representations of the args
+func formatArgs(args []interface{}) []string {
+	if len(args) == constant.ZeroInt {
+		return nil
+	}
+
+	strArgs := make([]string, len(args))
+	for i, arg := range args {
+		cell := newCell(arg)
+		strArgs[i] = cell.Type + constant.ColonString + cell.Value
+	}
+
+	return strArgs
+}
+
+// matches returns if the entry has exactly the same command and args
+func (e *Entry) matches(command string, args []string) bool {
+	if e.Command != command || len(e.Args) != len(args) {
+		return false
+	}
+	for i := range args {
+		if e.Args[i] != args[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Golden is a golden file which holds the recorded entries
+type Golden struct {
+	path string
+
+	mutex   sync.Mutex
+	entries []*Entry
+	used    []bool
+}
+
+// NewGolden returns a new empty *Golden which will be saved to given path
+func NewGolden(path string) *Golden {
+	return &Golden{path: path}
+}
+
+// LoadGolden loads the golden file of given path
+func LoadGolden(path string) (*Golden, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unmarshal golden file failed. path: %s. %s", path, err.Error()))
+	}
+
+	return &Golden{
+		path:    path,
+		entries: entries,
+		used:    make([]bool, len(entries)),
+	}, nil
+}
+
+// Path returns the path of the golden file
+func (g *Golden) Path() string {
+	return g.path
+}
+
+// Entries returns the recorded entries
+func (g *Golden) Entries() []*Entry {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	entries := make([]*Entry, len(g.entries))
+	copy(entries, g.entries)
+
+	return entries
+}
+
+// Add appends the entry to the golden file
+func (g *Golden) Add(entry *Entry) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.entries = append(g.entries, entry)
+	g.used = append(g.used, false)
+}
+
+// Find finds the entry of given command and args, the candidates are tried in following order:
+// 1. the unused entry which has exactly the same command and args
+// 2. the unused entry which has the same fingerprint
+// 3. the used entry which has exactly the same command and args
+// 4. the used entry which has the same fingerprint
+// the unused entries are matched in the recording order, so that repeated calls replay the results one by one
+func (g *Golden) Find(command string, args ...interface{}) (*Entry, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	strArgs := formatArgs(args)
+	fingerprint := Fingerprint(command)
+
+	matchers := []func(e *Entry) bool{
+		func(e *Entry) bool { return e.matches(command, strArgs) },
+		func(e *Entry) bool { return e.Fingerprint == fingerprint },
+	}
+	for _, reuse := range []bool{false, true} {
+		for _, match := range matchers {
+			for i, entry := range g.entries {
+				if g.used[i] != reuse || !match(entry) {
+					continue
+				}
+				g.used[i] = true
+
+				return entry, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Save writes the entries to the golden file, the parent directories will be created if not exist
+func (g *Golden) Save() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	entries := g.entries
+	if entries == nil {
+		entries = []*Entry{}
+	}
+	data, err := json.MarshalIndent(entries, constant.EmptyString, strings.Repeat(constant.SpaceString, 4))
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(g.path), defaultDirMode)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(g.path, data, defaultFileMode)
+}
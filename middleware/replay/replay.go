@@ -0,0 +1,323 @@
+package replay
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/result"
+)
+
+const (
+	middlewareType = "replay"
+	rowsFieldName  = "Rows"
+)
+
+type Mode int
+
+const (
+	// ModeReplay serves the results from the golden file without touching the middleware
+	ModeReplay Mode = iota
+	// ModeRecord executes the commands on the middleware and records the results to the golden file
+	ModeRecord
+	// ModePassthrough executes the commands on the middleware without recording
+	ModePassthrough
+)
+
+// ModeFromString returns the mode of given string, it is convenient to switch the mode with an environment variable,
+// empty string means ModeReplay
+func ModeFromString(mode string) (Mode, error) {
+	switch strings.ToLower(mode) {
+	case constant.EmptyString, "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "passthrough":
+		return ModePassthrough, nil
+	default:
+		return ModeReplay, errors.New(fmt.Sprintf("mode should be one of replay, record and passthrough, %s is not valid", mode))
+	}
+}
+
+// ErrNotRecorded is returned in replay mode when there is no recorded entry matches the command
+var ErrNotRecorded = errors.New("command was not recorded in the golden file")
+
+var (
+	_ middleware.PoolConn  = (*Conn)(nil)
+	_ middleware.Statement = (*Statement)(nil)
+	_ middleware.Result    = (*Result)(nil)
+)
+
+// Conn wraps a middleware connection, it records or replays the calls of Execute() with the golden file
+type Conn struct {
+	conn   middleware.PoolConn
+	mode   Mode
+	golden *Golden
+}
+
+// NewConn returns a new *Conn, conn could be nil in replay mode
+func NewConn(conn middleware.PoolConn, mode Mode, golden *Golden) (*Conn, error) {
+	if golden == nil {
+		return nil, errors.New("golden file should not be nil")
+	}
+	if conn == nil && mode != ModeReplay {
+		return nil, errors.New("connection should not be nil when mode is not replay")
+	}
+
+	return &Conn{
+		conn:   conn,
+		mode:   mode,
+		golden: golden,
+	}, nil
+}
+
+// Mode returns the mode of the connection
+func (c *Conn) Mode() Mode {
+	return c.mode
+}
+
+// Golden returns the golden file of the connection
+func (c *Conn) Golden() *Golden {
+	return c.golden
+}
+
+// Close saves the golden file in record mode and returns the underlying connection back to the pool
+func (c *Conn) Close() error {
+	if c.mode == ModeRecord {
+		err := c.golden.Save()
+		if err != nil {
+			return err
+		}
+	}
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
+
+// Disconnect saves the golden file in record mode and disconnects the underlying connection
+func (c *Conn) Disconnect() error {
+	if c.mode == ModeRecord {
+		err := c.golden.Save()
+		if err != nil {
+			return err
+		}
+	}
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Disconnect()
+}
+
+// IsValid always returns true in replay mode, otherwise, it validates the underlying connection
+func (c *Conn) IsValid() bool {
+	if c.conn == nil {
+		return c.mode == ModeReplay
+	}
+
+	return c.conn.IsValid()
+}
+
+// Prepare returns a Statement which records or replays the command with the connection,
+// note that the command is not prepared on the middleware
+func (c *Conn) Prepare(command string) (middleware.Statement, error) {
+	return c.PrepareContext(context.Background(), command)
+}
+
+// PrepareContext returns a Statement which records or replays the command with the connection,
+// note that the command is not prepared on the middleware
+func (c *Conn) PrepareContext(ctx context.Context, command string) (middleware.Statement, error) {
+	return &Statement{
+		conn:    c,
+		command: command,
+	}, nil
+}
+
+// Execute executes given command and placeholders, or replays the recorded result
+func (c *Conn) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return c.ExecuteContext(context.Background(), command, args...)
+}
+
+// ExecuteContext executes given command and placeholders with context, or replays the recorded result
+func (c *Conn) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	switch c.mode {
+	case ModeReplay:
+		return c.replay(command, args...)
+	case ModeRecord:
+		return c.record(ctx, command, args...)
+	default:
+		return c.conn.ExecuteContext(ctx, command, args...)
+	}
+}
+
+// replay returns the recorded result of the command
+func (c *Conn) replay(command string, args ...interface{}) (middleware.Result, error) {
+	entry, ok := c.golden.Find(command, args...)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("%s. golden file: %s, command: %s", ErrNotRecorded.Error(), c.golden.Path(), command))
+	}
+	if entry.Error != constant.EmptyString {
+		return nil, errors.New(entry.Error)
+	}
+
+	return NewResult(entry)
+}
+
+// record executes the command on the middleware and records the result
+func (c *Conn) record(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	entry := &Entry{
+		Command:     command,
+		Args:        formatArgs(args),
+		Fingerprint: Fingerprint(command),
+	}
+
+	res, err := c.conn.ExecuteContext(ctx, command, args...)
+	if err != nil {
+		entry.Error = err.Error()
+		c.golden.Add(entry)
+		return nil, err
+	}
+
+	err = fillEntry(entry, res)
+	if err != nil {
+		return nil, err
+	}
+	c.golden.Add(entry)
+
+	return res, nil
+}
+
+// fillEntry fills the fields, values and metadata of the result to the entry
+func fillEntry(entry *Entry, res middleware.Result) error {
+	entry.Fields = make([]string, res.ColumnNumber())
+	fields := fieldSlice(res)
+	for i := range entry.Fields {
+		if i < len(fields) {
+			entry.Fields[i] = fields[i]
+			continue
+		}
+		entry.Fields[i] = strconv.Itoa(i)
+	}
+
+	entry.Values = make([][]Cell, res.RowNumber())
+	for i := range entry.Values {
+		entry.Values[i] = make([]Cell, res.ColumnNumber())
+		for j := range entry.Values[i] {
+			value, err := res.GetValue(i, j)
+			if err != nil {
+				return err
+			}
+			entry.Values[i][j] = newCell(value)
+		}
+	}
+
+	lastInsertID, err := res.LastInsertID()
+	if err == nil {
+		entry.LastInsertID = &lastInsertID
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		entry.RowsAffected = &rowsAffected
+	}
+
+	return nil
+}
+
+// fieldSlice returns the column names of the result, the results of this repository embed *result.Rows,
+// if the result does not embed it, nil will be returned
+func fieldSlice(res middleware.Result) []string {
+	val := reflect.Indirect(reflect.ValueOf(res))
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	field := val.FieldByName(rowsFieldName)
+	if !field.IsValid() || !field.CanInterface() {
+		return nil
+	}
+	rows, ok := field.Interface().(*result.Rows)
+	if !ok || rows == nil {
+		return nil
+	}
+
+	return rows.FieldSlice
+}
+
+// Statement records or replays the prepared command with the connection
+type Statement struct {
+	conn    *Conn
+	command string
+}
+
+// Execute executes the statement with given placeholders, or replays the recorded result
+func (s *Statement) Execute(args ...interface{}) (middleware.Result, error) {
+	return s.conn.Execute(s.command, args...)
+}
+
+// ExecuteContext executes the statement with given placeholders and context, or replays the recorded result
+func (s *Statement) ExecuteContext(ctx context.Context, args ...interface{}) (middleware.Result, error) {
+	return s.conn.ExecuteContext(ctx, s.command, args...)
+}
+
+// Result is the replayed result
+type Result struct {
+	Raw *Entry
+	*result.Rows
+	result.Map
+}
+
+// NewResult returns a new *Result of the recorded entry
+func NewResult(entry *Entry) (*Result, error) {
+	fieldMap := make(map[string]int, len(entry.Fields))
+	for i, field := range entry.Fields {
+		fieldMap[field] = i
+	}
+
+	values := make([][]driver.Value, len(entry.Values))
+	for i, row := range entry.Values {
+		values[i] = make([]driver.Value, len(row))
+		for j, cell := range row {
+			value, err := cell.driverValue()
+			if err != nil {
+				return nil, err
+			}
+			values[i][j] = value
+		}
+	}
+
+	return &Result{
+		Raw:  entry,
+		Rows: result.NewRows(entry.Fields, fieldMap, values),
+		Map:  result.NewEmptyMap(middlewareType),
+	}, nil
+}
+
+// LastInsertID returns the recorded last insert id
+func (r *Result) LastInsertID() (int, error) {
+	if r.Raw.LastInsertID == nil {
+		return constant.ZeroInt, errors.New("LastInsertID() was not supported by the recorded middleware")
+	}
+
+	return *r.Raw.LastInsertID, nil
+}
+
+// RowsAffected returns the recorded number of affected rows
+func (r *Result) RowsAffected() (int, error) {
+	if r.Raw.RowsAffected == nil {
+		return constant.ZeroInt, errors.New("RowsAffected() was not supported by the recorded middleware")
+	}
+
+	return *r.Raw.RowsAffected, nil
+}
+
+// GetRaw returns the recorded entry
+func (r *Result) GetRaw() interface{} {
+	return r.Raw
+}
@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type fakeConn struct {
+	executed int
+}
+
+func (fc *fakeConn) Close() error      { return nil }
+func (fc *fakeConn) Disconnect() error { return nil }
+func (fc *fakeConn) IsValid() bool     { return true }
+
+func (fc *fakeConn) Prepare(command string) (middleware.Statement, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fc *fakeConn) PrepareContext(ctx context.Context, command string) (middleware.Statement, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fc *fakeConn) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return fc.ExecuteContext(context.Background(), command, args...)
+}
+
+func (fc *fakeConn) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	fc.executed++
+	if command == "select * from t_missing" {
+		return nil, errors.New("table t_missing does not exist")
+	}
+
+	id := args[0].(int)
+	affected := 0
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	return NewResult(&Entry{
+		Fields: []string{"id", "name", "data", "created_at", "score"},
+		Values: [][]Cell{{
+			newCell(int64(id)),
+			newCell(nil),
+			newCell([]byte("raw")),
+			newCell(ts),
+			newCell(1.5),
+		}},
+		RowsAffected: &affected,
+	})
+}
+
+func TestReplay(t *testing.T) {
+	asst := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "testdata", "replay.golden.json")
+	command := "select * from t_user where id = ?"
+
+	// record
+	fc := &fakeConn{}
+	conn, err := NewConn(fc, ModeRecord, NewGolden(path))
+	asst.Nil(err, "test NewConn() failed")
+	_, err = conn.Execute(command, 1)
+	asst.Nil(err, "test Execute() failed")
+	_, err = conn.Execute(command, 2)
+	asst.Nil(err, "test Execute() failed")
+	_, err = conn.Execute("select * from t_missing")
+	asst.NotNil(err, "test Execute() failed")
+	asst.Nil(conn.Close(), "test Close() failed")
+	asst.Equal(3, fc.executed, "test Execute() failed")
+
+	// replay
+	golden, err := LoadGolden(path)
+	asst.Nil(err, "test LoadGolden() failed")
+	asst.Equal(3, len(golden.Entries()), "test LoadGolden() failed")
+	conn, err = NewConn(nil, ModeReplay, golden)
+	asst.Nil(err, "test NewConn() failed")
+
+	res, err := conn.Execute(command, 2)
+	asst.Nil(err, "test Execute() failed")
+	id, err := res.GetIntByName(0, "id")
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(2, id, "test Execute() failed")
+	isNull, err := res.IsNullByName(0, "name")
+	asst.Nil(err, "test Execute() failed")
+	asst.True(isNull, "test Execute() failed")
+	data, err := res.GetValueByName(0, "data")
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal([]byte("raw"), data, "test Execute() failed")
+	createdAt, err := res.GetValueByName(0, "created_at")
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), createdAt, "test Execute() failed")
+	score, err := res.GetFloatByName(0, "score")
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(1.5, score, "test Execute() failed")
+	affected, err := res.RowsAffected()
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(0, affected, "test Execute() failed")
+	_, err = res.LastInsertID()
+	asst.NotNil(err, "test Execute() failed")
+
+	// the command differs in whitespaces and args, it matches the remaining entry by fingerprint
+	res, err = conn.Execute("SELECT *   FROM t_user WHERE id = ?", 3)
+	asst.Nil(err, "test Execute() failed")
+	id, err = res.GetIntByName(0, "id")
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(1, id, "test Execute() failed")
+
+	stmt, err := conn.Prepare(command)
+	asst.Nil(err, "test Prepare() failed")
+	res, err = stmt.Execute(2)
+	asst.Nil(err, "test Execute() failed")
+	id, err = res.GetIntByName(0, "id")
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(2, id, "test Execute() failed")
+
+	_, err = conn.Execute("select * from t_missing")
+	asst.EqualError(err, "table t_missing does not exist", "test Execute() failed")
+	_, err = conn.Execute("select * from t_other")
+	asst.NotNil(err, "test Execute() failed")
+}
+
+func TestModeFromString(t *testing.T) {
+	asst := assert.New(t)
+
+	mode, err := ModeFromString("Record")
+	asst.Nil(err, "test ModeFromString() failed")
+	asst.Equal(ModeRecord, mode, "test ModeFromString() failed")
+	mode, err = ModeFromString("")
+	asst.Nil(err, "test ModeFromString() failed")
+	asst.Equal(ModeReplay, mode, "test ModeFromString() failed")
+	_, err = ModeFromString("unknown")
+	asst.NotNil(err, "test ModeFromString() failed")
+}
@@ -0,0 +1,187 @@
+package result
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// Get gets the value of given row and column number and stores it in the value pointed to by dest,
+// it works with any result which implements Value interface, so the callers do not need the typed getter of each type.
+// dest must be a non-nil pointer, the value will be converted to the type of dest,
+// supported types are bool, integers, floats, string, []byte, time.Time, slices of them, interface{},
+// pointers to them and the types which implement sql.Scanner interface,
+// if the value is null, the pointer will be set to nil, and the other types will be set to zero value
+func Get(r Value, row, column int, dest interface{}) error {
+	value, err := r.GetValue(row, column)
+	if err != nil {
+		return err
+	}
+
+	return assign(value, dest)
+}
+
+// GetByName gets the value of given row number and column name and stores it in the value pointed to by dest,
+// see Get() for the supported types of dest
+func GetByName(r Value, row int, name string, dest interface{}) error {
+	column, err := r.NameIndex(name)
+	if err != nil {
+		return err
+	}
+
+	return Get(r, row, column, dest)
+}
+
+// assign converts the value and stores it in the value pointed to by dest
+func assign(value interface{}, dest interface{}) error {
+	scanner, ok := dest.(sql.Scanner)
+	if ok {
+		return scanner.Scan(value)
+	}
+
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+		return errors.New(fmt.Sprintf("dest must be a non-nil pointer, %T is not valid", dest))
+	}
+
+	return convertValue(value, destVal.Elem())
+}
+
+// convertValue converts the value to the type of dest and sets it to dest
+func convertValue(value interface{}, dest reflect.Value) error {
+	if dest.Kind() == reflect.Ptr {
+		if value == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+
+		return assign(value, dest.Interface())
+	}
+
+	if value == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+
+	if dest.Type() == timeType {
+		return convertTime(value, dest)
+	}
+
+	switch dest.Kind() {
+	case reflect.Interface:
+		dest.Set(reflect.ValueOf(value))
+	case reflect.Bool:
+		v, err := common.ConvertToBool(value)
+		if err != nil {
+			return err
+		}
+		dest.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := common.ConvertToInt(value)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowInt(int64(v)) {
+			return errors.New(fmt.Sprintf("value %d overflows %s", v, dest.Type().String()))
+		}
+		dest.SetInt(int64(v))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := common.ConvertToInt(value)
+		if err != nil {
+			return err
+		}
+		if v < constant.ZeroInt || dest.OverflowUint(uint64(v)) {
+			return errors.New(fmt.Sprintf("value %d overflows %s", v, dest.Type().String()))
+		}
+		dest.SetUint(uint64(v))
+	case reflect.Float32, reflect.Float64:
+		v, err := common.ConvertToFloat(value)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowFloat(v) {
+			return errors.New(fmt.Sprintf("value %f overflows %s", v, dest.Type().String()))
+		}
+		dest.SetFloat(v)
+	case reflect.String:
+		v, err := common.ConvertToString(value)
+		if err != nil {
+			return err
+		}
+		dest.SetString(v)
+	case reflect.Slice:
+		return convertSlice(value, dest)
+	default:
+		return errors.New(fmt.Sprintf("unsupported data type of dest: %s", dest.Type().String()))
+	}
+
+	return nil
+}
+
+// convertTime converts the value to time.Time, string value must be in the format of constant.TimeLayoutSecond,
+// the fractional seconds are optional
+func convertTime(value interface{}, dest reflect.Value) error {
+	t, ok := value.(time.Time)
+	if !ok {
+		v, err := common.ConvertToString(value)
+		if err != nil {
+			return err
+		}
+		t, err = time.ParseInLocation(constant.TimeLayoutSecond, v, time.Local)
+		if err != nil {
+			return err
+		}
+	}
+
+	dest.Set(reflect.ValueOf(t))
+
+	return nil
+}
+
+// convertSlice converts the value to the slice type of dest
+func convertSlice(value interface{}, dest reflect.Value) error {
+	if dest.Type().ConvertibleTo(bytesType) {
+		var b []byte
+		switch v := value.(type) {
+		case []byte:
+			// copy the bytes, so that the result will not be changed by dest
+			b = append([]byte(nil), v...)
+		case string:
+			b = []byte(v)
+		default:
+			return errors.New(fmt.Sprintf("can not convert %T to %s", value, dest.Type().String()))
+		}
+		dest.Set(reflect.ValueOf(b).Convert(dest.Type()))
+
+		return nil
+	}
+
+	valueVal := reflect.ValueOf(value)
+	if valueVal.Kind() != reflect.Slice {
+		return errors.New(fmt.Sprintf("value must be a slice, not %s", valueVal.Kind().String()))
+	}
+
+	s := reflect.MakeSlice(dest.Type(), valueVal.Len(), valueVal.Len())
+	for i := 0; i < valueVal.Len(); i++ {
+		err := convertValue(valueVal.Index(i).Interface(), s.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+	dest.Set(s)
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package result
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStatus int
+
+func TestGet(t *testing.T) {
+	asst := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	r := NewRows(
+		[]string{"id", "name", "score", "created_at", "tags", "deleted", "updated_at", "checked_at"},
+		map[string]int{"id": 0, "name": 1, "score": 2, "created_at": 3, "tags": 4, "deleted": 5, "updated_at": 6, "checked_at": 7},
+		[][]driver.Value{{int64(1), []byte("test"), "98.5", now, []interface{}{"a", "b"}, nil,
+			[]byte("2021-01-02 03:04:05"), []byte("2021-01-02 03:04:05.123456")}},
+	)
+
+	var (
+		id        int
+		status    testStatus
+		small     int8
+		name      string
+		raw       []byte
+		score     float64
+		createdAt time.Time
+		tags      []string
+		deleted   *string
+		nullName  sql.NullString
+		value     interface{}
+	)
+
+	asst.Nil(Get(r, 0, 0, &id), "test Get() failed")
+	asst.Equal(1, id, "test Get() failed")
+	asst.Nil(GetByName(r, 0, "id", &status), "test GetByName() failed")
+	asst.Equal(testStatus(1), status, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "id", &small), "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "name", &name), "test GetByName() failed")
+	asst.Equal("test", name, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "name", &raw), "test GetByName() failed")
+	asst.Equal([]byte("test"), raw, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "score", &score), "test GetByName() failed")
+	asst.Equal(98.5, score, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "created_at", &createdAt), "test GetByName() failed")
+	asst.True(now.Equal(createdAt), "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "updated_at", &createdAt), "test GetByName() failed")
+	asst.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.Local), createdAt, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "checked_at", &createdAt), "test GetByName() failed")
+	asst.Equal(time.Date(2021, 1, 2, 3, 4, 5, 123456000, time.Local), createdAt, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "tags", &tags), "test GetByName() failed")
+	asst.Equal([]string{"a", "b"}, tags, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "deleted", &deleted), "test GetByName() failed")
+	asst.Nil(deleted, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "deleted", &nullName), "test GetByName() failed")
+	asst.False(nullName.Valid, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "name", &deleted), "test GetByName() failed")
+	asst.Equal("test", *deleted, "test GetByName() failed")
+	asst.Nil(GetByName(r, 0, "id", &value), "test GetByName() failed")
+	asst.Equal(int64(1), value, "test GetByName() failed")
+
+	asst.NotNil(Get(r, 0, 0, id), "test Get() failed")
+	asst.NotNil(Get(r, 1, 0, &id), "test Get() failed")
+	asst.NotNil(GetByName(r, 0, "name", &id), "test GetByName() failed")
+	asst.NotNil(GetByName(r, 0, "not_exists", &id), "test GetByName() failed")
+
+	r.Values[0][0] = int64(1000)
+	asst.NotNil(Get(r, 0, 0, &small), "test Get() failed")
+}
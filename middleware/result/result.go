@@ -1,6 +1,7 @@
 package result
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
@@ -342,22 +343,28 @@ func (r *Rows) GetStringSliceByName(row int, name string) ([]string, error) {
 }
 
 // MapToStructSlice maps each row to a struct of the first argument,
-// first argument must be a slice of pointers to structs,
+// first argument must be a slice of pointers to structs or a pointer to a slice of structs or pointers to structs,
+// if it is a slice, the length of it must be equal to the row number,
+// if it is a pointer to a slice, the slice will be reset to the row number and the structs will be allocated,
 // each row in the result maps to a struct in the slice,
 // each column in the row maps to a field of the struct,
 // tag argument is the tag of the field, it represents the column name,
 // if there is no such tag in the field, this field will be ignored,
 // so set tag to each field that need to be mapped,
-// using "middleware" as the tag is recommended.
+// using "middleware" as the tag is recommended, in that case, the "db" tag will be used if the field has no "middleware" tag.
 func (r *Rows) MapToStructSlice(in interface{}, tag string) error {
-	if reflect.TypeOf(in).Kind() != reflect.Slice {
-		return errors.New("first argument must be a slice of pointers to struct")
-	}
 	if tag == constant.EmptyString {
 		return errors.New("tag argument could not be empty")
 	}
 
 	inVal := reflect.ValueOf(in)
+	if inVal.Kind() == reflect.Ptr && inVal.Elem().Kind() == reflect.Slice {
+		return r.mapToNewStructSlice(inVal.Elem(), tag)
+	}
+	if inVal.Kind() != reflect.Slice {
+		return errors.New("first argument must be a slice of pointers to struct or a pointer to a slice of struct")
+	}
+
 	rowNum := r.RowNumber()
 	length := inVal.Len()
 	if rowNum != length {
@@ -375,6 +382,37 @@ func (r *Rows) MapToStructSlice(in interface{}, tag string) error {
 	return nil
 }
 
+// mapToNewStructSlice makes a new slice which has the same length as the row number,
+// maps each row to a new allocated struct of the slice, and sets the new slice to given slice value
+func (r *Rows) mapToNewStructSlice(sliceVal reflect.Value, tag string) error {
+	elemType := sliceVal.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf("element of the slice must be a struct or a pointer to struct, %s is not valid", sliceVal.Type().Elem().String()))
+	}
+
+	rowNum := r.RowNumber()
+	s := reflect.MakeSlice(sliceVal.Type(), rowNum, rowNum)
+	for i := 0; i < rowNum; i++ {
+		elem := reflect.New(elemType)
+		err := r.mapToStructByRowIndex(elem.Interface(), i, tag)
+		if err != nil {
+			return err
+		}
+		if isPtr {
+			s.Index(i).Set(elem)
+			continue
+		}
+		s.Index(i).Set(elem.Elem())
+	}
+	sliceVal.Set(s)
+
+	return nil
+}
+
 // MapToStructByRowIndex maps row of given index result to the struct
 // first argument must be a pointer to struct,
 // each column in the row maps to a field of the struct,
@@ -409,11 +447,34 @@ func (r *Rows) mapToStructByRowIndex(in interface{}, row int, tag string) error
 		fieldType := inType.Field(i)
 		fieldName := fieldType.Name
 		columnName := fieldType.Tag.Get(tag)
+		if columnName == constant.EmptyString && tag == constant.DefaultMiddlewareTag {
+			columnName = fieldType.Tag.Get(constant.DefaultDBTag)
+		}
 		if columnName == constant.EmptyString {
 			// no such tag, ignore this field
 			continue
 		}
 
+		field := inVal.Field(i)
+		if !field.CanSet() {
+			return errors.New(fmt.Sprintf("field %s can not be set, please check if this field is exported", fieldName))
+		}
+
+		// the pointers and the sql.Scanner types(such as sql.NullString) hold the null value,
+		// so they are converted in the same way as Get()
+		_, isScanner := field.Addr().Interface().(sql.Scanner)
+		if isScanner || fieldType.Type.Kind() == reflect.Ptr {
+			value, err := r.GetValueByName(row, columnName)
+			if err != nil {
+				return err
+			}
+			err = assign(value, field.Addr().Interface())
+			if err != nil {
+				return errors.New(fmt.Sprintf("map column %s to field %s failed. %s", columnName, fieldName, err.Error()))
+			}
+			continue
+		}
+
 		// get value with row number and column name
 		fieldKind := fieldType.Type.Kind()
 		switch fieldKind {
@@ -428,6 +489,11 @@ func (r *Rows) mapToStructByRowIndex(in interface{}, row int, tag string) error
 			if err != nil {
 				return err
 			}
+			if value == nil {
+				// null value could not be held by the field, set it to zero value
+				field.Set(reflect.Zero(fieldType.Type))
+				continue
+			}
 
 			err = common.SetValueOfStructByKind(in, fieldName, value, fieldKind)
 			if err != nil {
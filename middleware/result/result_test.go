@@ -0,0 +1,84 @@
+package result
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/constant"
+)
+
+type testUser struct {
+	ID        int            `middleware:"id"`
+	Name      string         `db:"name"`
+	Score     *float64       `middleware:"score"`
+	Email     sql.NullString `middleware:"email"`
+	Age       int            `middleware:"age"`
+	CreatedAt time.Time      `middleware:"created_at"`
+	Ignored   string
+}
+
+type testUnexported struct {
+	ID    int      `middleware:"id"`
+	score *float64 `middleware:"score"`
+}
+
+func TestRows_MapToStructSlice(t *testing.T) {
+	asst := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	r := NewRows(
+		[]string{"id", "name", "score", "email", "age", "created_at"},
+		map[string]int{"id": 0, "name": 1, "score": 2, "email": 3, "age": 4, "created_at": 5},
+		[][]driver.Value{
+			{int64(1), []byte("a"), "98.5", "a@test.com", int64(18), now},
+			{int64(2), []byte("b"), nil, nil, nil, now},
+		},
+	)
+
+	// pointer to a slice of pointers to struct
+	var users []*testUser
+	err := r.MapToStructSlice(&users, constant.DefaultMiddlewareTag)
+	asst.Nil(err, "test MapToStructSlice() failed")
+	asst.Equal(2, len(users), "test MapToStructSlice() failed")
+	asst.Equal(1, users[0].ID, "test MapToStructSlice() failed")
+	asst.Equal("a", users[0].Name, "test MapToStructSlice() failed")
+	asst.Equal(98.5, *users[0].Score, "test MapToStructSlice() failed")
+	asst.Equal(sql.NullString{String: "a@test.com", Valid: true}, users[0].Email, "test MapToStructSlice() failed")
+	asst.Equal(18, users[0].Age, "test MapToStructSlice() failed")
+	asst.True(now.Equal(users[0].CreatedAt), "test MapToStructSlice() failed")
+	asst.Nil(users[1].Score, "test MapToStructSlice() failed")
+	asst.False(users[1].Email.Valid, "test MapToStructSlice() failed")
+	asst.Equal(0, users[1].Age, "test MapToStructSlice() failed")
+
+	// pointer to a slice of struct
+	var values []testUser
+	err = r.MapToStructSlice(&values, constant.DefaultMiddlewareTag)
+	asst.Nil(err, "test MapToStructSlice() failed")
+	asst.Equal(2, values[1].ID, "test MapToStructSlice() failed")
+
+	// slice of pointers to struct
+	users = []*testUser{{}, {}}
+	err = r.MapToStructSlice(users, constant.DefaultMiddlewareTag)
+	asst.Nil(err, "test MapToStructSlice() failed")
+	asst.Equal("b", users[1].Name, "test MapToStructSlice() failed")
+
+	// the db tag is only used as the fallback of the middleware tag
+	var jsonUsers []*testUser
+	err = r.MapToStructSlice(&jsonUsers, constant.DefaultJSONTag)
+	asst.Nil(err, "test MapToStructSlice() failed")
+	asst.Equal(constant.EmptyString, jsonUsers[0].Name, "test MapToStructSlice() failed")
+
+	err = r.MapToStructSlice([]*testUser{{}}, constant.DefaultMiddlewareTag)
+	asst.NotNil(err, "test MapToStructSlice() failed")
+	var ints []int
+	err = r.MapToStructSlice(&ints, constant.DefaultMiddlewareTag)
+	asst.NotNil(err, "test MapToStructSlice() failed")
+	// the tagged unexported field could not be set
+	var unexported []*testUnexported
+	err = r.MapToStructSlice(&unexported, constant.DefaultMiddlewareTag)
+	asst.NotNil(err, "test MapToStructSlice() failed")
+}
@@ -0,0 +1,399 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+)
+
+const (
+	DefaultMaxConnections     = 20
+	DefaultMaxIdleConnections = 10
+	DefaultMaxIdleTime        = 1800 // seconds
+	DefaultCheckTimeout       = 3 * time.Second
+)
+
+var (
+	_ middleware.PoolConn    = (*PoolConn)(nil)
+	_ middleware.Transaction = (*PoolConn)(nil)
+	_ middleware.Pool        = (*Pool)(nil)
+
+	queryPrefixes = []string{"select", "show", "with", "desc", "describe", "explain", "values", "("}
+)
+
+type Config struct {
+	DriverName string
+	DSN        string
+	// CheckSQL is used to validate the connections, it differs between databases,
+	// for example: "select 1 from dual" for oracle, "select 1" for sql server,
+	// if it is empty, (*sql.Conn).PingContext() will be used
+	CheckSQL string
+}
+
+// NewConfig returns a new Config
+func NewConfig(driverName, dsn, checkSQL string) Config {
+	return Config{
+		DriverName: driverName,
+		DSN:        dsn,
+		CheckSQL:   checkSQL,
+	}
+}
+
+type PoolConfig struct {
+	Config
+	MaxConnections     int
+	MaxIdleConnections int
+	MaxIdleTime        int
+}
+
+// NewPoolConfig returns a new PoolConfig
+func NewPoolConfig(driverName, dsn, checkSQL string, maxConnections, maxIdleConnections, maxIdleTime int) PoolConfig {
+	return PoolConfig{
+		Config:             NewConfig(driverName, dsn, checkSQL),
+		MaxConnections:     maxConnections,
+		MaxIdleConnections: maxIdleConnections,
+		MaxIdleTime:        maxIdleTime,
+	}
+}
+
+// Validate validates pool config
+func (cfg *PoolConfig) Validate() (bool, error) {
+	if cfg.DriverName == constant.EmptyString {
+		return false, errors.New("driver name should not be empty")
+	}
+	if cfg.MaxConnections <= constant.ZeroInt {
+		return false, errors.New("maximum connection argument should larger than 0")
+	}
+	if cfg.MaxIdleConnections < constant.ZeroInt {
+		return false, errors.New("maximum idle connection argument should not be smaller than 0")
+	}
+	if cfg.MaxIdleConnections > cfg.MaxConnections {
+		return false, errors.New("maximum idle connection argument should not be larger than maximum connection argument")
+	}
+	if cfg.MaxIdleTime <= constant.ZeroInt {
+		return false, errors.New("maximum idle time argument should be larger than 0")
+	}
+
+	return true, nil
+}
+
+type PoolConn struct {
+	*sql.Conn
+	Pool *Pool
+	tx   *sql.Tx
+}
+
+// NewPoolConn returns a new *PoolConn with given *sql.Conn
+func NewPoolConn(pool *Pool, conn *sql.Conn) *PoolConn {
+	return &PoolConn{
+		Conn: conn,
+		Pool: pool,
+	}
+}
+
+// Close returns connection back to the pool, if there is an uncommitted transaction, it will be rolled back
+func (pc *PoolConn) Close() error {
+	merr := &multierror.Error{}
+
+	if pc.tx != nil {
+		err := pc.Rollback()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	err := pc.Conn.Close()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// Disconnect disconnects from the database, the underlying connection will not be reused
+func (pc *PoolConn) Disconnect() error {
+	err := pc.Conn.Raw(func(driverConn interface{}) error {
+		return driver.ErrBadConn
+	})
+	if err != nil && err != driver.ErrBadConn {
+		return err
+	}
+
+	// database/sql has closed the driver connection and released the *sql.Conn after Raw() returns driver.ErrBadConn,
+	// so there is no need to close it again
+	pc.tx = nil
+
+	return nil
+}
+
+// IsValid validates if connection is valid
+func (pc *PoolConn) IsValid() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCheckTimeout)
+	defer cancel()
+
+	if pc.Pool == nil || pc.Pool.CheckSQL == constant.EmptyString {
+		return pc.PingContext(ctx) == nil
+	}
+
+	_, err := pc.executeContext(ctx, pc.Pool.CheckSQL)
+
+	return err == nil
+}
+
+// Prepare prepares a statement and returns a *Statement
+func (pc *PoolConn) Prepare(command string) (middleware.Statement, error) {
+	return pc.prepareContext(context.Background(), command)
+}
+
+// PrepareContext prepares a statement with context and returns a *Statement
+func (pc *PoolConn) PrepareContext(ctx context.Context, command string) (middleware.Statement, error) {
+	return pc.prepareContext(ctx, command)
+}
+
+// prepareContext prepares a statement with context and returns a *Statement,
+// if there is an active transaction, the statement will be prepared in the transaction
+func (pc *PoolConn) prepareContext(ctx context.Context, command string) (*Statement, error) {
+	var (
+		stmt *sql.Stmt
+		err  error
+	)
+
+	if pc.tx != nil {
+		stmt, err = pc.tx.PrepareContext(ctx, command)
+	} else {
+		stmt, err = pc.Conn.PrepareContext(ctx, command)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStatement(stmt, IsQuery(command)), nil
+}
+
+// Execute executes given command and placeholders on the database
+func (pc *PoolConn) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return pc.executeContext(context.Background(), command, args...)
+}
+
+// ExecuteContext executes given command and placeholders with context on the database
+func (pc *PoolConn) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	return pc.executeContext(ctx, command, args...)
+}
+
+// executeContext executes given command and placeholders with context on the database,
+// if there is an active transaction, the command will be executed in the transaction
+func (pc *PoolConn) executeContext(ctx context.Context, command string, args ...interface{}) (*Result, error) {
+	if IsQuery(command) {
+		var (
+			rows *sql.Rows
+			err  error
+		)
+		if pc.tx != nil {
+			rows, err = pc.tx.QueryContext(ctx, command, args...)
+		} else {
+			rows, err = pc.Conn.QueryContext(ctx, command, args...)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return NewResultWithRows(rows)
+	}
+
+	var (
+		r   sql.Result
+		err error
+	)
+	if pc.tx != nil {
+		r, err = pc.tx.ExecContext(ctx, command, args...)
+	} else {
+		r, err = pc.Conn.ExecContext(ctx, command, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultWithSQLResult(r), nil
+}
+
+// Begin begins a transaction
+func (pc *PoolConn) Begin() error {
+	if pc.tx != nil {
+		return errors.New("there is already an active transaction on this connection")
+	}
+
+	tx, err := pc.Conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	pc.tx = tx
+
+	return nil
+}
+
+// Commit commits current transaction
+func (pc *PoolConn) Commit() error {
+	if pc.tx == nil {
+		return errors.New("there is no active transaction on this connection")
+	}
+
+	err := pc.tx.Commit()
+	pc.tx = nil
+
+	return err
+}
+
+// Rollback rollbacks current transaction
+func (pc *PoolConn) Rollback() error {
+	if pc.tx == nil {
+		return errors.New("there is no active transaction on this connection")
+	}
+
+	err := pc.tx.Rollback()
+	pc.tx = nil
+
+	return err
+}
+
+// Pool wraps *sql.DB, the connection pool is maintained by database/sql package
+type Pool struct {
+	sync.Mutex
+	PoolConfig
+	DB       *sql.DB
+	isClosed bool
+}
+
+// NewPool returns a new *Pool, the driver must have been registered by importing the driver package
+func NewPool(driverName, dsn, checkSQL string, maxConnections, maxIdleConnections, maxIdleTime int) (*Pool, error) {
+	return NewPoolWithPoolConfig(NewPoolConfig(driverName, dsn, checkSQL, maxConnections, maxIdleConnections, maxIdleTime))
+}
+
+// NewPoolWithDefault returns a new *Pool with default configuration
+func NewPoolWithDefault(driverName, dsn, checkSQL string) (*Pool, error) {
+	return NewPool(driverName, dsn, checkSQL, DefaultMaxConnections, DefaultMaxIdleConnections, DefaultMaxIdleTime)
+}
+
+// NewPoolWithPoolConfig returns a new *Pool with a PoolConfig object
+func NewPoolWithPoolConfig(config PoolConfig) (*Pool, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+
+	db, err := sql.Open(config.DriverName, config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(config.MaxConnections)
+	db.SetMaxIdleConns(config.MaxIdleConnections)
+	db.SetConnMaxIdleTime(time.Duration(config.MaxIdleTime) * time.Second)
+
+	return &Pool{
+		PoolConfig: config,
+		DB:         db,
+	}, nil
+}
+
+// Close closes the database and prevents new queries from starting
+func (p *Pool) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.isClosed = true
+
+	return p.DB.Close()
+}
+
+// IsClosed returns if pool had been closed
+func (p *Pool) IsClosed() bool {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.isClosed
+}
+
+// Get gets a connection from the pool
+func (p *Pool) Get() (middleware.PoolConn, error) {
+	return p.get(context.Background())
+}
+
+// GetContext gets a connection from the pool, it waits until a connection is available or the context is done
+func (p *Pool) GetContext(ctx context.Context) (middleware.PoolConn, error) {
+	return p.get(ctx)
+}
+
+// get gets a connection from the pool with context
+func (p *Pool) get(ctx context.Context) (*PoolConn, error) {
+	conn, err := p.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPoolConn(p, conn), nil
+}
+
+// Transaction returns a connection that could run multiple statements in the same transaction
+func (p *Pool) Transaction() (middleware.Transaction, error) {
+	return p.get(context.Background())
+}
+
+// Supply creates given number of connections and puts them back to the pool as idle connections,
+// note that idle connections will not exceed maximum idle connections
+func (p *Pool) Supply(num int) error {
+	merr := &multierror.Error{}
+	conns := make([]*sql.Conn, constant.ZeroInt, num)
+
+	for i := 0; i < num; i++ {
+		conn, err := p.DB.Conn(context.Background())
+		if err != nil {
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		conns = append(conns, conn)
+	}
+
+	for _, conn := range conns {
+		err := conn.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// Release releases given number of idle connections
+func (p *Pool) Release(num int) error {
+	idle := p.DB.Stats().Idle
+	if num > idle {
+		num = idle
+	}
+
+	// lowering maximum idle connections closes excessive idle connections immediately
+	p.DB.SetMaxIdleConns(idle - num)
+	p.DB.SetMaxIdleConns(p.MaxIdleConnections)
+
+	return nil
+}
+
+// IsQuery returns if given command returns rows
+func IsQuery(command string) bool {
+	command = strings.ToLower(strings.TrimSpace(command))
+	for _, prefix := range queryPrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package adapter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDriverName       = "adapter_test"
+	testRecordDriverName = "adapter_test_record"
+)
+
+var testRecord = &recordDriver{}
+
+func init() {
+	sql.Register(testDriverName, testDriver{})
+	sql.Register(testRecordDriverName, testRecord)
+}
+
+// testDriver is a minimal driver which returns a fixed row for queries,
+// and returns 1 row affected for other statements
+type testDriver struct{}
+
+func (testDriver) Open(name string) (driver.Conn, error) { return &testConn{}, nil }
+
+// recordDriver records the opened connections, so that the tests could check if they are closed
+type recordDriver struct {
+	mutex sync.Mutex
+	conns []*testConn
+}
+
+func (d *recordDriver) Open(name string) (driver.Conn, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	conn := &testConn{}
+	d.conns = append(d.conns, conn)
+
+	return conn, nil
+}
+
+type testConn struct {
+	closed bool
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) { return &testStmt{query: query}, nil }
+func (c *testConn) Close() error                              { c.closed = true; return nil }
+func (c *testConn) Begin() (driver.Tx, error)                 { return testTx{}, nil }
+
+type testTx struct{}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+type testStmt struct {
+	query string
+}
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return strings.Count(s.query, "?") }
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testRows{}, nil
+}
+
+type testRows struct {
+	done bool
+}
+
+func (r *testRows) Columns() []string { return []string{"id", "name"} }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	dest[1] = []byte("test")
+
+	return nil
+}
+
+func TestIsQuery(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.True(IsQuery(" SELECT 1"), "test IsQuery() failed")
+	asst.True(IsQuery("show tables"), "test IsQuery() failed")
+	asst.False(IsQuery("insert into t01 values(1)"), "test IsQuery() failed")
+}
+
+func TestPool(t *testing.T) {
+	asst := assert.New(t)
+
+	pool, err := NewPoolWithDefault(testDriverName, "test", "select 1")
+	asst.Nil(err, "test NewPoolWithDefault() failed")
+
+	conn, err := pool.Get()
+	asst.Nil(err, "test Get() failed")
+	asst.True(conn.IsValid(), "test IsValid() failed")
+
+	res, err := conn.Execute("select id, name from t01 where id = ?", 1)
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal(1, res.RowNumber(), "test Execute() failed")
+	name, err := res.GetString(0, 1)
+	asst.Nil(err, "test Execute() failed")
+	asst.Equal("test", name, "test Execute() failed")
+
+	stmt, err := conn.Prepare("update t01 set name = ? where id = ?")
+	asst.Nil(err, "test Prepare() failed")
+	res, err = stmt.Execute("test", 1)
+	asst.Nil(err, "test Statement.Execute() failed")
+	affected, err := res.RowsAffected()
+	asst.Nil(err, "test Statement.Execute() failed")
+	asst.Equal(1, affected, "test Statement.Execute() failed")
+	asst.Nil(conn.Close(), "test Close() failed")
+
+	trx, err := pool.Transaction()
+	asst.Nil(err, "test Transaction() failed")
+	asst.Nil(trx.Begin(), "test Begin() failed")
+	asst.NotNil(trx.Begin(), "test Begin() failed")
+	_, err = trx.Execute("delete from t01 where id = ?", 1)
+	asst.Nil(err, "test Execute() failed")
+	asst.Nil(trx.Commit(), "test Commit() failed")
+	asst.NotNil(trx.Rollback(), "test Rollback() failed")
+	asst.Nil(trx.Close(), "test Close() failed")
+
+	asst.Nil(pool.Supply(3), "test Supply() failed")
+	asst.Nil(pool.Release(2), "test Release() failed")
+	asst.Nil(pool.Close(), "test Close() failed")
+	asst.True(pool.IsClosed(), "test IsClosed() failed")
+}
+
+func TestPoolConn_Disconnect(t *testing.T) {
+	asst := assert.New(t)
+
+	pool, err := NewPoolWithDefault(testRecordDriverName, "test", "select 1")
+	asst.Nil(err, "test NewPoolWithDefault() failed")
+	defer func() { _ = pool.Close() }()
+
+	conn, err := pool.Get()
+	asst.Nil(err, "test Get() failed")
+	asst.Nil(conn.Disconnect(), "test Disconnect() failed")
+
+	testRecord.mutex.Lock()
+	defer testRecord.mutex.Unlock()
+	asst.Equal(1, len(testRecord.conns), "test Disconnect() failed")
+	asst.True(testRecord.conns[0].closed, "test Disconnect() failed")
+	asst.Equal(0, pool.DB.Stats().OpenConnections, "test Disconnect() failed")
+}
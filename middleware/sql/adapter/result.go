@@ -0,0 +1,109 @@
+package adapter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/result"
+)
+
+const middlewareType = "sql"
+
+var _ middleware.Result = (*Result)(nil)
+
+type Result struct {
+	Raw interface{}
+	*result.Rows
+	result.Map
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// NewResultWithRows returns a new *Result built from given *sql.Rows, it reads all the rows and closes the rows
+func NewResultWithRows(rows *sql.Rows) (*Result, error) {
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	fieldMap := make(map[string]int, len(columns))
+	for i, column := range columns {
+		fieldMap[column] = i
+	}
+
+	var values [][]driver.Value
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]driver.Value, len(columns))
+		for i, v := range row {
+			// the underlying array of []byte may be reused by the driver, so copy it
+			if b, ok := v.([]byte); ok {
+				v = append([]byte(nil), b...)
+			}
+			value[i] = v
+		}
+		values = append(values, value)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Raw:  rows,
+		Rows: result.NewRows(columns, fieldMap, values),
+		Map:  result.NewEmptyMap(middlewareType),
+	}, nil
+}
+
+// NewResultWithSQLResult returns a new *Result built from given sql.Result,
+// note that some drivers do not support LastInsertId() or RowsAffected(), in this case, they will be 0
+func NewResultWithSQLResult(r sql.Result) *Result {
+	lastInsertID, err := r.LastInsertId()
+	if err != nil {
+		lastInsertID = constant.ZeroInt
+	}
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		rowsAffected = constant.ZeroInt
+	}
+
+	return &Result{
+		Raw:          r,
+		Rows:         result.NewEmptyRows(),
+		Map:          result.NewEmptyMap(middlewareType),
+		lastInsertID: lastInsertID,
+		rowsAffected: rowsAffected,
+	}
+}
+
+// LastInsertID returns the database's auto-generated ID
+// after, for example, an INSERT into a table with primary key.
+func (r *Result) LastInsertID() (int, error) {
+	return int(r.lastInsertID), nil
+}
+
+// RowsAffected returns the number of rows affected by the query.
+func (r *Result) RowsAffected() (int, error) {
+	return int(r.rowsAffected), nil
+}
+
+// GetRaw returns the raw data of the result, it's either *sql.Rows or sql.Result
+func (r *Result) GetRaw() interface{} {
+	return r.Raw
+}
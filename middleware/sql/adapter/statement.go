@@ -0,0 +1,53 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+var _ middleware.Statement = (*Statement)(nil)
+
+type Statement struct {
+	*sql.Stmt
+	isQuery bool
+}
+
+// NewStatement returns a new *Statement with given *sql.Stmt,
+// isQuery represents whether the statement returns rows
+func NewStatement(stmt *sql.Stmt, isQuery bool) *Statement {
+	return &Statement{
+		Stmt:    stmt,
+		isQuery: isQuery,
+	}
+}
+
+// Execute executes given placeholders and returns a result
+func (stmt *Statement) Execute(args ...interface{}) (middleware.Result, error) {
+	return stmt.executeContext(context.Background(), args...)
+}
+
+// ExecuteContext executes given placeholders with context and returns a result
+func (stmt *Statement) ExecuteContext(ctx context.Context, args ...interface{}) (middleware.Result, error) {
+	return stmt.executeContext(ctx, args...)
+}
+
+// executeContext executes given placeholders with context and returns a result
+func (stmt *Statement) executeContext(ctx context.Context, args ...interface{}) (*Result, error) {
+	if stmt.isQuery {
+		rows, err := stmt.Stmt.QueryContext(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewResultWithRows(rows)
+	}
+
+	r, err := stmt.Stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultWithSQLResult(r), nil
+}
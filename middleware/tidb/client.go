@@ -0,0 +1,307 @@
+package tidb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/mysql"
+)
+
+const (
+	HotRegionTypeRead  = "read"
+	HotRegionTypeWrite = "write"
+
+	DefaultSlowQueryLimit = 100
+)
+
+type Conn struct {
+	*mysql.Conn
+}
+
+// NewConn returns connection to tidb, be aware that addr is host:port style
+func NewConn(addr, dbName, dbUser, dbPass string) (*Conn, error) {
+	conn, err := mysql.NewConn(addr, dbName, dbUser, dbPass)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{conn}, nil
+}
+
+// NewConnWithMySQLConn returns a new *Conn with given mysql connection
+func NewConnWithMySQLConn(conn *mysql.Conn) *Conn {
+	return &Conn{conn}
+}
+
+// GetHotRegions returns hot regions of given type, type should be either HotRegionTypeRead or HotRegionTypeWrite,
+// if type is empty, all hot regions will be returned
+func (conn *Conn) GetHotRegions(regionType string) ([]*HotRegion, error) {
+	sql := `
+		select table_id, index_id, db_name, table_name, ifnull(index_name, '') as index_name,
+			region_id, type, max_hot_degree, region_count, flow_bytes
+		from information_schema.tidb_hot_regions
+	`
+	var args []interface{}
+	if regionType != constant.EmptyString {
+		sql += " where type = ?"
+		args = append(args, regionType)
+	}
+	sql += " order by flow_bytes desc;"
+
+	result, err := conn.Execute(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	hotRegions := make([]*HotRegion, result.RowNumber())
+	for i := range hotRegions {
+		hotRegions[i] = &HotRegion{}
+	}
+
+	err = result.MapToStructSlice(hotRegions, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return hotRegions, nil
+}
+
+// GetClusterInfo returns topology information of all the instances in the cluster
+func (conn *Conn) GetClusterInfo() ([]*ClusterInfo, error) {
+	sql := `
+		select type, instance, status_address, version, git_hash, start_time, uptime
+		from information_schema.cluster_info
+		order by type, instance;
+	`
+	result, err := conn.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterInfos := make([]*ClusterInfo, result.RowNumber())
+	for i := range clusterInfos {
+		clusterInfos[i] = &ClusterInfo{}
+	}
+
+	err = result.MapToStructSlice(clusterInfos, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterInfos, nil
+}
+
+// SetScatterRegion sets session variable tidb_scatter_region,
+// if it is enabled, regions will be scattered after splitting in this session
+func (conn *Conn) SetScatterRegion(scatter bool) error {
+	value := constant.ZeroInt
+	if scatter {
+		value = 1
+	}
+
+	_, err := conn.Execute(fmt.Sprintf("set @@session.tidb_scatter_region = %d;", value))
+
+	return err
+}
+
+// SplitTable splits the table evenly into given number of regions between lower and upper bound of the row id
+func (conn *Conn) SplitTable(dbName, tableName string, lower, upper interface{}, regions int) (*SplitResult, error) {
+	lowerStr, err := middleware.ConvertToString(lower)
+	if err != nil {
+		return nil, err
+	}
+	upperStr, err := middleware.ConvertToString(upper)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf("split table `%s`.`%s` between (%s) and (%s) regions %d;",
+		dbName, tableName, lowerStr, upperStr, regions)
+
+	return conn.split(sql)
+}
+
+// SplitTableByValues splits the table at given row id values
+func (conn *Conn) SplitTableByValues(dbName, tableName string, values ...interface{}) (*SplitResult, error) {
+	if len(values) == constant.ZeroInt {
+		return nil, errors.New(fmt.Sprintf("split values should not be empty. table: %s.%s", dbName, tableName))
+	}
+
+	sql := fmt.Sprintf("split table `%s`.`%s` by %s;", dbName, tableName, buildSplitValues(values))
+
+	return conn.split(sql)
+}
+
+// SplitIndex splits the index evenly into given number of regions between lower and upper bound of the index value
+func (conn *Conn) SplitIndex(dbName, tableName, indexName string, lower, upper interface{}, regions int) (*SplitResult, error) {
+	lowerStr, err := middleware.ConvertToString(lower)
+	if err != nil {
+		return nil, err
+	}
+	upperStr, err := middleware.ConvertToString(upper)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf("split table `%s`.`%s` index `%s` between (%s) and (%s) regions %d;",
+		dbName, tableName, indexName, lowerStr, upperStr, regions)
+
+	return conn.split(sql)
+}
+
+// split executes the split statement and returns the result
+func (conn *Conn) split(sql string) (*SplitResult, error) {
+	result, err := conn.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	totalSplitRegion, err := result.GetInt(constant.ZeroInt, 0)
+	if err != nil {
+		return nil, err
+	}
+	scatterFinishRatio, err := result.GetFloat(constant.ZeroInt, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SplitResult{
+		TotalSplitRegion:   totalSplitRegion,
+		ScatterFinishRatio: scatterFinishRatio,
+	}, nil
+}
+
+// GetTableRegions returns the regions of given table
+func (conn *Conn) GetTableRegions(dbName, tableName string) ([]*TableRegion, error) {
+	sql := fmt.Sprintf("show table `%s`.`%s` regions;", dbName, tableName)
+	result, err := conn.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	tableRegions := make([]*TableRegion, result.RowNumber())
+	for i := range tableRegions {
+		tableRegions[i] = &TableRegion{}
+	}
+
+	err = result.MapToStructSlice(tableRegions, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return tableRegions, nil
+}
+
+// CreatePlacementPolicy creates a placement policy with given options,
+// options are the raw placement options, for example: PRIMARY_REGION="us-east-1" REGIONS="us-east-1,us-west-1"
+func (conn *Conn) CreatePlacementPolicy(name, options string) error {
+	_, err := conn.Execute(fmt.Sprintf("create placement policy if not exists `%s` %s;", name, options))
+
+	return err
+}
+
+// AlterPlacementPolicy alters the placement policy with given options
+func (conn *Conn) AlterPlacementPolicy(name, options string) error {
+	_, err := conn.Execute(fmt.Sprintf("alter placement policy `%s` %s;", name, options))
+
+	return err
+}
+
+// DropPlacementPolicy drops the placement policy
+func (conn *Conn) DropPlacementPolicy(name string) error {
+	_, err := conn.Execute(fmt.Sprintf("drop placement policy if exists `%s`;", name))
+
+	return err
+}
+
+// SetTablePlacementPolicy attaches the placement policy to the table, empty policy name means detaching the policy
+func (conn *Conn) SetTablePlacementPolicy(dbName, tableName, policyName string) error {
+	policy := "default"
+	if policyName != constant.EmptyString {
+		policy = fmt.Sprintf("`%s`", policyName)
+	}
+
+	_, err := conn.Execute(fmt.Sprintf("alter table `%s`.`%s` placement policy = %s;", dbName, tableName, policy))
+
+	return err
+}
+
+// GetPlacementPolicies returns all the placement policies
+func (conn *Conn) GetPlacementPolicies() ([]*PlacementPolicy, error) {
+	sql := `
+		select policy_name, ifnull(primary_region, '') as primary_region, ifnull(regions, '') as regions,
+			ifnull(constraints, '') as constraints, ifnull(followers, 0) as followers
+		from information_schema.placement_policies
+		order by policy_name;
+	`
+	result, err := conn.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*PlacementPolicy, result.RowNumber())
+	for i := range policies {
+		policies[i] = &PlacementPolicy{}
+	}
+
+	err = result.MapToStructSlice(policies, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// GetSlowQueries returns slow queries of the whole cluster between start time and end time,
+// which query time is not less than minQueryTime, the result is ordered by query time descending,
+// if limit is not larger than 0, DefaultSlowQueryLimit will be used
+func (conn *Conn) GetSlowQueries(start, end time.Time, minQueryTime time.Duration, limit int) ([]*SlowQuery, error) {
+	if limit <= constant.ZeroInt {
+		limit = DefaultSlowQueryLimit
+	}
+
+	sql := `
+		select instance, time, ifnull(txn_start_ts, 0) as txn_start_ts, ifnull(user, '') as user, ifnull(host, '') as host,
+			ifnull(db, '') as db, query_time, ifnull(result_rows, 0) as result_rows, ifnull(process_keys, 0) as process_keys,
+			ifnull(total_keys, 0) as total_keys, digest, query
+		from information_schema.cluster_slow_query
+		where time between ? and ? and query_time >= ?
+		order by query_time desc
+		limit ?;
+	`
+	result, err := conn.Execute(sql, start.Format(constant.DefaultTimeLayout), end.Format(constant.DefaultTimeLayout),
+		minQueryTime.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	slowQueries := make([]*SlowQuery, result.RowNumber())
+	for i := range slowQueries {
+		slowQueries[i] = &SlowQuery{}
+	}
+
+	err = result.MapToStructSlice(slowQueries, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return slowQueries, nil
+}
+
+// buildSplitValues builds the by clause of the split statement
+func buildSplitValues(values []interface{}) string {
+	valueList := make([]string, len(values))
+	for i, value := range values {
+		valueStr, err := middleware.ConvertToString(value)
+		if err != nil {
+			valueStr = fmt.Sprintf("'%v'", value)
+		}
+		valueList[i] = constant.LeftParenthesis + valueStr + constant.RightParenthesis
+	}
+
+	return strings.Join(valueList, constant.CommaString)
+}
@@ -0,0 +1,74 @@
+package tidb
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/romberli/log"
+	"github.com/stretchr/testify/assert"
+)
+
+var conn = initConn()
+
+func initConn() *Conn {
+	addr := "192.168.10.219:4000"
+	dbName := "test"
+	dbUser := "root"
+	dbPass := "root"
+
+	c, err := NewConn(addr, dbName, dbUser, dbPass)
+	if err != nil {
+		log.Error(fmt.Sprintf("init connection failed.\n%s", err.Error()))
+		return nil
+	}
+
+	return c
+}
+
+func TestBuildSplitValues(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal("(1),(100),('a')", buildSplitValues([]interface{}{1, 100, "a"}), "test buildSplitValues() failed")
+}
+
+func TestConn_GetClusterInfo(t *testing.T) {
+	asst := assert.New(t)
+
+	clusterInfos, err := conn.GetClusterInfo()
+	asst.Nil(err, "test GetClusterInfo() failed")
+	for _, ci := range clusterInfos {
+		t.Logf("type: %s, instance: %s, version: %s", ci.Type, ci.Instance, ci.Version)
+	}
+
+	hotRegions, err := conn.GetHotRegions(HotRegionTypeWrite)
+	asst.Nil(err, "test GetHotRegions() failed")
+	t.Logf("hot region number: %d", len(hotRegions))
+}
+
+func TestConn_SplitTable(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := conn.Execute("create table if not exists t_split(id bigint primary key, name varchar(100));")
+	asst.Nil(err, "create table failed")
+
+	err = conn.SetScatterRegion(true)
+	asst.Nil(err, "test SetScatterRegion() failed")
+	result, err := conn.SplitTable("test", "t_split", 0, 1000000, 4)
+	asst.Nil(err, "test SplitTable() failed")
+	t.Logf("total split region: %d, scatter finish ratio: %f", result.TotalSplitRegion, result.ScatterFinishRatio)
+
+	regions, err := conn.GetTableRegions("test", "t_split")
+	asst.Nil(err, "test GetTableRegions() failed")
+	t.Logf("region number: %d", len(regions))
+}
+
+func TestConn_GetSlowQueries(t *testing.T) {
+	asst := assert.New(t)
+
+	slowQueries, err := conn.GetSlowQueries(time.Now().Add(-time.Hour), time.Now(), time.Second, 10)
+	asst.Nil(err, "test GetSlowQueries() failed")
+	for _, sq := range slowQueries {
+		t.Logf("query time: %f, query: %s", sq.QueryTime, sq.Query)
+	}
+}
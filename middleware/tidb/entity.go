@@ -0,0 +1,70 @@
+package tidb
+
+import (
+	"time"
+)
+
+type HotRegion struct {
+	TableID      int     `middleware:"table_id"`
+	IndexID      int     `middleware:"index_id"`
+	DBName       string  `middleware:"db_name"`
+	TableName    string  `middleware:"table_name"`
+	IndexName    string  `middleware:"index_name"`
+	RegionID     int     `middleware:"region_id"`
+	Type         string  `middleware:"type"`
+	MaxHotDegree int     `middleware:"max_hot_degree"`
+	RegionCount  int     `middleware:"region_count"`
+	FlowBytes    float64 `middleware:"flow_bytes"`
+}
+
+type ClusterInfo struct {
+	Type          string `middleware:"type"`
+	Instance      string `middleware:"instance"`
+	StatusAddress string `middleware:"status_address"`
+	Version       string `middleware:"version"`
+	GitHash       string `middleware:"git_hash"`
+	StartTime     string `middleware:"start_time"`
+	Uptime        string `middleware:"uptime"`
+}
+
+type SplitResult struct {
+	TotalSplitRegion   int
+	ScatterFinishRatio float64
+}
+
+type TableRegion struct {
+	RegionID       int    `middleware:"REGION_ID"`
+	StartKey       string `middleware:"START_KEY"`
+	EndKey         string `middleware:"END_KEY"`
+	LeaderID       int    `middleware:"LEADER_ID"`
+	LeaderStoreID  int    `middleware:"LEADER_STORE_ID"`
+	Peers          string `middleware:"PEERS"`
+	Scattering     int    `middleware:"SCATTERING"`
+	WrittenBytes   int    `middleware:"WRITTEN_BYTES"`
+	ReadBytes      int    `middleware:"READ_BYTES"`
+	ApproximateMB  int    `middleware:"APPROXIMATE_SIZE(MB)"`
+	ApproximateKey int    `middleware:"APPROXIMATE_KEYS"`
+}
+
+type PlacementPolicy struct {
+	PolicyName    string `middleware:"policy_name"`
+	PrimaryRegion string `middleware:"primary_region"`
+	Regions       string `middleware:"regions"`
+	Constraints   string `middleware:"constraints"`
+	Followers     int    `middleware:"followers"`
+}
+
+type SlowQuery struct {
+	Instance    string    `middleware:"instance"`
+	Time        time.Time `middleware:"time"`
+	TxnStartTS  uint64    `middleware:"txn_start_ts"`
+	User        string    `middleware:"user"`
+	Host        string    `middleware:"host"`
+	DB          string    `middleware:"db"`
+	QueryTime   float64   `middleware:"query_time"`
+	ResultRows  int       `middleware:"result_rows"`
+	ProcessKeys int       `middleware:"process_keys"`
+	TotalKeys   int       `middleware:"total_keys"`
+	Digest      string    `middleware:"digest"`
+	Query       string    `middleware:"query"`
+}
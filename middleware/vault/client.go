@@ -0,0 +1,286 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/credential"
+)
+
+const (
+	DefaultTimeout          = 30 * time.Second
+	DefaultAppRoleMountPath = "approle"
+
+	defaultHTTPPrefix  = "http://"
+	defaultHTTPSPrefix = "https://"
+
+	tokenHeader       = "X-Vault-Token"
+	namespaceHeader   = "X-Vault-Namespace"
+	contentTypeHeader = "Content-Type"
+	jsonContentType   = "application/json"
+	apiPrefix         = "/v1/"
+	healthPath        = "/v1/sys/health"
+)
+
+type Config struct {
+	Addr string
+	// Token is used if it is not empty, otherwise, RoleID and SecretID will be used to login with approle
+	Token            string
+	RoleID           string
+	SecretID         string
+	AppRoleMountPath string
+	// Namespace is only available in vault enterprise
+	Namespace string
+	Timeout   time.Duration
+}
+
+// NewConfig returns a new Config with given token
+func NewConfig(addr, token string) Config {
+	return Config{
+		Addr:    normalizeAddr(addr),
+		Token:   token,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// NewConfigWithAppRole returns a new Config which logins with approle
+func NewConfigWithAppRole(addr, roleID, secretID string) Config {
+	return Config{
+		Addr:             normalizeAddr(addr),
+		RoleID:           roleID,
+		SecretID:         secretID,
+		AppRoleMountPath: DefaultAppRoleMountPath,
+		Timeout:          DefaultTimeout,
+	}
+}
+
+// normalizeAddr adds http prefix to the address if it does not have one and trims the trailing slash
+func normalizeAddr(addr string) string {
+	address := strings.ToLower(addr)
+	if !strings.HasPrefix(address, defaultHTTPPrefix) && !strings.HasPrefix(address, defaultHTTPSPrefix) {
+		addr = defaultHTTPPrefix + addr
+	}
+
+	return strings.TrimSuffix(addr, constant.SlashString)
+}
+
+// APIError is returned when vault responds with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	Errors     []string
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("vault api returned an error. status code: %d, errors: %s", e.StatusCode, strings.Join(e.Errors, constant.CommaString))
+}
+
+// IsNotFound returns if given error is a vault api error with 404 status code
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Secret is the common response of vault apis
+type Secret struct {
+	RequestID     string                 `json:"request_id"`
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Warnings      []string               `json:"warnings"`
+	Auth          *Auth                  `json:"auth"`
+}
+
+type Auth struct {
+	ClientToken   string   `json:"client_token"`
+	Accessor      string   `json:"accessor"`
+	Policies      []string `json:"policies"`
+	LeaseDuration int      `json:"lease_duration"`
+	Renewable     bool     `json:"renewable"`
+}
+
+type Conn struct {
+	Config
+	Client *http.Client
+	mutex  sync.RWMutex
+	token  string
+}
+
+// NewConn returns a new *Conn with given token
+func NewConn(addr, token string) (*Conn, error) {
+	return NewConnWithConfig(NewConfig(addr, token))
+}
+
+// NewConnWithConfig returns a new *Conn with given config, if the token of the config is empty,
+// it logins with approle immediately, the token and the secret id could be encrypted by the credential package
+func NewConnWithConfig(config Config) (*Conn, error) {
+	token, err := credential.DecryptIfNeeded(config.Token)
+	if err != nil {
+		return nil, err
+	}
+	conn := &Conn{
+		Config: config,
+		Client: &http.Client{Timeout: config.Timeout},
+		token:  token,
+	}
+
+	if conn.token == constant.EmptyString {
+		if config.RoleID == constant.EmptyString {
+			return nil, errors.New("either token or role id should be specified")
+		}
+		_, err = conn.LoginWithAppRole(context.Background())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// Token returns current token
+func (conn *Conn) Token() string {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+
+	return conn.token
+}
+
+// SetToken sets the token which will be used by the following requests
+func (conn *Conn) SetToken(token string) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	conn.token = token
+}
+
+// LoginWithAppRole logins with the role id and secret id of the config, and uses the returned token for the following requests
+func (conn *Conn) LoginWithAppRole(ctx context.Context) (*Auth, error) {
+	mountPath := conn.AppRoleMountPath
+	if mountPath == constant.EmptyString {
+		mountPath = DefaultAppRoleMountPath
+	}
+
+	secretID, err := credential.DecryptIfNeeded(conn.SecretID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := conn.Write(ctx, "auth/"+mountPath+"/login", map[string]interface{}{
+		"role_id":   conn.RoleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secret.Auth == nil || secret.Auth.ClientToken == constant.EmptyString {
+		return nil, errors.New("login with approle failed, no token returned")
+	}
+
+	conn.SetToken(secret.Auth.ClientToken)
+
+	return secret.Auth, nil
+}
+
+// CheckInstanceStatus checks if vault is initialized, unsealed and active
+func (conn *Conn) CheckInstanceStatus() bool {
+	return conn.do(context.Background(), http.MethodGet, healthPath, nil, nil) == nil
+}
+
+// Read reads the secret of given path, the path does not contain the /v1/ prefix, for example: secret/data/mysql
+func (conn *Conn) Read(ctx context.Context, path string) (*Secret, error) {
+	secret := &Secret{}
+
+	err := conn.do(ctx, http.MethodGet, apiPrefix+strings.TrimPrefix(path, constant.SlashString), nil, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Write writes the data to given path and returns the response secret, the returned secret could be empty
+func (conn *Conn) Write(ctx context.Context, path string, data map[string]interface{}) (*Secret, error) {
+	secret := &Secret{}
+
+	err := conn.do(ctx, http.MethodPost, apiPrefix+strings.TrimPrefix(path, constant.SlashString), data, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Delete deletes the secret of given path
+func (conn *Conn) Delete(ctx context.Context, path string) error {
+	return conn.do(ctx, http.MethodDelete, apiPrefix+strings.TrimPrefix(path, constant.SlashString), nil, nil)
+}
+
+// do sends the request to vault, in will be marshaled as the request body if it is not nil,
+// and the response body will be unmarshaled to out if it is not nil
+func (conn *Conn) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, conn.Addr+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set(contentTypeHeader, jsonContentType)
+	}
+	if token := conn.Token(); token != constant.EmptyString {
+		req.Header.Set(tokenHeader, token)
+	}
+	if conn.Namespace != constant.EmptyString {
+		req.Header.Set(namespaceHeader, conn.Namespace)
+	}
+
+	resp, err := conn.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &errResp)
+
+		return &APIError{StatusCode: resp.StatusCode, Errors: errResp.Errors}
+	}
+
+	if out == nil || len(data) == constant.ZeroInt {
+		return nil
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return errors.New(fmt.Sprintf("unmarshal vault response failed. method: %s, path: %s. %s", method, path, err.Error()))
+	}
+
+	return nil
+}
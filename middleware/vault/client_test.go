@@ -0,0 +1,142 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "s.test"
+
+// newTestServer returns a fake vault server which only serves the apis used in the tests
+func newTestServer(renewCount *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["role_id"] != "role" || req["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors": ["invalid role or secret ID"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"auth": {"client_token": "` + testToken + `", "lease_duration": 3600, "renewable": true}}`))
+	})
+	mux.HandleFunc("/v1/secret/data/mysql/prod", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"data": {"version": 2, "created_time": "2021-01-01T00:00:00Z"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"data": {"password": "pass"}, "metadata": {"version": 1}}}`))
+	})
+	mux.HandleFunc("/v1/database/creds/readonly", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"lease_id": "database/creds/readonly/l01", "lease_duration": 1, "renewable": true,
+			"data": {"username": "v-readonly", "password": "pass"}}`))
+	})
+	mux.HandleFunc("/v1/sys/leases/renew", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(renewCount, 1) > 1 {
+			// reaching max ttl
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors": ["lease expired"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"lease_id": "database/creds/readonly/l01", "lease_duration": 1, "renewable": true}`))
+	})
+	mux.HandleFunc("/v1/transit/encrypt/k01", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"data": {"ciphertext": "vault:v1:` + req["plaintext"] + `"}}`))
+	})
+	mux.HandleFunc("/v1/transit/decrypt/k01", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"data": {"plaintext": "` + req["ciphertext"][len("vault:v1:"):] + `"}}`))
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/approle/login" && r.Header.Get(tokenHeader) != testToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestConn_KV(t *testing.T) {
+	asst := assert.New(t)
+
+	var renewCount int32
+	server := newTestServer(&renewCount)
+	defer server.Close()
+
+	_, err := NewConnWithConfig(NewConfigWithAppRole(server.URL, "role", "wrong"))
+	asst.NotNil(err, "test LoginWithAppRole() failed")
+	conn, err := NewConnWithConfig(NewConfigWithAppRole(server.URL, "role", "secret"))
+	asst.Nil(err, "test LoginWithAppRole() failed")
+	asst.Equal(testToken, conn.Token(), "test LoginWithAppRole() failed")
+
+	kv, err := conn.ReadKV(context.Background(), DefaultKVMountPath, "mysql/prod")
+	asst.Nil(err, "test ReadKV() failed")
+	asst.Equal(1, kv.Metadata.Version, "test ReadKV() failed")
+	metadata, err := conn.WriteKV(context.Background(), DefaultKVMountPath, "mysql/prod", map[string]interface{}{"password": "new"})
+	asst.Nil(err, "test WriteKV() failed")
+	asst.Equal(2, metadata.Version, "test WriteKV() failed")
+
+	password, err := conn.ResolveSecret(context.Background(), "secret/mysql/prod#password")
+	asst.Nil(err, "test ResolveSecret() failed")
+	asst.Equal("pass", password, "test ResolveSecret() failed")
+	_, err = conn.ResolveSecret(context.Background(), "mysql#password")
+	asst.NotNil(err, "test ResolveSecret() failed")
+
+	conn.SetToken("wrong")
+	_, err = conn.ReadKV(context.Background(), DefaultKVMountPath, "mysql/prod")
+	asst.NotNil(err, "test ReadKV() failed")
+}
+
+func TestConn_DatabaseCredential(t *testing.T) {
+	asst := assert.New(t)
+
+	var renewCount int32
+	server := newTestServer(&renewCount)
+	defer server.Close()
+
+	conn, err := NewConn(server.URL, testToken)
+	asst.Nil(err, "test NewConn() failed")
+	cred, err := conn.GetDatabaseCredential(context.Background(), DefaultDatabaseMountPath, "readonly")
+	asst.Nil(err, "test GetDatabaseCredential() failed")
+	asst.Equal("v-readonly", cred.Username, "test GetDatabaseCredential() failed")
+
+	expired := make(chan error, 1)
+	lr := conn.NewLeaseRenewer(cred.LeaseID, cred.LeaseDuration, func(err error) { expired <- err })
+	defer lr.Stop()
+
+	select {
+	case err = <-expired:
+		asst.NotNil(err, "test LeaseRenewer failed")
+		asst.Equal(int32(2), atomic.LoadInt32(&renewCount), "test LeaseRenewer failed")
+	case <-time.After(5 * time.Second):
+		asst.Fail("test LeaseRenewer failed")
+	}
+}
+
+func TestConn_Transit(t *testing.T) {
+	asst := assert.New(t)
+
+	var renewCount int32
+	server := newTestServer(&renewCount)
+	defer server.Close()
+
+	conn, err := NewConn(server.URL, testToken)
+	asst.Nil(err, "test NewConn() failed")
+	ciphertext, err := conn.Encrypt(context.Background(), DefaultTransitMountPath, "k01", []byte("hello"))
+	asst.Nil(err, "test Encrypt() failed")
+	plaintext, err := conn.Decrypt(context.Background(), DefaultTransitMountPath, "k01", ciphertext)
+	asst.Nil(err, "test Decrypt() failed")
+	asst.Equal("hello", string(plaintext), "test Decrypt() failed")
+}
@@ -0,0 +1,145 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultKVMountPath = "secret"
+
+	// secretRefSeparator separates the path and the key of a secret reference
+	secretRefSeparator = "#"
+)
+
+type KVMetadata struct {
+	CreatedTime  string `json:"created_time"`
+	DeletionTime string `json:"deletion_time"`
+	Destroyed    bool   `json:"destroyed"`
+	Version      int    `json:"version"`
+}
+
+// KVSecret is the secret of kv version 2 engine
+type KVSecret struct {
+	Data     map[string]interface{}
+	Metadata KVMetadata
+}
+
+// GetString returns the value of given key as a string
+func (kv *KVSecret) GetString(key string) (string, error) {
+	value, ok := kv.Data[key]
+	if !ok {
+		return constant.EmptyString, errors.New(fmt.Sprintf("key does not exist in the secret. key: %s", key))
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return constant.EmptyString, errors.New(fmt.Sprintf("value of the key is not a string. key: %s", key))
+	}
+
+	return str, nil
+}
+
+// ReadKV reads the latest version of the secret from kv version 2 engine, path does not contain the data/ prefix
+func (conn *Conn) ReadKV(ctx context.Context, mountPath, path string) (*KVSecret, error) {
+	return conn.ReadKVVersion(ctx, mountPath, path, constant.ZeroInt)
+}
+
+// ReadKVVersion reads given version of the secret from kv version 2 engine, version 0 means the latest version
+func (conn *Conn) ReadKVVersion(ctx context.Context, mountPath, path string, version int) (*KVSecret, error) {
+	p := kvPath(mountPath, "data", path)
+	if version > constant.ZeroInt {
+		p += "?" + url.Values{"version": {strconv.Itoa(version)}}.Encode()
+	}
+
+	secret, err := conn.Read(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKVSecret(secret)
+}
+
+// WriteKV writes the data to kv version 2 engine as a new version and returns the metadata of it
+func (conn *Conn) WriteKV(ctx context.Context, mountPath, path string, data map[string]interface{}) (*KVMetadata, error) {
+	secret, err := conn.Write(ctx, kvPath(mountPath, "data", path), map[string]interface{}{"data": data})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &KVMetadata{}
+	if version, ok := secret.Data["version"].(float64); ok {
+		metadata.Version = int(version)
+	}
+	metadata.CreatedTime, _ = secret.Data["created_time"].(string)
+
+	return metadata, nil
+}
+
+// DeleteKV soft deletes the latest version of the secret, it could be undeleted
+func (conn *Conn) DeleteKV(ctx context.Context, mountPath, path string) error {
+	return conn.Delete(ctx, kvPath(mountPath, "data", path))
+}
+
+// DestroyKV permanently deletes all the versions and the metadata of the secret
+func (conn *Conn) DestroyKV(ctx context.Context, mountPath, path string) error {
+	return conn.Delete(ctx, kvPath(mountPath, "metadata", path))
+}
+
+// ResolveSecret resolves the secret reference in the form of mount/path#key from kv version 2 engine,
+// for example: secret/mysql/prod#password, it is used to resolve the secret references in the config files
+func (conn *Conn) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	invalidErr := errors.New(fmt.Sprintf("secret reference must be in the form of mount/path#key. ref: %s", ref))
+
+	index := strings.LastIndex(ref, secretRefSeparator)
+	if index <= constant.ZeroInt || index == len(ref)-1 {
+		return constant.EmptyString, invalidErr
+	}
+	parts := strings.SplitN(strings.Trim(ref[:index], constant.SlashString), constant.SlashString, 2)
+	if len(parts) != 2 {
+		return constant.EmptyString, invalidErr
+	}
+
+	kv, err := conn.ReadKV(ctx, parts[0], parts[1])
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return kv.GetString(ref[index+1:])
+}
+
+// newKVSecret converts the response secret of kv version 2 engine to *KVSecret
+func newKVSecret(secret *Secret) (*KVSecret, error) {
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		// the latest version had been deleted
+		return nil, errors.New("secret data is empty, it may have been deleted")
+	}
+
+	kv := &KVSecret{Data: data}
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		kv.Metadata.CreatedTime, _ = metadata["created_time"].(string)
+		kv.Metadata.DeletionTime, _ = metadata["deletion_time"].(string)
+		kv.Metadata.Destroyed, _ = metadata["destroyed"].(bool)
+		if version, ok := metadata["version"].(float64); ok {
+			kv.Metadata.Version = int(version)
+		}
+	}
+
+	return kv, nil
+}
+
+// kvPath returns the api path of kv version 2 engine
+func kvPath(mountPath, kind, path string) string {
+	if mountPath == constant.EmptyString {
+		mountPath = DefaultKVMountPath
+	}
+
+	return strings.Trim(mountPath, constant.SlashString) + constant.SlashString + kind + constant.SlashString + strings.TrimPrefix(path, constant.SlashString)
+}
@@ -0,0 +1,145 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DefaultDatabaseMountPath = "database"
+
+	renewLeasePath  = "sys/leases/renew"
+	revokeLeasePath = "sys/leases/revoke"
+
+	// minRenewInterval prevents renewing too frequently when the lease duration is very short
+	minRenewInterval = time.Second
+)
+
+// DatabaseCredential is the dynamic credential generated by the database secrets engine
+type DatabaseCredential struct {
+	Username      string
+	Password      string
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// GetDatabaseCredential generates a new dynamic credential of given role from the database secrets engine
+func (conn *Conn) GetDatabaseCredential(ctx context.Context, mountPath, role string) (*DatabaseCredential, error) {
+	if mountPath == constant.EmptyString {
+		mountPath = DefaultDatabaseMountPath
+	}
+
+	secret, err := conn.Read(ctx, mountPath+"/creds/"+role)
+	if err != nil {
+		return nil, err
+	}
+
+	cred := &DatabaseCredential{
+		LeaseID:       secret.LeaseID,
+		LeaseDuration: time.Duration(secret.LeaseDuration) * time.Second,
+		Renewable:     secret.Renewable,
+	}
+	cred.Username, _ = secret.Data["username"].(string)
+	cred.Password, _ = secret.Data["password"].(string)
+	if cred.Username == constant.EmptyString {
+		return nil, errors.New(fmt.Sprintf("no username returned from vault. mount path: %s, role: %s", mountPath, role))
+	}
+
+	return cred, nil
+}
+
+// RenewLease renews the lease with given increment and returns the new lease duration,
+// note that vault may return a shorter duration than the increment because of the max ttl
+func (conn *Conn) RenewLease(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	secret, err := conn.Write(ctx, renewLeasePath, map[string]interface{}{
+		"lease_id":  leaseID,
+		"increment": int(increment.Seconds()),
+	})
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return time.Duration(secret.LeaseDuration) * time.Second, nil
+}
+
+// RevokeLease revokes the lease immediately, the dynamic credential will be dropped from the database
+func (conn *Conn) RevokeLease(ctx context.Context, leaseID string) error {
+	_, err := conn.Write(ctx, revokeLeasePath, map[string]interface{}{"lease_id": leaseID})
+
+	return err
+}
+
+// LeaseRenewer renews the lease in background before it expires
+type LeaseRenewer struct {
+	conn      *Conn
+	leaseID   string
+	increment time.Duration
+	// onExpire is called when the lease could not be renewed any more, for example: reaching the max ttl,
+	// the caller should request a new credential
+	onExpire func(err error)
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewLeaseRenewer returns a new *LeaseRenewer and starts renewing in background,
+// it renews the lease when 2/3 of the lease duration has elapsed
+func (conn *Conn) NewLeaseRenewer(leaseID string, leaseDuration time.Duration, onExpire func(err error)) *LeaseRenewer {
+	lr := &LeaseRenewer{
+		conn:      conn,
+		leaseID:   leaseID,
+		increment: leaseDuration,
+		onExpire:  onExpire,
+		stopChan:  make(chan struct{}),
+	}
+
+	go lr.loop(leaseDuration)
+
+	return lr
+}
+
+// Stop stops renewing, the lease will expire after the remaining duration, it does not revoke the lease
+func (lr *LeaseRenewer) Stop() {
+	lr.stopOnce.Do(func() { close(lr.stopChan) })
+}
+
+// loop renews the lease periodically until it is stopped or the lease could not be renewed
+func (lr *LeaseRenewer) loop(leaseDuration time.Duration) {
+	for {
+		interval := leaseDuration * 2 / 3
+		if interval < minRenewInterval {
+			interval = minRenewInterval
+		}
+
+		select {
+		case <-lr.stopChan:
+			return
+		case <-time.After(interval):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+		duration, err := lr.conn.RenewLease(ctx, lr.leaseID, lr.increment)
+		cancel()
+		if err == nil && duration <= constant.ZeroInt {
+			err = errors.New("lease duration returned by vault is 0, lease could not be renewed any more")
+		}
+		if err != nil {
+			log.Errorf("renew vault lease failed. lease id: %s. %s", lr.leaseID, err.Error())
+			if lr.onExpire != nil {
+				lr.onExpire(err)
+			}
+
+			return
+		}
+
+		log.Debugf("renew vault lease completed. lease id: %s, duration: %s", lr.leaseID, duration.String())
+		leaseDuration = duration
+	}
+}
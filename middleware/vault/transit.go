@@ -0,0 +1,55 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultTransitMountPath = "transit"
+
+// Encrypt encrypts the plaintext with the key of transit secrets engine and returns the ciphertext, for example: vault:v1:xxx
+func (conn *Conn) Encrypt(ctx context.Context, mountPath, key string, plaintext []byte) (string, error) {
+	secret, err := conn.Write(ctx, transitPath(mountPath, "encrypt", key), map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+	})
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return constant.EmptyString, errors.New(fmt.Sprintf("no ciphertext returned from vault. key: %s", key))
+	}
+
+	return ciphertext, nil
+}
+
+// Decrypt decrypts the ciphertext with the key of transit secrets engine and returns the plaintext
+func (conn *Conn) Decrypt(ctx context.Context, mountPath, key, ciphertext string) ([]byte, error) {
+	secret, err := conn.Write(ctx, transitPath(mountPath, "decrypt", key), map[string]interface{}{
+		"ciphertext": ciphertext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no plaintext returned from vault. key: %s", key))
+	}
+
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+// transitPath returns the api path of transit secrets engine
+func transitPath(mountPath, action, key string) string {
+	if mountPath == constant.EmptyString {
+		mountPath = DefaultTransitMountPath
+	}
+
+	return mountPath + constant.SlashString + action + constant.SlashString + key
+}
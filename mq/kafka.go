@@ -0,0 +1,179 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/kafka"
+)
+
+var (
+	_ Producer = (*KafkaProducer)(nil)
+	_ Consumer = (*KafkaConsumer)(nil)
+)
+
+type KafkaProducer struct {
+	Client   sarama.Client
+	Producer sarama.SyncProducer
+}
+
+// NewKafkaProducer returns a new *KafkaProducer which waits for all in-sync replicas to acknowledge the messages
+func NewKafkaProducer(kafkaVersion string, brokerList []string) (*KafkaProducer, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+
+	version, err := sarama.ParseKafkaVersion(kafkaVersion)
+	if err != nil {
+		return nil, err
+	}
+	config.Version = version
+
+	client, err := sarama.NewClient(brokerList, config)
+	if err != nil {
+		return nil, kafka.ClassifyError(err)
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return &KafkaProducer{
+		Client:   client,
+		Producer: producer,
+	}, nil
+}
+
+// Publish sends the message to given topic synchronously,
+// sarama does not support canceling the sending, so the context is only checked before sending
+func (p *KafkaProducer) Publish(ctx context.Context, topic string, message *Message) error {
+	if ctx.Err() != nil {
+		return kafka.ClassifyError(ctx.Err())
+	}
+
+	pm := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(message.Payload),
+	}
+	if message.Key != constant.EmptyString {
+		pm.Key = sarama.StringEncoder(message.Key)
+	}
+	for key, value := range message.Headers {
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	_, _, err := p.Producer.SendMessage(pm)
+
+	return kafka.ClassifyError(err)
+}
+
+// Close closes the producer and the client
+func (p *KafkaProducer) Close() error {
+	err := p.Producer.Close()
+	if err != nil {
+		return err
+	}
+
+	return p.Client.Close()
+}
+
+type KafkaConsumer struct {
+	GroupName string
+	Client    sarama.Client
+}
+
+// NewKafkaConsumer returns a new *KafkaConsumer of given consumer group, it consumes from the newest offset at the first time
+func NewKafkaConsumer(kafkaVersion string, brokerList []string, groupName string) (*KafkaConsumer, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+
+	version, err := sarama.ParseKafkaVersion(kafkaVersion)
+	if err != nil {
+		return nil, err
+	}
+	config.Version = version
+
+	client, err := sarama.NewClient(brokerList, config)
+	if err != nil {
+		return nil, kafka.ClassifyError(err)
+	}
+
+	return &KafkaConsumer{
+		GroupName: groupName,
+		Client:    client,
+	}, nil
+}
+
+// Subscribe consumes the messages of given topic with the consumer group until the context is done,
+// the offset of the message is marked only if the handler returns nil,
+// otherwise, the session ends and the messages will be redelivered from the last marked offset
+func (c *KafkaConsumer) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	group, err := sarama.NewConsumerGroupFromClient(c.GroupName, c.Client)
+	if err != nil {
+		return kafka.ClassifyError(err)
+	}
+	defer func() {
+		err = group.Close()
+		if err != nil {
+			log.Errorf("close consumer group failed. group: %s, topic: %s. %s", c.GroupName, topic, err.Error())
+		}
+	}()
+
+	h := &kafkaHandler{handler: handler}
+	for ctx.Err() == nil {
+		err = group.Consume(ctx, []string{topic}, h)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return kafka.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the client
+func (c *KafkaConsumer) Close() error {
+	return c.Client.Close()
+}
+
+// kafkaHandler adapts Handler to sarama.ConsumerGroupHandler
+type kafkaHandler struct {
+	kafka.DefaultConsumerGroupHandler
+	handler Handler
+}
+
+// ConsumeClaim passes the messages of the claim to the handler
+func (h *kafkaHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for cm := range claim.Messages() {
+		message := &Message{
+			Topic:   cm.Topic,
+			Key:     string(cm.Key),
+			Payload: cm.Value,
+		}
+		if len(cm.Headers) > constant.ZeroInt {
+			message.Headers = make(map[string]string, len(cm.Headers))
+			for _, header := range cm.Headers {
+				message.Headers[string(header.Key)] = string(header.Value)
+			}
+		}
+
+		err := h.handler(sess.Context(), message)
+		if err != nil {
+			log.Errorf("handle message failed, will redeliver later. topic: %s, partition: %d, offset: %d. %s",
+				cm.Topic, cm.Partition, cm.Offset, err.Error())
+			return err
+		}
+
+		sess.MarkMessage(cm, constant.EmptyString)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	TypeKafka  = "kafka"
+	TypePulsar = "pulsar"
+
+	DefaultKafkaVersion = "2.0.0"
+)
+
+// Message is the broker independent message
+type Message struct {
+	// Topic is the topic which the message is consumed from, it is ignored when publishing
+	Topic   string
+	Key     string
+	Payload []byte
+	// Headers are the headers of kafka message or the properties of pulsar message
+	Headers map[string]string
+}
+
+// NewMessage returns a new *Message
+func NewMessage(key string, payload []byte) *Message {
+	return &Message{
+		Key:     key,
+		Payload: payload,
+	}
+}
+
+// Handler handles the consumed message, if it returns an error, the message will be redelivered later
+type Handler func(ctx context.Context, message *Message) error
+
+type Producer interface {
+	// Publish sends the message to given topic synchronously, it returns after the message is acknowledged by the broker
+	Publish(ctx context.Context, topic string, message *Message) error
+	// Close closes the producer
+	Close() error
+}
+
+type Consumer interface {
+	// Subscribe consumes the messages of given topic and passes them to the handler,
+	// it blocks until the context is done or an unrecoverable error occurs
+	Subscribe(ctx context.Context, topic string, handler Handler) error
+	// Close closes the consumer
+	Close() error
+}
+
+type Config struct {
+	// Type is the type of the broker, it should be either kafka or pulsar
+	Type string `json:"type"`
+	// Addrs is the broker list of kafka, or the service url of pulsar, only the first address is used for pulsar
+	Addrs []string `json:"addrs"`
+	// Group is the consumer group of kafka or the subscription name of pulsar, it is required by the consumer
+	Group string `json:"group"`
+	// KafkaVersion is the version of kafka, if it is empty, DefaultKafkaVersion will be used
+	KafkaVersion string `json:"kafka_version"`
+}
+
+// NewConfig returns a new Config
+func NewConfig(typ string, addrs []string, group string) Config {
+	return Config{
+		Type:         typ,
+		Addrs:        addrs,
+		Group:        group,
+		KafkaVersion: DefaultKafkaVersion,
+	}
+}
+
+// Validate validates the config
+func (cfg *Config) Validate() (bool, error) {
+	if cfg.Type != TypeKafka && cfg.Type != TypePulsar {
+		return false, errors.New(fmt.Sprintf("mq type should be either %s or %s, %s is not valid", TypeKafka, TypePulsar, cfg.Type))
+	}
+	if len(cfg.Addrs) == constant.ZeroInt {
+		return false, errors.New("addrs should not be empty")
+	}
+
+	return true, nil
+}
+
+// NewProducer returns a new Producer of the broker specified by the config
+func NewProducer(config Config) (Producer, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+
+	switch config.Type {
+	case TypeKafka:
+		return NewKafkaProducer(config.kafkaVersion(), config.Addrs)
+	default:
+		return NewPulsarProducer(config.Addrs[constant.ZeroInt])
+	}
+}
+
+// NewConsumer returns a new Consumer of the broker specified by the config
+func NewConsumer(config Config) (Consumer, error) {
+	ok, err := config.Validate()
+	if !ok {
+		return nil, err
+	}
+	if config.Group == constant.EmptyString {
+		return nil, errors.New("group should not be empty")
+	}
+
+	switch config.Type {
+	case TypeKafka:
+		return NewKafkaConsumer(config.kafkaVersion(), config.Addrs, config.Group)
+	default:
+		return NewPulsarConsumer(config.Addrs[constant.ZeroInt], config.Group)
+	}
+}
+
+// kafkaVersion returns the kafka version of the config, if it is empty, returns DefaultKafkaVersion
+func (cfg *Config) kafkaVersion() string {
+	if cfg.KafkaVersion == constant.EmptyString {
+		return DefaultKafkaVersion
+	}
+
+	return cfg.KafkaVersion
+}
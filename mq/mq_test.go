@@ -0,0 +1,34 @@
+package mq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	asst := assert.New(t)
+
+	var config Config
+	err := json.Unmarshal([]byte(`{"type": "kafka", "addrs": ["127.0.0.1:9092"], "group": "test"}`), &config)
+	asst.Nil(err, "test Validate() failed")
+	ok, err := config.Validate()
+	asst.True(ok, "test Validate() failed")
+	asst.Nil(err, "test Validate() failed")
+	asst.Equal(DefaultKafkaVersion, config.kafkaVersion(), "test Validate() failed")
+
+	config = NewConfig("rabbitmq", []string{"127.0.0.1:5672"}, "test")
+	ok, err = config.Validate()
+	asst.False(ok, "test Validate() failed")
+	asst.NotNil(err, "test Validate() failed")
+	_, err = NewProducer(config)
+	asst.NotNil(err, "test NewProducer() failed")
+
+	config = NewConfig(TypePulsar, nil, "test")
+	ok, _ = config.Validate()
+	asst.False(ok, "test Validate() failed")
+
+	_, err = NewConsumer(NewConfig(TypeKafka, []string{"127.0.0.1:9092"}, ""))
+	asst.NotNil(err, "test NewConsumer() failed")
+}
@@ -0,0 +1,132 @@
+package mq
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/hashicorp/go-multierror"
+
+	mqpulsar "github.com/romberli/go-util/middleware/pulsar"
+)
+
+var (
+	_ Producer = (*PulsarProducer)(nil)
+	_ Consumer = (*PulsarConsumer)(nil)
+)
+
+type PulsarProducer struct {
+	Conn *mqpulsar.Conn
+
+	mutex     sync.Mutex
+	producers map[string]*mqpulsar.Producer
+}
+
+// NewPulsarProducer returns a new *PulsarProducer, the producer of each topic is created at the first publishing
+func NewPulsarProducer(url string) (*PulsarProducer, error) {
+	conn, err := mqpulsar.NewConn(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PulsarProducer{
+		Conn:      conn,
+		producers: make(map[string]*mqpulsar.Producer),
+	}, nil
+}
+
+// Publish sends the message to given topic synchronously
+func (p *PulsarProducer) Publish(ctx context.Context, topic string, message *Message) error {
+	producer, err := p.getProducer(topic)
+	if err != nil {
+		return err
+	}
+
+	_, err = producer.Produce(ctx, producer.BuildProducerMessage(message.Key, message.Payload, message.Headers))
+
+	return err
+}
+
+// getProducer returns the producer of given topic, it creates the producer if not exists
+func (p *PulsarProducer) getProducer(topic string) (*mqpulsar.Producer, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	producer, ok := p.producers[topic]
+	if ok {
+		return producer, nil
+	}
+
+	producer, err := mqpulsar.NewProducer(p.Conn, topic, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.producers[topic] = producer
+
+	return producer, nil
+}
+
+// Close closes the producers and the connection
+func (p *PulsarProducer) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var merr error
+	for topic, producer := range p.producers {
+		err := producer.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+		delete(p.producers, topic)
+	}
+
+	err := p.Conn.Close()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	return merr
+}
+
+type PulsarConsumer struct {
+	Conn             *mqpulsar.Conn
+	SubscriptionName string
+}
+
+// NewPulsarConsumer returns a new *PulsarConsumer, it subscribes the topics with shared subscription of given name,
+// so that the consumers with the same subscription name work like a kafka consumer group
+func NewPulsarConsumer(url, subscriptionName string) (*PulsarConsumer, error) {
+	conn, err := mqpulsar.NewConn(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PulsarConsumer{
+		Conn:             conn,
+		SubscriptionName: subscriptionName,
+	}, nil
+}
+
+// Subscribe consumes the messages of given topic until the context is done,
+// the message is acknowledged if the handler returns nil, otherwise, it will be redelivered later
+func (c *PulsarConsumer) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	consumer, err := mqpulsar.NewConsumer(c.Conn, mqpulsar.NewConsumerConfig(c.SubscriptionName, pulsar.Shared, topic))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = consumer.Close() }()
+
+	return consumer.Consume(ctx, mqpulsar.HandlerFunc(func(ctx context.Context, pm pulsar.Message) error {
+		return handler(ctx, &Message{
+			Topic:   pm.Topic(),
+			Key:     pm.Key(),
+			Payload: pm.Payload(),
+			Headers: pm.Properties(),
+		})
+	}))
+}
+
+// Close closes the connection
+func (c *PulsarConsumer) Close() error {
+	return c.Conn.Close()
+}
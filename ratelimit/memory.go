@@ -0,0 +1,248 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*LeakyBucket)(nil)
+	_ Limiter = (*SlidingWindow)(nil)
+
+	// ErrLimitExceeded is returned when the permit could never be acquired or the waiting queue is full
+	ErrLimitExceeded = errors.New("rate limit exceeded")
+)
+
+// TokenBucket is a token bucket limiter, tokens are added to the bucket at rate per second,
+// and the bucket holds at most burst tokens, each event consumes a token
+type TokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a new *TokenBucket, the bucket is full at the beginning
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// advance adds the tokens generated since last time
+func (tb *TokenBucket) advance(now time.Time) {
+	elapsed := now.Sub(tb.last)
+	if elapsed > 0 {
+		tb.tokens = math.Min(float64(tb.burst), tb.tokens+elapsed.Seconds()*tb.rate)
+		tb.last = now
+	}
+}
+
+// Allow reports whether an event may happen now
+func (tb *TokenBucket) Allow() bool {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.advance(time.Now())
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+
+	return true
+}
+
+// Reserve reserves a token, the tokens could be negative which means the future tokens are reserved
+func (tb *TokenBucket) Reserve() *Reservation {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.advance(time.Now())
+	if tb.tokens < 1 && tb.rate <= 0 {
+		return newFailedReservation(ErrLimitExceeded)
+	}
+	tb.tokens--
+
+	var delay time.Duration
+	if tb.tokens < 0 {
+		delay = time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+	}
+
+	return newReservation(delay, func() {
+		tb.mutex.Lock()
+		defer tb.mutex.Unlock()
+
+		tb.tokens = math.Min(float64(tb.burst), tb.tokens+1)
+	})
+}
+
+// Wait blocks until a token is acquired or the context is done
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	return wait(ctx, tb)
+}
+
+// LeakyBucket is a leaky bucket limiter, the events leak out of the bucket at a constant rate of one per interval,
+// at most capacity events could be waiting in the bucket, so that the events are smoothed without any burst
+type LeakyBucket struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	capacity int
+	next     time.Time
+}
+
+// NewLeakyBucket returns a new *LeakyBucket, rate is the number of events per second
+func NewLeakyBucket(rate float64, capacity int) (*LeakyBucket, error) {
+	if rate <= 0 {
+		return nil, errors.New(fmt.Sprintf("rate must be positive. rate: %f", rate))
+	}
+
+	return &LeakyBucket{
+		interval: time.Duration(float64(time.Second) / rate),
+		capacity: capacity,
+	}, nil
+}
+
+// Allow reports whether an event may happen now, it does not wait in the bucket
+func (lb *LeakyBucket) Allow() bool {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+
+	now := time.Now()
+	if lb.next.After(now) {
+		return false
+	}
+	lb.next = now.Add(lb.interval)
+
+	return true
+}
+
+// Reserve puts the event into the bucket and returns when it leaks out,
+// if the bucket is full, the reservation is not ok
+func (lb *LeakyBucket) Reserve() *Reservation {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+
+	now := time.Now()
+	at := lb.next
+	if at.Before(now) {
+		at = now
+	}
+	delay := at.Sub(now)
+	if delay > time.Duration(lb.capacity)*lb.interval {
+		return newFailedReservation(ErrLimitExceeded)
+	}
+	lb.next = at.Add(lb.interval)
+
+	return newReservation(delay, func() {
+		lb.mutex.Lock()
+		defer lb.mutex.Unlock()
+
+		// only the last reservation could be given back, otherwise, the events after it would be rescheduled
+		if lb.next.Equal(at.Add(lb.interval)) {
+			lb.next = at
+		}
+	})
+}
+
+// Wait blocks until the event leaks out of the bucket or the context is done
+func (lb *LeakyBucket) Wait(ctx context.Context) error {
+	return wait(ctx, lb)
+}
+
+// SlidingWindow allows at most limit events in any window of time,
+// it keeps the time of the events in the window, so that the window slides precisely
+type SlidingWindow struct {
+	mutex  sync.Mutex
+	limit  int
+	window time.Duration
+	// events are the time of the recent events in ascending order, the future time means reserved events
+	events []time.Time
+}
+
+// NewSlidingWindow returns a new *SlidingWindow
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+		events: make([]time.Time, 0, limit),
+	}
+}
+
+// nextTime discards the events out of the window and returns the earliest time that a new event is allowed,
+// it must be called with lock held
+func (sw *SlidingWindow) nextTime(now time.Time) time.Time {
+	start := now.Add(-sw.window)
+	expired := 0
+	for expired < len(sw.events) && !sw.events[expired].After(start) {
+		expired++
+	}
+	if expired > 0 {
+		sw.events = append(sw.events[:0], sw.events[expired:]...)
+	}
+
+	if len(sw.events) < sw.limit {
+		// keep the events in order, so that the reserved events will not be affected
+		if len(sw.events) > 0 && sw.events[len(sw.events)-1].After(now) {
+			return sw.events[len(sw.events)-1]
+		}
+
+		return now
+	}
+
+	return sw.events[len(sw.events)-sw.limit].Add(sw.window)
+}
+
+// Allow reports whether an event may happen now
+func (sw *SlidingWindow) Allow() bool {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	if sw.limit <= 0 {
+		return false
+	}
+	now := time.Now()
+	if sw.nextTime(now).After(now) {
+		return false
+	}
+	sw.events = append(sw.events, now)
+
+	return true
+}
+
+// Reserve reserves the earliest time when the window allows a new event
+func (sw *SlidingWindow) Reserve() *Reservation {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	if sw.limit <= 0 {
+		return newFailedReservation(ErrLimitExceeded)
+	}
+	now := time.Now()
+	at := sw.nextTime(now)
+	sw.events = append(sw.events, at)
+
+	return newReservation(at.Sub(now), func() {
+		sw.mutex.Lock()
+		defer sw.mutex.Unlock()
+
+		// only the last reservation could be given back, otherwise, the events after it would be rescheduled
+		last := len(sw.events) - 1
+		if last >= 0 && sw.events[last].Equal(at) {
+			sw.events = sw.events[:last]
+		}
+	})
+}
+
+// Wait blocks until the window allows a new event or the context is done
+func (sw *SlidingWindow) Wait(ctx context.Context) error {
+	return wait(ctx, sw)
+}
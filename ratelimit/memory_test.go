@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	asst := assert.New(t)
+
+	tb := NewTokenBucket(100, 2)
+	asst.True(tb.Allow(), "test Allow() failed")
+	asst.True(tb.Allow(), "test Allow() failed")
+	asst.False(tb.Allow(), "test Allow() failed")
+
+	r := tb.Reserve()
+	asst.True(r.OK(), "test Reserve() failed")
+	asst.True(r.Delay() > 0 && r.Delay() <= 10*time.Millisecond, "test Reserve() failed")
+	r.Cancel()
+
+	start := time.Now()
+	asst.Nil(tb.Wait(context.Background()), "test Wait() failed")
+	asst.True(time.Since(start) > 5*time.Millisecond, "test Wait() failed")
+
+	// the context deadline is too short to wait for the token
+	asst.False(tb.Allow(), "test Allow() failed")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	asst.NotNil(tb.Wait(ctx), "test Wait() failed")
+
+	asst.False(NewTokenBucket(0, 0).Reserve().OK(), "test Reserve() failed")
+}
+
+func TestLeakyBucket(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := NewLeakyBucket(0, 1)
+	asst.NotNil(err, "test NewLeakyBucket() failed")
+
+	lb, err := NewLeakyBucket(100, 1)
+	asst.Nil(err, "test NewLeakyBucket() failed")
+	asst.True(lb.Allow(), "test Allow() failed")
+	asst.False(lb.Allow(), "test Allow() failed")
+
+	r := lb.Reserve()
+	asst.True(r.OK(), "test Reserve() failed")
+	asst.True(r.Delay() > 0, "test Reserve() failed")
+	// the bucket is full
+	asst.Equal(ErrLimitExceeded, lb.Reserve().Err(), "test Reserve() failed")
+	r.Cancel()
+	asst.True(lb.Reserve().OK(), "test Cancel() failed")
+}
+
+func TestSlidingWindow(t *testing.T) {
+	asst := assert.New(t)
+
+	sw := NewSlidingWindow(2, 20*time.Millisecond)
+	asst.True(sw.Allow(), "test Allow() failed")
+	asst.True(sw.Allow(), "test Allow() failed")
+	asst.False(sw.Allow(), "test Allow() failed")
+
+	r := sw.Reserve()
+	asst.True(r.OK(), "test Reserve() failed")
+	asst.True(r.Delay() > 0 && r.Delay() <= 20*time.Millisecond, "test Reserve() failed")
+	r.Cancel()
+	asst.Equal(2, len(sw.events), "test Cancel() failed")
+
+	start := time.Now()
+	asst.Nil(sw.Wait(context.Background()), "test Wait() failed")
+	asst.True(time.Since(start) > 10*time.Millisecond, "test Wait() failed")
+
+	time.Sleep(30 * time.Millisecond)
+	asst.True(sw.Allow(), "test Allow() failed")
+	asst.Equal(1, len(sw.events), "test Allow() failed")
+
+	asst.False(NewSlidingWindow(0, time.Second).Allow(), "test Allow() failed")
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Limiter controls how frequently events are allowed to happen
+type Limiter interface {
+	// Allow reports whether an event may happen now, the permit will be consumed if it returns true
+	Allow() bool
+	// Reserve reserves a permit and returns a *Reservation which tells how long the caller should wait,
+	// if the caller decides not to act, it should call Reservation.Cancel() to return the permit
+	Reserve() *Reservation
+	// Wait blocks until a permit is acquired or the context is done
+	Wait(ctx context.Context) error
+}
+
+// Reservation holds the information of a reserved permit
+type Reservation struct {
+	delay  time.Duration
+	err    error
+	cancel func()
+}
+
+// newReservation returns a new *Reservation
+func newReservation(delay time.Duration, cancel func()) *Reservation {
+	return &Reservation{
+		delay:  delay,
+		cancel: cancel,
+	}
+}
+
+// newFailedReservation returns a *Reservation which is not ok
+func newFailedReservation(err error) *Reservation {
+	return &Reservation{err: err}
+}
+
+// OK returns if the permit had been reserved
+func (r *Reservation) OK() bool {
+	return r.err == nil
+}
+
+// Err returns the reason why the permit could not be reserved
+func (r *Reservation) Err() error {
+	return r.err
+}
+
+// Delay returns how long the caller should wait before the event happens
+func (r *Reservation) Delay() time.Duration {
+	return r.delay
+}
+
+// Cancel returns the reserved permit back to the limiter as much as possible
+func (r *Reservation) Cancel() {
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// wait reserves a permit with the limiter and waits for the delay,
+// if the context will be done before the permit is available, it cancels the reservation and returns error immediately
+func wait(ctx context.Context, limiter Limiter) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	r := limiter.Reserve()
+	if !r.OK() {
+		return r.Err()
+	}
+	delay := r.Delay()
+	if delay <= 0 {
+		return nil
+	}
+
+	deadline, ok := ctx.Deadline()
+	if ok && time.Now().Add(delay).After(deadline) {
+		r.Cancel()
+		return errors.New(fmt.Sprintf("rate limit wait %s would exceed context deadline", delay.String()))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
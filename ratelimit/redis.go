@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/locker"
+)
+
+const (
+	redisMemberRandomBytes = 8
+
+	// redisReserveScript implements the sliding window with a sorted set, the score is the time of the event in milliseconds,
+	// it reserves the earliest time when the window allows a new event and returns the delay in milliseconds,
+	// if ARGV[5] is 1, it does not reserve any future time and returns -1 instead
+	redisReserveScript = `local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('zremrangebyscore', KEYS[1], '-inf', now - window)
+local count = redis.call('zcard', KEYS[1])
+local at = now
+if count >= limit then
+	local entry = redis.call('zrange', KEYS[1], count - limit, count - limit, 'withscores')
+	at = tonumber(entry[2]) + window
+elseif count > 0 then
+	local last = redis.call('zrange', KEYS[1], -1, -1, 'withscores')
+	at = math.max(now, tonumber(last[2]))
+end
+if at > now and ARGV[5] == '1' then
+	return -1
+end
+redis.call('zadd', KEYS[1], at, ARGV[4])
+redis.call('pexpire', KEYS[1], at - now + window)
+return at - now`
+	redisCancelScript = `return redis.call('zrem', KEYS[1], ARGV[1])`
+)
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// RedisLimiter is a distributed sliding window limiter, all the limiters with the same key share the same window,
+// the time of the events is taken from the local clock, so the clocks of the clients should be synchronized
+type RedisLimiter struct {
+	evaler locker.RedisEvaler
+	key    string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisLimiter returns a new *RedisLimiter which allows at most limit events in any window of time
+func NewRedisLimiter(evaler locker.RedisEvaler, key string, limit int, window time.Duration) *RedisLimiter {
+	return &RedisLimiter{
+		evaler: evaler,
+		key:    key,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Allow reports whether an event may happen now, if redis is unavailable, it returns false
+func (rl *RedisLimiter) Allow() bool {
+	delay, _, err := rl.reserve(true)
+	if err == ErrLimitExceeded {
+		return false
+	}
+	if err != nil {
+		log.Errorf("redis rate limiter failed. key: %s. %s", rl.key, err.Error())
+		return false
+	}
+
+	return delay == 0
+}
+
+// Reserve reserves the earliest time when the window allows a new event
+func (rl *RedisLimiter) Reserve() *Reservation {
+	delay, member, err := rl.reserve(false)
+	if err != nil {
+		return newFailedReservation(err)
+	}
+
+	return newReservation(delay, func() {
+		_, err := rl.evaler.Eval(context.Background(), redisCancelScript, []string{rl.key}, member)
+		if err != nil {
+			log.Errorf("cancel redis rate limiter reservation failed. key: %s. %s", rl.key, err.Error())
+		}
+	})
+}
+
+// Wait blocks until the window allows a new event or the context is done
+func (rl *RedisLimiter) Wait(ctx context.Context) error {
+	return wait(ctx, rl)
+}
+
+// reserve runs the reserve script and returns the delay and the member of the event
+func (rl *RedisLimiter) reserve(nowOnly bool) (time.Duration, string, error) {
+	if rl.limit <= 0 {
+		return constant.ZeroInt, constant.EmptyString, ErrLimitExceeded
+	}
+
+	b := make([]byte, redisMemberRandomBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return constant.ZeroInt, constant.EmptyString, err
+	}
+	member := hex.EncodeToString(b)
+	nowOnlyArg := "0"
+	if nowOnly {
+		nowOnlyArg = "1"
+	}
+
+	result, err := rl.evaler.Eval(context.Background(), redisReserveScript, []string{rl.key},
+		time.Now().UnixNano()/int64(time.Millisecond), rl.window.Milliseconds(), rl.limit, member, nowOnlyArg)
+	if err != nil {
+		return constant.ZeroInt, constant.EmptyString, err
+	}
+	delay, ok := result.(int64)
+	if !ok {
+		return constant.ZeroInt, constant.EmptyString, errors.New(fmt.Sprintf("redis script should return an integer. actual: %T", result))
+	}
+	if delay < 0 {
+		return constant.ZeroInt, constant.EmptyString, ErrLimitExceeded
+	}
+
+	return time.Duration(delay) * time.Millisecond, member, nil
+}
@@ -0,0 +1,285 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+)
+
+const (
+	DefaultOutboxTable   = "t_outbox"
+	DefaultBatchSize     = 100
+	DefaultRelayInterval = time.Second
+
+	outboxCreateTableSQL = `create table if not exists %s (
+	id bigint unsigned not null auto_increment primary key,
+	topic varchar(200) not null,
+	message_key varchar(200) not null default '',
+	payload mediumtext not null,
+	sent tinyint not null default 0,
+	create_time datetime(6) not null default current_timestamp(6),
+	sent_time datetime(6) null,
+	key idx_sent_id (sent, id)
+) engine = innodb`
+	outboxInsertSQL = `insert into %s(topic, message_key, payload) values(?, ?, ?)`
+	outboxSelectSQL = `select id, topic, message_key, payload from %s where sent = 0 order by id limit ?`
+	outboxMarkSQL   = `update %s set sent = 1, sent_time = now(6) where id = ?`
+)
+
+// Message is the message saved in the outbox table
+type Message struct {
+	ID      int    `middleware:"id"`
+	Topic   string `middleware:"topic"`
+	Key     string `middleware:"message_key"`
+	Payload string `middleware:"payload"`
+}
+
+// NewMessage returns a new *Message
+func NewMessage(topic, key, payload string) *Message {
+	return &Message{
+		Topic:   topic,
+		Key:     key,
+		Payload: payload,
+	}
+}
+
+// Publisher publishes the outbox messages, Publish should not return until the message is acknowledged by the broker,
+// otherwise, the message may be lost
+type Publisher interface {
+	Publish(ctx context.Context, message *Message) error
+}
+
+// PublisherFunc is an adapter to allow the use of ordinary functions as Publisher
+type PublisherFunc func(ctx context.Context, message *Message) error
+
+// Publish calls f(ctx, message)
+func (f PublisherFunc) Publish(ctx context.Context, message *Message) error {
+	return f(ctx, message)
+}
+
+// NewKafkaPublisher returns a Publisher which sends the messages to kafka synchronously with given client,
+// for example, the Client of kafka.AsyncProducer,
+// the client should be configured with Producer.Return.Successes = true
+func NewKafkaPublisher(client sarama.Client) (Publisher, error) {
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return PublisherFunc(func(ctx context.Context, message *Message) error {
+		pm := &sarama.ProducerMessage{
+			Topic: message.Topic,
+			Value: sarama.StringEncoder(message.Payload),
+		}
+		if message.Key != constant.EmptyString {
+			pm.Key = sarama.StringEncoder(message.Key)
+		}
+
+		_, _, err := producer.SendMessage(pm)
+
+		return err
+	}), nil
+}
+
+// Outbox implements the transactional outbox pattern with mysql,
+// the messages are saved in the same transaction with the business data,
+// and the relay publishes them afterwards, so that the messages are delivered at least once,
+// the consumers should be idempotent, message id could be used to deduplicate.
+// only one relay should be running at the same time to keep the messages in order,
+// locker package could be used to elect the relay when there are multiple instances
+type Outbox struct {
+	pool      middleware.Pool
+	publisher Publisher
+	table     string
+	batchSize int
+
+	mutex    sync.Mutex
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewOutbox returns a new *Outbox, if table is empty, it will use DefaultOutboxTable
+func NewOutbox(pool middleware.Pool, publisher Publisher, table string) *Outbox {
+	if table == constant.EmptyString {
+		table = DefaultOutboxTable
+	}
+
+	return &Outbox{
+		pool:      pool,
+		publisher: publisher,
+		table:     table,
+		batchSize: DefaultBatchSize,
+	}
+}
+
+// SetBatchSize sets the max number of messages published by each Relay()
+func (o *Outbox) SetBatchSize(batchSize int) {
+	if batchSize > constant.ZeroInt {
+		o.batchSize = batchSize
+	}
+}
+
+// CreateTable creates the outbox table if it does not exist
+func (o *Outbox) CreateTable(ctx context.Context) error {
+	conn, err := o.pool.Get()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.ExecuteContext(ctx, fmt.Sprintf(outboxCreateTableSQL, o.table))
+
+	return err
+}
+
+// Execute runs fn and saves the messages to the outbox table in the same transaction,
+// if fn returns error, the transaction will be rolled back and the messages will not be published
+func (o *Outbox) Execute(ctx context.Context, fn func(tx middleware.Transaction) error, messages ...*Message) (err error) {
+	tx, err := o.pool.Transaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := tx.Close()
+		if closeErr != nil {
+			log.Errorf("close transaction connection failed. %s", closeErr.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				err = multierror.Append(err, rollbackErr)
+			}
+		}
+	}()
+
+	if fn != nil {
+		err = fn(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, message := range messages {
+		_, err = tx.ExecuteContext(ctx, fmt.Sprintf(outboxInsertSQL, o.table), message.Topic, message.Key, message.Payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Relay publishes a batch of unsent messages in order and marks them as sent, it returns the number of published messages,
+// if publishing a message failed, it stops and the message will be retried by the next relay
+func (o *Outbox) Relay(ctx context.Context) (int, error) {
+	conn, err := o.pool.Get()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	result, err := conn.ExecuteContext(ctx, fmt.Sprintf(outboxSelectSQL, o.table), o.batchSize)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	messages := make([]*Message, result.RowNumber())
+	for i := range messages {
+		messages[i] = &Message{}
+	}
+	err = result.MapToStructSlice(messages, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	for i, message := range messages {
+		err = o.publisher.Publish(ctx, message)
+		if err != nil {
+			return i, errors.New(fmt.Sprintf("publish outbox message failed. id: %d, topic: %s. %s", message.ID, message.Topic, err.Error()))
+		}
+		// if marking failed, the message will be published again, which is allowed by at-least-once delivery
+		_, err = conn.ExecuteContext(ctx, fmt.Sprintf(outboxMarkSQL, o.table), message.ID)
+		if err != nil {
+			return i + 1, err
+		}
+	}
+
+	return len(messages), nil
+}
+
+// Start runs Relay() in the background with given interval, if a batch is full, the next relay runs immediately
+func (o *Outbox) Start(interval time.Duration) {
+	if interval <= constant.ZeroInt {
+		interval = DefaultRelayInterval
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.stopChan != nil {
+		return
+	}
+	o.stopChan = make(chan struct{})
+	o.doneChan = make(chan struct{})
+
+	go o.loop(interval, o.stopChan, o.doneChan)
+}
+
+// Stop stops the background relay and waits for the running relay to finish
+func (o *Outbox) Stop() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.stopChan == nil {
+		return
+	}
+	close(o.stopChan)
+	<-o.doneChan
+	o.stopChan = nil
+	o.doneChan = nil
+}
+
+// loop runs Relay() until stopChan is closed
+func (o *Outbox) loop(interval time.Duration, stopChan, doneChan chan struct{}) {
+	defer close(doneChan)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopChan
+		cancel()
+	}()
+
+	for {
+		n, err := o.Relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("relay outbox messages failed. table: %s. %s", o.table, err.Error())
+		}
+
+		wait := interval
+		if err == nil && n == o.batchSize {
+			wait = constant.ZeroInt
+		}
+
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+)
+
+// StepFunc is the action or the compensation of a step
+type StepFunc func(ctx context.Context) error
+
+// Step is a single step of the saga, compensate undoes what action has done, it could be nil if nothing to undo
+type Step struct {
+	Name       string
+	Action     StepFunc
+	Compensate StepFunc
+}
+
+// Error is returned when a step of the saga failed
+type Error struct {
+	Saga string
+	Step string
+	// Err is the error returned by the action of the failed step
+	Err error
+	// CompensateErr holds the errors returned by the compensations, if it is not nil,
+	// the saga is partially compensated and may need manual intervention
+	CompensateErr error
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	if e.CompensateErr != nil {
+		return fmt.Sprintf("saga failed and compensation failed. saga: %s, step: %s. %s\ncompensation: %s",
+			e.Saga, e.Step, e.Err.Error(), e.CompensateErr.Error())
+	}
+
+	return fmt.Sprintf("saga failed and had been compensated. saga: %s, step: %s. %s", e.Saga, e.Step, e.Err.Error())
+}
+
+// Unwrap returns the error of the failed action
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsCompensated returns if all the executed steps had been compensated successfully
+func (e *Error) IsCompensated() bool {
+	return e.CompensateErr == nil
+}
+
+// Saga coordinates multiple steps, if any step fails,
+// the compensations of the executed steps will be run in the reverse order
+type Saga struct {
+	Name  string
+	Steps []*Step
+}
+
+// NewSaga returns a new *Saga
+func NewSaga(name string) *Saga {
+	return &Saga{Name: name}
+}
+
+// AddStep appends a step to the saga and returns the saga itself, so that it could be chained
+func (s *Saga) AddStep(name string, action, compensate StepFunc) *Saga {
+	s.Steps = append(s.Steps, &Step{
+		Name:       name,
+		Action:     action,
+		Compensate: compensate,
+	})
+
+	return s
+}
+
+// Execute runs the steps in order, if a step fails or the context is done,
+// it compensates the executed steps in the reverse order and returns *Error.
+// note that the compensations run with a context which will not be cancelled,
+// because an interrupted compensation leaves the data inconsistent
+func (s *Saga) Execute(ctx context.Context) error {
+	for i, step := range s.Steps {
+		err := ctx.Err()
+		if err == nil {
+			if step.Action == nil {
+				err = errors.New("action of the step should not be nil")
+			} else {
+				err = step.Action(ctx)
+			}
+		}
+		if err != nil {
+			return &Error{
+				Saga:          s.Name,
+				Step:          step.Name,
+				Err:           err,
+				CompensateErr: s.compensate(i - 1),
+			}
+		}
+	}
+
+	return nil
+}
+
+// compensate runs the compensations from the step of given index to the first step
+func (s *Saga) compensate(index int) error {
+	var merr error
+	for i := index; i >= 0; i-- {
+		step := s.Steps[i]
+		if step.Compensate == nil {
+			continue
+		}
+
+		err := step.Compensate(context.Background())
+		if err != nil {
+			log.Errorf("compensate saga step failed. saga: %s, step: %s. %s", s.Name, step.Name, err.Error())
+			merr = multierror.Append(merr, errors.New(fmt.Sprintf("step: %s. %s", step.Name, err.Error())))
+		}
+	}
+
+	return merr
+}
@@ -0,0 +1,61 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaga_Execute(t *testing.T) {
+	asst := assert.New(t)
+
+	var executed []string
+	step := func(name string, err error) StepFunc {
+		return func(ctx context.Context) error {
+			executed = append(executed, name)
+			return err
+		}
+	}
+
+	s := NewSaga("test").
+		AddStep("insert", step("insert", nil), step("delete", nil)).
+		AddStep("publish", step("publish", nil), nil)
+	asst.Nil(s.Execute(context.Background()), "test Execute() failed")
+	asst.Equal([]string{"insert", "publish"}, executed, "test Execute() failed")
+
+	// the executed steps will be compensated in the reverse order
+	executed = nil
+	s = NewSaga("test").
+		AddStep("insert", step("insert", nil), step("delete", nil)).
+		AddStep("update", step("update", nil), step("restore", nil)).
+		AddStep("publish", step("publish", errors.New("test")), step("unpublish", nil))
+	err := s.Execute(context.Background())
+	asst.NotNil(err, "test Execute() failed")
+	asst.Equal([]string{"insert", "update", "publish", "restore", "delete"}, executed, "test Execute() failed")
+	sagaErr, ok := err.(*Error)
+	asst.True(ok, "test Execute() failed")
+	asst.Equal("publish", sagaErr.Step, "test Execute() failed")
+	asst.True(sagaErr.IsCompensated(), "test Execute() failed")
+
+	// compensation continues even if some of them failed
+	executed = nil
+	s = NewSaga("test").
+		AddStep("insert", step("insert", nil), step("delete", nil)).
+		AddStep("update", step("update", nil), step("restore", errors.New("test"))).
+		AddStep("publish", step("publish", errors.New("test")), nil)
+	err = s.Execute(context.Background())
+	asst.Equal([]string{"insert", "update", "publish", "restore", "delete"}, executed, "test Execute() failed")
+	asst.False(err.(*Error).IsCompensated(), "test Execute() failed")
+
+	// cancelled context stops the saga
+	executed = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	s = NewSaga("test").
+		AddStep("insert", func(ctx context.Context) error { cancel(); executed = append(executed, "insert"); return nil }, step("delete", nil)).
+		AddStep("publish", step("publish", nil), nil)
+	err = s.Execute(ctx)
+	asst.True(errors.Is(err, context.Canceled), "test Execute() failed")
+	asst.Equal([]string{"insert", "delete"}, executed, "test Execute() failed")
+}
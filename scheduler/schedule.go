@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	cronFieldNum = 5
+	// maxSearchYears limits the search of the next time, so that the impossible schedule such as 0 0 30 2 * returns
+	maxSearchYears = 5
+
+	cronStarString = "*"
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Schedule returns the next activation time which is later than given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a Schedule which activates once every interval, if interval is not positive, it will use 1 second
+func Every(interval time.Duration) Schedule {
+	if interval <= constant.ZeroInt {
+		interval = time.Second
+	}
+
+	return &intervalSchedule{interval: interval}
+}
+
+// Next returns the next activation time
+func (is *intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(is.interval)
+}
+
+// cronSchedule is the standard 5-field cron schedule, each field is a bit set of the allowed values
+type cronSchedule struct {
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// ParseCron parses the standard cron expression: minute hour day-of-month month day-of-week,
+// each field supports *, a, a-b, */n, a-b/n and the comma separated list of them, sunday is 0 and 7 is also accepted,
+// the descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly and @every <duration> are supported,
+// the time is evaluated in the location of the time passed to Next()
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("parse cron expression failed. spec: %s. %s", spec, err.Error()))
+		}
+
+		return Every(interval), nil
+	}
+	descriptor, ok := cronDescriptors[spec]
+	if ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != cronFieldNum {
+		return nil, errors.New(fmt.Sprintf("cron expression should have %d fields. spec: %s", cronFieldNum, spec))
+	}
+
+	bits := make([]uint64, cronFieldNum)
+	for i, field := range fields {
+		// 7 is also sunday
+		max := cronFields[i].max
+		if i == cronFieldNum-1 {
+			max = 7
+		}
+		b, err := parseCronField(field, cronFields[i].min, max)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("parse %s field of cron expression failed. spec: %s. %s", cronFields[i].name, spec, err.Error()))
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], cronStarString),
+		dowStar: strings.HasPrefix(fields[4], cronStarString),
+	}, nil
+}
+
+// parseCronField parses a field to a bit set
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, constant.CommaString) {
+		step := 1
+		rangeStr := part
+		if i := strings.Index(part, constant.SlashString); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.New(fmt.Sprintf("invalid step. part: %s", part))
+			}
+			rangeStr = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case rangeStr == cronStarString:
+		case strings.Contains(rangeStr, constant.DashString):
+			bounds := strings.SplitN(rangeStr, constant.DashString, 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, errors.New(fmt.Sprintf("invalid range. part: %s", part))
+			}
+			end, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				return 0, errors.New(fmt.Sprintf("invalid range. part: %s", part))
+			}
+		default:
+			value, err := strconv.Atoi(rangeStr)
+			if err != nil {
+				return 0, errors.New(fmt.Sprintf("invalid value. part: %s", part))
+			}
+			start = value
+			// a/n means from a to max with step n
+			if step == 1 {
+				end = value
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, errors.New(fmt.Sprintf("value out of range [%d, %d]. part: %s", min, max, part))
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the next activation time which is later than t
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if cs.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !cs.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if cs.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if cs.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay checks the day of month and the day of week, if both of them are restricted,
+// the day matches when either of them matches, which is the same as the standard cron
+func (cs *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := cs.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) != 0
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
@@ -0,0 +1,352 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/locker"
+)
+
+const (
+	DefaultLeaderTTL = 15 * time.Second
+)
+
+const (
+	// OverlapSkip skips the activation if the previous run of the job is still running, it is the default policy
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the job again right after the previous run finished, at most one activation is queued
+	OverlapQueue
+)
+
+// OverlapPolicy decides what to do when the job is activated while the previous run is still running
+type OverlapPolicy int
+
+// JobFunc is the function of the job, it should return once the context is done
+type JobFunc func(ctx context.Context) error
+
+// JobOption configures the job
+type JobOption func(job *job)
+
+// WithTimeout sets the timeout of each run, the context passed to the job will be cancelled after timeout
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = timeout
+	}
+}
+
+// WithJitter delays each activation by a random duration in [0, jitter), so that the jobs of multiple instances spread out
+func WithJitter(jitter time.Duration) JobOption {
+	return func(j *job) {
+		j.jitter = jitter
+	}
+}
+
+// WithOverlapPolicy sets the overlap policy of the job
+func WithOverlapPolicy(policy OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = policy
+	}
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       JobFunc
+	timeout  time.Duration
+	jitter   time.Duration
+	overlap  OverlapPolicy
+
+	mutex   sync.Mutex
+	running bool
+	pending bool
+	stop    chan struct{}
+}
+
+// Scheduler runs the jobs by their schedules
+type Scheduler struct {
+	mutex   sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	wg      sync.WaitGroup
+
+	locker    locker.Locker
+	leaderKey string
+	leaderTTL time.Duration
+	isLeader  bool
+}
+
+// NewScheduler returns a new *Scheduler
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		jobs:   make(map[string]*job),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// SetLeaderElection enables the leader election with the distributed lock, only the leader instance runs the jobs,
+// the leader holds the lock of given key and renews it every ttl/3, if ttl is not positive, DefaultLeaderTTL will be used,
+// it must be called before Start()
+func (s *Scheduler) SetLeaderElection(l locker.Locker, key string, ttl time.Duration) {
+	if ttl <= constant.ZeroInt {
+		ttl = DefaultLeaderTTL
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.locker = l
+	s.leaderKey = key
+	s.leaderTTL = ttl
+}
+
+// IsLeader returns if the jobs could be run by this instance, it is always true if leader election is disabled
+func (s *Scheduler) IsLeader() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.locker == nil || s.isLeader
+}
+
+// AddCronJob adds a job which runs by the cron expression
+func (s *Scheduler) AddCronJob(name, spec string, fn JobFunc, opts ...JobOption) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	return s.AddJob(name, schedule, fn, opts...)
+}
+
+// AddIntervalJob adds a job which runs once every interval
+func (s *Scheduler) AddIntervalJob(name string, interval time.Duration, fn JobFunc, opts ...JobOption) error {
+	return s.AddJob(name, Every(interval), fn, opts...)
+}
+
+// AddJob adds a job with given schedule, if the scheduler had been started, the job starts immediately
+func (s *Scheduler) AddJob(name string, schedule Schedule, fn JobFunc, opts ...JobOption) error {
+	j := &job{
+		name:     name,
+		schedule: schedule,
+		fn:       fn,
+		overlap:  OverlapSkip,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.jobs[name]
+	if ok {
+		return errors.New(fmt.Sprintf("job already exists. name: %s", name))
+	}
+	s.jobs[name] = j
+	if s.started {
+		s.startJob(j)
+	}
+
+	return nil
+}
+
+// RemoveJob removes the job, the running job will not be interrupted
+func (s *Scheduler) RemoveJob(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, ok := s.jobs[name]
+	if ok {
+		close(j.stop)
+		delete(s.jobs, name)
+	}
+}
+
+// Start starts the scheduler
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	if s.locker != nil {
+		s.wg.Add(1)
+		go s.elect()
+	}
+	for _, j := range s.jobs {
+		s.startJob(j)
+	}
+}
+
+// Stop stops the scheduler, cancels the contexts of the running jobs and waits for them to return,
+// the scheduler could not be started again
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// startJob starts the loop of the job, it must be called with lock held
+func (s *Scheduler) startJob(j *job) {
+	s.wg.Add(1)
+	go s.loop(j)
+}
+
+// loop waits for the activations of the job
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	for {
+		now := time.Now()
+		next := j.schedule.Next(now)
+		if next.IsZero() {
+			log.Warnf("job will never be activated. name: %s", j.name)
+			return
+		}
+		if j.jitter > constant.ZeroInt {
+			next = next.Add(time.Duration(rand.Int63n(int64(j.jitter))))
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-j.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.activate(j)
+		}
+	}
+}
+
+// activate runs the job according to the overlap policy
+func (s *Scheduler) activate(j *job) {
+	if !s.IsLeader() {
+		log.Debugf("not the leader, skip the job. name: %s", j.name)
+		return
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.running {
+		if j.overlap == OverlapQueue {
+			j.pending = true
+			return
+		}
+		log.Warnf("previous run of the job is still running, skip this activation. name: %s", j.name)
+		return
+	}
+	j.running = true
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			s.run(j)
+
+			j.mutex.Lock()
+			if !j.pending || s.ctx.Err() != nil {
+				j.running = false
+				j.pending = false
+				j.mutex.Unlock()
+				return
+			}
+			j.pending = false
+			j.mutex.Unlock()
+		}
+	}()
+}
+
+// run runs the job once with timeout and recovers from the panic
+func (s *Scheduler) run(j *job) {
+	ctx := s.ctx
+	if j.timeout > constant.ZeroInt {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		r := recover()
+		if r != nil {
+			log.Errorf("job panicked. name: %s, panic: %v\n%s", j.name, r, string(debug.Stack()))
+		}
+	}()
+
+	err := j.fn(ctx)
+	if err != nil {
+		log.Errorf("job failed. name: %s, duration: %s. %s", j.name, time.Since(start).String(), err.Error())
+		return
+	}
+	log.Debugf("job completed. name: %s, duration: %s", j.name, time.Since(start).String())
+}
+
+// elect tries to acquire the leader lock and keeps renewing it until the scheduler stops
+func (s *Scheduler) elect() {
+	defer s.wg.Done()
+
+	interval := s.leaderTTL / 3
+	var lock locker.Lock
+	for {
+		var err error
+		if lock == nil {
+			lock, err = s.locker.Lock(s.ctx, s.leaderKey, s.leaderTTL)
+			if err != nil && err != locker.ErrNotAcquired {
+				log.Errorf("acquire leader lock failed. key: %s. %s", s.leaderKey, err.Error())
+			}
+			if err == nil {
+				log.Infof("became the leader of the scheduler. key: %s", s.leaderKey)
+			}
+		} else {
+			err = lock.Renew(s.ctx, s.leaderTTL)
+			if err != nil {
+				log.Errorf("renew leader lock failed, step down. key: %s. %s", s.leaderKey, err.Error())
+				lock = nil
+			}
+		}
+		s.setLeader(lock != nil)
+
+		select {
+		case <-s.ctx.Done():
+			if lock != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				err = lock.Unlock(ctx)
+				cancel()
+				if err != nil {
+					log.Errorf("release leader lock failed. key: %s. %s", s.leaderKey, err.Error())
+				}
+			}
+			s.setLeader(false)
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// setLeader sets if this instance is the leader
+func (s *Scheduler) setLeader(isLeader bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.isLeader = isLeader
+}
@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/go-util/locker"
+)
+
+func TestParseCron(t *testing.T) {
+	asst := assert.New(t)
+
+	base := time.Date(2021, 3, 15, 10, 30, 20, 0, time.UTC) // monday
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2021, 3, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2021, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2021, 3, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 20 * 3", time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"5,10 0 29 2 *", time.Date(2024, 2, 29, 0, 5, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", base.Add(time.Hour)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseCron(c.spec)
+		asst.Nil(err, "test ParseCron() failed. spec: %s", c.spec)
+		asst.Equal(c.expected, schedule.Next(base), "test Next() failed. spec: %s", c.spec)
+	}
+
+	schedule, err := ParseCron("0 0 30 2 *")
+	asst.Nil(err, "test ParseCron() failed")
+	asst.True(schedule.Next(base).IsZero(), "test Next() failed")
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *", "@every x"} {
+		_, err = ParseCron(spec)
+		asst.NotNil(err, "test ParseCron() failed. spec: %s", spec)
+	}
+}
+
+func TestScheduler_AddIntervalJob(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewScheduler()
+	var runs int32
+	err := s.AddIntervalJob("count", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	asst.Nil(err, "test AddIntervalJob() failed")
+	err = s.AddIntervalJob("count", time.Second, func(ctx context.Context) error { return nil })
+	asst.NotNil(err, "test AddIntervalJob() failed")
+	// panic should be recovered and the job keeps running
+	var panics int32
+	err = s.AddIntervalJob("panic", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&panics, 1)
+		panic("test")
+	})
+	asst.Nil(err, "test AddIntervalJob() failed")
+
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+	s.Stop()
+	asst.True(atomic.LoadInt32(&runs) >= 3, "test AddIntervalJob() failed")
+	asst.True(atomic.LoadInt32(&panics) >= 3, "test AddIntervalJob() failed")
+}
+
+func TestScheduler_OverlapPolicy(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewScheduler()
+	var skipRuns, queueRuns int32
+	slow := func(counter *int32) JobFunc {
+		return func(ctx context.Context) error {
+			atomic.AddInt32(counter, 1)
+			time.Sleep(35 * time.Millisecond)
+			return nil
+		}
+	}
+	asst.Nil(s.AddIntervalJob("skip", 10*time.Millisecond, slow(&skipRuns)), "test WithOverlapPolicy() failed")
+	asst.Nil(s.AddIntervalJob("queue", 10*time.Millisecond, slow(&queueRuns), WithOverlapPolicy(OverlapQueue)),
+		"test WithOverlapPolicy() failed")
+
+	s.Start()
+	time.Sleep(120 * time.Millisecond)
+	s.Stop()
+	// the queued activation runs right after the previous run, so queue policy runs more times than skip policy
+	asst.True(atomic.LoadInt32(&skipRuns) <= 3, "test WithOverlapPolicy() failed")
+	asst.True(atomic.LoadInt32(&queueRuns) > atomic.LoadInt32(&skipRuns), "test WithOverlapPolicy() failed")
+}
+
+func TestScheduler_WithTimeout(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewScheduler()
+	done := make(chan error, 1)
+	err := s.AddIntervalJob("timeout", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		select {
+		case done <- ctx.Err():
+		default:
+		}
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+	asst.Nil(err, "test WithTimeout() failed")
+
+	s.Start()
+	defer s.Stop()
+	select {
+	case err = <-done:
+		asst.Equal(context.DeadlineExceeded, err, "test WithTimeout() failed")
+	case <-time.After(time.Second):
+		asst.Fail("test WithTimeout() failed")
+	}
+}
+
+type testLocker struct {
+	mutex sync.Mutex
+	owner *testLock
+}
+
+type testLock struct {
+	locker *testLocker
+	key    string
+}
+
+func (tl *testLocker) Lock(ctx context.Context, key string, ttl time.Duration) (locker.Lock, error) {
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+
+	if tl.owner != nil {
+		return nil, locker.ErrNotAcquired
+	}
+	tl.owner = &testLock{locker: tl, key: key}
+
+	return tl.owner, nil
+}
+
+func (l *testLock) Key() string {
+	return l.key
+}
+
+func (l *testLock) Token() int64 {
+	return 1
+}
+
+func (l *testLock) Renew(ctx context.Context, ttl time.Duration) error {
+	l.locker.mutex.Lock()
+	defer l.locker.mutex.Unlock()
+
+	if l.locker.owner != l {
+		return locker.ErrLockLost
+	}
+
+	return nil
+}
+
+func (l *testLock) Unlock(ctx context.Context) error {
+	l.locker.mutex.Lock()
+	defer l.locker.mutex.Unlock()
+
+	if l.locker.owner != l {
+		return locker.ErrLockLost
+	}
+	l.locker.owner = nil
+
+	return nil
+}
+
+func TestScheduler_SetLeaderElection(t *testing.T) {
+	asst := assert.New(t)
+
+	l := &testLocker{}
+	var runs1, runs2 int32
+	s1 := NewScheduler()
+	s1.SetLeaderElection(l, "scheduler", 30*time.Millisecond)
+	asst.Nil(s1.AddIntervalJob("job", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs1, 1)
+		return nil
+	}), "test SetLeaderElection() failed")
+	s2 := NewScheduler()
+	s2.SetLeaderElection(l, "scheduler", 30*time.Millisecond)
+	asst.Nil(s2.AddIntervalJob("job", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs2, 1)
+		return nil
+	}), "test SetLeaderElection() failed")
+
+	s1.Start()
+	time.Sleep(20 * time.Millisecond)
+	s2.Start()
+	time.Sleep(80 * time.Millisecond)
+	asst.True(s1.IsLeader(), "test SetLeaderElection() failed")
+	asst.False(s2.IsLeader(), "test SetLeaderElection() failed")
+	asst.True(atomic.LoadInt32(&runs1) > 0, "test SetLeaderElection() failed")
+	asst.Equal(int32(0), atomic.LoadInt32(&runs2), "test SetLeaderElection() failed")
+
+	// s2 takes over after s1 stopped
+	s1.Stop()
+	time.Sleep(80 * time.Millisecond)
+	s2.Stop()
+	asst.True(atomic.LoadInt32(&runs2) > 0, "test SetLeaderElection() failed")
+}
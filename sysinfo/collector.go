@@ -0,0 +1,157 @@
+package sysinfo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/romberli/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const DefaultCollectInterval = 15 * time.Second
+
+// Collector collects the host metrics periodically, and calculates the rates of the disk io and the network
+type Collector struct {
+	interval time.Duration
+	paths    []string
+
+	mutex  sync.RWMutex
+	latest *HostStat
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCollector returns a new *Collector, paths are the disk paths of which usages will be collected,
+// if interval is not positive, DefaultCollectInterval will be used
+func NewCollector(interval time.Duration, paths ...string) *Collector {
+	if interval <= constant.ZeroInt {
+		interval = DefaultCollectInterval
+	}
+
+	return &Collector{
+		interval: interval,
+		paths:    paths,
+	}
+}
+
+// Latest returns the latest host stat, it returns nil if nothing had been collected
+func (c *Collector) Latest() *HostStat {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.latest
+}
+
+// Collect collects the host metrics once, the rates are calculated with the previous collection
+func (c *Collector) Collect(ctx context.Context) (*HostStat, error) {
+	stat, err := GetHostStat(ctx, c.paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.latest != nil {
+		calculateRates(c.latest, stat)
+	}
+	c.latest = stat
+
+	return stat, nil
+}
+
+// Start starts collecting periodically, it collects once immediately
+func (c *Collector) Start() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.cancel != nil {
+		return
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	c.wg.Add(1)
+	go c.loop(c.ctx)
+}
+
+// Stop stops collecting, the latest stat is kept
+func (c *Collector) Stop() {
+	c.mutex.Lock()
+	cancel := c.cancel
+	c.cancel = nil
+	c.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		c.wg.Wait()
+	}
+}
+
+// loop collects the host metrics until the context is done
+func (c *Collector) loop(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := c.Collect(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("collect host metrics failed. %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// calculateRates calculates the rates of current stat with the previous stat
+func calculateRates(prev, curr *HostStat) {
+	seconds := curr.Time.Sub(prev.Time).Seconds()
+	if seconds <= constant.ZeroInt {
+		return
+	}
+
+	prevDiskIOs := make(map[string]*DiskIOStat, len(prev.DiskIOs))
+	for _, d := range prev.DiskIOs {
+		prevDiskIOs[d.Name] = d
+	}
+	for _, d := range curr.DiskIOs {
+		p, ok := prevDiskIOs[d.Name]
+		if !ok {
+			continue
+		}
+		d.ReadCountPerSec = rate(p.ReadCount, d.ReadCount, seconds)
+		d.WriteCountPerSec = rate(p.WriteCount, d.WriteCount, seconds)
+		d.ReadBytesPerSec = rate(p.ReadBytes, d.ReadBytes, seconds)
+		d.WriteBytesPerSec = rate(p.WriteBytes, d.WriteBytes, seconds)
+	}
+
+	prevNetworks := make(map[string]*NetworkStat, len(prev.Networks))
+	for _, n := range prev.Networks {
+		prevNetworks[n.Name] = n
+	}
+	for _, n := range curr.Networks {
+		p, ok := prevNetworks[n.Name]
+		if !ok {
+			continue
+		}
+		n.BytesSentPerSec = rate(p.BytesSent, n.BytesSent, seconds)
+		n.BytesRecvPerSec = rate(p.BytesRecv, n.BytesRecv, seconds)
+	}
+}
+
+// rate returns the increase per second, the counter may be reset, in this case, it returns 0
+func rate(prev, curr uint64, seconds float64) float64 {
+	if curr < prev {
+		return constant.ZeroInt
+	}
+
+	return float64(curr-prev) / seconds
+}
@@ -0,0 +1,117 @@
+package sysinfo
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/romberli/go-util/metrics"
+)
+
+const hostSubsystem = "host"
+
+var _ prometheus.Collector = (*Exporter)(nil)
+
+// Exporter exports the latest host stat of the collector as the prometheus metrics,
+// the collector should be started, otherwise, nothing will be exported
+type Exporter struct {
+	collector *Collector
+
+	cpuCount         *prometheus.Desc
+	cpuUsage         *prometheus.Desc
+	memoryTotal      *prometheus.Desc
+	memoryAvailable  *prometheus.Desc
+	memoryUsed       *prometheus.Desc
+	swapTotal        *prometheus.Desc
+	swapUsed         *prometheus.Desc
+	load             *prometheus.Desc
+	diskTotal        *prometheus.Desc
+	diskUsed         *prometheus.Desc
+	diskReadBytes    *prometheus.Desc
+	diskWriteBytes   *prometheus.Desc
+	diskReadCount    *prometheus.Desc
+	diskWriteCount   *prometheus.Desc
+	networkSentBytes *prometheus.Desc
+	networkRecvBytes *prometheus.Desc
+}
+
+// NewExporter returns a new *Exporter
+func NewExporter(collector *Collector) *Exporter {
+	return &Exporter{
+		collector:        collector,
+		cpuCount:         newDesc("cpu_count", "number of logical cpus"),
+		cpuUsage:         newDesc("cpu_usage_percent", "cpu usage percent"),
+		memoryTotal:      newDesc("memory_total_bytes", "total memory in bytes"),
+		memoryAvailable:  newDesc("memory_available_bytes", "available memory in bytes"),
+		memoryUsed:       newDesc("memory_used_bytes", "used memory in bytes"),
+		swapTotal:        newDesc("swap_total_bytes", "total swap in bytes"),
+		swapUsed:         newDesc("swap_used_bytes", "used swap in bytes"),
+		load:             newDesc("load_average", "load average", "period"),
+		diskTotal:        newDesc("disk_total_bytes", "total disk space in bytes", "path", "device"),
+		diskUsed:         newDesc("disk_used_bytes", "used disk space in bytes", "path", "device"),
+		diskReadBytes:    newDesc("disk_read_bytes_total", "bytes read from the device", "device"),
+		diskWriteBytes:   newDesc("disk_write_bytes_total", "bytes written to the device", "device"),
+		diskReadCount:    newDesc("disk_reads_total", "reads completed on the device", "device"),
+		diskWriteCount:   newDesc("disk_writes_total", "writes completed on the device", "device"),
+		networkSentBytes: newDesc("network_sent_bytes_total", "bytes sent by the interface", "interface"),
+		networkRecvBytes: newDesc("network_received_bytes_total", "bytes received by the interface", "interface"),
+	}
+}
+
+// newDesc returns a new *prometheus.Desc of the host metric
+func newDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, hostSubsystem, name), help, labels, nil)
+}
+
+// Register registers the exporter to the registerer
+func (e *Exporter) Register(registerer prometheus.Registerer) error {
+	return registerer.Register(e)
+}
+
+// Describe implements prometheus.Collector interface
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		e.cpuCount, e.cpuUsage, e.memoryTotal, e.memoryAvailable, e.memoryUsed, e.swapTotal, e.swapUsed, e.load,
+		e.diskTotal, e.diskUsed, e.diskReadBytes, e.diskWriteBytes, e.diskReadCount, e.diskWriteCount,
+		e.networkSentBytes, e.networkRecvBytes,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector interface
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	stat := e.collector.Latest()
+	if stat == nil {
+		return
+	}
+
+	if stat.CPU != nil {
+		ch <- prometheus.MustNewConstMetric(e.cpuCount, prometheus.GaugeValue, float64(stat.CPU.Count))
+		ch <- prometheus.MustNewConstMetric(e.cpuUsage, prometheus.GaugeValue, stat.CPU.UsagePercent)
+	}
+	if stat.Memory != nil {
+		ch <- prometheus.MustNewConstMetric(e.memoryTotal, prometheus.GaugeValue, float64(stat.Memory.Total))
+		ch <- prometheus.MustNewConstMetric(e.memoryAvailable, prometheus.GaugeValue, float64(stat.Memory.Available))
+		ch <- prometheus.MustNewConstMetric(e.memoryUsed, prometheus.GaugeValue, float64(stat.Memory.Used))
+		ch <- prometheus.MustNewConstMetric(e.swapTotal, prometheus.GaugeValue, float64(stat.Memory.SwapTotal))
+		ch <- prometheus.MustNewConstMetric(e.swapUsed, prometheus.GaugeValue, float64(stat.Memory.SwapUsed))
+	}
+	if stat.Load != nil {
+		ch <- prometheus.MustNewConstMetric(e.load, prometheus.GaugeValue, stat.Load.Load1, "1m")
+		ch <- prometheus.MustNewConstMetric(e.load, prometheus.GaugeValue, stat.Load.Load5, "5m")
+		ch <- prometheus.MustNewConstMetric(e.load, prometheus.GaugeValue, stat.Load.Load15, "15m")
+	}
+	for _, d := range stat.Disks {
+		ch <- prometheus.MustNewConstMetric(e.diskTotal, prometheus.GaugeValue, float64(d.Total), d.Path, d.Device)
+		ch <- prometheus.MustNewConstMetric(e.diskUsed, prometheus.GaugeValue, float64(d.Used), d.Path, d.Device)
+	}
+	for _, d := range stat.DiskIOs {
+		ch <- prometheus.MustNewConstMetric(e.diskReadBytes, prometheus.CounterValue, float64(d.ReadBytes), d.Name)
+		ch <- prometheus.MustNewConstMetric(e.diskWriteBytes, prometheus.CounterValue, float64(d.WriteBytes), d.Name)
+		ch <- prometheus.MustNewConstMetric(e.diskReadCount, prometheus.CounterValue, float64(d.ReadCount), d.Name)
+		ch <- prometheus.MustNewConstMetric(e.diskWriteCount, prometheus.CounterValue, float64(d.WriteCount), d.Name)
+	}
+	for _, n := range stat.Networks {
+		ch <- prometheus.MustNewConstMetric(e.networkSentBytes, prometheus.CounterValue, float64(n.BytesSent), n.Name)
+		ch <- prometheus.MustNewConstMetric(e.networkRecvBytes, prometheus.CounterValue, float64(n.BytesRecv), n.Name)
+	}
+}
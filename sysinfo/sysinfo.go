@@ -0,0 +1,255 @@
+package sysinfo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const loopbackInterface = "lo"
+
+type CPUStat struct {
+	Count int
+	// UsagePercent is the cpu usage since the last call, the first call returns the usage since boot
+	UsagePercent float64
+}
+
+type MemoryStat struct {
+	Total       uint64
+	Available   uint64
+	Used        uint64
+	UsedPercent float64
+	SwapTotal   uint64
+	SwapUsed    uint64
+}
+
+type DiskStat struct {
+	Path        string
+	Device      string
+	FSType      string
+	Total       uint64
+	Used        uint64
+	Free        uint64
+	UsedPercent float64
+}
+
+type DiskIOStat struct {
+	Name       string
+	ReadCount  uint64
+	WriteCount uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+	// the rates are calculated by the Collector, they are 0 if the stat is not got from the Collector
+	ReadCountPerSec  float64
+	WriteCountPerSec float64
+	ReadBytesPerSec  float64
+	WriteBytesPerSec float64
+}
+
+type NetworkStat struct {
+	Name        string
+	BytesSent   uint64
+	BytesRecv   uint64
+	PacketsSent uint64
+	PacketsRecv uint64
+	// the rates are calculated by the Collector, they are 0 if the stat is not got from the Collector
+	BytesSentPerSec float64
+	BytesRecvPerSec float64
+}
+
+type LoadStat struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+}
+
+// HostStat is the snapshot of the host metrics
+type HostStat struct {
+	Time     time.Time
+	CPU      *CPUStat
+	Memory   *MemoryStat
+	Load     *LoadStat
+	Disks    []*DiskStat
+	DiskIOs  []*DiskIOStat
+	Networks []*NetworkStat
+}
+
+// GetCPUStat returns the cpu stat
+func GetCPUStat(ctx context.Context) (*CPUStat, error) {
+	count, err := cpu.CountsWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	// 0 interval compares with the last call
+	percents, err := cpu.PercentWithContext(ctx, constant.ZeroInt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &CPUStat{Count: count}
+	if len(percents) > constant.ZeroInt {
+		stat.UsagePercent = percents[constant.ZeroInt]
+	}
+
+	return stat, nil
+}
+
+// GetMemoryStat returns the memory stat
+func GetMemoryStat(ctx context.Context) (*MemoryStat, error) {
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := mem.SwapMemoryWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryStat{
+		Total:       vm.Total,
+		Available:   vm.Available,
+		Used:        vm.Used,
+		UsedPercent: vm.UsedPercent,
+		SwapTotal:   swap.Total,
+		SwapUsed:    swap.Used,
+	}, nil
+}
+
+// GetLoadStat returns the load averages
+func GetLoadStat(ctx context.Context) (*LoadStat, error) {
+	avg, err := load.AvgWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoadStat{
+		Load1:  avg.Load1,
+		Load5:  avg.Load5,
+		Load15: avg.Load15,
+	}, nil
+}
+
+// GetDiskStats returns the usages of given paths, if paths is empty, it returns the usages of all the physical partitions
+func GetDiskStats(ctx context.Context, paths ...string) ([]*DiskStat, error) {
+	devices := make(map[string]string)
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, partition := range partitions {
+		devices[partition.Mountpoint] = partition.Device
+	}
+	if len(paths) == constant.ZeroInt {
+		for _, partition := range partitions {
+			paths = append(paths, partition.Mountpoint)
+		}
+	}
+
+	stats := make([]*DiskStat, constant.ZeroInt, len(paths))
+	for _, path := range paths {
+		usage, err := disk.UsageWithContext(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, &DiskStat{
+			Path:        path,
+			Device:      devices[path],
+			FSType:      usage.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+
+	return stats, nil
+}
+
+// GetDiskIOStats returns the io counters of given devices, if names is empty, it returns the io counters of all the devices
+func GetDiskIOStats(ctx context.Context, names ...string) ([]*DiskIOStat, error) {
+	counters, err := disk.IOCountersWithContext(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*DiskIOStat, constant.ZeroInt, len(counters))
+	for name, counter := range counters {
+		stats = append(stats, &DiskIOStat{
+			Name:       name,
+			ReadCount:  counter.ReadCount,
+			WriteCount: counter.WriteCount,
+			ReadBytes:  counter.ReadBytes,
+			WriteBytes: counter.WriteBytes,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+
+	return stats, nil
+}
+
+// GetNetworkStats returns the io counters of the network interfaces except the loopback one
+func GetNetworkStats(ctx context.Context) ([]*NetworkStat, error) {
+	counters, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*NetworkStat, constant.ZeroInt, len(counters))
+	for _, counter := range counters {
+		if counter.Name == loopbackInterface {
+			continue
+		}
+		stats = append(stats, &NetworkStat{
+			Name:        counter.Name,
+			BytesSent:   counter.BytesSent,
+			BytesRecv:   counter.BytesRecv,
+			PacketsSent: counter.PacketsSent,
+			PacketsRecv: counter.PacketsRecv,
+		})
+	}
+
+	return stats, nil
+}
+
+// GetHostStat returns all the host metrics, paths are the disk paths of which usages will be collected
+func GetHostStat(ctx context.Context, paths ...string) (*HostStat, error) {
+	var (
+		err  error
+		stat = &HostStat{Time: time.Now()}
+	)
+
+	stat.CPU, err = GetCPUStat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stat.Memory, err = GetMemoryStat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stat.Load, err = GetLoadStat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stat.Disks, err = GetDiskStats(ctx, paths...)
+	if err != nil {
+		return nil, err
+	}
+	stat.DiskIOs, err = GetDiskIOStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stat.Networks, err = GetNetworkStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return stat, nil
+}
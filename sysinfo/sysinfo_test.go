@@ -0,0 +1,59 @@
+package sysinfo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostStat(t *testing.T) {
+	asst := assert.New(t)
+
+	stat, err := GetHostStat(context.Background(), "/")
+	asst.Nil(err, "test GetHostStat() failed")
+	asst.True(stat.CPU.Count > 0, "test GetHostStat() failed")
+	asst.True(stat.Memory.Total > 0, "test GetHostStat() failed")
+	asst.Equal(1, len(stat.Disks), "test GetHostStat() failed")
+	asst.True(stat.Disks[0].Total > 0, "test GetHostStat() failed")
+}
+
+func TestCollector(t *testing.T) {
+	asst := assert.New(t)
+
+	c := NewCollector(20*time.Millisecond, "/")
+	asst.Nil(c.Latest(), "test Latest() failed")
+	c.Start()
+	time.Sleep(50 * time.Millisecond)
+	c.Stop()
+	asst.NotNil(c.Latest(), "test Start() failed")
+
+	registry := prometheus.NewRegistry()
+	asst.Nil(NewExporter(c).Register(registry), "test Register() failed")
+	families, err := registry.Gather()
+	asst.Nil(err, "test Collect() failed")
+	asst.True(len(families) > 0, "test Collect() failed")
+}
+
+func TestCalculateRates(t *testing.T) {
+	asst := assert.New(t)
+
+	now := time.Now()
+	prev := &HostStat{
+		Time:     now,
+		DiskIOs:  []*DiskIOStat{{Name: "sda", ReadBytes: 100}},
+		Networks: []*NetworkStat{{Name: "eth0", BytesSent: 1000, BytesRecv: 500}},
+	}
+	curr := &HostStat{
+		Time:     now.Add(2 * time.Second),
+		DiskIOs:  []*DiskIOStat{{Name: "sda", ReadBytes: 300}},
+		Networks: []*NetworkStat{{Name: "eth0", BytesSent: 3000, BytesRecv: 100}},
+	}
+	calculateRates(prev, curr)
+	asst.Equal(100.0, curr.DiskIOs[0].ReadBytesPerSec, "test calculateRates() failed")
+	asst.Equal(1000.0, curr.Networks[0].BytesSentPerSec, "test calculateRates() failed")
+	// the counter had been reset
+	asst.Equal(0.0, curr.Networks[0].BytesRecvPerSec, "test calculateRates() failed")
+}
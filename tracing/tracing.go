@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	ComponentMySQL      = "mysql"
+	ComponentPrometheus = "prometheus"
+	ComponentKafka      = "kafka"
+
+	OperationExecute = "execute"
+	OperationPublish = "publish"
+	OperationConsume = "consume"
+
+	spanNameSeparator = "."
+)
+
+var (
+	globalMutex   sync.RWMutex
+	globalTracer  opentracing.Tracer
+	globalEnabled bool
+	noopTracer    = opentracing.NoopTracer{}
+)
+
+// Enable enables tracing of the middleware clients with given tracer,
+// if tracer is nil, it will use opentracing.GlobalTracer()
+func Enable(tracer opentracing.Tracer) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	globalTracer = tracer
+	globalEnabled = true
+}
+
+// Disable disables tracing of the middleware clients, it is the default state
+func Disable() {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	globalTracer = nil
+	globalEnabled = false
+}
+
+// IsEnabled returns if tracing is enabled
+func IsEnabled() bool {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	return globalEnabled
+}
+
+// Tracer returns the tracer used by the middleware clients, if tracing is disabled, it returns a noop tracer
+func Tracer() opentracing.Tracer {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	if !globalEnabled {
+		return noopTracer
+	}
+	if globalTracer == nil {
+		return opentracing.GlobalTracer()
+	}
+
+	return globalTracer
+}
+
+// SpanName returns the span name in the form of component.operation, for example: mysql.execute
+func SpanName(component, operation string) string {
+	return component + spanNameSeparator + operation
+}
+
+// StartSpan starts a client span as a child of the span in the context,
+// the span is tagged with the component and the peer address,
+// it returns the span and a new context which holds the span
+func StartSpan(ctx context.Context, component, operation, peer string) (opentracing.Span, context.Context) {
+	return startSpan(ctx, component, operation, peer, ext.SpanKindRPCClientEnum)
+}
+
+// StartProducerSpan starts a producer span as a child of the span in the context
+func StartProducerSpan(ctx context.Context, component, peer string) (opentracing.Span, context.Context) {
+	return startSpan(ctx, component, OperationPublish, peer, ext.SpanKindProducerEnum)
+}
+
+// StartConsumerSpan starts a consumer span which follows the span extracted from the carrier,
+// if no span could be extracted, it starts a root span
+func StartConsumerSpan(ctx context.Context, component, peer string, carrier opentracing.TextMapReader) (opentracing.Span, context.Context) {
+	tracer := Tracer()
+	opts := []opentracing.StartSpanOption{ext.SpanKindConsumer, opentracing.Tag{Key: string(ext.Component), Value: component}}
+	spanContext, err := tracer.Extract(opentracing.TextMap, carrier)
+	if err == nil {
+		opts = append(opts, opentracing.FollowsFrom(spanContext))
+	}
+	span := tracer.StartSpan(SpanName(component, OperationConsume), opts...)
+	if peer != constant.EmptyString {
+		ext.PeerAddress.Set(span, peer)
+	}
+
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+// Inject injects the span context into the carrier, so that it could be propagated to the consumers
+func Inject(span opentracing.Span, carrier opentracing.TextMapWriter) error {
+	return Tracer().Inject(span.Context(), opentracing.TextMap, carrier)
+}
+
+// Finish records the error if it is not nil and finishes the span
+func Finish(span opentracing.Span, err error) {
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+	}
+
+	span.Finish()
+}
+
+// startSpan starts a span with given span kind
+func startSpan(ctx context.Context, component, operation, peer string, kind ext.SpanKindEnum) (opentracing.Span, context.Context) {
+	tracer := Tracer()
+	opts := []opentracing.StartSpanOption{
+		opentracing.Tag{Key: string(ext.SpanKind), Value: kind},
+		opentracing.Tag{Key: string(ext.Component), Value: component},
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent := opentracing.SpanFromContext(ctx)
+	if parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	span := tracer.StartSpan(SpanName(component, operation), opts...)
+	if peer != constant.EmptyString {
+		ext.PeerAddress.Set(span, peer)
+	}
+
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
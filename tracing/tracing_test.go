@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartSpan(t *testing.T) {
+	asst := assert.New(t)
+
+	tracer := mocktracer.New()
+	Enable(tracer)
+	defer Disable()
+
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	span, ctx := StartSpan(ctx, ComponentMySQL, OperationExecute, "127.0.0.1:3306")
+	asst.Equal(span, opentracing.SpanFromContext(ctx), "test StartSpan() failed")
+	Finish(span, errors.New("test"))
+
+	finished := tracer.FinishedSpans()
+	asst.Equal(1, len(finished), "test StartSpan() failed")
+	asst.Equal("mysql.execute", finished[0].OperationName, "test StartSpan() failed")
+	asst.Equal(parent.(*mocktracer.MockSpan).SpanContext.SpanID, finished[0].ParentID, "test StartSpan() failed")
+	asst.Equal("127.0.0.1:3306", finished[0].Tag(string(ext.PeerAddress)), "test StartSpan() failed")
+	asst.Equal(true, finished[0].Tag(string(ext.Error)), "test Finish() failed")
+
+	Disable()
+	span, _ = StartSpan(context.Background(), ComponentMySQL, OperationExecute, "127.0.0.1:3306")
+	Finish(span, nil)
+	asst.Equal(1, len(tracer.FinishedSpans()), "test Disable() failed")
+}
+
+func TestStartConsumerSpan(t *testing.T) {
+	asst := assert.New(t)
+
+	tracer := mocktracer.New()
+	Enable(tracer)
+	defer Disable()
+
+	producerSpan, _ := StartProducerSpan(context.Background(), ComponentKafka, "127.0.0.1:9092")
+	carrier := opentracing.TextMapCarrier{}
+	asst.Nil(Inject(producerSpan, carrier), "test Inject() failed")
+	Finish(producerSpan, nil)
+
+	consumerSpan, _ := StartConsumerSpan(context.Background(), ComponentKafka, "", carrier)
+	Finish(consumerSpan, nil)
+
+	finished := tracer.FinishedSpans()
+	asst.Equal(2, len(finished), "test StartConsumerSpan() failed")
+	asst.Equal("kafka.consume", finished[1].OperationName, "test StartConsumerSpan() failed")
+	asst.Equal(finished[0].SpanContext.TraceID, finished[1].SpanContext.TraceID, "test StartConsumerSpan() failed")
+}